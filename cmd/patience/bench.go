@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"time"
+
+	"github.com/shaneisley/patience/pkg/backoff"
+	"github.com/spf13/cobra"
+)
+
+// BenchConfig holds configuration for the bench subcommand
+type BenchConfig struct {
+	Strategy        string
+	Trials          int
+	Attempts        int
+	FailureRate     float64
+	RetryAfterRate  float64
+	RetryAfter      time.Duration
+	AttemptDuration time.Duration
+	Seed            int64
+
+	StrategyParams
+}
+
+// StrategyParams are the strategy parameters shared by the commands that build
+// a strategy by name without running anything; each strategy uses the ones
+// that apply to it
+type StrategyParams struct {
+	BaseDelay  time.Duration
+	Multiplier float64
+	Exponent   float64
+	MaxDelay   time.Duration
+	Increment  time.Duration
+	Delay      time.Duration
+	Fallback   string
+}
+
+// addStrategyParamFlags registers the flags for StrategyParams
+func addStrategyParamFlags(cmd *cobra.Command, params *StrategyParams) {
+	cmd.Flags().DurationVarP(&params.BaseDelay, "base-delay", "b", 1*time.Second, "Base delay (exponential, jitter, decorrelated-jitter, fibonacci, polynomial)")
+	cmd.Flags().Float64VarP(&params.Multiplier, "multiplier", "x", 2.0, "Multiplier (exponential, jitter, decorrelated-jitter)")
+	cmd.Flags().Float64VarP(&params.Exponent, "exponent", "e", 2.0, "Exponent (polynomial)")
+	cmd.Flags().DurationVarP(&params.MaxDelay, "max-delay", "m", 60*time.Second, "Maximum delay")
+	cmd.Flags().DurationVarP(&params.Increment, "increment", "i", 1*time.Second, "Delay increment (linear)")
+	cmd.Flags().DurationVarP(&params.Delay, "delay", "d", 1*time.Second, "Delay (fixed)")
+	cmd.Flags().StringVarP(&params.Fallback, "fallback", "f", "exponential", "Fallback strategy (http-aware)")
+}
+
+// strategyFactory returns a constructor for fresh instances of the named
+// strategy, so stateful strategies start each simulated trial clean
+func strategyFactory(name string, config StrategyParams) (func() backoff.Strategy, error) {
+	switch name {
+	case "fixed", "fix":
+		return func() backoff.Strategy { return backoff.NewFixed(config.Delay) }, nil
+	case "linear", "lin":
+		return func() backoff.Strategy { return backoff.NewLinear(config.Increment, config.MaxDelay) }, nil
+	case "exponential", "exp":
+		return func() backoff.Strategy {
+			return backoff.NewExponential(config.BaseDelay, config.Multiplier, config.MaxDelay)
+		}, nil
+	case "jitter", "jit":
+		return func() backoff.Strategy {
+			return backoff.NewJitter(config.BaseDelay, config.Multiplier, config.MaxDelay)
+		}, nil
+	case "decorrelated-jitter", "dj":
+		return func() backoff.Strategy {
+			return backoff.NewDecorrelatedJitter(config.BaseDelay, config.Multiplier, config.MaxDelay)
+		}, nil
+	case "fibonacci", "fib":
+		return func() backoff.Strategy { return backoff.NewFibonacci(config.BaseDelay, config.MaxDelay) }, nil
+	case "polynomial", "poly":
+		// Validate the parameters once so the constructor cannot fail later
+		if _, err := backoff.NewPolynomial(config.BaseDelay, config.Exponent, config.MaxDelay); err != nil {
+			return nil, err
+		}
+		return func() backoff.Strategy {
+			strategy, _ := backoff.NewPolynomial(config.BaseDelay, config.Exponent, config.MaxDelay)
+			return strategy
+		}, nil
+	case "http-aware", "ha":
+		// Only the CLI's fallback strategies qualify, which keeps http-aware
+		// from falling back to itself
+		if err := validateFallback(config.Fallback); err != nil {
+			return nil, fmt.Errorf("invalid fallback: %w", err)
+		}
+		newFallback, err := strategyFactory(config.Fallback, config)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fallback: %w", err)
+		}
+		return func() backoff.Strategy { return backoff.NewHTTPAware(newFallback(), config.MaxDelay) }, nil
+	}
+	return nil, fmt.Errorf("unknown strategy %q (supported: fixed, linear, exponential, jitter, decorrelated-jitter, fibonacci, polynomial, http-aware)", name)
+}
+
+// createBenchCommand creates the bench subcommand
+func createBenchCommand() *cobra.Command {
+	config := BenchConfig{}
+
+	cmd := &cobra.Command{
+		Use:   "bench [OPTIONS] STRATEGY",
+		Short: "Simulate a strategy against a synthetic failure model",
+		Long: `Simulate a backoff strategy against attempts that fail with a given probability,
+optionally carrying Retry-After hints, over many trials. No commands are executed
+and no time is spent waiting; the report shows how many attempts runs needed and
+percentiles of their total time.
+
+Supported strategies: fixed, linear, exponential, jitter, decorrelated-jitter,
+fibonacci, polynomial, http-aware.`,
+		Example: `  patience bench exponential --failure-rate 0.4 --attempts 6
+  patience bench http-aware --fallback exponential --retry-after-rate 0.5 --retry-after 10s`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config.Strategy = args[0]
+			return runBench(cmd.OutOrStdout(), config)
+		},
+	}
+
+	cmd.Flags().IntVar(&config.Trials, "trials", 10000, "Number of simulated runs")
+	cmd.Flags().IntVarP(&config.Attempts, "attempts", "a", 3, "Maximum attempts per run")
+	cmd.Flags().Float64Var(&config.FailureRate, "failure-rate", 0.5, "Probability that an attempt fails (0-1)")
+	cmd.Flags().Float64Var(&config.RetryAfterRate, "retry-after-rate", 0, "Probability that a failure carries a Retry-After hint (0-1)")
+	cmd.Flags().DurationVar(&config.RetryAfter, "retry-after", 5*time.Second, "Retry-After delay injected into failures")
+	cmd.Flags().DurationVar(&config.AttemptDuration, "attempt-duration", 0, "Simulated run time of each attempt")
+	cmd.Flags().Int64Var(&config.Seed, "seed", 0, "Random seed for reproducible results (0 = random)")
+	addStrategyParamFlags(cmd, &config.StrategyParams)
+
+	return cmd
+}
+
+// runBench simulates the configured strategy and writes the report to w
+func runBench(w io.Writer, config BenchConfig) error {
+	newStrategy, err := strategyFactory(config.Strategy, config.StrategyParams)
+	if err != nil {
+		return err
+	}
+
+	seed := config.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	result, err := backoff.Simulate(newStrategy, backoff.SimulationConfig{
+		Trials:          config.Trials,
+		MaxAttempts:     config.Attempts,
+		FailureRate:     config.FailureRate,
+		RetryAfterRate:  config.RetryAfterRate,
+		RetryAfter:      config.RetryAfter,
+		AttemptDuration: config.AttemptDuration,
+		Rand:            rand.New(rand.NewSource(seed)),
+	})
+	if err != nil {
+		return err
+	}
+
+	printBenchReport(w, config, result)
+	return nil
+}
+
+// printBenchReport writes the attempts-to-success distribution and total time percentiles
+func printBenchReport(w io.Writer, config BenchConfig, result *backoff.SimulationResult) {
+	fmt.Fprintf(w, "Strategy: %s, %d trials, %d attempt(s), failure rate %.2f\n",
+		config.Strategy, result.Trials, config.Attempts, config.FailureRate)
+	fmt.Fprintf(w, "Success rate: %.1f%% (%d/%d)\n", result.SuccessRate()*100, result.Successes, result.Trials)
+
+	fmt.Fprintln(w, "Attempts to success:")
+	for attempt := 1; attempt < len(result.AttemptsToSuccess); attempt++ {
+		count := result.AttemptsToSuccess[attempt]
+		fmt.Fprintf(w, "  %d: %d (%.1f%%)\n", attempt, count, float64(count)/float64(result.Trials)*100)
+	}
+	if failed := result.Trials - result.Successes; failed > 0 {
+		fmt.Fprintf(w, "  failed: %d (%.1f%%)\n", failed, float64(failed)/float64(result.Trials)*100)
+	}
+	if result.Successes > 0 {
+		fmt.Fprintf(w, "Mean attempts (successful runs): %.2f\n", result.MeanAttempts())
+	}
+
+	fmt.Fprintln(w, "Total time:")
+	for _, p := range []float64{50, 90, 95, 99} {
+		fmt.Fprintf(w, "  p%.0f: %v\n", p, result.TimePercentile(p))
+	}
+	fmt.Fprintf(w, "  max: %v\n", result.TimePercentile(100))
+}
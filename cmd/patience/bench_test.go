@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBenchSubcommand(t *testing.T) {
+	// Given a bench of fixed 1s delays where every attempt fails
+	rootCmd := createTestRootCommand()
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"bench", "fixed", "--delay", "1s", "--attempts", "3",
+		"--failure-rate", "1", "--trials", "100", "--seed", "1"})
+
+	// When the simulation runs
+	require.NoError(t, rootCmd.Execute())
+
+	// Then no run succeeds and each waits twice
+	report := out.String()
+	assert.Contains(t, report, "Strategy: fixed, 100 trials, 3 attempt(s), failure rate 1.00")
+	assert.Contains(t, report, "Success rate: 0.0% (0/100)")
+	assert.Contains(t, report, "  failed: 100 (100.0%)")
+	assert.Contains(t, report, "  p50: 2s")
+	assert.Contains(t, report, "  max: 2s")
+}
+
+func TestBenchSubcommand_KnownFailureRate(t *testing.T) {
+	// Given exponential backoff against attempts failing half the time
+	rootCmd := createTestRootCommand()
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"bench", "exp", "--attempts", "2", "--failure-rate", "0.5",
+		"--trials", "20000", "--seed", "7"})
+
+	// When the simulation runs
+	require.NoError(t, rootCmd.Execute())
+
+	// Then about half succeed at once, a quarter on retry, and a quarter fail
+	report := out.String()
+	assert.Regexp(t, `  1: \d+ \((49|50|51)\.\d%\)`, report)
+	assert.Regexp(t, `  2: \d+ \((24|25|26)\.\d%\)`, report)
+	assert.Regexp(t, `  failed: \d+ \((24|25|26)\.\d%\)`, report)
+	assert.Contains(t, report, "  p90: 1s")
+}
+
+func TestBenchSubcommand_InvalidInput(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "unknown strategy", args: []string{"bench", "adaptive"}},
+		{name: "bad failure rate", args: []string{"bench", "fixed", "--failure-rate", "2"}},
+		{name: "bad fallback", args: []string{"bench", "http-aware", "--fallback", "nope"}},
+		{name: "http-aware fallback", args: []string{"bench", "http-aware", "--fallback", "http-aware"}},
+		{name: "http-aware alias fallback", args: []string{"bench", "ha", "--fallback", "ha"}},
+		{name: "missing strategy", args: []string{"bench"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rootCmd := createTestRootCommand()
+			rootCmd.SetOut(&bytes.Buffer{})
+			rootCmd.SetErr(&bytes.Buffer{})
+			rootCmd.SetArgs(tt.args)
+			assert.Error(t, rootCmd.Execute())
+		})
+	}
+}
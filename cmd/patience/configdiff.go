@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/shaneisley/patience/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// configDiffReport is the --json form of a config-diff
+type configDiffReport struct {
+	From    string             `json:"from"`
+	To      string             `json:"to"`
+	Changes []config.FieldDiff `json:"changes"`
+}
+
+// createConfigDiffCommand creates the config-diff subcommand
+func createConfigDiffCommand() *cobra.Command {
+	commonConfig := NewCommonConfig()
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "config-diff [OPTIONS] [FILE_A FILE_B]",
+		Short: "Compare two configuration resolutions field by field",
+		Long: `Resolve the configuration twice and print the fields that differ, with the
+source of each value (default, config file, environment variable or CLI flag).
+
+Without arguments the config file (--config, or the one found in the current or
+home directory) is resolved with and without the given flags, showing what the
+flags change. With two config files, each is resolved on its own and the two are
+compared; flags cannot be combined with this form.`,
+		Example: `  patience config-diff --attempts 8 --timeout 30s
+  patience config-diff --config ci.toml --success-pattern deployed --json
+  patience config-diff staging.toml production.toml`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 && len(args) != 2 {
+				return fmt.Errorf("config-diff takes no arguments or two config files, got %d", len(args))
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var report configDiffReport
+			var err error
+			if len(args) == 2 {
+				_, explicitFields := configFlags(cmd, &commonConfig)
+				if len(explicitFields) > 0 || cmd.Flags().Changed("config") {
+					return fmt.Errorf("flags cannot be combined with two config files")
+				}
+				report, err = diffConfigFiles(args[0], args[1])
+			} else {
+				report, err = diffConfigFlags(cmd, &commonConfig)
+			}
+			if err != nil {
+				return err
+			}
+			return writeConfigDiff(cmd.OutOrStdout(), report, jsonOutput)
+		},
+	}
+
+	cmd.Flags().StringVar(&commonConfig.ConfigFile, "config", "", "Configuration file path")
+	cmd.Flags().IntVarP(&commonConfig.Attempts, "attempts", "a", 3, "Maximum retry attempts")
+	cmd.Flags().DurationVarP(&commonConfig.Timeout, "timeout", "t", 0, "Timeout per attempt")
+	cmd.Flags().StringVar(&commonConfig.SuccessPattern, "success-pattern", "", "Regex pattern for success detection")
+	cmd.Flags().StringVar(&commonConfig.FailurePattern, "failure-pattern", "", "Regex pattern for failure detection")
+	cmd.Flags().BoolVar(&commonConfig.CaseInsensitive, "case-insensitive", false, "Case-insensitive pattern matching")
+	cmd.Flags().BoolVar(&commonConfig.DaemonEnabled, "daemon", false, "Enable daemon coordination")
+	cmd.Flags().StringVar(&commonConfig.DaemonSocket, "daemon-socket", "/tmp/patience-daemon.sock", "Daemon socket path")
+	cmd.Flags().DurationVar(&commonConfig.DaemonTimeout, "daemon-timeout", 5*time.Second, "Daemon connection timeout")
+	cmd.Flags().BoolVar(&commonConfig.DaemonAutoStart, "daemon-auto-start", true, "Automatically start daemon if not running")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Print the diff as JSON")
+
+	return cmd
+}
+
+// diffConfigFlags compares the config file resolved without and with the
+// command's config flags
+func diffConfigFlags(cmd *cobra.Command, commonConfig *CommonConfig) (configDiffReport, error) {
+	configPath := commonConfig.ConfigFile
+	if configPath == "" {
+		configPath, _ = discoverConfigFile()
+	}
+	if configPath == config.StdinPath {
+		return configDiffReport{}, fmt.Errorf("config-diff needs a config file path, not stdin")
+	}
+
+	base := "defaults"
+	if configPath != "" {
+		base = "config file " + configPath
+	}
+
+	from, fromInfo, err := config.LoadWithPrecedenceAndExplicitFlags(configPath, nil, nil, true)
+	if err != nil {
+		return configDiffReport{}, fmt.Errorf("failed to resolve %s: %w", base, err)
+	}
+	flagConfig, explicitFields := configFlags(cmd, commonConfig)
+	to, toInfo, err := config.LoadWithPrecedenceAndExplicitFlags(configPath, flagConfig, explicitFields, true)
+	if err != nil {
+		return configDiffReport{}, fmt.Errorf("failed to resolve %s with CLI flags: %w", base, err)
+	}
+
+	return configDiffReport{
+		From:    base,
+		To:      base + " with CLI flags",
+		Changes: config.Diff(from, fromInfo, to, toInfo),
+	}, nil
+}
+
+// diffConfigFiles compares two config files, each resolved on its own
+func diffConfigFiles(fromPath, toPath string) (configDiffReport, error) {
+	from, fromInfo, err := config.LoadWithPrecedenceAndExplicitFlags(fromPath, nil, nil, true)
+	if err != nil {
+		return configDiffReport{}, fmt.Errorf("failed to resolve config file %s: %w", fromPath, err)
+	}
+	to, toInfo, err := config.LoadWithPrecedenceAndExplicitFlags(toPath, nil, nil, true)
+	if err != nil {
+		return configDiffReport{}, fmt.Errorf("failed to resolve config file %s: %w", toPath, err)
+	}
+
+	return configDiffReport{
+		From:    "config file " + fromPath,
+		To:      "config file " + toPath,
+		Changes: config.Diff(from, fromInfo, to, toInfo),
+	}, nil
+}
+
+// writeConfigDiff prints the report as text or JSON
+func writeConfigDiff(w io.Writer, report configDiffReport, jsonOutput bool) error {
+	if jsonOutput {
+		if report.Changes == nil {
+			report.Changes = []config.FieldDiff{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	fmt.Fprintf(w, "--- %s\n+++ %s\n", report.From, report.To)
+	if len(report.Changes) == 0 {
+		fmt.Fprintln(w, "no differences")
+		return nil
+	}
+	for _, change := range report.Changes {
+		fmt.Fprintf(w, "%s: %s (%s) -> %s (%s)\n",
+			change.Key, change.From, change.FromSource, change.To, change.ToSource)
+	}
+	return nil
+}
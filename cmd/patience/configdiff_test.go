@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeConfigFile writes a TOML config file to a temporary directory
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestConfigDiffSubcommand_Flags(t *testing.T) {
+	// Given a config file and flags overriding part of it
+	configPath := writeConfigFile(t, "ci.toml", "attempts = 5\ntimeout = \"10s\"\n")
+	rootCmd := createTestRootCommand()
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"config-diff", "--config", configPath, "--attempts", "8", "--success-pattern", "deployed"})
+
+	// When diffing the resolutions
+	require.NoError(t, rootCmd.Execute())
+
+	// Then the changed fields are listed with their origins
+	assert.Equal(t, "--- config file "+configPath+"\n"+
+		"+++ config file "+configPath+" with CLI flags\n"+
+		"attempts: 5 (config file) -> 8 (CLI flag)\n"+
+		"success_pattern: \"\" (default) -> \"deployed\" (CLI flag)\n", out.String())
+}
+
+func TestConfigDiffSubcommand_TwoFilesJSON(t *testing.T) {
+	// Given two config files
+	staging := writeConfigFile(t, "staging.toml", "attempts = 5\n")
+	production := writeConfigFile(t, "production.toml", "attempts = 5\ntimeout = \"30s\"\n")
+	rootCmd := createTestRootCommand()
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"config-diff", "--json", staging, production})
+
+	// When diffing them as JSON
+	require.NoError(t, rootCmd.Execute())
+
+	// Then only the timeout differs, from its default to the second file
+	var report map[string]interface{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+	assert.Equal(t, "config file "+staging, report["from"])
+	assert.Equal(t, []interface{}{map[string]interface{}{
+		"key": "timeout", "from": "0s", "from_source": "default", "to": "30s", "to_source": "config file",
+	}}, report["changes"])
+}
+
+func TestConfigDiffSubcommand_InvalidUsage(t *testing.T) {
+	staging := writeConfigFile(t, "staging.toml", "attempts = 5\n")
+
+	tests := map[string][]string{
+		"one file":             {"config-diff", staging},
+		"flags with two files": {"config-diff", "--attempts", "2", staging, staging},
+	}
+	for name, args := range tests {
+		t.Run(name, func(t *testing.T) {
+			rootCmd := createTestRootCommand()
+			rootCmd.SetOut(io.Discard)
+			rootCmd.SetErr(io.Discard)
+			rootCmd.SetArgs(args)
+			assert.Error(t, rootCmd.Execute())
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/shaneisley/patience/pkg/backoff"
+	"github.com/shaneisley/patience/pkg/executor"
+)
+
+// Supported --format values for --dry-run output
+const (
+	dryRunFormatText = "text"
+	dryRunFormatCSV  = "csv"
+)
+
+// dryRunUnlimitedDelays is how many delays --dry-run shows for --attempts 0
+const dryRunUnlimitedDelays = 10
+
+// printDryRun writes the computed delay schedule without executing the command.
+// Each row is the delay applied after the given attempt fails; the final attempt has no delay.
+func printDryRun(w io.Writer, strategy backoff.Strategy, config CommonConfig, commandArgs []string) error {
+	caps, err := executor.ParseAttemptCaps(config.AttemptCaps)
+	if err != nil {
+		return err
+	}
+	strategy = decorateStrategy(strategy, config)
+
+	// The final attempt has no delay after it; unlimited runs show a prefix
+	attempts := config.Attempts
+	if attempts == 0 {
+		attempts = dryRunUnlimitedDelays + 1
+	}
+	schedule := backoff.Schedule(strategy, attempts-1)
+	delays := make([]float64, 0, attempts)
+	for attempt := 1; attempt < attempts; attempt++ {
+		var delay time.Duration
+		if schedule != nil {
+			delay = schedule[attempt-1]
+		}
+		delay = caps.Apply(attempt, delay)
+		if config.CapDelay > 0 && delay > config.CapDelay {
+			delay = config.CapDelay
+		}
+		delays = append(delays, delay.Seconds())
+	}
+
+	name := strings.TrimPrefix(getStrategyTypeName(strategy), "*backoff.")
+	executable, found := checkExecutable(config, commandArgs)
+
+	switch config.Format {
+	case dryRunFormatCSV:
+		if config.DelayCommand != "" {
+			fmt.Fprintln(w, "# delays come from --delay-command at runtime; showing strategy schedule")
+		} else if isRuntimeDependent(strategy) {
+			fmt.Fprintf(w, "# %s delays depend on runtime feedback; showing fallback schedule\n", name)
+		}
+		if config.DelayJSONPath != "" {
+			fmt.Fprintf(w, "# responses with a %s field set their own delay at runtime\n", config.DelayJSONPath)
+		}
+		if config.AlignInterval > 0 {
+			fmt.Fprintf(w, "# retries wait for the next %s boundary after each delay\n", config.AlignInterval)
+		}
+		if config.Attempts == 0 {
+			fmt.Fprintf(w, "# unlimited attempts; showing the first %d delays\n", len(delays))
+		}
+		if !found {
+			fmt.Fprintf(w, "# executable %s\n", executable)
+		}
+		fmt.Fprintln(w, "attempt,delay_seconds")
+		for i, seconds := range delays {
+			fmt.Fprintf(w, "%d,%.3f\n", i+1, seconds)
+		}
+	default:
+		fmt.Fprintf(w, "[dry-run] Command: %s\n", strings.Join(config.headerRedaction().Command(commandArgs), " "))
+		fmt.Fprintf(w, "[dry-run] Executable: %s\n", executable)
+		if steps, err := executor.SplitSteps(commandArgs); config.Steps && err == nil && len(steps) > 1 {
+			fmt.Fprintf(w, "[dry-run] Steps: %d, run in sequence within each attempt\n", len(steps))
+		}
+		if config.Attempts == 0 {
+			fmt.Fprintf(w, "[dry-run] Strategy: %s, unlimited attempts; showing the first %d delays\n", name, len(delays))
+		} else {
+			fmt.Fprintf(w, "[dry-run] Strategy: %s, %d attempt(s)\n", name, config.Attempts)
+		}
+		if config.DelayCommand != "" {
+			fmt.Fprintln(w, "[dry-run] Delays come from --delay-command at runtime; showing strategy schedule")
+		} else if isRuntimeDependent(strategy) {
+			fmt.Fprintln(w, "[dry-run] Delays depend on runtime feedback; showing fallback schedule")
+		}
+		if config.DelayJSONPath != "" {
+			fmt.Fprintf(w, "[dry-run] Responses with a %s field set their own delay at runtime\n", config.DelayJSONPath)
+		}
+		if config.AlignInterval > 0 {
+			fmt.Fprintf(w, "[dry-run] Retries wait for the next %s boundary after each delay\n", config.AlignInterval)
+		}
+		for i, seconds := range delays {
+			fmt.Fprintf(w, "[dry-run] Attempt %d fails -> wait %.3fs\n", i+1, seconds)
+		}
+		if config.Attempts > 0 {
+			fmt.Fprintf(w, "[dry-run] Attempt %d is final\n", config.Attempts)
+		}
+	}
+
+	return nil
+}
+
+// checkExecutable resolves the command's binary on PATH, without running it,
+// so a typo shows up before a long run. Commands run through sh -c are not
+// checked.
+func checkExecutable(config CommonConfig, commandArgs []string) (string, bool) {
+	if config.Shell {
+		return "not checked (runs through sh -c)", true
+	}
+	if len(commandArgs) == 0 {
+		return "not found: no command given", false
+	}
+	path, err := exec.LookPath(commandArgs[0])
+	if err != nil {
+		return fmt.Sprintf("not found: %s", commandArgs[0]), false
+	}
+	return "found at " + path, true
+}
+
+// isRuntimeDependent reports whether a strategy adapts its delays to command feedback
+func isRuntimeDependent(strategy backoff.Strategy) bool {
+	switch backoff.Unwrap(strategy).(type) {
+	case interface {
+		ProcessCommandOutput(stdout, stderr string, exitCode int)
+	}:
+		return true
+	case interface {
+		RecordOutcome(delay time.Duration, success bool, latency time.Duration)
+	}:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shaneisley/patience/pkg/backoff"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDryRunCSV_Exponential(t *testing.T) {
+	// Given an exponential strategy and 5 attempts
+	strategy := backoff.NewExponential(500*time.Millisecond, 2.0, 3*time.Second)
+	config := NewCommonConfig()
+	config.Attempts = 5
+	config.DryRun = true
+	config.Format = dryRunFormatCSV
+
+	// When printing the dry-run schedule as CSV
+	var buf bytes.Buffer
+	require.NoError(t, printDryRun(&buf, strategy, config, []string{"echo", "test"}))
+
+	// Then each row should match the strategy's computed delay
+	expected := []string{"attempt,delay_seconds"}
+	for attempt := 1; attempt < config.Attempts; attempt++ {
+		expected = append(expected, fmt.Sprintf("%d,%.3f", attempt, strategy.Delay(attempt).Seconds()))
+	}
+	assert.Equal(t, strings.Join(expected, "\n")+"\n", buf.String())
+	assert.Contains(t, buf.String(), "4,3.000") // capped at max delay
+}
+
+func TestDryRunCSV_RuntimeDependentStrategy(t *testing.T) {
+	// Given an HTTP-aware strategy with a fixed fallback
+	strategy := backoff.NewHTTPAware(backoff.NewFixed(2*time.Second), time.Minute)
+	config := NewCommonConfig()
+	config.DryRun = true
+	config.Format = dryRunFormatCSV
+
+	// When printing the dry-run schedule as CSV
+	var buf bytes.Buffer
+	require.NoError(t, printDryRun(&buf, strategy, config, []string{"curl", "https://example.com"}))
+
+	// Then it should note the fallback schedule in a header comment
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 4)
+	assert.True(t, strings.HasPrefix(lines[0], "# HTTPAware delays depend on runtime feedback"))
+	assert.Equal(t, "attempt,delay_seconds", lines[1])
+	assert.Equal(t, "1,2.000", lines[2])
+	assert.Equal(t, "2,2.000", lines[3])
+}
+
+func TestDryRunCSV_AttemptCaps(t *testing.T) {
+	// Given a fixed 30s strategy with caps stepping up at attempts 3 and 5
+	strategy := backoff.NewFixed(30 * time.Second)
+	config := NewCommonConfig()
+	config.Attempts = 7
+	config.DryRun = true
+	config.Format = dryRunFormatCSV
+	config.AttemptCaps = "1:1s,3:10s,5:60s"
+
+	// When printing the dry-run schedule as CSV
+	var buf bytes.Buffer
+	require.NoError(t, printDryRun(&buf, strategy, config, []string{"echo", "test"}))
+
+	// Then each delay should be clamped by the cap in effect for its attempt
+	expected := "attempt,delay_seconds\n1,1.000\n2,1.000\n3,10.000\n4,10.000\n5,30.000\n6,30.000\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestDryRunFormatValidation(t *testing.T) {
+	config := NewCommonConfig()
+
+	// CSV without --dry-run is rejected
+	config.Format = dryRunFormatCSV
+	assert.ErrorContains(t, config.Validate(), "requires --dry-run")
+
+	// Unknown formats are rejected
+	config.DryRun = true
+	config.Format = "xml"
+	assert.ErrorContains(t, config.Validate(), "unknown format")
+}
+
+func TestDryRunDoesNotExecute(t *testing.T) {
+	// Given a dry-run of a command that would fail
+	rootCmd := createTestRootCommand()
+	rootCmd.SetArgs([]string{"fixed", "--dry-run", "--format", "csv", "--", "false"})
+
+	// When the command is executed
+	err := rootCmd.Execute()
+
+	// Then it should succeed without running the command
+	assert.NoError(t, err)
+}
+
+func TestDryRunText_RedactsHeaders(t *testing.T) {
+	// Given a curl command with an Authorization header and an extra redacted header
+	config := NewCommonConfig()
+	config.DryRun = true
+	config.RedactHeaders = []string{"X-Session-Token"}
+	command := []string{"curl", "-H", "Authorization: Bearer s3cret", "-H", "X-Session-Token: t0k3n", "https://api.example.com"}
+
+	// When printing the dry-run schedule
+	var buf bytes.Buffer
+	require.NoError(t, printDryRun(&buf, backoff.NewFixed(time.Second), config, command))
+
+	// Then the header names should remain but their values be redacted
+	output := buf.String()
+	assert.Contains(t, output, "[dry-run] Command: curl -H Authorization: [REDACTED] -H X-Session-Token: [REDACTED] https://api.example.com")
+	assert.NotContains(t, output, "s3cret")
+	assert.NotContains(t, output, "t0k3n")
+}
+
+func TestRedactHeaderValidation(t *testing.T) {
+	config := NewCommonConfig()
+
+	// Valid header names are accepted
+	config.RedactHeaders = []string{"X-Session-Token"}
+	config.ShowHeaders = []string{"Cookie"}
+	assert.NoError(t, config.Validate())
+
+	// Invalid header names are rejected
+	config.RedactHeaders = []string{"Bad Header"}
+	assert.ErrorContains(t, config.Validate(), "invalid --redact-header")
+	config.RedactHeaders = nil
+	config.ShowHeaders = []string{""}
+	assert.ErrorContains(t, config.Validate(), "invalid --show-header")
+}
+
+func TestDryRun_ChecksExecutable(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		shell    bool
+		command  []string
+		expected string
+	}{
+		{name: "found", command: []string{"sh", "-c", "exit 0"}, expected: "[dry-run] Executable: found at "},
+		{name: "missing", command: []string{"patience-no-such-command-xyz"}, expected: "[dry-run] Executable: not found: patience-no-such-command-xyz"},
+		{name: "shell", shell: true, command: []string{"patience-no-such-command-xyz | cat"}, expected: "[dry-run] Executable: not checked (runs through sh -c)"},
+		{name: "missing in csv", format: dryRunFormatCSV, command: []string{"patience-no-such-command-xyz"}, expected: "# executable not found: patience-no-such-command-xyz"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given a dry-run of the command
+			config := NewCommonConfig()
+			config.Attempts = 3
+			config.DryRun = true
+			config.Format = tt.format
+			config.Shell = tt.shell
+
+			// When printing the dry-run schedule
+			var buf bytes.Buffer
+			require.NoError(t, printDryRun(&buf, backoff.NewFixed(time.Second), config, tt.command))
+
+			// Then it should report the executable and still print the schedule
+			output := buf.String()
+			assert.Contains(t, output, tt.expected)
+			if tt.format == dryRunFormatCSV {
+				assert.Contains(t, output, "attempt,delay_seconds\n1,1.000\n2,1.000\n")
+			} else {
+				assert.Contains(t, output, "[dry-run] Attempt 2 fails -> wait 1.000s")
+			}
+		})
+	}
+}
+
+func TestDryRun_NotesAlignment(t *testing.T) {
+	// Given a dry-run with retries aligned to whole minutes
+	config := NewCommonConfig()
+	config.Attempts = 3
+	config.DryRun = true
+	config.AlignInterval = time.Minute
+
+	// When printing the dry-run schedule
+	var buf bytes.Buffer
+	require.NoError(t, printDryRun(&buf, backoff.NewFixed(time.Second), config, []string{"sh", "-c", "exit 0"}))
+
+	// Then it should say the delays are stretched to the boundaries
+	assert.Contains(t, buf.String(), "[dry-run] Retries wait for the next 1m0s boundary after each delay")
+}
+
+func TestDryRun_NotesSteps(t *testing.T) {
+	// Given a dry-run of a two-step command
+	config := NewCommonConfig()
+	config.Attempts = 2
+	config.DryRun = true
+	config.Steps = true
+
+	// When printing the dry-run schedule
+	var buf bytes.Buffer
+	require.NoError(t, printDryRun(&buf, backoff.NewFixed(time.Second), config, []string{"sh", "-c", "exit 0", "--and", "sh", "-c", "exit 1"}))
+
+	// Then it should say the steps run in sequence
+	assert.Contains(t, buf.String(), "[dry-run] Steps: 2, run in sequence within each attempt")
+}
+
+func TestDryRun_UnlimitedAttempts(t *testing.T) {
+	// Given a dry-run with unlimited attempts bounded by total time
+	config := NewCommonConfig()
+	config.Attempts = 0
+	config.MaxTotalTime = time.Minute
+	config.DryRun = true
+
+	// When printing the dry-run schedule
+	var buf bytes.Buffer
+	require.NoError(t, printDryRun(&buf, backoff.NewFixed(time.Second), config, []string{"sh", "-c", "exit 0"}))
+
+	// Then it shows a prefix of the schedule and no final attempt
+	output := buf.String()
+	assert.Contains(t, output, "unlimited attempts; showing the first 10 delays")
+	assert.Contains(t, output, "[dry-run] Attempt 10 fails -> wait 1.000s")
+	assert.NotContains(t, output, "is final")
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shaneisley/patience/pkg/backoff"
+)
+
+// fallbackStrategy is a strategy that http-aware and adaptive can fall back to
+type fallbackStrategy struct {
+	name    string
+	aliases []string
+	create  func() (backoff.Strategy, error)
+}
+
+// fallbackStrategies lists the valid --fallback values with the default
+// parameters each fallback is created with
+var fallbackStrategies = []fallbackStrategy{
+	{"exponential", []string{"exp"}, func() (backoff.Strategy, error) {
+		return backoff.NewExponential(1*time.Second, 2.0, 60*time.Second), nil
+	}},
+	{"linear", []string{"lin"}, func() (backoff.Strategy, error) {
+		return backoff.NewLinear(1*time.Second, 60*time.Second), nil
+	}},
+	{"fixed", []string{"fix"}, func() (backoff.Strategy, error) {
+		return backoff.NewFixed(1 * time.Second), nil
+	}},
+	{"jitter", []string{"jit"}, func() (backoff.Strategy, error) {
+		return backoff.NewJitter(1*time.Second, 2.0, 60*time.Second), nil
+	}},
+	{"decorrelated-jitter", []string{"dj"}, func() (backoff.Strategy, error) {
+		return backoff.NewDecorrelatedJitter(1*time.Second, 2.0, 60*time.Second), nil
+	}},
+	{"fibonacci", []string{"fib"}, func() (backoff.Strategy, error) {
+		return backoff.NewFibonacci(1*time.Second, 60*time.Second), nil
+	}},
+	{"polynomial", []string{"poly"}, func() (backoff.Strategy, error) {
+		return backoff.NewPolynomial(1*time.Second, 2.0, 60*time.Second)
+	}},
+}
+
+// lookupFallback finds a fallback strategy by name or alias, returning an error
+// that suggests the closest valid name when there is no match
+func lookupFallback(name string) (fallbackStrategy, error) {
+	for _, fallback := range fallbackStrategies {
+		if name == fallback.name {
+			return fallback, nil
+		}
+		for _, alias := range fallback.aliases {
+			if name == alias {
+				return fallback, nil
+			}
+		}
+	}
+
+	names := make([]string, len(fallbackStrategies))
+	for i, fallback := range fallbackStrategies {
+		names[i] = fallback.name
+	}
+	if suggestion := suggestFallback(name); suggestion != "" {
+		return fallbackStrategy{}, fmt.Errorf("unknown fallback strategy: %s (did you mean %q?)", name, suggestion)
+	}
+	return fallbackStrategy{}, fmt.Errorf("unknown fallback strategy: %s (valid: %s)", name, strings.Join(names, ", "))
+}
+
+// validateFallback checks that name is a known fallback strategy or alias
+func validateFallback(name string) error {
+	_, err := lookupFallback(name)
+	return err
+}
+
+// createFallbackStrategy creates a fallback strategy from the given type
+func createFallbackStrategy(fallbackType string) (backoff.Strategy, error) {
+	fallback, err := lookupFallback(fallbackType)
+	if err != nil {
+		return nil, err
+	}
+	return fallback.create()
+}
+
+// suggestFallback returns the canonical name closest to name by edit distance,
+// or "" when nothing is close enough to be a plausible typo
+func suggestFallback(name string) string {
+	name = strings.ToLower(name)
+	best, bestDistance := "", -1
+	for _, fallback := range fallbackStrategies {
+		for _, candidate := range append([]string{fallback.name}, fallback.aliases...) {
+			distance := editDistance(name, candidate)
+			if bestDistance < 0 || distance < bestDistance {
+				best, bestDistance = fallback.name, distance
+			}
+		}
+	}
+
+	// Allow roughly one edit per three characters, and at least two
+	limit := len(name) / 3
+	if limit < 2 {
+		limit = 2
+	}
+	if bestDistance > limit {
+		return ""
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
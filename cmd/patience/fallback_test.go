@@ -0,0 +1,60 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFallbackValidation(t *testing.T) {
+	t.Run("accepts each canonical name", func(t *testing.T) {
+		for _, name := range []string{"exponential", "linear", "fixed", "jitter", "decorrelated-jitter", "fibonacci", "polynomial"} {
+			strategy, err := createFallbackStrategy(name)
+			require.NoError(t, err, name)
+			assert.NotNil(t, strategy, name)
+		}
+	})
+
+	t.Run("accepts aliases", func(t *testing.T) {
+		assert.NoError(t, validateFallback("dj"))
+		assert.NoError(t, HTTPAwareConfig{Fallback: "exp"}.Validate())
+	})
+
+	t.Run("suggests the closest name for a typo", func(t *testing.T) {
+		err := HTTPAwareConfig{Fallback: "expontial"}.Validate()
+		assert.EqualError(t, err, `unknown fallback strategy: expontial (did you mean "exponential"?)`)
+
+		err = validateFallback("fibbonaci")
+		assert.EqualError(t, err, `unknown fallback strategy: fibbonaci (did you mean "fibonacci"?)`)
+	})
+
+	t.Run("lists valid names when nothing is close", func(t *testing.T) {
+		err := validateFallback("quantum")
+		assert.EqualError(t, err, "unknown fallback strategy: quantum (valid: exponential, linear, fixed, jitter, decorrelated-jitter, fibonacci, polynomial)")
+	})
+}
+
+func TestFallbackValidation_Subcommands(t *testing.T) {
+	for _, strategy := range []string{"http-aware", "adaptive"} {
+		t.Run(strategy, func(t *testing.T) {
+			// Given a misspelled fallback
+			rootCmd := createTestRootCommand()
+			rootCmd.SetArgs([]string{strategy, "--fallback", "expontial", "--", "echo", "test"})
+
+			// When the command runs
+			err := rootCmd.Execute()
+
+			// Then it should fail before running anything, suggesting the fix
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), `did you mean "exponential"?`)
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("exp", "exp"))
+	assert.Equal(t, 2, editDistance("expontial", "exponential"))
+	assert.Equal(t, 3, editDistance("kitten", "sitting"))
+	assert.Equal(t, 5, editDistance("", "fixed"))
+}
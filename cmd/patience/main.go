@@ -70,22 +70,19 @@ func createExecutor(cfg *config.Config) (*executor.Executor, error) {
 
 // executeCommand runs the command with the given executor and exits with appropriate code
 func executeCommand(exec *executor.Executor, args []string) error {
-	result, err := exec.Run(args)
+	result, err := runExecutor(exec, args)
 	if err != nil {
-		return fmt.Errorf("execution error: %w", err)
+		return err
 	}
 
+	// Send metrics to the daemon before the summary, in the background unless --metrics-sync
+	exec.DispatchMetrics(result, metrics.NewClient(metrics.DefaultSocketPath()))
+
 	// Show final summary if we have statistics
 	if result.Stats != nil && exec.Reporter != nil {
 		exec.Reporter.FinalSummary(result.Stats)
 	}
 
-	// Send metrics to daemon asynchronously (fire-and-forget)
-	if result.Metrics != nil {
-		metricsClient := metrics.NewClient(metrics.DefaultSocketPath())
-		metricsClient.SendMetricsAsync(result.Metrics)
-	}
-
 	// Exit with appropriate code based on success
 	if result.Success {
 		os.Exit(0)
@@ -105,6 +102,7 @@ var (
 	flagConfig  config.Config
 	configFile  string
 	debugConfig bool
+	whichConfig bool
 )
 
 var rootCmd = &cobra.Command{
@@ -137,10 +135,23 @@ EXAMPLES:
 
   # Using abbreviations for brevity
   patience ha -f exp -- curl -i https://api.github.com
-  patience exp -b 1s -x 2.0 -- curl https://httpbin.org/status/503`,
+  patience exp -b 1s -x 2.0 -- curl https://httpbin.org/status/503
+
+  # Show which config file would be loaded
+  patience --which-config`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if whichConfig {
+			found, searched := discoverConfigFile()
+			return printWhichConfig(cmd.OutOrStdout(), found, searched)
+		}
+		return cmd.Help()
+	},
 }
 
 func init() {
+	rootCmd.Flags().BoolVar(&whichConfig, "which-config", false,
+		"Print the config file that would be loaded (or the search order if none) and exit")
+
 	// Add strategy subcommands
 	rootCmd.AddCommand(createHTTPAwareCommand())
 	rootCmd.AddCommand(createExponentialCommand())
@@ -151,7 +162,15 @@ func init() {
 	rootCmd.AddCommand(createFibonacciCommand())
 	rootCmd.AddCommand(createPolynomialCommand())
 	rootCmd.AddCommand(createAdaptiveCommand())
+	rootCmd.AddCommand(createPIDCommand())
+	rootCmd.AddCommand(createRemoteScheduleCommand())
 	rootCmd.AddCommand(createDiophantineCommand())
+
+	// Add utility subcommands
+	rootCmd.AddCommand(createBenchCommand())
+	rootCmd.AddCommand(createNextDelayCommand())
+	rootCmd.AddCommand(createReplayMetricsCommand())
+	rootCmd.AddCommand(createConfigDiffCommand())
 }
 
 // loadConfiguration loads configuration with full precedence support
@@ -163,17 +182,7 @@ func loadConfiguration(cmd *cobra.Command) (*config.Config, error) {
 		configPath = configFile
 	} else {
 		// Search for config file in standard locations
-		cwd, _ := os.Getwd()
-		if found := config.FindConfigFile(cwd); found != "" {
-			configPath = found
-		} else {
-			// Check home directory
-			if homeDir, err := os.UserHomeDir(); err == nil {
-				if found := config.FindConfigFile(homeDir); found != "" {
-					configPath = found
-				}
-			}
-		}
+		configPath, _ = discoverConfigFile()
 	}
 
 	// Create explicit flags map and flag config
@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -96,6 +97,30 @@ func TestCLI_WithTimeoutFlag(t *testing.T) {
 	}
 }
 
+func TestCLI_TimeoutOverheadZero(t *testing.T) {
+	// Given a compiled retry binary
+	binary := buildBinary(t)
+
+	// When executing with a 50ms timeout and no overhead buffer
+	start := time.Now()
+	cmd := exec.Command(binary, "fixed", "--attempts", "1",
+		"--timeout", "50ms", "--timeout-overhead", "0", "--", "sleep", "1")
+	err := cmd.Run()
+	elapsed := time.Since(start)
+
+	// Then it should time out well before the command finishes
+	require.Error(t, err)
+	assert.Less(t, elapsed, 500*time.Millisecond)
+
+	// When the overhead is not a duration
+	cmd = exec.Command(binary, "fixed", "--timeout-overhead", "soon", "--", "true")
+	output, err := cmd.CombinedOutput()
+
+	// Then it should be rejected
+	require.Error(t, err)
+	assert.Contains(t, string(output), "invalid timeout-overhead")
+}
+
 func TestCLI_HelpFlag(t *testing.T) {
 	// Given a compiled retry binary
 	binary := buildBinary(t)
@@ -205,6 +230,55 @@ func TestCLI_CaseInsensitivePattern(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestCLI_SuccessJSONEq(t *testing.T) {
+	// Given a compiled retry binary
+	binary := buildBinary(t)
+
+	// When executing with a JSON equality that matches a nested key
+	cmd := exec.Command(binary, "fixed", "--delay", "10ms",
+		"--success-json-eq", "data.status=ok",
+		"--", "sh", "-c", `echo '{"data": {"status": "ok"}}'; exit 1`)
+	err := cmd.Run()
+
+	// Then it should succeed despite exit code 1
+	require.NoError(t, err)
+
+	// When the field never matches
+	cmd = exec.Command(binary, "fixed", "--delay", "10ms", "--attempts", "2",
+		"--success-json-eq", "status=ok",
+		"--", "sh", "-c", `echo '{"status": "pending"}'`)
+	output, err := cmd.CombinedOutput()
+
+	// Then it should retry and fail
+	require.Error(t, err)
+	assert.Contains(t, string(output), "Attempt 2/2")
+	assert.Contains(t, string(output), "json field mismatch (status)")
+}
+
+func TestCLI_MinOutputLines(t *testing.T) {
+	// Given a compiled retry binary
+	binary := buildBinary(t)
+
+	// When a command prints fewer lines than required
+	cmd := exec.Command(binary, "fixed", "--delay", "10ms", "--attempts", "2",
+		"--min-output-lines", "3",
+		"--", "sh", "-c", "echo one; echo two")
+	output, err := cmd.CombinedOutput()
+
+	// Then it should retry and fail despite exit code 0
+	require.Error(t, err)
+	assert.Contains(t, string(output), "insufficient output (2 lines, need 3)")
+
+	// When a command prints enough lines
+	cmd = exec.Command(binary, "fixed", "--delay", "10ms",
+		"--min-output-lines", "3",
+		"--", "sh", "-c", "echo one; echo two; echo three")
+	err = cmd.Run()
+
+	// Then it should succeed
+	require.NoError(t, err)
+}
+
 func TestCLI_ConfigFile(t *testing.T) {
 	// Given a compiled retry binary
 	binary := buildBinary(t)
@@ -255,6 +329,41 @@ success_pattern = "success"
 	require.NoError(t, err)
 }
 
+func TestCLI_ConfigFromStdin(t *testing.T) {
+	// Given a compiled retry binary
+	binary := buildBinary(t)
+
+	// When a TOML config is piped in with --config -
+	cmd := exec.Command(binary, "exponential",
+		"--config", "-",
+		"--base-delay", "10ms",
+		"--", "sh", "-c", "echo 'still failing'; exit 1")
+	cmd.Stdin = strings.NewReader("attempts = 2\n")
+	output, err := cmd.CombinedOutput()
+
+	// Then the piped attempts value should apply to the command after --
+	require.Error(t, err)
+	outputStr := string(output)
+	assert.Contains(t, outputStr, "Attempt 2/2")
+	assert.NotContains(t, outputStr, "Attempt 3")
+	assert.Contains(t, outputStr, "still failing")
+}
+
+func TestCLI_ConfigFromStdinWithType(t *testing.T) {
+	// Given a compiled retry binary
+	binary := buildBinary(t)
+
+	// When a YAML config is piped in with its type
+	cmd := exec.Command(binary, "exponential",
+		"--config", "-", "--config-type", "yaml",
+		"--", "sh", "-c", "echo 'deployment success'; exit 1")
+	cmd.Stdin = strings.NewReader("attempts: 1\nsuccess_pattern: success\n")
+	output, err := cmd.CombinedOutput()
+
+	// Then its settings should apply
+	require.NoError(t, err, string(output))
+}
+
 func TestCLI_AutoDiscoverConfigFile(t *testing.T) {
 	// Given a compiled retry binary
 	binary := buildBinary(t)
@@ -282,6 +391,62 @@ success_pattern = "success"
 	require.NoError(t, err)
 }
 
+func TestCLI_WhichConfig(t *testing.T) {
+	// Given a compiled retry binary
+	binary := buildBinary(t)
+	absBinary, err := filepath.Abs(binary)
+	require.NoError(t, err)
+
+	whichConfig := func(cwd, home string) string {
+		cmd := exec.Command(absBinary, "--which-config")
+		cmd.Dir = cwd
+		cmd.Env = append(os.Environ(), "HOME="+home)
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+		return string(output)
+	}
+
+	t.Run("file in cwd", func(t *testing.T) {
+		// Given a config file in the working directory
+		cwd, home := t.TempDir(), t.TempDir()
+		configFile := filepath.Join(cwd, ".patience.toml")
+		require.NoError(t, os.WriteFile(configFile, []byte("attempts = 1"), 0644))
+
+		// When asking which config is used
+		output := whichConfig(cwd, home)
+
+		// Then its path should be printed
+		assert.Equal(t, configFile+"\n", output)
+	})
+
+	t.Run("file in home", func(t *testing.T) {
+		// Given a config file only in the home directory
+		cwd, home := t.TempDir(), t.TempDir()
+		configFile := filepath.Join(home, "patience.toml")
+		require.NoError(t, os.WriteFile(configFile, []byte("attempts = 1"), 0644))
+
+		// When asking which config is used
+		output := whichConfig(cwd, home)
+
+		// Then the home directory file should be printed
+		assert.Equal(t, configFile+"\n", output)
+	})
+
+	t.Run("none present", func(t *testing.T) {
+		// Given no config files
+		cwd, home := t.TempDir(), t.TempDir()
+
+		// When asking which config is used
+		output := whichConfig(cwd, home)
+
+		// Then defaults are reported along with the search order
+		assert.True(t, strings.HasPrefix(output, "none; using defaults\nsearched:\n"), output)
+		cwdIndex := strings.Index(output, filepath.Join(cwd, ".patience.toml"))
+		homeIndex := strings.Index(output, filepath.Join(home, "patience.yaml"))
+		assert.True(t, cwdIndex >= 0 && homeIndex > cwdIndex, output)
+	})
+}
+
 func TestCLI_InvalidConfigFile(t *testing.T) {
 	// Given a compiled retry binary
 	binary := buildBinary(t)
@@ -353,6 +518,52 @@ func TestCLI_StatusOutput_Failure(t *testing.T) {
 	assert.Contains(t, outputStr, "Final Reason: max retries reached (exit code 1)")
 }
 
+func TestCLI_SummaryOnlyOnFailure(t *testing.T) {
+	// Given a compiled retry binary
+	binary := buildBinary(t)
+
+	// When a command succeeds with --summary-only-on-failure
+	cmd := exec.Command(binary, "fixed", "--delay", "0", "--summary-only-on-failure", "--", "true")
+	output, err := cmd.CombinedOutput()
+
+	// Then per-attempt progress should remain but the summary be absent
+	require.NoError(t, err)
+	assert.Contains(t, string(output), "[retry] Attempt 1/3 starting...")
+	assert.NotContains(t, string(output), "Run Statistics:")
+	assert.NotContains(t, string(output), "Command succeeded")
+
+	// When a command fails with --summary-only-on-failure
+	cmd = exec.Command(binary, "fixed", "--delay", "0", "--attempts", "2", "--summary-only-on-failure", "--", "false")
+	output, err = cmd.CombinedOutput()
+
+	// Then the summary should be printed
+	require.Error(t, err)
+	assert.Contains(t, string(output), "❌ [retry] Command failed after 2 attempts.")
+	assert.Contains(t, string(output), "Run Statistics:")
+}
+
+func TestCLI_QuietSummaryOnlyOnFailure(t *testing.T) {
+	// Given a compiled retry binary
+	binary := buildBinary(t)
+
+	// When a command succeeds with --quiet and --summary-only-on-failure
+	cmd := exec.Command(binary, "fixed", "--delay", "0", "--quiet", "--summary-only-on-failure", "--", "true")
+	output, err := cmd.CombinedOutput()
+
+	// Then patience should print nothing at all
+	require.NoError(t, err)
+	assert.Empty(t, string(output))
+
+	// When a command fails with both flags
+	cmd = exec.Command(binary, "fixed", "--delay", "0", "--attempts", "2", "--quiet", "--summary-only-on-failure", "--", "false")
+	output, err = cmd.CombinedOutput()
+
+	// Then only the summary should be printed
+	require.Error(t, err)
+	assert.NotContains(t, string(output), "[retry] Attempt")
+	assert.Contains(t, string(output), "Run Statistics:")
+}
+
 func TestCLI_StatusOutput_WithDelay(t *testing.T) {
 	// Given a compiled retry binary
 	binary := buildBinary(t)
@@ -366,7 +577,7 @@ func TestCLI_StatusOutput_WithDelay(t *testing.T) {
 
 	// And show delay in status messages
 	outputStr := string(output)
-	assert.Contains(t, outputStr, "[retry] Attempt 1/2 failed (exit code 1). Retrying in 0.1s.")
+	assert.Contains(t, outputStr, "[retry] Attempt 1/2 failed (exit code 1). Retrying in 100ms.")
 }
 
 func TestCLI_MetricsIntegration_DaemonNotRunning(t *testing.T) {
@@ -541,7 +752,20 @@ func TestCLI_ConfigurationValidation(t *testing.T) {
 	// Then it should return validation error
 	require.Error(t, err)
 	outputStr := string(output)
-	assert.Contains(t, outputStr, "must be between 1 and 1000")
+	assert.Contains(t, outputStr, "--attempts 0 retries without limit and needs a bound")
+}
+
+func TestCLI_UnlimitedAttemptsWithBound(t *testing.T) {
+	// Given a compiled retry binary
+	binary := buildBinary(t)
+
+	// When running with unlimited attempts bounded by total time
+	cmd := exec.Command(binary, "fixed", "--attempts", "0", "--max-total-time", "10s", "--", "sh", "-c", "exit 0")
+	output, err := cmd.CombinedOutput()
+
+	// Then the run is allowed and succeeds
+	require.NoError(t, err, string(output))
+	assert.Contains(t, string(output), "Attempt 1 starting")
 }
 
 func TestCLI_DebugConfiguration(t *testing.T) {
@@ -652,3 +876,27 @@ func TestCLI_ComplexEnvironmentConfiguration(t *testing.T) {
 	assert.Contains(t, outputStr, "test")
 	assert.Contains(t, outputStr, "Total Attempts: 1") // Should succeed on first try
 }
+
+func TestCLI_SuccessIfStderrMatches(t *testing.T) {
+	// Given a compiled retry binary
+	binary := buildBinary(t)
+
+	// When a command exits 1 with a benign "already exists" message on stderr
+	cmd := exec.Command(binary, "fixed", "--delay", "10ms", "--attempts", "3",
+		"--success-if-stderr-matches", "already exists",
+		"--", "sh", "-c", "echo 'resource already exists' >&2; exit 1")
+	output, err := cmd.CombinedOutput()
+
+	// Then it should exit 0 after a single attempt
+	require.NoError(t, err, string(output))
+	assert.Contains(t, string(output), "Total Attempts: 1")
+
+	// When a different error occurs
+	cmd = exec.Command(binary, "fixed", "--delay", "10ms", "--attempts", "2",
+		"--success-if-stderr-matches", "already exists",
+		"--", "sh", "-c", "echo 'permission denied' >&2; exit 1")
+	err = cmd.Run()
+
+	// Then the nonzero exit should still be treated as a failure
+	require.Error(t, err)
+}
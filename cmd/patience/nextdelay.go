@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/shaneisley/patience/pkg/backoff"
+	"github.com/spf13/cobra"
+)
+
+// Units next-delay can print the delay in
+const (
+	nextDelayUnitSeconds      = "s"
+	nextDelayUnitMilliseconds = "ms"
+	nextDelayUnitDuration     = "duration"
+)
+
+// NextDelayConfig holds configuration for the next-delay subcommand
+type NextDelayConfig struct {
+	Strategy string
+	Attempt  int
+	Unit     string
+
+	// LastResponse is a file holding the output of the failed attempt, which
+	// http-aware reads for Retry-After and rate limit hints
+	LastResponse string
+
+	StrategyParams
+}
+
+// createNextDelayCommand creates the next-delay subcommand
+func createNextDelayCommand() *cobra.Command {
+	config := NextDelayConfig{}
+
+	cmd := &cobra.Command{
+		Use:   "next-delay [OPTIONS] STRATEGY --attempt N",
+		Short: "Print the delay a strategy would wait after an attempt",
+		Long: `Print the delay a strategy waits after failed attempt N and exit, without
+running anything, for orchestrators that drive retries themselves.
+
+Strategies that keep state between delays are stepped through the earlier
+attempts first. http-aware needs --last-response, a file with the failed
+attempt's output (such as curl -i), to honour Retry-After and rate limit
+headers; without a hint it falls back to --fallback.
+
+Supported strategies: fixed, linear, exponential, jitter, decorrelated-jitter,
+fibonacci, polynomial, http-aware. adaptive, pid, remote-schedule and
+diophantine are not supported: their delays come from outcomes, a fetched
+schedule or the daemon rather than the attempt number.`,
+		Example: `  patience next-delay exponential --attempt 3 --base-delay 2s
+  patience next-delay http-aware --attempt 2 --last-response response.txt --unit ms`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config.Strategy = args[0]
+			return runNextDelay(cmd.OutOrStdout(), config)
+		},
+	}
+
+	cmd.Flags().IntVarP(&config.Attempt, "attempt", "a", 0, "Failed attempt number to compute the following delay for (from 1)")
+	cmd.Flags().StringVar(&config.Unit, "unit", nextDelayUnitSeconds, "Unit to print: s, ms or duration (e.g. 1m30s)")
+	cmd.Flags().StringVar(&config.LastResponse, "last-response", "", "File with the failed attempt's output (required for http-aware)")
+	addStrategyParamFlags(cmd, &config.StrategyParams)
+	_ = cmd.MarkFlagRequired("attempt")
+
+	return cmd
+}
+
+// runNextDelay computes the delay after the configured attempt and writes it
+// to w in the configured unit
+func runNextDelay(w io.Writer, config NextDelayConfig) error {
+	if config.Attempt < 1 {
+		return fmt.Errorf("--attempt must be at least 1, got %d", config.Attempt)
+	}
+	switch config.Unit {
+	case nextDelayUnitSeconds, nextDelayUnitMilliseconds, nextDelayUnitDuration:
+	default:
+		return fmt.Errorf("invalid --unit %q (expected s, ms or duration)", config.Unit)
+	}
+	newStrategy, err := strategyFactory(config.Strategy, config.StrategyParams)
+	if err != nil {
+		return err
+	}
+	strategy := newStrategy()
+
+	var response []byte
+	httpAware, needsResponse := strategy.(backoff.HTTPAwareStrategy)
+	if needsResponse {
+		if config.LastResponse == "" {
+			return fmt.Errorf("%s needs --last-response with the failed attempt's output", config.Strategy)
+		}
+		response, err = os.ReadFile(config.LastResponse)
+		if err != nil {
+			return fmt.Errorf("failed to read last response: %w", err)
+		}
+	}
+
+	for attempt := 1; attempt < config.Attempt; attempt++ {
+		strategy.Delay(attempt)
+	}
+	if needsResponse {
+		httpAware.ProcessCommandOutput(string(response), "", 1)
+	}
+	fmt.Fprintln(w, formatNextDelay(strategy.Delay(config.Attempt), config.Unit))
+	return nil
+}
+
+// formatNextDelay renders a delay in the given unit; seconds may be fractional
+// and milliseconds are whole
+func formatNextDelay(delay time.Duration, unit string) string {
+	switch unit {
+	case nextDelayUnitMilliseconds:
+		return strconv.FormatInt(delay.Milliseconds(), 10)
+	case nextDelayUnitDuration:
+		return delay.String()
+	default:
+		return strconv.FormatFloat(delay.Seconds(), 'f', -1, 64)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runNextDelayCommand runs next-delay with args and returns its output
+func runNextDelayCommand(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	rootCmd := createTestRootCommand()
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetErr(&bytes.Buffer{})
+	rootCmd.SetArgs(append([]string{"next-delay"}, args...))
+	err := rootCmd.Execute()
+	return out.String(), err
+}
+
+func TestNextDelaySubcommand(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "exponential first attempt", args: []string{"exponential", "--attempt", "1", "--base-delay", "2s"}, want: "2"},
+		{name: "exponential third attempt", args: []string{"exponential", "--attempt", "3", "--base-delay", "2s"}, want: "8"},
+		{name: "exponential capped", args: []string{"exp", "--attempt", "10", "--max-delay", "30s"}, want: "30"},
+		{name: "exponential fractional seconds", args: []string{"exp", "--attempt", "2", "--base-delay", "250ms"}, want: "0.5"},
+		{name: "fibonacci first attempt", args: []string{"fibonacci", "--attempt", "1", "--unit", "ms"}, want: "1000"},
+		{name: "fibonacci fifth attempt", args: []string{"fibonacci", "--attempt", "5", "--unit", "ms"}, want: "5000"},
+		{name: "fibonacci sixth attempt", args: []string{"fib", "--attempt", "6", "--unit", "duration"}, want: "8s"},
+		{name: "polynomial third attempt", args: []string{"polynomial", "--attempt", "3", "--exponent", "2"}, want: "9"},
+		{name: "linear second attempt", args: []string{"linear", "--attempt", "2", "--increment", "3s"}, want: "6"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// When next-delay runs
+			out, err := runNextDelayCommand(t, tt.args...)
+
+			// Then it prints the strategy's delay and nothing else
+			require.NoError(t, err)
+			assert.Equal(t, tt.want+"\n", out)
+		})
+	}
+}
+
+func TestNextDelaySubcommand_HTTPAware(t *testing.T) {
+	// Given a saved 429 response asking to wait 7 seconds
+	response := filepath.Join(t.TempDir(), "response.txt")
+	require.NoError(t, os.WriteFile(response,
+		[]byte("HTTP/1.1 429 Too Many Requests\r\nRetry-After: 7\r\n\r\n"), 0644))
+
+	// When next-delay computes the http-aware delay from it
+	out, err := runNextDelayCommand(t, "http-aware", "--attempt", "2", "--last-response", response, "--unit", "ms")
+
+	// Then Retry-After wins over the fallback
+	require.NoError(t, err)
+	assert.Equal(t, "7000\n", out)
+
+	// And a response without hints uses the fallback's delay for the attempt
+	require.NoError(t, os.WriteFile(response, []byte("HTTP/1.1 503 Service Unavailable\r\n\r\n"), 0644))
+	out, err = runNextDelayCommand(t, "http-aware", "--attempt", "2", "--last-response", response)
+	require.NoError(t, err)
+	assert.Equal(t, "2\n", out)
+}
+
+func TestNextDelaySubcommand_InvalidInput(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "missing attempt", args: []string{"exponential"}, wantErr: `"attempt" not set`},
+		{name: "attempt zero", args: []string{"exponential", "--attempt", "0"}, wantErr: "--attempt must be at least 1"},
+		{name: "unsupported strategy", args: []string{"adaptive", "--attempt", "1"}, wantErr: `unknown strategy "adaptive"`},
+		{name: "http-aware falling back to itself", args: []string{"http-aware", "--attempt", "1", "--fallback", "ha", "--last-response", "/dev/null"}, wantErr: "invalid fallback"},
+		{name: "invalid polynomial", args: []string{"polynomial", "--attempt", "1", "--base-delay", "0s"}, wantErr: "base delay must be positive"},
+		{name: "bad unit", args: []string{"fixed", "--attempt", "1", "--unit", "h"}, wantErr: `invalid --unit "h"`},
+		{name: "http-aware without response", args: []string{"http-aware", "--attempt", "1"}, wantErr: "needs --last-response"},
+		{name: "missing response file", args: []string{"http-aware", "--attempt", "1", "--last-response", "/nonexistent"}, wantErr: "failed to read last response"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := runNextDelayCommand(t, tt.args...)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
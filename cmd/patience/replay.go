@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/shaneisley/patience/pkg/metrics"
+	"github.com/spf13/cobra"
+)
+
+// createReplayMetricsCommand creates the replay-metrics subcommand
+func createReplayMetricsCommand() *cobra.Command {
+	socketPath := metrics.DefaultSocketPath()
+
+	cmd := &cobra.Command{
+		Use:   "replay-metrics [OPTIONS] FILE",
+		Short: "Send recorded run metrics to the metrics daemon",
+		Long: `Read run metrics from an NDJSON file, one JSON record per line, and send each
+record to the metrics daemon so its aggregates cover runs made while it was down.
+Use "-" to read from standard input. Malformed lines are skipped and counted.`,
+		Example: `  patience replay-metrics runs.ndjson
+  patience replay-metrics --socket /var/run/patience/daemon.sock runs.ndjson`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var in io.Reader = cmd.InOrStdin()
+			if args[0] != "-" {
+				f, err := os.Open(args[0])
+				if err != nil {
+					return fmt.Errorf("failed to open metrics file: %w", err)
+				}
+				defer f.Close()
+				in = f
+			}
+			return runReplayMetrics(cmd.OutOrStdout(), in, metrics.NewClient(socketPath))
+		},
+	}
+
+	cmd.Flags().StringVar(&socketPath, "socket", socketPath, "Metrics daemon socket path")
+
+	return cmd
+}
+
+// runReplayMetrics sends the records read from in to the daemon and writes a
+// summary to w
+func runReplayMetrics(w io.Writer, in io.Reader, client *metrics.Client) error {
+	result, err := metrics.Replay(in, client.SendMetrics)
+	fmt.Fprintf(w, "Replayed %d record(s), skipped %d malformed line(s)\n", result.Sent, result.Skipped)
+	return err
+}
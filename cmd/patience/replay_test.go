@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shaneisley/patience/pkg/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startMockMetricsDaemon accepts one metrics payload per connection on a unix
+// socket and forwards the decoded records
+func startMockMetricsDaemon(t *testing.T) (string, <-chan metrics.RunMetrics) {
+	t.Helper()
+	socketPath := fmt.Sprintf("/tmp/patience-replay-%d.sock", time.Now().UnixNano())
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		listener.Close()
+		os.Remove(socketPath)
+	})
+
+	received := make(chan metrics.RunMetrics, 16)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			data, _ := io.ReadAll(conn)
+			conn.Close()
+			payload, err := metrics.DecodePayload(data)
+			if err != nil {
+				continue
+			}
+			var record metrics.RunMetrics
+			if json.Unmarshal(payload, &record) == nil {
+				received <- record
+			}
+		}
+	}()
+	return socketPath, received
+}
+
+func TestReplayMetricsSubcommand(t *testing.T) {
+	// Given a mock daemon and a metrics file with one malformed line
+	socketPath, received := startMockMetricsDaemon(t)
+	file := filepath.Join(t.TempDir(), "runs.ndjson")
+	require.NoError(t, os.WriteFile(file, []byte(
+		`{"command":"curl a","final_status":"succeeded","total_attempts":1,"timestamp":1700000000}`+"\n"+
+			`garbage`+"\n"+
+			`{"command":"curl b","final_status":"failed","total_attempts":3,"timestamp":1700000060}`+"\n"), 0o644))
+
+	rootCmd := createTestRootCommand()
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"replay-metrics", "--socket", socketPath, file})
+
+	// When replaying the file
+	require.NoError(t, rootCmd.Execute())
+
+	// Then the daemon receives both records and the bad line is reported
+	assert.Contains(t, out.String(), "Replayed 2 record(s), skipped 1 malformed line(s)")
+	var commands []string
+	for i := 0; i < 2; i++ {
+		select {
+		case record := <-received:
+			commands = append(commands, record.Command)
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for replayed metrics")
+		}
+	}
+	assert.Equal(t, []string{"curl a", "curl b"}, commands)
+}
+
+func TestReplayMetricsSubcommand_DaemonNotRunning(t *testing.T) {
+	// Given a metrics file but no daemon listening
+	file := filepath.Join(t.TempDir(), "runs.ndjson")
+	require.NoError(t, os.WriteFile(file, []byte(`{"command":"curl a","final_status":"failed"}`+"\n"), 0o644))
+
+	rootCmd := createTestRootCommand()
+	rootCmd.SetOut(io.Discard)
+	rootCmd.SetErr(io.Discard)
+	rootCmd.SetArgs([]string{"replay-metrics", "--socket", filepath.Join(t.TempDir(), "missing.sock"), file})
+
+	// When replaying the file
+	err := rootCmd.Execute()
+
+	// Then the connection failure is reported
+	assert.ErrorContains(t, err, "failed to connect to daemon")
+}
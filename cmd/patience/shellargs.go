@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shaneisley/patience/pkg/executor"
+)
+
+// findShellSyntax returns the first argument that looks like shell syntax: a
+// bare operator, an operator between words (e.g. "curl x | jq ."), or a command
+// substitution. Operators inside a single word, such as the regex "a|b", are
+// left alone because they are usually meant literally, and so is the script
+// after -c, which the command (e.g. sh -c or python -c) interprets itself.
+func findShellSyntax(args []string) (string, bool) {
+	for i, arg := range args {
+		if i > 0 && args[i-1] == "-c" {
+			continue
+		}
+		if executor.IsShellOperator(arg) || strings.Contains(arg, "$(") || strings.Contains(arg, "`") {
+			return arg, true
+		}
+		for _, field := range strings.Fields(arg) {
+			if field != arg && executor.IsShellOperator(field) {
+				return arg, true
+			}
+		}
+	}
+	return "", false
+}
+
+// checkShellArgs enables shell execution for --shell, and otherwise warns (or
+// with --strict-args, fails) when the command contains shell syntax that will
+// be passed to the program literally
+func checkShellArgs(exec *executor.Executor, config CommonConfig, args []string) error {
+	if config.Shell {
+		exec.Shell = true
+		return nil
+	}
+
+	arg, found := findShellSyntax(args)
+	if !found {
+		return nil
+	}
+
+	message := fmt.Sprintf("argument %q contains shell syntax, which is passed to the command literally; use --shell to run it through sh -c", arg)
+	if config.StrictArgs {
+		return errors.New(message)
+	}
+	if exec.Reporter != nil {
+		exec.Reporter.ShowWarning(message)
+	}
+	return nil
+}
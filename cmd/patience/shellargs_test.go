@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/shaneisley/patience/pkg/executor"
+	"github.com/shaneisley/patience/pkg/ui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindShellSyntax(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		found bool
+	}{
+		{"bare pipe", []string{"echo", "hi", "|", "grep", "h"}, true},
+		{"quoted pipeline", []string{"curl -s example.com | jq ."}, true},
+		{"and list", []string{"make && make test"}, true},
+		{"redirect", []string{"echo", ">", "out.txt"}, true},
+		{"command substitution", []string{"echo", "$(date)"}, true},
+		{"backticks", []string{"echo", "`date`"}, true},
+		{"plain arguments", []string{"curl", "-f", "https://example.com"}, false},
+		{"regex alternation", []string{"grep", "-E", "error|warn", "log.txt"}, false},
+		{"url query", []string{"curl", "https://example.com/?a=1&b=2"}, false},
+		{"sh -c script", []string{"sh", "-c", "curl -s example.com | jq . > out.json"}, false},
+		{"bash -c substitution", []string{"bash", "-c", "echo $(date)"}, false},
+		{"pipe after sh -c script", []string{"sh", "-c", "echo hi", "|", "grep", "h"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, found := findShellSyntax(tt.args)
+			assert.Equal(t, tt.found, found)
+		})
+	}
+}
+
+func TestCheckShellArgs(t *testing.T) {
+	newExecutor := func(buf *bytes.Buffer) *executor.Executor {
+		exec := executor.NewExecutor(1)
+		exec.Reporter = ui.NewReporter(buf)
+		return exec
+	}
+	args := []string{"echo", "hi", "|", "grep", "h"}
+
+	t.Run("warns about a pipe", func(t *testing.T) {
+		// Given a command with a literal pipe argument
+		var buf bytes.Buffer
+		exec := newExecutor(&buf)
+
+		// When the arguments are checked
+		err := checkShellArgs(exec, NewCommonConfig(), args)
+
+		// Then it warns and suggests --shell without changing execution
+		require.NoError(t, err)
+		assert.Contains(t, buf.String(), `[warning] argument "|" contains shell syntax`)
+		assert.Contains(t, buf.String(), "use --shell")
+		assert.False(t, exec.Shell)
+	})
+
+	t.Run("shell suppresses the warning", func(t *testing.T) {
+		// Given --shell
+		var buf bytes.Buffer
+		exec := newExecutor(&buf)
+		config := NewCommonConfig()
+		config.Shell = true
+
+		// When the arguments are checked
+		err := checkShellArgs(exec, config, args)
+
+		// Then no warning is shown and the command runs through the shell
+		require.NoError(t, err)
+		assert.Empty(t, buf.String())
+		assert.True(t, exec.Shell)
+	})
+
+	t.Run("strict args fails", func(t *testing.T) {
+		// Given --strict-args
+		var buf bytes.Buffer
+		exec := newExecutor(&buf)
+		config := NewCommonConfig()
+		config.StrictArgs = true
+
+		// When the arguments are checked
+		err := checkShellArgs(exec, config, args)
+
+		// Then it returns an error instead of warning
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "use --shell")
+		assert.Empty(t, buf.String())
+	})
+}
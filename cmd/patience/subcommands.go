@@ -2,7 +2,9 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"regexp"
 	"strings"
@@ -12,6 +14,7 @@ import (
 	"github.com/shaneisley/patience/pkg/conditions"
 	"github.com/shaneisley/patience/pkg/config"
 	"github.com/shaneisley/patience/pkg/daemon"
+	"github.com/shaneisley/patience/pkg/discovery"
 	"github.com/shaneisley/patience/pkg/executor"
 	"github.com/shaneisley/patience/pkg/metrics"
 	"github.com/shaneisley/patience/pkg/ui"
@@ -34,39 +37,559 @@ type CommonConfig struct {
 	SuccessPattern  string        `json:"success_pattern"`
 	FailurePattern  string        `json:"failure_pattern"`
 	CaseInsensitive bool          `json:"case_insensitive"`
-	ConfigFile      string        `json:"-"` // Config file path (not serialized)
-	DebugConfig     bool          `json:"-"` // Debug config flag (not serialized)
+	SuccessJSONEq   []string      `json:"success_json_eq"`
+
+	// AbortPattern stops retrying on a match, keeping the attempt's exit code and reason
+	AbortPattern string `json:"abort_pattern"`
+
+	// FatalPattern stops the run on a match in any attempt, exiting with FatalExit
+	FatalPattern string `json:"fatal_pattern"`
+	FatalExit    int    `json:"fatal_exit"`
+
+	// ReasonTemplate is a Go template rendering failed attempts' reasons from executor.ReasonData
+	ReasonTemplate string `json:"reason_template"`
+
+	// RequireSuccessPattern succeeds only when SuccessPattern matches, never on the
+	// exit code or an empty 2xx HTTP response alone
+	RequireSuccessPattern bool `json:"require_success_pattern"`
+
+	// RetryJSONErrorCodes retries failures whose JSON body carries one of these
+	// error codes and stops on any other code
+	RetryJSONErrorCodes []string `json:"retry_json_error_codes"`
+
+	// NormalizeNewlines converts \r\n and \r to \n before patterns are matched
+	NormalizeNewlines bool `json:"normalize_newlines"`
+
+	// RegexTimeout bounds each success, failure or allowlist pattern match (0 = unbounded)
+	RegexTimeout time.Duration `json:"regex_timeout"`
+
+	// PatternStream is the stream success and failure patterns match: stdout, stderr or both
+	PatternStream string `json:"pattern_stream"`
+
+	// MergeStreams captures stdout and stderr as one interleaved stream, like 2>&1
+	MergeStreams bool `json:"merge_streams"`
+
+	// Allowlist patterns that declare a nonzero exit successful
+	SuccessIfStdoutMatches string `json:"success_if_stdout_matches"`
+	SuccessIfStderrMatches string `json:"success_if_stderr_matches"`
+
+	// StrategyFallbackOnError substitutes exponential defaults when a strategy
+	// constructor rejects its configuration instead of aborting the run
+	StrategyFallbackOnError bool `json:"strategy_fallback_on_error"`
+
+	// ConfirmLongWaits prompts on a TTY before delays longer than this threshold
+	ConfirmLongWaits time.Duration `json:"confirm_long_waits"`
+
+	// TimeoutOverhead is "auto" (proportional default) or a fixed duration added to Timeout
+	TimeoutOverhead string `json:"timeout_overhead"`
+
+	// WarnAfter warns when an attempt runs longer than this without cancelling it
+	WarnAfter time.Duration `json:"warn_after"`
+
+	// RequirePasses succeeds once this many of the attempts have passed (--of sets Attempts)
+	RequirePasses int `json:"require_passes"`
+
+	// CumulativePattern matches patterns against all attempts' output so far,
+	// retaining at most MaxOutputSize bytes per stream
+	CumulativePattern bool `json:"cumulative_pattern"`
+	MaxOutputSize     int  `json:"max_output_size"`
+
+	// DryRun prints the delay schedule in Format instead of executing the command
+	DryRun bool   `json:"-"`
+	Format string `json:"-"`
+
+	// Output selects machine-readable JSON on stdout: text (none), ndjson or json-events
+	Output string `json:"output"`
+
+	// Color is auto, always or never for the human-readable messages on
+	// stderr; JSON output is never colored
+	Color string `json:"color"`
+
+	// Minimum captured output for an attempt to succeed (0 = no minimum)
+	MinOutputLines int `json:"min_output_lines"`
+	MinOutputBytes int `json:"min_output_bytes"`
+
+	// RetryUntilStable succeeds once output is unchanged for this many consecutive attempts
+	RetryUntilStable int `json:"retry_until_stable"`
+
+	// Tags label run metrics as key=value pairs for aggregation by the daemon
+	Tags []string `json:"tags"`
+
+	// Metrics record numeric key=number fields in run metrics, e.g. build_number=1234
+	Metrics []string `json:"metrics"`
+
+	// FailureCategories classify failed runs in metrics as NAME=REGEX, e.g. auth=401|403
+	FailureCategories []string `json:"failure_categories"`
+
+	// MetricsSync waits for the run's metrics to be written to the daemon socket instead of
+	// sending them in the background
+	MetricsSync bool `json:"metrics_sync"`
+
+	// MetricsFile appends the run's metrics to an NDJSON file for replay-metrics
+	MetricsFile string `json:"metrics_file"`
+
+	// StateFile persists attempt progress so a killed run can resume its schedule
+	StateFile string `json:"state_file"`
+
+	// StateMaxAge ignores a state file last updated longer ago than this
+	StateMaxAge time.Duration `json:"state_max_age"`
+
+	// AttemptsFile counts attempts across invocations so --attempts caps their total
+	AttemptsFile string `json:"attempts_file"`
+
+	// DiscoveryCache keeps discovered rate limits in a file across runs, each
+	// entry usable for DiscoveryCacheTTL after it was last seen
+	DiscoveryCache    string        `json:"discovery_cache"`
+	DiscoveryCacheTTL time.Duration `json:"discovery_cache_ttl"`
+
+	// PrefixOutput marks each line of the command's output with its attempt number
+	PrefixOutput bool `json:"prefix_output"`
+
+	// TimingMarkers prints a parseable "PATIENCE attempt=N ..." line to stderr per attempt
+	TimingMarkers bool `json:"timing_markers"`
+
+	// ProgressFIFO is a named pipe receiving a compact record per attempt event
+	ProgressFIFO string `json:"progress_fifo"`
+
+	// DelayCommand is a shell command that prints the delay after each failed attempt
+	DelayCommand string `json:"delay_command"`
+
+	// DelayJSONPath names a field of the response body JSON holding the delay
+	// after a failed attempt, counted in DelayJSONUnit ("ms" or "s")
+	DelayJSONPath string `json:"delay_json_path"`
+	DelayJSONUnit string `json:"delay_json_unit"`
+
+	// CapDelay bounds every delay between attempts (0 = no cap)
+	CapDelay time.Duration `json:"cap_delay"`
+
+	// Monotonic keeps each delay at least as long as the previous one
+	Monotonic bool `json:"monotonic"`
+
+	// CostPerAttempt and MaxCost stop retrying before the budget would be exceeded
+	CostPerAttempt float64 `json:"cost_per_attempt"`
+	MaxCost        float64 `json:"max_cost"`
+
+	// CheckCommand is a shell command whose exit code decides each attempt's outcome
+	CheckCommand string `json:"check_command"`
+
+	// RetryIf is an expression deciding after each attempt whether to retry it,
+	// e.g. "exit != 0 and not match(out, 'done') or http == 503"
+	RetryIf string `json:"retry_if"`
+
+	// AttemptCaps is a per-attempt cap schedule such as "1:1s,3:10s,5:60s"
+	AttemptCaps string `json:"attempt_caps"`
+
+	// MinFreeDisk and MinFreeMem stop retrying when free disk space in the
+	// working directory or available memory drops below a size such as "1G"
+	MinFreeDisk string `json:"min_free_disk"`
+	MinFreeMem  string `json:"min_free_mem"`
+
+	// AllowedWindow holds attempts until the time of day is inside a window such
+	// as "22:00-06:00" (optionally followed by a time zone)
+	AllowedWindow string `json:"allowed_window"`
+
+	// MaxTotalTime stops the run instead of waiting past this long since it started
+	MaxTotalTime time.Duration `json:"max_total_time"`
+
+	// AlignInterval stretches each delay so retries start on the next multiple
+	// of this interval on the wall clock (0 = no alignment)
+	AlignInterval time.Duration `json:"align_interval"`
+
+	// ResetBackoffOnProgress restarts the backoff from the base delay whenever a
+	// failed attempt's output matches ProgressPattern
+	ResetBackoffOnProgress bool   `json:"reset_backoff_on_progress"`
+	ProgressPattern        string `json:"progress_pattern"`
+
+	// Shell runs the command through sh -c; StrictArgs rejects shell syntax without it
+	Shell      bool `json:"shell"`
+	StrictArgs bool `json:"strict_args"`
+
+	// Steps splits the command at "--and" into steps each attempt runs in sequence
+	Steps bool `json:"steps"`
+
+	// EnvAllowlist (NAME or NAME=VALUE entries) and EnvClear limit the command's environment
+	EnvAllowlist []string `json:"env_allowlist"`
+	EnvClear     bool     `json:"env_clear"`
+
+	// RedactHeaders and ShowHeaders adjust which header values are hidden when the
+	// command is printed or recorded (Authorization, Cookie and X-Api-Key by default)
+	RedactHeaders []string `json:"redact_headers"`
+	ShowHeaders   []string `json:"show_headers"`
+
+	// Verbose adds detail such as discovered rate limits to the final summary
+	Verbose bool `json:"verbose"`
+
+	// PatternMetrics adds the match counts and timings of each configured
+	// pattern to the final summary
+	PatternMetrics bool `json:"pattern_metrics"`
+
+	// Quiet hides per-attempt progress and warnings; SummaryOnlyOnFailure skips
+	// the final summary when the run succeeds
+	Quiet                bool `json:"quiet"`
+	SummaryOnlyOnFailure bool `json:"summary_only_on_failure"`
+
+	// FirstSuccessExitFast skips the statistics, summary and metrics work after
+	// a first-attempt success
+	FirstSuccessExitFast bool `json:"first_success_exit_fast"`
+
+	// IfRunning is wait, skip or fail when another invocation holds the run lock
+	// (LockFile, or one derived from the command); empty disables locking
+	IfRunning string `json:"if_running"`
+	LockFile  string `json:"lock_file"`
+
+	ConfigFile  string `json:"-"` // Config file path, or "-" for stdin (not serialized)
+	ConfigType  string `json:"-"` // Format of a config read from stdin (not serialized)
+	DebugConfig bool   `json:"-"` // Debug config flag (not serialized)
 
 	// Daemon configuration
 	DaemonEnabled   bool          `json:"daemon_enabled"`
 	DaemonSocket    string        `json:"daemon_socket"`
 	DaemonTimeout   time.Duration `json:"daemon_timeout"`
 	DaemonAutoStart bool          `json:"daemon_auto_start"`
+
+	// DaemonCoordination is best-effort (fall back to local scheduling when the
+	// daemon can't be reached) or required (abort instead)
+	DaemonCoordination string `json:"daemon_coordination"`
+
+	// DaemonMaxRegistrations bounds the planned requests registered with the
+	// daemon ahead of the current attempt (0 = the executor's default)
+	DaemonMaxRegistrations int `json:"daemon_max_registrations"`
 }
 
 // Validate validates the common configuration
 func (c CommonConfig) Validate() error {
-	if c.Attempts < 1 || c.Attempts > 1000 {
-		return fmt.Errorf("attempts must be between 1 and 1000, got %d", c.Attempts)
+	if err := c.validateAttempts(); err != nil {
+		return err
+	}
+	if err := c.validateTimeouts(); err != nil {
+		return err
+	}
+	if err := c.validateOutput(); err != nil {
+		return err
+	}
+	if err := c.validateDryRun(); err != nil {
+		return err
+	}
+	if err := c.validateBudgets(); err != nil {
+		return err
+	}
+	if err := c.validateCoordination(); err != nil {
+		return err
+	}
+	if c.ConfigType != "" && c.ConfigFile != config.StdinPath {
+		return fmt.Errorf("--config-type only applies to --config - (stdin)")
+	}
+	if err := c.validatePreconditions(); err != nil {
+		return err
+	}
+	if err := c.validateDelays(); err != nil {
+		return err
+	}
+	if err := c.validatePatterns(); err != nil {
+		return err
+	}
+	if err := c.validateConditions(); err != nil {
+		return err
+	}
+	if err := c.validateReporting(); err != nil {
+		return err
+	}
+	_, err := c.envPolicy()
+	return err
+}
+
+// validateAttempts checks the attempt limit and the passes it must hold
+func (c CommonConfig) validateAttempts() error {
+	if c.Attempts < 0 || c.Attempts > 1000 {
+		return fmt.Errorf("attempts must be between 1 and 1000, or 0 for unlimited, got %d", c.Attempts)
+	}
+	if c.Attempts == 0 && c.MaxTotalTime <= 0 && c.MaxCost <= 0 {
+		return fmt.Errorf("--attempts 0 retries without limit and needs a bound: set --max-total-time or --max-cost")
 	}
 
+	if c.RequirePasses < 0 {
+		return fmt.Errorf("require-passes must be non-negative, got %d", c.RequirePasses)
+	}
+	if c.Attempts > 0 && c.RequirePasses > c.Attempts {
+		return fmt.Errorf("require-passes (%d) cannot exceed attempts (%d)", c.RequirePasses, c.Attempts)
+	}
+	return nil
+}
+
+// validateTimeouts checks the per-attempt and whole-run durations
+func (c CommonConfig) validateTimeouts() error {
 	if c.Timeout < 0 {
 		return fmt.Errorf("timeout must be non-negative, got %v", c.Timeout)
 	}
+	if c.RegexTimeout < 0 {
+		return fmt.Errorf("regex-timeout must be non-negative, got %v", c.RegexTimeout)
+	}
+
+	if c.WarnAfter < 0 {
+		return fmt.Errorf("warn-after must be non-negative, got %v", c.WarnAfter)
+	}
+	if c.WarnAfter > 0 && c.Timeout > 0 && c.WarnAfter >= c.Timeout {
+		return fmt.Errorf("warn-after (%v) must be less than timeout (%v)", c.WarnAfter, c.Timeout)
+	}
+	if _, err := parseTimeoutOverhead(c.TimeoutOverhead); err != nil {
+		return err
+	}
+
+	if c.ConfirmLongWaits < 0 {
+		return fmt.Errorf("confirm-long-waits must be non-negative, got %v", c.ConfirmLongWaits)
+	}
+	if c.StateMaxAge < 0 {
+		return fmt.Errorf("state-max-age must be non-negative, got %v", c.StateMaxAge)
+	}
+	if c.MaxTotalTime < 0 {
+		return fmt.Errorf("max-total-time must be non-negative, got %v", c.MaxTotalTime)
+	}
+	if c.DiscoveryCacheTTL < 0 {
+		return fmt.Errorf("discovery-cache-ttl must be non-negative, got %v", c.DiscoveryCacheTTL)
+	}
+	if c.AlignInterval < 0 {
+		return fmt.Errorf("align-interval must be non-negative, got %v", c.AlignInterval)
+	}
+	return nil
+}
+
+// validateOutput checks how progress and captured output are shown
+func (c CommonConfig) validateOutput() error {
+	if c.Output != "" && !ui.ValidOutputMode(c.Output) {
+		return fmt.Errorf("unknown output %q (valid: %s, %s, %s)", c.Output, ui.OutputText, ui.OutputNDJSON, ui.OutputJSONEvents)
+	}
+	if c.FirstSuccessExitFast && c.Output != "" && c.Output != ui.OutputText {
+		return fmt.Errorf("--first-success-exit-fast cannot be combined with --output %s", c.Output)
+	}
+
+	if c.Color != "" && !ui.ValidColorMode(c.Color) {
+		return fmt.Errorf("unknown color %q (valid: %s, %s, %s)", c.Color, ui.ColorAuto, ui.ColorAlways, ui.ColorNever)
+	}
+
+	if c.MaxOutputSize < 1 {
+		return fmt.Errorf("max-output-size must be positive, got %d", c.MaxOutputSize)
+	}
+	return nil
+}
 
-	// Validate regex patterns
-	if c.SuccessPattern != "" {
-		if _, err := regexp.Compile(c.SuccessPattern); err != nil {
-			return fmt.Errorf("invalid success pattern: %w", err)
+// validateDryRun checks the format of the printed schedule
+func (c CommonConfig) validateDryRun() error {
+	switch c.Format {
+	case "", dryRunFormatText:
+	case dryRunFormatCSV:
+		if !c.DryRun {
+			return fmt.Errorf("--format %s requires --dry-run", c.Format)
 		}
+	default:
+		return fmt.Errorf("unknown format %q (valid: %s, %s)", c.Format, dryRunFormatText, dryRunFormatCSV)
+	}
+
+	// The dry-run schedule is printed on stdout, where it would corrupt JSON
+	if c.DryRun && c.Output != "" && c.Output != ui.OutputText {
+		return fmt.Errorf("--dry-run cannot be combined with --output %s; use --format %s for machine-readable schedules", c.Output, dryRunFormatCSV)
+	}
+	return nil
+}
+
+// validateBudgets checks the cost budget and the per-outcome attempt caps
+func (c CommonConfig) validateBudgets() error {
+	if c.CostPerAttempt < 0 {
+		return fmt.Errorf("cost-per-attempt must be non-negative, got %g", c.CostPerAttempt)
+	}
+	if c.MaxCost < 0 {
+		return fmt.Errorf("max-cost must be non-negative, got %g", c.MaxCost)
+	}
+	if c.MaxCost > 0 && c.CostPerAttempt == 0 {
+		return fmt.Errorf("--max-cost requires --cost-per-attempt")
+	}
+	if c.MaxCost > 0 && c.CostPerAttempt > c.MaxCost {
+		return fmt.Errorf("--cost-per-attempt (%g) must not exceed --max-cost (%g)", c.CostPerAttempt, c.MaxCost)
 	}
 
-	if c.FailurePattern != "" {
-		if _, err := regexp.Compile(c.FailurePattern); err != nil {
-			return fmt.Errorf("invalid failure pattern: %w", err)
+	_, err := executor.ParseAttemptCaps(c.AttemptCaps)
+	return err
+}
+
+// validateCoordination checks daemon coordination and the overlapping-run lock
+func (c CommonConfig) validateCoordination() error {
+	if c.DaemonMaxRegistrations < 0 {
+		return fmt.Errorf("daemon-max-registrations must be non-negative, got %d", c.DaemonMaxRegistrations)
+	}
+
+	switch c.DaemonCoordination {
+	case "", executor.CoordinationBestEffort:
+	case executor.CoordinationRequired:
+		if !c.DaemonEnabled {
+			return fmt.Errorf("--daemon-coordination %s requires --daemon", c.DaemonCoordination)
 		}
+	default:
+		return fmt.Errorf("invalid daemon-coordination %q: must be %s or %s",
+			c.DaemonCoordination, executor.CoordinationBestEffort, executor.CoordinationRequired)
 	}
 
+	switch c.IfRunning {
+	case "", executor.IfRunningWait, executor.IfRunningSkip, executor.IfRunningFail:
+	default:
+		return fmt.Errorf("unknown if-running policy %q (valid: %s, %s, %s)",
+			c.IfRunning, executor.IfRunningWait, executor.IfRunningSkip, executor.IfRunningFail)
+	}
+	if c.LockFile != "" && c.IfRunning == "" {
+		return fmt.Errorf("--lock-file requires --if-running")
+	}
+	return nil
+}
+
+// validatePreconditions checks what must hold before each attempt starts:
+// free disk and memory and the allowed window
+func (c CommonConfig) validatePreconditions() error {
+	if c.MinFreeDisk != "" {
+		if _, err := executor.ParseByteSize(c.MinFreeDisk); err != nil {
+			return fmt.Errorf("invalid min-free-disk: %w", err)
+		}
+	}
+	if c.MinFreeMem != "" {
+		if _, err := executor.ParseByteSize(c.MinFreeMem); err != nil {
+			return fmt.Errorf("invalid min-free-mem: %w", err)
+		}
+	}
+	if c.AllowedWindow != "" {
+		if _, err := executor.ParseAllowedWindow(c.AllowedWindow); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateDelays checks the overrides of the strategy's delays
+func (c CommonConfig) validateDelays() error {
+	if c.CapDelay < 0 {
+		return fmt.Errorf("cap-delay must be non-negative, got %v", c.CapDelay)
+	}
+	if c.DelayJSONPath != "" {
+		if _, err := executor.NewDelayJSON(c.DelayJSONPath, c.DelayJSONUnit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validatePatterns checks that the output patterns compile and have what
+// they depend on
+func (c CommonConfig) validatePatterns() error {
+	for _, p := range []struct{ name, pattern string }{
+		{"success", c.SuccessPattern},
+		{"failure", c.FailurePattern},
+		{"abort", c.AbortPattern},
+		{"fatal", c.FatalPattern},
+		{"progress", c.ProgressPattern},
+		{"success-if-stdout-matches", c.SuccessIfStdoutMatches},
+		{"success-if-stderr-matches", c.SuccessIfStderrMatches},
+	} {
+		if err := validatePattern(p.name, p.pattern); err != nil {
+			return err
+		}
+	}
+
+	if c.RequireSuccessPattern && c.SuccessPattern == "" {
+		return fmt.Errorf("--require-success-pattern requires --success-pattern")
+	}
+	if c.FatalPattern != "" && (c.FatalExit < 1 || c.FatalExit > 255) {
+		return fmt.Errorf("fatal-exit must be between 1 and 255, got %d", c.FatalExit)
+	}
+	if c.ResetBackoffOnProgress && c.ProgressPattern == "" {
+		return fmt.Errorf("--reset-backoff-on-progress requires --progress-pattern")
+	}
+	if c.ProgressPattern != "" && !c.ResetBackoffOnProgress {
+		return fmt.Errorf("--progress-pattern has no effect without --reset-backoff-on-progress")
+	}
+	return nil
+}
+
+// validatePattern checks that a pattern, when set, compiles
+func validatePattern(name, pattern string) error {
+	if pattern == "" {
+		return nil
+	}
+	if _, err := regexp.Compile(pattern); err != nil {
+		return fmt.Errorf("invalid %s pattern: %w", name, err)
+	}
+	return nil
+}
+
+// validateConditions checks the success conditions other than the patterns
+func (c CommonConfig) validateConditions() error {
+	switch c.PatternStream {
+	case "", conditions.PatternStreamStdout, conditions.PatternStreamStderr, conditions.PatternStreamBoth:
+	default:
+		return fmt.Errorf("pattern-stream must be stdout, stderr or both, got %q", c.PatternStream)
+	}
+	if c.MergeStreams && (c.PatternStream == conditions.PatternStreamStderr || c.SuccessIfStderrMatches != "") {
+		return fmt.Errorf("--merge-streams captures stderr together with stdout; use --pattern-stream and --success-if-stdout-matches instead")
+	}
+
+	if len(c.SuccessJSONEq) > 0 {
+		checker, _ := conditions.NewChecker("", "", false)
+		for _, expr := range c.SuccessJSONEq {
+			if err := checker.AddJSONEquals(expr); err != nil {
+				return err
+			}
+		}
+	}
+	for _, code := range c.RetryJSONErrorCodes {
+		if strings.TrimSpace(code) == "" {
+			return fmt.Errorf("retry-json-error-code must not be empty")
+		}
+	}
+	if c.RetryIf != "" {
+		if _, err := conditions.CompileRetryIf(c.RetryIf); err != nil {
+			return err
+		}
+	}
+
+	if c.RetryUntilStable < 0 {
+		return fmt.Errorf("retry-until-stable must be non-negative, got %d", c.RetryUntilStable)
+	}
+	if c.MinOutputLines < 0 {
+		return fmt.Errorf("min-output-lines must be non-negative, got %d", c.MinOutputLines)
+	}
+	if c.MinOutputBytes < 0 {
+		return fmt.Errorf("min-output-bytes must be non-negative, got %d", c.MinOutputBytes)
+	}
+	return nil
+}
+
+// validateReporting checks what is reported about a run: failure reasons,
+// metrics, redacted headers and the progress FIFO
+func (c CommonConfig) validateReporting() error {
+	if c.ReasonTemplate != "" {
+		if _, err := executor.ParseReasonTemplate(c.ReasonTemplate); err != nil {
+			return err
+		}
+	}
+
+	if _, err := metrics.ParseTags(c.Tags); err != nil {
+		return err
+	}
+	if _, err := metrics.ParseMetrics(c.Metrics); err != nil {
+		return err
+	}
+	if _, err := executor.ParseFailureCategories(c.FailureCategories); err != nil {
+		return err
+	}
+
+	for _, name := range c.RedactHeaders {
+		if err := executor.ValidateHeaderName(name); err != nil {
+			return fmt.Errorf("invalid --redact-header: %w", err)
+		}
+	}
+	for _, name := range c.ShowHeaders {
+		if err := executor.ValidateHeaderName(name); err != nil {
+			return fmt.Errorf("invalid --show-header: %w", err)
+		}
+	}
+	if c.ProgressFIFO != "" {
+		return executor.ValidateProgressFIFO(c.ProgressFIFO)
+	}
 	return nil
 }
 
@@ -78,6 +601,14 @@ func NewCommonConfig() CommonConfig {
 		SuccessPattern:  "",
 		FailurePattern:  "",
 		CaseInsensitive: false,
+		TimeoutOverhead: "auto",
+		Color:           ui.ColorAuto,
+		MaxOutputSize:   executor.DefaultMaxCumulativeOutput,
+		FatalExit:       executor.DefaultFatalExitCode,
+
+		NormalizeNewlines: true,
+		PatternStream:     conditions.PatternStreamBoth,
+		DelayJSONUnit:     executor.DelayJSONUnitSeconds,
 
 		// Daemon defaults
 		DaemonEnabled:   false,
@@ -89,8 +620,33 @@ func NewCommonConfig() CommonConfig {
 
 // HTTPAwareConfig holds configuration for HTTP-aware strategy
 type HTTPAwareConfig struct {
-	Fallback string
-	MaxDelay time.Duration
+	Fallback         string
+	MaxDelay         time.Duration
+	RespectRemaining bool
+	StatusActions    string
+
+	// RetryStatus lists the statuses and classes (e.g. "5..,408") whose
+	// failed attempts are retried; other statuses stop the run
+	RetryStatus string
+
+	// SuccessStatus and SuccessJSONEq form a compound success condition on
+	// the final response: a matching status and matching body fields
+	SuccessStatus string
+	SuccessJSONEq []string
+
+	// IdempotencyHeader names a header added to curl commands with one key per run
+	IdempotencyHeader string
+
+	// LoadHeader names a response header reporting server load (0-100) that
+	// stretches the fallback delay
+	LoadHeader string
+
+	// StatusMultipliers scale the fallback delay by the response's status or
+	// class, e.g. "5..:1.5,429:3"
+	StatusMultipliers string
+
+	// InitialDelay is waited before the first attempt, when there is no server timing yet
+	InitialDelay time.Duration
 }
 
 // Validate validates the HTTP-aware configuration
@@ -99,14 +655,39 @@ func (h HTTPAwareConfig) Validate() error {
 		return fmt.Errorf("max-delay must be non-negative, got %v", h.MaxDelay)
 	}
 
-	validFallbacks := []string{"exponential", "exp", "linear", "lin", "fixed", "fix", "jitter", "jit", "decorrelated-jitter", "dj", "fibonacci", "fib"}
-	for _, valid := range validFallbacks {
-		if h.Fallback == valid {
-			return nil
+	if _, err := executor.ParseHTTPStatusActions(h.StatusActions); err != nil {
+		return err
+	}
+
+	if _, err := executor.ParseHTTPStatusMatcher(h.RetryStatus); err != nil {
+		return err
+	}
+
+	if _, err := executor.ParseHTTPSuccessCriteria(h.SuccessStatus, h.SuccessJSONEq); err != nil {
+		return fmt.Errorf("invalid HTTP success criteria: %w", err)
+	}
+
+	if h.IdempotencyHeader != "" {
+		if err := executor.ValidateHeaderName(h.IdempotencyHeader); err != nil {
+			return fmt.Errorf("invalid --idempotency-header: %w", err)
 		}
 	}
 
-	return fmt.Errorf("unknown fallback strategy: %s", h.Fallback)
+	if h.LoadHeader != "" {
+		if err := executor.ValidateHeaderName(h.LoadHeader); err != nil {
+			return fmt.Errorf("invalid --load-header: %w", err)
+		}
+	}
+
+	if _, err := backoff.ParseStatusMultipliers(h.StatusMultipliers); err != nil {
+		return err
+	}
+
+	if h.InitialDelay < 0 {
+		return fmt.Errorf("http-initial-delay must be non-negative, got %v", h.InitialDelay)
+	}
+
+	return validateFallback(h.Fallback)
 }
 
 // ExponentialConfig holds configuration for exponential strategy
@@ -130,17 +711,183 @@ func (e ExponentialConfig) Validate() error {
 		return fmt.Errorf("max-delay must be non-negative, got %v", e.MaxDelay)
 	}
 
+	return validateDelayBounds("base-delay", e.BaseDelay, e.MaxDelay)
+}
+
+// validateDelayBounds ensures a starting delay flag does not exceed --max-delay.
+// A max delay of 0 means no limit and is always accepted.
+func validateDelayBounds(flag string, delay, maxDelay time.Duration) error {
+	if maxDelay > 0 && delay > maxDelay {
+		return fmt.Errorf("conflicting flags: --%s (%v) must not exceed --max-delay (%v)", flag, delay, maxDelay)
+	}
 	return nil
 }
 
+// headerRedaction returns the header redaction for printed and recorded commands
+func (c CommonConfig) headerRedaction() executor.HeaderRedaction {
+	return executor.HeaderRedaction{Redact: c.RedactHeaders, Show: c.ShowHeaders}
+}
+
+// envPolicy returns the command's environment policy, or nil to pass the
+// parent's environment unchanged
+func (c CommonConfig) envPolicy() (*executor.EnvPolicy, error) {
+	if len(c.EnvAllowlist) == 0 && !c.EnvClear {
+		return nil, nil
+	}
+	return executor.NewEnvPolicy(c.EnvAllowlist, c.EnvClear)
+}
+
 // addCommonFlags adds common configuration flags to a command
 func addCommonFlags(cmd *cobra.Command, config *CommonConfig) {
-	cmd.Flags().IntVarP(&config.Attempts, "attempts", "a", 3, "Maximum retry attempts (1-1000)")
+	cmd.Flags().IntVarP(&config.Attempts, "attempts", "a", 3, "Maximum retry attempts (1-1000, or 0 for unlimited with --max-total-time or --max-cost)")
+	cmd.Flags().IntVar(&config.RequirePasses, "require-passes", 0,
+		"Succeed once this many attempts have passed, running up to --of attempts (0 = first pass succeeds)")
+	cmd.Flags().IntVar(&config.Attempts, "of", 3, "Attempts to run with --require-passes (same as --attempts)")
 	cmd.Flags().DurationVarP(&config.Timeout, "timeout", "t", 0, "Timeout per attempt (0 = no timeout)")
+	cmd.Flags().StringVar(&config.TimeoutOverhead, "timeout-overhead", "auto",
+		"Extra time allowed past --timeout before cancelling: a duration, or auto (2% of timeout, max 50ms)")
+	cmd.Flags().DurationVar(&config.WarnAfter, "warn-after", 0,
+		"Warn when an attempt runs longer than this, without cancelling it (0 = never)")
 	cmd.Flags().StringVar(&config.SuccessPattern, "success-pattern", "", "Regex pattern for success detection")
+	cmd.Flags().BoolVar(&config.RequireSuccessPattern, "require-success-pattern", false,
+		"Succeed only when --success-pattern matches, not on exit code 0 or an empty 2xx HTTP response")
 	cmd.Flags().StringVar(&config.FailurePattern, "failure-pattern", "", "Regex pattern for failure detection")
+	cmd.Flags().StringVar(&config.AbortPattern, "abort-pattern", "",
+		"Regex pattern that stops retrying, keeping the command's exit code and reason")
+	cmd.Flags().StringVar(&config.FatalPattern, "fatal-pattern", "",
+		"Regex pattern that stops the run on any attempt, even a successful one, exiting with --fatal-exit")
+	cmd.Flags().IntVar(&config.FatalExit, "fatal-exit", executor.DefaultFatalExitCode,
+		"Exit code when --fatal-pattern matches")
+	cmd.Flags().StringVar(&config.ReasonTemplate, "reason-template", "",
+		"Go template for failed attempts' reasons, using .Attempt, .ExitCode, .Reason, .Pattern, .HTTPStatus and .TimedOut")
 	cmd.Flags().BoolVar(&config.CaseInsensitive, "case-insensitive", false, "Case-insensitive pattern matching")
-	cmd.Flags().StringVar(&config.ConfigFile, "config", "", "Configuration file path")
+	cmd.Flags().BoolVar(&config.NormalizeNewlines, "normalize-newlines", true,
+		"Convert \\r\\n and \\r to \\n in output before matching patterns")
+	cmd.Flags().DurationVar(&config.RegexTimeout, "regex-timeout", 0,
+		"Fail the attempt when a single pattern match runs longer than this (0 = no limit)")
+	cmd.Flags().StringVar(&config.PatternStream, "pattern-stream", conditions.PatternStreamBoth,
+		"Stream success and failure patterns match: stdout, stderr or both")
+	cmd.Flags().BoolVar(&config.MergeStreams, "merge-streams", false,
+		"Capture stdout and stderr interleaved in write order, like 2>&1 (live output all goes to stdout)")
+	cmd.Flags().BoolVar(&config.CumulativePattern, "cumulative-pattern", false,
+		"Match patterns against the combined output of all attempts so far")
+	cmd.Flags().IntVar(&config.MaxOutputSize, "max-output-size", executor.DefaultMaxCumulativeOutput,
+		"Bytes of output retained per stream for --cumulative-pattern (oldest output is dropped)")
+	cmd.Flags().StringArrayVar(&config.SuccessJSONEq, "success-json-eq", nil,
+		"Succeed when stdout JSON field equals value, e.g. status=ok or data.state=ready (repeatable)")
+	cmd.Flags().StringArrayVar(&config.RetryJSONErrorCodes, "retry-json-error-code", nil,
+		"Retry only failures whose JSON body has this error code, e.g. TRY_AGAIN; other codes stop (repeatable)")
+	cmd.Flags().StringVar(&config.SuccessIfStdoutMatches, "success-if-stdout-matches", "",
+		"Treat a nonzero exit as success when stdout matches this regex")
+	cmd.Flags().StringVar(&config.SuccessIfStderrMatches, "success-if-stderr-matches", "",
+		"Treat a nonzero exit as success when stderr matches this regex (e.g. \"already exists\")")
+	cmd.Flags().BoolVar(&config.StrategyFallbackOnError, "strategy-fallback-on-error", false,
+		"Fall back to exponential defaults if the strategy can't be constructed")
+	cmd.Flags().DurationVar(&config.ConfirmLongWaits, "confirm-long-waits", 0,
+		"On a TTY, ask before waiting longer than this threshold (0 = never ask)")
+	cmd.Flags().IntVar(&config.MinOutputLines, "min-output-lines", 0,
+		"Retry when the command prints fewer than this many lines (0 = no minimum)")
+	cmd.Flags().IntVar(&config.MinOutputBytes, "min-output-bytes", 0,
+		"Retry when the command prints fewer than this many bytes (0 = no minimum)")
+	cmd.Flags().IntVar(&config.RetryUntilStable, "retry-until-stable", 0,
+		"Keep retrying until output is unchanged for this many consecutive attempts (0 = disabled)")
+	cmd.Flags().StringArrayVar(&config.Tags, "tag", nil,
+		"Label run metrics with key=value, e.g. env=prod (repeatable)")
+	cmd.Flags().StringArrayVar(&config.Metrics, "metric", nil,
+		"Record a numeric field in run metrics as key=number, e.g. build_number=1234 (repeatable)")
+	cmd.Flags().StringArrayVar(&config.FailureCategories, "failure-category", nil,
+		"Classify a failed run in metrics as NAME when REGEX matches its final reason or output, e.g. auth=401|403 (repeatable; first match wins)")
+	cmd.Flags().BoolVar(&config.MetricsSync, "metrics-sync", false,
+		"Wait until the run's metrics are written to the metrics daemon socket and warn if they are not")
+	cmd.Flags().StringVar(&config.MetricsFile, "metrics-file", "",
+		"Also append the run's metrics to this NDJSON file, for replay-metrics")
+	cmd.Flags().StringVar(&config.StateFile, "state-file", "",
+		"Persist progress to this file and resume the schedule from it after a restart")
+	cmd.Flags().DurationVar(&config.StateMaxAge, "state-max-age", executor.DefaultStateMaxAge,
+		"Ignore a --state-file last updated longer ago than this")
+	cmd.Flags().StringVar(&config.AttemptsFile, "attempts-from-file", "",
+		"Count attempts in this file across invocations so --attempts caps their total")
+	cmd.Flags().StringVar(&config.DiscoveryCache, "discovery-cache", "",
+		"Keep discovered rate limits in this file so later runs wait for a used-up limit to reset")
+	cmd.Flags().DurationVar(&config.DiscoveryCacheTTL, "discovery-cache-ttl", discovery.DefaultCacheTTL,
+		"How long a cached rate limit stays usable after it was last seen")
+	cmd.Flags().BoolVar(&config.PrefixOutput, "prefix-output", false,
+		"Prefix each line of the command's output with [attempt N] (binary output is left unchanged)")
+	cmd.Flags().BoolVar(&config.TimingMarkers, "timing-markers", false,
+		"Print a machine-readable timing line to stderr after each attempt")
+	cmd.Flags().StringVar(&config.ProgressFIFO, "progress-fifo", "",
+		"Write compact progress records (attempt=N/MAX state=...) to this named pipe, creating it if needed")
+	cmd.Flags().StringVar(&config.DelayCommand, "delay-command", "",
+		"Shell command that receives attempt JSON on stdin and prints the next delay (e.g. 2.5s)")
+	cmd.Flags().StringVar(&config.DelayJSONPath, "delay-json-path", "",
+		"Take the delay after a failed attempt from this field of the JSON response body (e.g. $.wait_ms)")
+	cmd.Flags().StringVar(&config.DelayJSONUnit, "delay-json-unit", executor.DelayJSONUnitSeconds,
+		"Unit of the --delay-json-path field: ms or s")
+	cmd.Flags().DurationVar(&config.CapDelay, "cap-delay", 0,
+		"Upper bound on any delay between attempts (0 = no cap)")
+	cmd.Flags().BoolVar(&config.Monotonic, "monotonic", false,
+		"Never wait less than the previous delay, even when jitter draws a shorter one")
+	cmd.Flags().Float64Var(&config.CostPerAttempt, "cost-per-attempt", 0,
+		"Cost charged for each attempt, reported in the summary")
+	cmd.Flags().Float64Var(&config.MaxCost, "max-cost", 0,
+		"Stop retrying before the total cost would exceed this budget (0 = no budget)")
+	cmd.Flags().StringVar(&config.CheckCommand, "check-command", "",
+		"Shell command that receives attempt JSON on stdin and exits 0 (success), 2 (retry) or other (fail)")
+	cmd.Flags().StringVar(&config.RetryIf, "retry-if", "",
+		"Retry an attempt when this expression holds, e.g. \"exit != 0 and not match(out, 'done') or http == 503\"")
+	cmd.Flags().StringVar(&config.AttemptCaps, "attempt-caps", "",
+		"Per-attempt delay caps as attempt:max steps, e.g. 1:1s,3:10s,5:60s")
+	cmd.Flags().StringVar(&config.MinFreeDisk, "min-free-disk", "",
+		"Stop before an attempt when free disk space in the working directory is below this size (e.g. 1G)")
+	cmd.Flags().StringVar(&config.MinFreeMem, "min-free-mem", "",
+		"Stop before an attempt when available memory is below this size (e.g. 512M)")
+	cmd.Flags().StringVar(&config.AllowedWindow, "allowed-window", "",
+		"Only start attempts within this daily window, e.g. \"22:00-06:00\" or \"22:00-06:00 Europe/London\"; wait otherwise")
+	cmd.Flags().DurationVar(&config.MaxTotalTime, "max-total-time", 0,
+		"Stop instead of waiting past this long since the run started (0 = no limit)")
+	cmd.Flags().DurationVar(&config.AlignInterval, "align-interval", 0,
+		"Start retries on the next wall-clock multiple of this interval after each delay, e.g. 1m (0 = no alignment)")
+	cmd.Flags().BoolVar(&config.ResetBackoffOnProgress, "reset-backoff-on-progress", false,
+		"Restart the backoff from the base delay when a failed attempt's output matches --progress-pattern")
+	cmd.Flags().StringVar(&config.ProgressPattern, "progress-pattern", "",
+		"Regex pattern marking attempts that made progress (with --reset-backoff-on-progress)")
+	cmd.Flags().BoolVar(&config.Shell, "shell", false,
+		"Run the command through sh -c so pipes, redirects and && work")
+	cmd.Flags().BoolVar(&config.Steps, "steps", false,
+		"Split the command at --and into steps that each attempt runs in sequence")
+	cmd.Flags().StringArrayVar(&config.EnvAllowlist, "env-allowlist", nil,
+		"Pass only this variable to the command: NAME keeps the parent's value, NAME=VALUE sets it (repeatable)")
+	cmd.Flags().BoolVar(&config.EnvClear, "env-clear", false,
+		"Run the command with an empty environment, apart from --env-allowlist NAME=VALUE entries")
+	cmd.Flags().BoolVar(&config.StrictArgs, "strict-args", false,
+		"Fail instead of warning when the command contains shell syntax without --shell")
+	cmd.Flags().StringArrayVar(&config.RedactHeaders, "redact-header", nil,
+		"Hide this header's value when the command is printed or recorded, besides Authorization, Cookie and X-Api-Key (repeatable)")
+	cmd.Flags().StringArrayVar(&config.ShowHeaders, "show-header", nil,
+		"Show this header's value when the command is printed or recorded, even if redacted by default (repeatable)")
+	cmd.Flags().BoolVar(&config.Verbose, "verbose", false,
+		"Show extra detail in the final summary, such as rate limits reported by the server")
+	cmd.Flags().BoolVar(&config.PatternMetrics, "pattern-metrics", false,
+		"Show how often each success/failure pattern was matched and how long matching took")
+	cmd.Flags().BoolVarP(&config.Quiet, "quiet", "q", false,
+		"Hide per-attempt progress and warnings; the final summary is still shown")
+	cmd.Flags().BoolVar(&config.SummaryOnlyOnFailure, "summary-only-on-failure", false,
+		"Show the final summary only when the command ultimately fails")
+	cmd.Flags().BoolVar(&config.FirstSuccessExitFast, "first-success-exit-fast", false,
+		"Skip the summary and metrics when the first attempt succeeds")
+	cmd.Flags().StringVar(&config.IfRunning, "if-running", "",
+		"When another invocation of the same command is running: wait, skip or fail (default: no lock)")
+	cmd.Flags().StringVar(&config.LockFile, "lock-file", "",
+		"Lock file for --if-running (default: derived from the command)")
+	cmd.Flags().BoolVar(&config.DryRun, "dry-run", false, "Print the retry schedule without running the command")
+	cmd.Flags().StringVar(&config.Format, "format", dryRunFormatText, "Dry-run output format: text or csv")
+	cmd.Flags().StringVar(&config.Output, "output", ui.OutputText,
+		"Machine-readable output on stdout: text, ndjson (one object per run) or json-events (one per event)")
+	cmd.Flags().StringVar(&config.Color, "color", ui.ColorAuto,
+		"Color the messages on stderr: auto (terminals without NO_COLOR), always or never; JSON output is never colored")
+	cmd.Flags().StringVar(&config.ConfigFile, "config", "", "Configuration file path (- reads it from stdin)")
+	cmd.Flags().StringVar(&config.ConfigType, "config-type", "",
+		"Format of a config read from stdin: toml, yaml or json (default toml)")
 	cmd.Flags().BoolVar(&config.DebugConfig, "debug-config", false, "Show configuration debug information")
 }
 
@@ -199,6 +946,24 @@ Falls back to specified strategy when no HTTP information is available.`,
 	// Add strategy-specific flags
 	cmd.Flags().StringVarP(&strategyConfig.Fallback, "fallback", "f", "exponential", "Fallback strategy when no HTTP info available")
 	cmd.Flags().DurationVarP(&strategyConfig.MaxDelay, "max-delay", "m", 30*time.Minute, "Maximum delay cap")
+	cmd.Flags().BoolVar(&strategyConfig.RespectRemaining, "respect-remaining", false,
+		"Cap attempts to the X-RateLimit-Remaining budget reported by the server")
+	cmd.Flags().StringVar(&strategyConfig.StatusActions, "http-status-action", "",
+		"Map HTTP statuses to success, retry or fail, e.g. 202:retry,200:success,409:fail")
+	cmd.Flags().StringVar(&strategyConfig.RetryStatus, "retry-http-status", "",
+		"Retry failed attempts only for these HTTP statuses or classes, e.g. 5..,408,429; other statuses stop")
+	cmd.Flags().StringVar(&strategyConfig.SuccessStatus, "success-http-status", "",
+		"Succeed only when the final response has one of these statuses or classes, e.g. 200 or 2.. (needs curl -i or -v)")
+	cmd.Flags().StringArrayVar(&strategyConfig.SuccessJSONEq, "success-http-json-eq", nil,
+		"Succeed only when the final response body has this JSON field value, e.g. status=ready (repeatable; needs curl -i or -v)")
+	cmd.Flags().StringVar(&strategyConfig.IdempotencyHeader, "idempotency-header", "",
+		"Add this header to curl commands with a key that stays the same across a run's attempts")
+	cmd.Flags().StringVar(&strategyConfig.LoadHeader, "load-header", "",
+		"Response header reporting server load from 0 to 100 (e.g. X-Server-Load); stretches the fallback delay by up to 2x")
+	cmd.Flags().StringVar(&strategyConfig.StatusMultipliers, "status-multiplier", "",
+		"Scale the fallback delay by the response's status or class, e.g. 5..:1.5,429:3 (default 1x)")
+	cmd.Flags().DurationVar(&strategyConfig.InitialDelay, "http-initial-delay", 0,
+		"Wait this long before the first attempt, before any server timing is known (0 = start immediately)")
 
 	// Add common flags
 	addCommonFlags(cmd, &commonConfig)
@@ -209,6 +974,7 @@ Falls back to specified strategy when no HTTP information is available.`,
 // createExponentialCommand creates the exponential subcommand
 func createExponentialCommand() *cobra.Command {
 	var strategyConfig ExponentialConfig
+	var halfLife float64
 	var commonConfig CommonConfig = NewCommonConfig()
 
 	cmd := &cobra.Command{
@@ -216,7 +982,8 @@ func createExponentialCommand() *cobra.Command {
 		Aliases: []string{"exp"},
 		Short:   "Exponentially increasing delays",
 		Long: `Exponential backoff strategy with configurable base delay, multiplier, and maximum delay.
-Each retry attempt increases the delay by the specified multiplier.`,
+Each retry attempt increases the delay by the specified multiplier.
+Alternatively, --half-life sets the number of attempts over which the delay doubles.`,
 		Args: cobra.ArbitraryArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Check if we have any arguments
@@ -242,6 +1009,18 @@ Each retry attempt increases the delay by the specified multiplier.`,
 				return err
 			}
 
+			// Derive the multiplier from the half-life
+			if cmd.Flags().Changed("half-life") {
+				if cmd.Flags().Changed("multiplier") {
+					return fmt.Errorf("--half-life and --multiplier are mutually exclusive")
+				}
+				multiplier, err := backoff.HalfLifeMultiplier(halfLife)
+				if err != nil {
+					return err
+				}
+				strategyConfig.Multiplier = multiplier
+			}
+
 			if err := strategyConfig.Validate(); err != nil {
 				return err
 			}
@@ -258,6 +1037,7 @@ Each retry attempt increases the delay by the specified multiplier.`,
 	// Add strategy-specific flags
 	cmd.Flags().DurationVarP(&strategyConfig.BaseDelay, "base-delay", "b", 1*time.Second, "Base delay")
 	cmd.Flags().Float64VarP(&strategyConfig.Multiplier, "multiplier", "x", 2.0, "Multiplier")
+	cmd.Flags().Float64Var(&halfLife, "half-life", 0, "Attempts over which the delay doubles (derives the multiplier; excludes --multiplier)")
 	cmd.Flags().DurationVarP(&strategyConfig.MaxDelay, "max-delay", "m", 60*time.Second, "Maximum delay")
 
 	// Add common flags
@@ -289,159 +1069,511 @@ func executeWithHTTPAware(strategyConfig HTTPAwareConfig, commonConfig CommonCon
 
 	// Create HTTP-aware strategy
 	strategy := backoff.NewHTTPAware(fallbackStrategy, strategyConfig.MaxDelay)
-
-	// Create executor
-	exec, err := createExecutorFromConfig(strategy, commonConfig)
-	if err != nil {
-		return fmt.Errorf("failed to create executor: %w", err)
+	if strategyConfig.LoadHeader != "" {
+		strategy.SetLoadHeader(strategyConfig.LoadHeader)
 	}
-
-	// Execute command
-	result, err := exec.Run(commandArgs)
+	multipliers, err := backoff.ParseStatusMultipliers(strategyConfig.StatusMultipliers)
 	if err != nil {
-		return fmt.Errorf("execution error: %w", err)
+		return err
 	}
+	strategy.SetStatusMultipliers(multipliers)
 
-	// Handle results
-	return handleExecutionResult(result, exec)
+	return executeWithStrategy(strategy, commonConfig, commandArgs, func(exec *executor.Executor) error {
+		var err error
+		exec.RespectRemaining = strategyConfig.RespectRemaining
+		exec.HTTPStatusActions, err = executor.ParseHTTPStatusActions(strategyConfig.StatusActions)
+		if err != nil {
+			return err
+		}
+		exec.RetryHTTPStatus, err = executor.ParseHTTPStatusMatcher(strategyConfig.RetryStatus)
+		if err != nil {
+			return err
+		}
+		exec.HTTPSuccess, err = executor.ParseHTTPSuccessCriteria(strategyConfig.SuccessStatus, strategyConfig.SuccessJSONEq)
+		if err != nil {
+			return err
+		}
+		exec.IdempotencyHeader = strategyConfig.IdempotencyHeader
+		exec.InitialDelay = strategyConfig.InitialDelay
+		return nil
+	})
 }
 
 // executeWithExponential executes command with exponential strategy
 func executeWithExponential(strategyConfig ExponentialConfig, commonConfig CommonConfig, commandArgs []string) error {
-	// Create exponential strategy
 	strategy := backoff.NewExponential(strategyConfig.BaseDelay, strategyConfig.Multiplier, strategyConfig.MaxDelay)
+	return executeWithStrategy(strategy, commonConfig, commandArgs)
+}
 
-	// Create executor
-	exec, err := createExecutorFromConfig(strategy, commonConfig)
+// loadConfigWithPrecedence loads configuration from file, environment, and CLI flags
+func loadConfigWithPrecedence(cmd *cobra.Command, commonConfig *CommonConfig) (*config.Config, error) {
+	// Determine config file path
+	configPath := commonConfig.ConfigFile
+	if configPath == "" {
+		// Auto-discover config file in the current or home directory
+		configPath, _ = discoverConfigFile()
+	}
+
+	flagConfig, explicitFields := configFlags(cmd, commonConfig)
+
+	// Load configuration with precedence, reading it from stdin for --config -
+	var finalConfig *config.Config
+	var debugInfo *config.ConfigDebugInfo
+	var err error
+	if configPath == config.StdinPath {
+		finalConfig, debugInfo, err = config.LoadReaderWithPrecedenceAndExplicitFlags(cmd.InOrStdin(), commonConfig.ConfigType,
+			flagConfig, explicitFields, commonConfig.DebugConfig)
+	} else {
+		finalConfig, debugInfo, err = config.LoadWithPrecedenceAndExplicitFlags(configPath, flagConfig, explicitFields, commonConfig.DebugConfig)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to create executor: %w", err)
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
 	}
 
-	// Execute command
-	result, err := exec.Run(commandArgs)
+	// Print debug information if requested
+	if commonConfig.DebugConfig && debugInfo != nil {
+		debugInfo.PrintDebugInfo()
+		fmt.Println() // Add blank line after debug info
+	}
+
+	return finalConfig, nil
+}
+
+// configFlags returns the config-file settings given as flags, and which of
+// them were set explicitly and so override the config file and environment
+func configFlags(cmd *cobra.Command, commonConfig *CommonConfig) (*config.Config, map[string]bool) {
+	flagConfig := &config.Config{
+		Attempts:        commonConfig.Attempts,
+		Timeout:         commonConfig.Timeout,
+		SuccessPattern:  commonConfig.SuccessPattern,
+		FailurePattern:  commonConfig.FailurePattern,
+		CaseInsensitive: commonConfig.CaseInsensitive,
+
+		// Daemon configuration
+		DaemonEnabled:   commonConfig.DaemonEnabled,
+		DaemonSocket:    commonConfig.DaemonSocket,
+		DaemonTimeout:   commonConfig.DaemonTimeout,
+		DaemonAutoStart: commonConfig.DaemonAutoStart,
+	}
+
+	// Track which flags were explicitly set
+	explicitFields := make(map[string]bool)
+	if cmd.Flags().Changed("attempts") || cmd.Flags().Changed("of") {
+		explicitFields["attempts"] = true
+	}
+	if cmd.Flags().Changed("timeout") {
+		explicitFields["timeout"] = true
+	}
+	if cmd.Flags().Changed("success-pattern") {
+		explicitFields["success_pattern"] = true
+	}
+	if cmd.Flags().Changed("failure-pattern") {
+		explicitFields["failure_pattern"] = true
+	}
+	if cmd.Flags().Changed("case-insensitive") {
+		explicitFields["case_insensitive"] = true
+	}
+	if cmd.Flags().Changed("daemon") {
+		explicitFields["daemon_enabled"] = true
+	}
+	if cmd.Flags().Changed("daemon-socket") {
+		explicitFields["daemon_socket"] = true
+	}
+	if cmd.Flags().Changed("daemon-timeout") {
+		explicitFields["daemon_timeout"] = true
+	}
+	if cmd.Flags().Changed("daemon-auto-start") {
+		explicitFields["daemon_auto_start"] = true
+	}
+
+	return flagConfig, explicitFields
+}
+
+// decorateStrategy applies the common options that wrap the strategy, such as
+// --monotonic
+func decorateStrategy(strategy backoff.Strategy, config CommonConfig) backoff.Strategy {
+	if strategy != nil && config.Monotonic {
+		return backoff.WithMonotonic(strategy)
+	}
+	return strategy
+}
+
+// createExecutorFromConfig creates an executor from strategy and common configuration
+func createExecutorFromConfig(strategy backoff.Strategy, config CommonConfig) (*executor.Executor, error) {
+	exec := newExecutor(decorateStrategy(strategy, config), config)
+
+	if err := applyConditions(exec, config); err != nil {
+		return nil, err
+	}
+	if err := applyPatterns(exec, config); err != nil {
+		return nil, err
+	}
+
+	overhead, err := parseTimeoutOverhead(config.TimeoutOverhead)
 	if err != nil {
-		return fmt.Errorf("execution error: %w", err)
+		return nil, err
+	}
+	exec.TimeoutOverhead = overhead
+	exec.WarnAfter = config.WarnAfter
+	exec.RequirePasses = config.RequirePasses
+	exec.PrefixOutput = config.PrefixOutput
+
+	exec.CumulativePatterns = config.CumulativePattern
+	exec.MaxOutputSize = config.MaxOutputSize
+	exec.MinOutputLines = config.MinOutputLines
+	exec.MinOutputBytes = config.MinOutputBytes
+	exec.StableAttempts = config.RetryUntilStable
+	exec.StateFile = config.StateFile
+	exec.StateMaxAge = config.StateMaxAge
+	exec.AttemptsFile = config.AttemptsFile
+	exec.Steps = config.Steps
+	exec.RetryJSONErrorCodes = config.RetryJSONErrorCodes
+	exec.HeaderRedaction = config.headerRedaction()
+	exec.CheckCommand = config.CheckCommand
+	exec.IfRunning = config.IfRunning
+	exec.LockFile = config.LockFile
+	exec.FirstSuccessExitFast = config.FirstSuccessExitFast
+
+	if err := applyDelays(exec, config); err != nil {
+		return nil, err
+	}
+	if err := applyBudgets(exec, config); err != nil {
+		return nil, err
+	}
+	if err := applyPreconditions(exec, config); err != nil {
+		return nil, err
 	}
+	if err := applyReporting(exec, config); err != nil {
+		return nil, err
+	}
+	if err := applyReporter(exec, config); err != nil {
+		return nil, err
+	}
+	return exec, nil
+}
 
-	// Handle results
-	return handleExecutionResult(result, exec)
+// newExecutor creates the base executor with strategy and timeout
+func newExecutor(strategy backoff.Strategy, config CommonConfig) *executor.Executor {
+	switch {
+	case strategy != nil && config.Timeout > 0:
+		return executor.NewExecutorWithBackoffAndTimeout(config.Attempts, strategy, config.Timeout)
+	case strategy != nil:
+		return executor.NewExecutorWithBackoff(config.Attempts, strategy)
+	case config.Timeout > 0:
+		return executor.NewExecutorWithTimeout(config.Attempts, config.Timeout)
+	default:
+		return executor.NewExecutor(config.Attempts)
+	}
 }
 
-// loadConfigWithPrecedence loads configuration from file, environment, and CLI flags
-func loadConfigWithPrecedence(cmd *cobra.Command, commonConfig *CommonConfig) (*config.Config, error) {
-	// Determine config file path
-	configPath := commonConfig.ConfigFile
-	if configPath == "" {
-		// Auto-discover config file
-		if cwd, err := os.Getwd(); err == nil {
-			if found := config.FindConfigFile(cwd); found != "" {
-				configPath = found
-			}
+// applyConditions adds a condition checker when success or failure conditions
+// are set, along with --retry-if
+func applyConditions(exec *executor.Executor, config CommonConfig) error {
+	exec.RequireSuccessPattern = config.RequireSuccessPattern
+	if config.RetryIf != "" {
+		retryIf, err := conditions.CompileRetryIf(config.RetryIf)
+		if err != nil {
+			return err
+		}
+		exec.RetryIf = retryIf
+	}
+
+	if config.SuccessPattern == "" && config.FailurePattern == "" && len(config.SuccessJSONEq) == 0 &&
+		config.SuccessIfStdoutMatches == "" && config.SuccessIfStderrMatches == "" {
+		return nil
+	}
+	checker, err := newConditionChecker(config)
+	if err != nil {
+		return fmt.Errorf("failed to create condition checker: %w", err)
+	}
+	exec.Conditions = checker
+	return nil
+}
+
+// newConditionChecker creates the checker for the success and failure conditions
+func newConditionChecker(config CommonConfig) (*conditions.Checker, error) {
+	checker, err := conditions.NewChecker(config.SuccessPattern, config.FailurePattern, config.CaseInsensitive)
+	if err != nil {
+		return nil, err
+	}
+	for _, expr := range config.SuccessJSONEq {
+		if err := checker.AddJSONEquals(expr); err != nil {
+			return nil, err
+		}
+	}
+	if config.SuccessIfStdoutMatches != "" {
+		if err := checker.SetSuccessIfStdoutMatches(config.SuccessIfStdoutMatches); err != nil {
+			return nil, err
+		}
+	}
+	if config.SuccessIfStderrMatches != "" {
+		if err := checker.SetSuccessIfStderrMatches(config.SuccessIfStderrMatches); err != nil {
+			return nil, err
+		}
+	}
+	checker.SetNormalizeNewlines(config.NormalizeNewlines)
+	if err := checker.SetMatchTimeout(config.RegexTimeout); err != nil {
+		return nil, err
+	}
+	if config.PatternStream != "" {
+		if err := checker.SetPatternStream(config.PatternStream); err != nil {
+			return nil, err
+		}
+	}
+	return checker, nil
+}
+
+// applyPatterns compiles the abort, fatal and progress patterns
+func applyPatterns(exec *executor.Executor, config CommonConfig) error {
+	var err error
+	if config.AbortPattern != "" {
+		exec.AbortPattern, err = compilePattern("abort", config.AbortPattern, config.CaseInsensitive)
+		if err != nil {
+			return err
+		}
+	}
+	if config.FatalPattern != "" {
+		exec.FatalPattern, err = compilePattern("fatal", config.FatalPattern, config.CaseInsensitive)
+		if err != nil {
+			return err
+		}
+		exec.FatalExitCode = config.FatalExit
+	}
+	if config.ResetBackoffOnProgress {
+		exec.ProgressPattern, err = compilePattern("progress", config.ProgressPattern, config.CaseInsensitive)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compilePattern compiles a pattern, honoring --case-insensitive
+func compilePattern(name, pattern string, caseInsensitive bool) (*regexp.Regexp, error) {
+	if caseInsensitive {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s pattern: %w", name, err)
+	}
+	return re, nil
+}
+
+// applyDelays sets the overrides of the strategy's delays
+func applyDelays(exec *executor.Executor, config CommonConfig) error {
+	exec.DelayCommand = config.DelayCommand
+	exec.CapDelay = config.CapDelay
+	exec.Alignment = executor.ClockAlignment{Interval: config.AlignInterval}
+	if config.DelayJSONPath != "" {
+		delayJSON, err := executor.NewDelayJSON(config.DelayJSONPath, config.DelayJSONUnit)
+		if err != nil {
+			return err
+		}
+		exec.DelayJSON = delayJSON
+	}
+	return nil
+}
+
+// applyBudgets sets the cost and time budgets and the per-outcome attempt caps
+func applyBudgets(exec *executor.Executor, config CommonConfig) error {
+	exec.CostPerAttempt = config.CostPerAttempt
+	exec.MaxCost = config.MaxCost
+	exec.MaxTotalTime = config.MaxTotalTime
+	caps, err := executor.ParseAttemptCaps(config.AttemptCaps)
+	if err != nil {
+		return err
+	}
+	exec.AttemptCaps = caps
+	return nil
+}
+
+// applyPreconditions sets what must hold before each attempt starts
+func applyPreconditions(exec *executor.Executor, config CommonConfig) error {
+	var err error
+	if config.MinFreeDisk != "" {
+		exec.MinFreeDisk, err = executor.ParseByteSize(config.MinFreeDisk)
+		if err != nil {
+			return fmt.Errorf("invalid min-free-disk: %w", err)
+		}
+	}
+	if config.MinFreeMem != "" {
+		exec.MinFreeMemory, err = executor.ParseByteSize(config.MinFreeMem)
+		if err != nil {
+			return fmt.Errorf("invalid min-free-mem: %w", err)
+		}
+	}
+	if config.AllowedWindow != "" {
+		exec.AllowedWindow, err = executor.ParseAllowedWindow(config.AllowedWindow)
+		if err != nil {
+			return err
 		}
 	}
+	return nil
+}
 
-	// Create flag config from common config
-	flagConfig := &config.Config{
-		Attempts:        commonConfig.Attempts,
-		Timeout:         commonConfig.Timeout,
-		SuccessPattern:  commonConfig.SuccessPattern,
-		FailurePattern:  commonConfig.FailurePattern,
-		CaseInsensitive: commonConfig.CaseInsensitive,
-
-		// Daemon configuration
-		DaemonEnabled:   commonConfig.DaemonEnabled,
-		DaemonSocket:    commonConfig.DaemonSocket,
-		DaemonTimeout:   commonConfig.DaemonTimeout,
-		DaemonAutoStart: commonConfig.DaemonAutoStart,
+// applyReporting sets what is recorded about a run: failure reasons, metrics,
+// timing markers, discovered rate limits and the progress FIFO
+func applyReporting(exec *executor.Executor, config CommonConfig) error {
+	var err error
+	if config.ReasonTemplate != "" {
+		exec.ReasonTemplate, err = executor.ParseReasonTemplate(config.ReasonTemplate)
+		if err != nil {
+			return err
+		}
 	}
 
-	// Track which flags were explicitly set
-	explicitFields := make(map[string]bool)
-	if cmd.Flags().Changed("attempts") {
-		explicitFields["attempts"] = true
-	}
-	if cmd.Flags().Changed("timeout") {
-		explicitFields["timeout"] = true
+	exec.Tags, err = metrics.ParseTags(config.Tags)
+	if err != nil {
+		return err
 	}
-	if cmd.Flags().Changed("success-pattern") {
-		explicitFields["success_pattern"] = true
+	exec.CustomMetrics, err = metrics.ParseMetrics(config.Metrics)
+	if err != nil {
+		return err
 	}
-	if cmd.Flags().Changed("failure-pattern") {
-		explicitFields["failure_pattern"] = true
+	exec.MetricsSync = config.MetricsSync
+	exec.MetricsFile = config.MetricsFile
+	exec.FailureCategories, err = executor.ParseFailureCategories(config.FailureCategories)
+	if err != nil {
+		return err
 	}
-	if cmd.Flags().Changed("case-insensitive") {
-		explicitFields["case_insensitive"] = true
+	if config.TimingMarkers {
+		exec.TimingMarkers = os.Stderr
 	}
-	if cmd.Flags().Changed("daemon") {
-		explicitFields["daemon_enabled"] = true
+
+	exec.DiscoverRateLimits = true
+	exec.ReportPatternMetrics = config.PatternMetrics
+	if config.DiscoveryCache != "" {
+		cache, err := discovery.LoadCache(config.DiscoveryCache, config.DiscoveryCacheTTL)
+		if err != nil {
+			ui.NewReporter(os.Stderr).ShowWarning(fmt.Sprintf("%v; starting with an empty cache", err))
+			cache = discovery.NewCache(config.DiscoveryCache, config.DiscoveryCacheTTL)
+		}
+		exec.DiscoveryCache = cache
 	}
-	if cmd.Flags().Changed("daemon-socket") {
-		explicitFields["daemon_socket"] = true
+	if config.ProgressFIFO != "" {
+		exec.ProgressFIFO, err = executor.NewProgressFIFO(config.ProgressFIFO)
+		if err != nil {
+			return err
+		}
 	}
-	if cmd.Flags().Changed("daemon-timeout") {
-		explicitFields["daemon_timeout"] = true
+	return nil
+}
+
+// applyReporter adds the status reporter and sets up the command's streams
+// and environment around it
+func applyReporter(exec *executor.Executor, config CommonConfig) error {
+	reporter := ui.NewReporter(os.Stderr)
+	reporter.SetVerbose(config.Verbose)
+	reporter.SetQuiet(config.Quiet)
+	reporter.SetSummaryOnlyOnFailure(config.SummaryOnlyOnFailure)
+	reporter.SetColor(ui.UseColor(config.Color, os.Stderr))
+	exec.Reporter = reporter
+
+	// Keep stdout for JSON only; the command's own stdout moves to stderr
+	if config.Output != "" && config.Output != ui.OutputText {
+		reporter.SetJSONOutput(config.Output, os.Stdout)
+		exec.Runner = &executor.SystemCommandRunner{Stdout: os.Stderr}
 	}
-	if cmd.Flags().Changed("daemon-auto-start") {
-		explicitFields["daemon_auto_start"] = true
+	if runner, ok := exec.Runner.(*executor.SystemCommandRunner); ok {
+		env, err := config.envPolicy()
+		if err != nil {
+			return err
+		}
+		runner.MergeStreams = config.MergeStreams
+		runner.Env = env
 	}
 
-	// Load configuration with precedence
-	finalConfig, debugInfo, err := config.LoadWithPrecedenceAndExplicitFlags(configPath, flagConfig, explicitFields, commonConfig.DebugConfig)
-	if err != nil {
-		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	// Prompt before long waits when attached to a terminal
+	if config.ConfirmLongWaits > 0 {
+		reporter.SetInput(os.Stdin, ui.IsTerminal(os.Stdin))
+		exec.ConfirmWaitsOver = config.ConfirmLongWaits
 	}
+	return nil
+}
 
-	// Print debug information if requested
-	if commonConfig.DebugConfig && debugInfo != nil {
-		debugInfo.PrintDebugInfo()
-		fmt.Println() // Add blank line after debug info
+// parseTimeoutOverhead parses --timeout-overhead, returning nil for the automatic default
+func parseTimeoutOverhead(value string) (*time.Duration, error) {
+	if value == "" || value == "auto" {
+		return nil, nil
 	}
 
-	return finalConfig, nil
+	overhead, err := time.ParseDuration(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timeout-overhead %q: expected a duration or auto", value)
+	}
+	if overhead < 0 {
+		return nil, fmt.Errorf("timeout-overhead must be non-negative, got %v", overhead)
+	}
+	return &overhead, nil
 }
 
-// createExecutorFromConfig creates an executor from strategy and common configuration
-func createExecutorFromConfig(strategy backoff.Strategy, config CommonConfig) (*executor.Executor, error) {
-	// Create base executor with strategy and timeout
-	var exec *executor.Executor
-
-	if strategy != nil && config.Timeout > 0 {
-		exec = executor.NewExecutorWithBackoffAndTimeout(config.Attempts, strategy, config.Timeout)
-	} else if strategy != nil {
-		exec = executor.NewExecutorWithBackoff(config.Attempts, strategy)
-	} else if config.Timeout > 0 {
-		exec = executor.NewExecutorWithTimeout(config.Attempts, config.Timeout)
-	} else {
-		exec = executor.NewExecutor(config.Attempts)
+// resolveStrategy returns the constructed strategy, or exponential defaults with a
+// warning when construction failed and --strategy-fallback-on-error is set
+func resolveStrategy(name string, strategy backoff.Strategy, err error, config CommonConfig) (backoff.Strategy, error) {
+	if err == nil {
+		return strategy, nil
 	}
 
-	// Add condition checker if patterns specified
-	if config.SuccessPattern != "" || config.FailurePattern != "" {
-		checker, err := conditions.NewChecker(config.SuccessPattern, config.FailurePattern, config.CaseInsensitive)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create condition checker: %w", err)
-		}
-		exec.Conditions = checker
+	if !config.StrategyFallbackOnError {
+		return nil, fmt.Errorf("failed to create %s strategy: %w", name, err)
 	}
 
-	// Add status reporter
-	reporter := ui.NewReporter(os.Stderr)
-	exec.Reporter = reporter
+	ui.NewReporter(os.Stderr).ShowWarning(
+		fmt.Sprintf("failed to create %s strategy (%v), falling back to exponential defaults", name, err))
+	return backoff.NewExponential(1*time.Second, 2.0, 60*time.Second), nil
+}
 
-	return exec, nil
+// newRunSummary describes a finished run for ndjson output
+func newRunSummary(result *executor.Result) ui.RunSummary {
+	summary := ui.RunSummary{
+		Success:       result.Success,
+		ExitCode:      result.ExitCode,
+		TimedOut:      result.TimedOut,
+		Reason:        result.Reason,
+		TotalAttempts: result.AttemptCount,
+	}
+	if result.Stats != nil {
+		summary.SetStats(result.Stats)
+	}
+	if result.Metrics != nil {
+		summary.Command = result.Metrics.Command
+		summary.TotalDurationSeconds = result.Metrics.TotalDurationSeconds
+		summary.TotalDelaySeconds = result.Metrics.TotalDelaySeconds
+		summary.TotalExecutionSeconds = result.Metrics.TotalExecutionSeconds
+		summary.Attempts = result.Metrics.Attempts
+		summary.Tags = result.Metrics.Tags
+		summary.CustomMetrics = result.Metrics.CustomMetrics
+		summary.RateLimit = result.Metrics.RateLimit
+	}
+	summary.MetricsDelivered = result.MetricsDelivered
+	return summary
+}
+
+// runExecutor runs commandArgs with exec. A run that finished without
+// succeeding is not an error here: its Result carries the outcome, and only
+// errors that kept the run from finishing are returned.
+func runExecutor(exec *executor.Executor, commandArgs []string) (*executor.Result, error) {
+	result, err := exec.Run(commandArgs)
+	var runErr *executor.RunError
+	if err != nil && (result == nil || !errors.As(err, &runErr)) {
+		return nil, fmt.Errorf("execution error: %w", err)
+	}
+	return result, nil
 }
 
 // handleExecutionResult handles the result of command execution
 func handleExecutionResult(result *executor.Result, exec *executor.Executor) error {
+	// Send metrics to the daemon, in the background unless --metrics-sync, so
+	// the summary can report whether they were delivered
+	exec.DispatchMetrics(result, metrics.NewClient(metrics.DefaultSocketPath()))
+
 	// Show final summary if we have statistics
 	if result.Stats != nil && exec.Reporter != nil {
 		exec.Reporter.FinalSummary(result.Stats)
 	}
-
-	// Send metrics to daemon asynchronously (fire-and-forget)
-	if result.Metrics != nil {
-		metricsClient := metrics.NewClient(metrics.DefaultSocketPath())
-		metricsClient.SendMetricsAsync(result.Metrics)
+	if exec.Reporter != nil {
+		exec.Reporter.RunResult(newRunSummary(result))
 	}
 
 	// Exit with appropriate code based on success (skip during tests)
@@ -449,9 +1581,9 @@ func handleExecutionResult(result *executor.Result, exec *executor.Executor) err
 		if result.Success {
 			os.Exit(0)
 		} else {
-			// If failure was due to pattern matching, use exit code 1
+			// If failure was due to pattern or JSON matching, use exit code 1
 			// Otherwise use the original exit code
-			if strings.Contains(result.Reason, "failure pattern matched") {
+			if strings.Contains(result.Reason, "failure pattern matched") || result.ExitCode == 0 {
 				os.Exit(1)
 			} else {
 				os.Exit(result.ExitCode)
@@ -467,26 +1599,6 @@ func handleExecutionResult(result *executor.Result, exec *executor.Executor) err
 	return nil
 }
 
-// createFallbackStrategy creates a fallback strategy from the given type
-func createFallbackStrategy(fallbackType string) (backoff.Strategy, error) {
-	switch fallbackType {
-	case "exponential", "exp":
-		return backoff.NewExponential(1*time.Second, 2.0, 60*time.Second), nil
-	case "linear", "lin":
-		return backoff.NewLinear(1*time.Second, 60*time.Second), nil
-	case "fixed", "fix":
-		return backoff.NewFixed(1 * time.Second), nil
-	case "jitter", "jit":
-		return backoff.NewJitter(1*time.Second, 2.0, 60*time.Second), nil
-	case "decorrelated-jitter", "dj":
-		return backoff.NewDecorrelatedJitter(1*time.Second, 2.0, 60*time.Second), nil
-	case "fibonacci", "fib":
-		return backoff.NewFibonacci(1*time.Second, 60*time.Second), nil
-	default:
-		return nil, fmt.Errorf("unknown fallback strategy: %s", fallbackType)
-	}
-}
-
 // Test helper functions
 func getLastParsedHTTPAwareConfig() HTTPAwareConfig {
 	return lastHTTPAwareConfig
@@ -520,6 +1632,12 @@ func createTestRootCommand() *cobra.Command {
 	rootCmd.AddCommand(createFibonacciCommand())
 	rootCmd.AddCommand(createPolynomialCommand())
 	rootCmd.AddCommand(createAdaptiveCommand())
+	rootCmd.AddCommand(createPIDCommand())
+	rootCmd.AddCommand(createRemoteScheduleCommand())
+	rootCmd.AddCommand(createBenchCommand())
+	rootCmd.AddCommand(createNextDelayCommand())
+	rootCmd.AddCommand(createReplayMetricsCommand())
+	rootCmd.AddCommand(createConfigDiffCommand())
 
 	return rootCmd
 }
@@ -548,16 +1666,9 @@ func createStrategyFromConfig(strategyType string, config interface{}) (backoff.
 		}
 
 		// Create fallback strategy
-		var fallbackStrategy backoff.Strategy
-		switch httpConfig.Fallback {
-		case "exponential", "exp":
-			fallbackStrategy = backoff.NewExponential(1*time.Second, 2.0, 60*time.Second)
-		case "linear":
-			fallbackStrategy = backoff.NewLinear(1*time.Second, 60*time.Second)
-		case "fixed":
-			fallbackStrategy = backoff.NewFixed(1 * time.Second)
-		default:
-			fallbackStrategy = backoff.NewExponential(1*time.Second, 2.0, 60*time.Second)
+		fallbackStrategy, err := createFallbackStrategy(httpConfig.Fallback)
+		if err != nil {
+			return nil, err
 		}
 
 		return backoff.NewHTTPAware(fallbackStrategy, httpConfig.MaxDelay), nil
@@ -586,6 +1697,17 @@ type LinearConfig struct {
 	MaxDelay  time.Duration
 }
 
+// Validate validates the linear configuration
+func (l LinearConfig) Validate() error {
+	if l.Increment < 0 {
+		return fmt.Errorf("increment must be non-negative, got %v", l.Increment)
+	}
+	if l.MaxDelay < 0 {
+		return fmt.Errorf("max-delay must be non-negative, got %v", l.MaxDelay)
+	}
+	return validateDelayBounds("increment", l.Increment, l.MaxDelay)
+}
+
 type FixedConfig struct {
 	Delay time.Duration
 }
@@ -596,17 +1718,38 @@ type JitterConfig struct {
 	MaxDelay   time.Duration
 }
 
+// Validate validates the jitter configuration
+func (j JitterConfig) Validate() error {
+	return ExponentialConfig(j).Validate()
+}
+
 type DecorrelatedJitterConfig struct {
 	BaseDelay  time.Duration
 	Multiplier float64
 	MaxDelay   time.Duration
 }
 
+// Validate validates the decorrelated jitter configuration
+func (d DecorrelatedJitterConfig) Validate() error {
+	return ExponentialConfig(d).Validate()
+}
+
 type FibonacciConfig struct {
 	BaseDelay time.Duration
 	MaxDelay  time.Duration
 }
 
+// Validate validates the fibonacci configuration
+func (f FibonacciConfig) Validate() error {
+	if f.BaseDelay < 0 {
+		return fmt.Errorf("base-delay must be non-negative, got %v", f.BaseDelay)
+	}
+	if f.MaxDelay < 0 {
+		return fmt.Errorf("max-delay must be non-negative, got %v", f.MaxDelay)
+	}
+	return validateDelayBounds("base-delay", f.BaseDelay, f.MaxDelay)
+}
+
 // PolynomialConfig holds configuration for polynomial backoff strategy
 type PolynomialConfig struct {
 	BaseDelay time.Duration
@@ -614,14 +1757,31 @@ type PolynomialConfig struct {
 	MaxDelay  time.Duration
 }
 
+// Validate checks that --base-delay does not exceed --max-delay; the
+// constructor validates the rest of the polynomial configuration
+func (p PolynomialConfig) Validate() error {
+	return validateDelayBounds("base-delay", p.BaseDelay, p.MaxDelay)
+}
+
 // AdaptiveConfig holds configuration for adaptive backoff strategy
 type AdaptiveConfig struct {
 	LearningRate     float64
 	MemoryWindow     int
+	Decay            float64
 	FallbackStrategy string
 	FallbackConfig   interface{}
 }
 
+// PIDConfig holds configuration for the PID controller strategy
+type PIDConfig struct {
+	Setpoint float64
+	Kp       float64
+	Ki       float64
+	Kd       float64
+	MinDelay time.Duration
+	MaxDelay time.Duration
+}
+
 // DiophantineConfig holds configuration for the Diophantine strategy
 type DiophantineConfig struct {
 	RateLimit    int           `json:"rate_limit"`
@@ -670,6 +1830,10 @@ func createLinearCommand() *cobra.Command {
 				return err
 			}
 
+			if err := strategyConfig.Validate(); err != nil {
+				return err
+			}
+
 			strategy := backoff.NewLinear(strategyConfig.Increment, strategyConfig.MaxDelay)
 			return executeWithStrategy(strategy, commonConfig, args)
 		},
@@ -739,6 +1903,10 @@ func createJitterCommand() *cobra.Command {
 				return err
 			}
 
+			if err := strategyConfig.Validate(); err != nil {
+				return err
+			}
+
 			strategy := backoff.NewJitter(strategyConfig.BaseDelay, strategyConfig.Multiplier, strategyConfig.MaxDelay)
 			return executeWithStrategy(strategy, commonConfig, args)
 		},
@@ -775,6 +1943,10 @@ func createDecorrelatedJitterCommand() *cobra.Command {
 				return err
 			}
 
+			if err := strategyConfig.Validate(); err != nil {
+				return err
+			}
+
 			strategy := backoff.NewDecorrelatedJitter(strategyConfig.BaseDelay, strategyConfig.Multiplier, strategyConfig.MaxDelay)
 			return executeWithStrategy(strategy, commonConfig, args)
 		},
@@ -811,6 +1983,10 @@ func createFibonacciCommand() *cobra.Command {
 				return err
 			}
 
+			if err := strategyConfig.Validate(); err != nil {
+				return err
+			}
+
 			strategy := backoff.NewFibonacci(strategyConfig.BaseDelay, strategyConfig.MaxDelay)
 			return executeWithStrategy(strategy, commonConfig, args)
 		},
@@ -825,18 +2001,33 @@ func createFibonacciCommand() *cobra.Command {
 	return cmd
 }
 
-// executeWithStrategy executes command with the given strategy
-func executeWithStrategy(strategy backoff.Strategy, commonConfig CommonConfig, commandArgs []string) error {
+// executeWithStrategy executes command with the given strategy. Each configure
+// function applies a strategy's own options to the executor; then the schedule
+// is previewed with --dry-run, or the command runs and its result is reported.
+func executeWithStrategy(strategy backoff.Strategy, commonConfig CommonConfig, commandArgs []string, configure ...func(*executor.Executor) error) error {
 	// Create executor
 	exec, err := createExecutorFromConfig(strategy, commonConfig)
 	if err != nil {
 		return fmt.Errorf("failed to create executor: %w", err)
 	}
+	if err := checkShellArgs(exec, commonConfig, commandArgs); err != nil {
+		return err
+	}
+	for _, apply := range configure {
+		if err := apply(exec); err != nil {
+			return err
+		}
+	}
+
+	// Preview the schedule instead of running when requested
+	if commonConfig.DryRun {
+		return printDryRun(os.Stdout, strategy, commonConfig, commandArgs)
+	}
 
 	// Execute command
-	result, err := exec.Run(commandArgs)
+	result, err := runExecutor(exec, commandArgs)
 	if err != nil {
-		return fmt.Errorf("execution error: %w", err)
+		return err
 	}
 
 	// Handle results
@@ -881,24 +2072,16 @@ Examples:
 				return err
 			}
 
-			// Validate polynomial-specific configuration
-			if strategyConfig.BaseDelay <= 0 {
-				return fmt.Errorf("base delay must be positive")
-			}
-			if strategyConfig.Exponent < 0 {
-				return fmt.Errorf("exponent must be non-negative")
-			}
-			if strategyConfig.MaxDelay <= 0 {
-				return fmt.Errorf("max delay must be positive")
-			}
-			if strategyConfig.BaseDelay > strategyConfig.MaxDelay {
-				return fmt.Errorf("base delay cannot be greater than max delay")
+			// Create strategy (the constructor validates polynomial-specific
+			// configuration); conflicting flags can fall back like any other error
+			var strategy backoff.Strategy
+			err := strategyConfig.Validate()
+			if err == nil {
+				strategy, err = backoff.NewPolynomial(strategyConfig.BaseDelay, strategyConfig.Exponent, strategyConfig.MaxDelay)
 			}
-
-			// Create strategy
-			strategy, err := backoff.NewPolynomial(strategyConfig.BaseDelay, strategyConfig.Exponent, strategyConfig.MaxDelay)
+			strategy, err = resolveStrategy("polynomial", strategy, err, commonConfig)
 			if err != nil {
-				return fmt.Errorf("failed to create polynomial strategy: %w", err)
+				return err
 			}
 
 			return executeWithStrategy(strategy, commonConfig, args)
@@ -941,6 +2124,7 @@ It uses a fallback strategy when insufficient learning data is available.
 Key parameters:
 - learning-rate: How quickly to adapt (0.01-1.0, default 0.1)
 - memory-window: Number of recent outcomes to remember (5-10000, default 50)
+- decay: Weight kept per newer outcome, so recent outcomes count more (0-1, default 1 = no decay)
 - fallback: Strategy to use when learning data is insufficient
 
 Examples:
@@ -951,7 +2135,10 @@ Examples:
   patience adapt --learning-rate 0.5 --fallback fixed -- flaky-command
   
   # Conservative learning with large memory
-  patience adaptive -r 0.05 -w 200 --fallback linear -- database-operation`,
+  patience adaptive -r 0.05 -w 200 --fallback linear -- database-operation
+
+  # Large memory that still reacts quickly to recent outcomes
+  patience adaptive -w 200 --decay 0.9 -- flaky-service`,
 		Args: cobra.ArbitraryArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
@@ -963,43 +2150,21 @@ Examples:
 				return err
 			}
 
-			// Validate adaptive-specific configuration
-			if strategyConfig.LearningRate <= 0 || strategyConfig.LearningRate > 1.0 {
-				return fmt.Errorf("learning rate must be between 0.01 and 1.0, got %f", strategyConfig.LearningRate)
-			}
-			if strategyConfig.MemoryWindow <= 0 || strategyConfig.MemoryWindow > 10000 {
-				return fmt.Errorf("memory window must be between 1 and 10000, got %d", strategyConfig.MemoryWindow)
-			}
-
 			// Create fallback strategy
-			var fallbackStrategy backoff.Strategy
-			switch strategyConfig.FallbackStrategy {
-			case "exponential", "exp":
-				fallbackStrategy = backoff.NewExponential(1*time.Second, 2.0, 60*time.Second)
-			case "linear", "lin":
-				fallbackStrategy = backoff.NewLinear(1*time.Second, 60*time.Second)
-			case "fixed", "fix":
-				fallbackStrategy = backoff.NewFixed(1 * time.Second)
-			case "jitter", "jit":
-				fallbackStrategy = backoff.NewJitter(1*time.Second, 2.0, 60*time.Second)
-			case "decorrelated-jitter", "dj":
-				fallbackStrategy = backoff.NewDecorrelatedJitter(1*time.Second, 2.0, 60*time.Second)
-			case "fibonacci", "fib":
-				fallbackStrategy = backoff.NewFibonacci(1*time.Second, 60*time.Second)
-			case "polynomial", "poly":
-				poly, err := backoff.NewPolynomial(1*time.Second, 2.0, 60*time.Second)
-				if err != nil {
-					return fmt.Errorf("failed to create polynomial fallback: %w", err)
-				}
-				fallbackStrategy = poly
-			default:
-				fallbackStrategy = backoff.NewExponential(1*time.Second, 2.0, 60*time.Second)
+			fallbackStrategy, err := createFallbackStrategy(strategyConfig.FallbackStrategy)
+			if err != nil {
+				return err
 			}
 
-			// Create adaptive strategy
-			strategy, err := backoff.NewAdaptive(fallbackStrategy, strategyConfig.LearningRate, strategyConfig.MemoryWindow)
+			// Create adaptive strategy (the constructor validates learning rate and memory window)
+			adaptive, err := backoff.NewAdaptive(fallbackStrategy, strategyConfig.LearningRate, strategyConfig.MemoryWindow)
+			if err == nil {
+				err = adaptive.SetDecay(strategyConfig.Decay)
+			}
+			var strategy backoff.Strategy = adaptive
+			strategy, err = resolveStrategy("adaptive", strategy, err, commonConfig)
 			if err != nil {
-				return fmt.Errorf("failed to create adaptive strategy: %w", err)
+				return err
 			}
 
 			return executeWithStrategy(strategy, commonConfig, args)
@@ -1009,6 +2174,7 @@ Examples:
 	// Set default values
 	strategyConfig.LearningRate = 0.1
 	strategyConfig.MemoryWindow = 50
+	strategyConfig.Decay = 1.0
 	strategyConfig.FallbackStrategy = "exponential"
 
 	// Add strategy-specific flags
@@ -1016,6 +2182,8 @@ Examples:
 		"Learning rate for adaptation (0.01-1.0)")
 	cmd.Flags().IntVarP(&strategyConfig.MemoryWindow, "memory-window", "w", strategyConfig.MemoryWindow,
 		"Number of recent outcomes to remember (5-10000)")
+	cmd.Flags().Float64Var(&strategyConfig.Decay, "decay", strategyConfig.Decay,
+		"Weight kept per newer outcome so recent outcomes count more (0-1, 1 disables decay)")
 	cmd.Flags().StringVarP(&strategyConfig.FallbackStrategy, "fallback", "f", strategyConfig.FallbackStrategy,
 		"Fallback strategy (exponential, linear, fixed, jitter, decorrelated-jitter, fibonacci, polynomial)")
 
@@ -1025,6 +2193,175 @@ Examples:
 	return cmd
 }
 
+// createPIDCommand creates the pid subcommand
+func createPIDCommand() *cobra.Command {
+	var strategyConfig PIDConfig
+	var commonConfig CommonConfig = NewCommonConfig()
+
+	cmd := &cobra.Command{
+		Use:     "pid [OPTIONS] -- COMMAND [ARGS...]",
+		Aliases: []string{"controller"},
+		Short:   "PID controller that tunes delays toward a target success rate",
+		Long: `PID strategy adjusts delays with a feedback controller aiming for a target success rate.
+
+Each attempt's outcome updates a smoothed success rate. When it falls below the setpoint
+the delay grows; when successes dominate the delay shrinks, always staying within
+min-delay and max-delay. This is an advanced alternative to the adaptive strategy.
+
+Key parameters:
+- setpoint: Target success rate (0-1, exclusive, default 0.9)
+- kp, ki, kd: Proportional, integral and derivative gains
+- min-delay, max-delay: Bounds on the delay (the controller starts at min-delay)
+
+Examples:
+  # Aim for 90% of attempts succeeding
+  patience pid --setpoint 0.9 -- curl https://api.example.com
+
+  # More aggressive reaction to failures
+  patience controller --kp 2.0 --ki 0.2 --max-delay 2m -- flaky-command`,
+		Args: cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("no command specified after '--'")
+			}
+
+			// Validate configurations
+			if err := commonConfig.Validate(); err != nil {
+				return err
+			}
+
+			// Create strategy (the constructor validates setpoint, gains and bounds)
+			pid, err := backoff.NewPID(strategyConfig.Setpoint, strategyConfig.Kp, strategyConfig.Ki,
+				strategyConfig.Kd, strategyConfig.MinDelay, strategyConfig.MaxDelay)
+			var strategy backoff.Strategy = pid
+			strategy, err = resolveStrategy("pid", strategy, err, commonConfig)
+			if err != nil {
+				return err
+			}
+
+			return executeWithStrategy(strategy, commonConfig, args)
+		},
+	}
+
+	// Set default values
+	strategyConfig.Setpoint = 0.9
+	strategyConfig.Kp = 1.0
+	strategyConfig.Ki = 0.1
+	strategyConfig.Kd = 0.0
+	strategyConfig.MinDelay = 500 * time.Millisecond
+	strategyConfig.MaxDelay = 60 * time.Second
+
+	// Add strategy-specific flags
+	cmd.Flags().Float64Var(&strategyConfig.Setpoint, "setpoint", strategyConfig.Setpoint,
+		"Target success rate (0-1, exclusive)")
+	cmd.Flags().Float64Var(&strategyConfig.Kp, "kp", strategyConfig.Kp, "Proportional gain")
+	cmd.Flags().Float64Var(&strategyConfig.Ki, "ki", strategyConfig.Ki, "Integral gain")
+	cmd.Flags().Float64Var(&strategyConfig.Kd, "kd", strategyConfig.Kd, "Derivative gain")
+	cmd.Flags().DurationVar(&strategyConfig.MinDelay, "min-delay", strategyConfig.MinDelay,
+		"Minimum delay (starting delay)")
+	cmd.Flags().DurationVarP(&strategyConfig.MaxDelay, "max-delay", "m", strategyConfig.MaxDelay,
+		"Maximum delay cap")
+
+	// Add common flags
+	addCommonFlags(cmd, &commonConfig)
+
+	return cmd
+}
+
+// RemoteScheduleConfig holds configuration for the remote-schedule strategy
+type RemoteScheduleConfig struct {
+	ScheduleURL  string
+	FetchTimeout time.Duration
+	Fallback     string
+}
+
+// Validate validates the remote-schedule configuration
+func (r RemoteScheduleConfig) Validate() error {
+	if r.ScheduleURL == "" {
+		return fmt.Errorf("--schedule-url is required")
+	}
+	if u, err := url.Parse(r.ScheduleURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid --schedule-url %q: expected an http or https URL", r.ScheduleURL)
+	}
+	if r.FetchTimeout <= 0 {
+		return fmt.Errorf("fetch-timeout must be positive, got %v", r.FetchTimeout)
+	}
+	return validateFallback(r.Fallback)
+}
+
+// createRemoteScheduleCommand creates the remote-schedule subcommand
+func createRemoteScheduleCommand() *cobra.Command {
+	var strategyConfig RemoteScheduleConfig
+	var commonConfig CommonConfig = NewCommonConfig()
+
+	cmd := &cobra.Command{
+		Use:     "remote-schedule [OPTIONS] -- COMMAND [ARGS...]",
+		Aliases: []string{"remote"},
+		Short:   "Delays from a schedule fetched from an HTTP endpoint",
+		Long: `Remote schedule strategy fetches its delays from a URL, so pacing can be managed centrally.
+
+The endpoint must return a JSON array of delays in seconds, such as [1, 5, 30]. The
+schedule is fetched once, before the first attempt, and reused for every retry: the
+Nth delay follows attempt N, and the last delay repeats once the schedule runs out.
+If the fetch fails or takes longer than fetch-timeout, a warning is shown and the
+fallback strategy is used instead.
+
+Examples:
+  # Pace retries from a central schedule
+  patience remote-schedule --schedule-url https://config.example.com/retry.json -- deploy.sh
+
+  # Short fetch timeout with a fixed fallback
+  patience remote --schedule-url https://config.example.com/retry.json --fetch-timeout 2s --fallback fixed -- sync-job`,
+		Args: cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("no command specified after '--'")
+			}
+
+			// Validate configurations
+			if err := strategyConfig.Validate(); err != nil {
+				return err
+			}
+			if err := commonConfig.Validate(); err != nil {
+				return err
+			}
+
+			fallbackStrategy, err := createFallbackStrategy(strategyConfig.Fallback)
+			if err != nil {
+				return err
+			}
+
+			remote, err := backoff.NewRemoteSchedule(strategyConfig.ScheduleURL, strategyConfig.FetchTimeout, fallbackStrategy)
+			if err != nil {
+				return err
+			}
+			if err := remote.FetchError(); err != nil {
+				ui.NewReporter(os.Stderr).ShowWarning(
+					fmt.Sprintf("%v; using %s fallback", err, strategyConfig.Fallback))
+			}
+
+			return executeWithStrategy(remote, commonConfig, args)
+		},
+	}
+
+	// Set default values
+	strategyConfig.FetchTimeout = 5 * time.Second
+	strategyConfig.Fallback = "exponential"
+
+	// Add strategy-specific flags
+	cmd.Flags().StringVar(&strategyConfig.ScheduleURL, "schedule-url", "",
+		"URL returning a JSON array of delays in seconds (required)")
+	cmd.Flags().DurationVar(&strategyConfig.FetchTimeout, "fetch-timeout", strategyConfig.FetchTimeout,
+		"Maximum time to wait for the schedule")
+	cmd.Flags().StringVarP(&strategyConfig.Fallback, "fallback", "f", strategyConfig.Fallback,
+		"Fallback strategy if the schedule cannot be fetched (exponential, linear, fixed, jitter, decorrelated-jitter, fibonacci, polynomial)")
+
+	// Add common flags
+	addCommonFlags(cmd, &commonConfig)
+
+	return cmd
+}
+
 // parseRetryOffsets parses a comma-separated string of retry offsets into time.Duration slice
 func parseRetryOffsets(offsetsStr string) ([]time.Duration, error) {
 	if offsetsStr == "" {
@@ -1179,6 +2516,10 @@ This strategy is ideal for controlled environments where you schedule tasks.`,
 	cmd.Flags().StringVar(&commonConfig.DaemonSocket, "daemon-socket", "/tmp/patience-daemon.sock", "Daemon socket path")
 	cmd.Flags().DurationVar(&commonConfig.DaemonTimeout, "daemon-timeout", 5*time.Second, "Daemon connection timeout")
 	cmd.Flags().BoolVar(&commonConfig.DaemonAutoStart, "daemon-auto-start", true, "Automatically start daemon if not running")
+	cmd.Flags().StringVar(&commonConfig.DaemonCoordination, "daemon-coordination", executor.CoordinationBestEffort,
+		"When daemon coordination fails: best-effort (schedule locally) or required (abort the run)")
+	cmd.Flags().IntVar(&commonConfig.DaemonMaxRegistrations, "daemon-max-registrations", executor.DefaultMaxPlannedRegistrations,
+		"Planned requests registered with the daemon ahead of the current attempt; more are registered as attempts proceed")
 
 	// Add common flags
 	addCommonFlags(cmd, &commonConfig)
@@ -1197,31 +2538,24 @@ func executeWithDiophantine(strategyConfig DiophantineConfig, commonConfig Commo
 	// Create Diophantine strategy
 	strategy := backoff.NewDiophantine(strategyConfig.RateLimit, strategyConfig.Window, retryOffsets)
 
-	// Create executor
-	exec, err := createExecutorFromConfig(strategy, commonConfig)
-	if err != nil {
-		return fmt.Errorf("failed to create executor: %w", err)
-	}
-
-	// Set resource ID if specified
-	if strategyConfig.ResourceID != "" {
-		exec.ResourceID = strategyConfig.ResourceID
-	}
+	return executeWithStrategy(strategy, commonConfig, commandArgs, func(exec *executor.Executor) error {
+		// Set resource ID if specified
+		if strategyConfig.ResourceID != "" {
+			exec.ResourceID = strategyConfig.ResourceID
+		}
+		exec.DaemonCoordination = commonConfig.DaemonCoordination
+		exec.MaxPlannedRegistrations = commonConfig.DaemonMaxRegistrations
 
-	// Configure daemon client if enabled
-	if commonConfig.DaemonEnabled {
-		err := configureDaemonClient(exec, commonConfig)
-		if err != nil {
+		// Configure daemon client if enabled, unless only previewing the schedule
+		if !commonConfig.DaemonEnabled || commonConfig.DryRun {
+			return nil
+		}
+		if err := configureDaemonClient(exec, commonConfig); err != nil {
+			if commonConfig.DaemonCoordination == executor.CoordinationRequired {
+				return fmt.Errorf("daemon coordination is required: %w", err)
+			}
 			fmt.Printf("Warning: Failed to connect to daemon, falling back to local-only mode: %v\n", err)
 		}
-	}
-
-	// Execute command
-	result, err := exec.Run(commandArgs)
-	if err != nil {
-		return fmt.Errorf("execution error: %w", err)
-	}
-
-	// Handle results
-	return handleExecutionResult(result, exec)
+		return nil
+	})
 }
@@ -1,9 +1,18 @@
 package main
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/shaneisley/patience/pkg/backoff"
+	"github.com/shaneisley/patience/pkg/conditions"
+	"github.com/shaneisley/patience/pkg/executor"
+	"github.com/shaneisley/patience/pkg/metrics"
+	"github.com/shaneisley/patience/pkg/ui"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -272,3 +281,930 @@ func TestIntegrationWithExecutor(t *testing.T) {
 }
 
 // Test helper functions are now implemented in subcommands.go
+
+func TestStrategyFallbackOnError(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		expectError bool
+	}{
+		{
+			name:        "invalid polynomial config fails without fallback",
+			args:        []string{"polynomial", "--base-delay", "2m", "--max-delay", "1s", "--", "echo", "test"},
+			expectError: true,
+		},
+		{
+			name: "invalid polynomial config falls back to exponential",
+			args: []string{"polynomial", "--base-delay", "2m", "--max-delay", "1s",
+				"--strategy-fallback-on-error", "--", "echo", "test"},
+		},
+		{
+			name: "invalid adaptive config falls back to exponential",
+			args: []string{"adaptive", "--learning-rate", "5",
+				"--strategy-fallback-on-error", "--", "echo", "test"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given a root command with an invalid strategy configuration
+			rootCmd := createTestRootCommand()
+			rootCmd.SetArgs(tt.args)
+
+			// When the command is executed
+			err := rootCmd.Execute()
+
+			// Then it should only run when fallback is enabled
+			if tt.expectError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "failed to create polynomial strategy")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestWarnAfterValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		warnAfter   time.Duration
+		timeout     time.Duration
+		errContains string
+	}{
+		{name: "disabled", warnAfter: 0, timeout: time.Second},
+		{name: "below timeout", warnAfter: 500 * time.Millisecond, timeout: time.Second},
+		{name: "no timeout", warnAfter: time.Minute},
+		{name: "negative", warnAfter: -time.Second, errContains: "warn-after must be non-negative"},
+		{name: "not below timeout", warnAfter: time.Second, timeout: time.Second, errContains: "must be less than timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given a config with warn-after and timeout
+			config := NewCommonConfig()
+			config.WarnAfter = tt.warnAfter
+			config.Timeout = tt.timeout
+
+			// When it is validated
+			err := config.Validate()
+
+			// Then only invalid combinations should be rejected
+			if tt.errContains == "" {
+				assert.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+			}
+		})
+	}
+}
+
+func TestMinFreeResourcesValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		disk        string
+		mem         string
+		errContains string
+	}{
+		{name: "disabled"},
+		{name: "valid sizes", disk: "1G", mem: "512MiB"},
+		{name: "bad disk size", disk: "lots", errContains: "invalid min-free-disk"},
+		{name: "bad memory size", mem: "-1M", errContains: "invalid min-free-mem"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given a config with resource thresholds
+			config := NewCommonConfig()
+			config.MinFreeDisk = tt.disk
+			config.MinFreeMem = tt.mem
+
+			// When it is validated
+			err := config.Validate()
+
+			// Then only unparseable sizes should be rejected
+			if tt.errContains == "" {
+				assert.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+			}
+		})
+	}
+}
+func TestRetryJSONErrorCodeValidation(t *testing.T) {
+	// Given configs with retryable JSON error codes
+	valid := NewCommonConfig()
+	valid.RetryJSONErrorCodes = []string{"TRY_AGAIN", "429"}
+	empty := NewCommonConfig()
+	empty.RetryJSONErrorCodes = []string{"TRY_AGAIN", " "}
+
+	// When they are validated, Then only the empty code should be rejected
+	assert.NoError(t, valid.Validate())
+	assert.ErrorContains(t, empty.Validate(), "retry-json-error-code must not be empty")
+}
+
+func TestFirstSuccessExitFastValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		output      string
+		confirm     time.Duration
+		errContains string
+	}{
+		{name: "text output", output: ui.OutputText},
+		{name: "json output", output: ui.OutputNDJSON, errContains: "cannot be combined with --output ndjson"},
+		{name: "confirm long waits", output: ui.OutputText, confirm: time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given a fast-exit config with other output options
+			config := NewCommonConfig()
+			config.FirstSuccessExitFast = true
+			config.Output = tt.output
+			config.ConfirmLongWaits = tt.confirm
+
+			// When it is validated
+			err := config.Validate()
+
+			// Then only JSON output, which expects the summary, should be rejected
+			if tt.errContains == "" {
+				require.NoError(t, err)
+
+				// And the run should keep its reporter for progress and warnings
+				exec, err := createExecutorFromConfig(nil, config)
+				require.NoError(t, err)
+				assert.True(t, exec.FirstSuccessExitFast)
+				assert.NotNil(t, exec.Reporter)
+			} else {
+				assert.ErrorContains(t, err, tt.errContains)
+			}
+		})
+	}
+}
+
+func TestAdaptiveDecayValidation(t *testing.T) {
+	// Given an adaptive command with a decay outside (0, 1]
+	rootCmd := createTestRootCommand()
+	rootCmd.SetArgs([]string{"adaptive", "--decay", "1.5", "--", "echo", "test"})
+
+	// When the command is executed
+	err := rootCmd.Execute()
+
+	// Then strategy creation should fail with a decay error
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to create adaptive strategy")
+	assert.Contains(t, err.Error(), "decay must be greater than 0")
+}
+
+func TestMaxDelayBelowBaseDelay(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		errContains string
+	}{
+		{
+			name:        "exponential",
+			args:        []string{"exponential", "--base-delay", "2m", "--max-delay", "1s", "--", "echo", "test"},
+			errContains: "--base-delay (2m0s) must not exceed --max-delay (1s)",
+		},
+		{
+			name:        "linear",
+			args:        []string{"linear", "--increment", "2m", "--max-delay", "1s", "--", "echo", "test"},
+			errContains: "--increment (2m0s) must not exceed --max-delay (1s)",
+		},
+		{
+			name:        "jitter",
+			args:        []string{"jitter", "--base-delay", "2m", "--max-delay", "1s", "--", "echo", "test"},
+			errContains: "--base-delay (2m0s) must not exceed --max-delay (1s)",
+		},
+		{
+			name:        "decorrelated-jitter",
+			args:        []string{"decorrelated-jitter", "--base-delay", "2m", "--max-delay", "1s", "--", "echo", "test"},
+			errContains: "--base-delay (2m0s) must not exceed --max-delay (1s)",
+		},
+		{
+			name:        "fibonacci",
+			args:        []string{"fibonacci", "--base-delay", "2m", "--max-delay", "1s", "--", "echo", "test"},
+			errContains: "--base-delay (2m0s) must not exceed --max-delay (1s)",
+		},
+		{
+			name:        "polynomial",
+			args:        []string{"polynomial", "--base-delay", "2m", "--max-delay", "1s", "--", "echo", "test"},
+			errContains: "--base-delay (2m0s) must not exceed --max-delay (1s)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given a strategy whose max delay is below its starting delay
+			rootCmd := createTestRootCommand()
+			rootCmd.SetArgs(tt.args)
+
+			// When the command is executed
+			err := rootCmd.Execute()
+
+			// Then it should fail with an error naming the conflicting flags
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errContains)
+		})
+	}
+}
+
+func TestDelayBoundsAllowUnlimitedMaxDelay(t *testing.T) {
+	// Given configurations with max delay 0 (no limit)
+	// Then validation should not report a conflict
+	assert.NoError(t, LinearConfig{Increment: time.Minute}.Validate())
+	assert.NoError(t, FibonacciConfig{BaseDelay: time.Minute}.Validate())
+	assert.NoError(t, JitterConfig{BaseDelay: time.Minute, Multiplier: 2}.Validate())
+	assert.NoError(t, DecorrelatedJitterConfig{BaseDelay: time.Minute, Multiplier: 2}.Validate())
+}
+
+func TestExponentialHalfLife(t *testing.T) {
+	t.Run("derives the multiplier", func(t *testing.T) {
+		// Given an exponential strategy configured by half-life
+		rootCmd := createTestRootCommand()
+		rootCmd.SetArgs([]string{"exponential", "--half-life", "3", "--base-delay", "1s", "--max-delay", "0", "--", "true"})
+
+		// When the command is executed
+		require.NoError(t, rootCmd.Execute())
+
+		// Then the delay should double every three attempts
+		config := getLastParsedExponentialConfig()
+		assert.InDelta(t, 1.259921, config.Multiplier, 1e-6)
+		strategy := backoff.NewExponential(config.BaseDelay, config.Multiplier, config.MaxDelay)
+		backoff.AssertDelayWithin(t, strategy.Delay(4), 2*time.Second, time.Millisecond)
+		backoff.AssertDelayWithin(t, strategy.Delay(7), 4*time.Second, time.Millisecond)
+	})
+
+	tests := []struct {
+		name        string
+		args        []string
+		errContains string
+	}{
+		{
+			name:        "with multiplier",
+			args:        []string{"exponential", "--half-life", "3", "--multiplier", "1.5", "--", "true"},
+			errContains: "--half-life and --multiplier are mutually exclusive",
+		},
+		{
+			name:        "non-positive",
+			args:        []string{"exponential", "--half-life", "0", "--", "true"},
+			errContains: "half-life must be a positive number of attempts",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rootCmd := createTestRootCommand()
+			rootCmd.SetArgs(tt.args)
+
+			err := rootCmd.Execute()
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errContains)
+		})
+	}
+}
+
+func TestOutputModeValidation(t *testing.T) {
+	// Given an unknown --output mode
+	rootCmd := createTestRootCommand()
+	rootCmd.SetArgs([]string{"fixed", "--output", "xml", "--", "true"})
+
+	// When the command is executed
+	err := rootCmd.Execute()
+
+	// Then it should fail listing the valid modes
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown output "xml" (valid: text, ndjson, json-events)`)
+}
+
+func TestOutputCombinationValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		modify      func(*CommonConfig)
+		errContains string
+	}{
+		{name: "color always with json", modify: func(c *CommonConfig) { c.Color, c.Output = ui.ColorAlways, ui.OutputNDJSON }},
+		{name: "quiet with json", modify: func(c *CommonConfig) { c.Quiet, c.Output = true, ui.OutputJSONEvents }},
+		{name: "unknown color", modify: func(c *CommonConfig) { c.Color = "sometimes" }, errContains: `unknown color "sometimes" (valid: auto, always, never)`},
+		{name: "dry run with json", modify: func(c *CommonConfig) { c.DryRun, c.Output = true, ui.OutputNDJSON }, errContains: "--dry-run cannot be combined with --output ndjson"},
+		{name: "dry run with text", modify: func(c *CommonConfig) { c.DryRun, c.Output = true, ui.OutputText }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given a config combining output options
+			config := NewCommonConfig()
+			tt.modify(&config)
+
+			// When it is validated
+			err := config.Validate()
+
+			// Then only combinations that would corrupt stdout are rejected
+			if tt.errContains == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.errContains)
+			}
+		})
+	}
+}
+
+func TestCostBudgetValidation(t *testing.T) {
+	config := NewCommonConfig()
+	config.CostPerAttempt, config.MaxCost = 0.25, 1
+	assert.NoError(t, config.Validate())
+
+	config.CostPerAttempt = 0
+	assert.ErrorContains(t, config.Validate(), "--max-cost requires --cost-per-attempt")
+
+	config.CostPerAttempt = 2
+	assert.ErrorContains(t, config.Validate(), "--cost-per-attempt (2) must not exceed --max-cost (1)")
+
+	config.CostPerAttempt, config.MaxCost = -1, 0
+	assert.ErrorContains(t, config.Validate(), "cost-per-attempt must be non-negative")
+}
+
+func TestAbortPattern(t *testing.T) {
+	// Given a case-insensitive abort pattern
+	config := NewCommonConfig()
+	config.Attempts = 5
+	config.AbortPattern = "invalid credentials"
+	config.CaseInsensitive = true
+	require.NoError(t, config.Validate())
+
+	exec, err := createExecutorFromConfig(backoff.NewFixed(time.Millisecond), config)
+	require.NoError(t, err)
+
+	// When the command fails with exit code 3 and a matching message
+	result, err := exec.Run([]string{"sh", "-c", "echo 'Invalid Credentials' >&2; exit 3"})
+
+	// Then it should stop after one attempt, keeping the exit code
+	require.ErrorIs(t, err, executor.ErrFailurePattern)
+	assert.Equal(t, 1, result.AttemptCount)
+	assert.Equal(t, 3, result.ExitCode)
+	assert.Equal(t, "exit code 3", result.Reason)
+
+	config.AbortPattern = "invalid ("
+	assert.ErrorContains(t, config.Validate(), "invalid abort pattern")
+}
+
+func TestPatternStream(t *testing.T) {
+	// Given a success pattern restricted to stderr
+	config := NewCommonConfig()
+	config.SuccessPattern = "done"
+	config.PatternStream = "stderr"
+	require.NoError(t, config.Validate())
+
+	exec, err := createExecutorFromConfig(nil, config)
+	require.NoError(t, err)
+
+	// When "done" is only printed to stdout
+	result, err := exec.Run([]string{"sh", "-c", "echo done; exit 1"})
+
+	// Then the pattern should not match
+	require.ErrorIs(t, err, executor.ErrMaxAttempts)
+	assert.False(t, result.Success)
+
+	config.PatternStream = "stdin"
+	assert.ErrorContains(t, config.Validate(), "pattern-stream must be stdout, stderr or both")
+}
+
+func TestHTTPAwareIdempotencyHeaderValidation(t *testing.T) {
+	config := HTTPAwareConfig{Fallback: "exponential", IdempotencyHeader: "Idempotency-Key"}
+	assert.NoError(t, config.Validate())
+
+	config.IdempotencyHeader = "Idempotency Key"
+	assert.ErrorContains(t, config.Validate(), "invalid --idempotency-header")
+}
+
+func TestHTTPAwareLoadHeaderValidation(t *testing.T) {
+	config := HTTPAwareConfig{Fallback: "exponential", LoadHeader: "X-Server-Load"}
+	assert.NoError(t, config.Validate())
+
+	config.LoadHeader = "X Server Load"
+	assert.ErrorContains(t, config.Validate(), "invalid --load-header")
+}
+
+func TestHTTPAwareStatusMultiplierValidation(t *testing.T) {
+	config := HTTPAwareConfig{Fallback: "jitter", StatusMultipliers: "5..:1.5,429:3"}
+	assert.NoError(t, config.Validate())
+
+	config.StatusMultipliers = "429:0"
+	assert.ErrorContains(t, config.Validate(), "invalid status multiplier")
+}
+
+func TestHTTPAwareInitialDelayValidation(t *testing.T) {
+	config := HTTPAwareConfig{Fallback: "exponential", InitialDelay: 30 * time.Second}
+	assert.NoError(t, config.Validate())
+
+	config.InitialDelay = -time.Second
+	assert.ErrorContains(t, config.Validate(), "http-initial-delay must be non-negative")
+}
+
+func TestHTTPAwareRetryStatusValidation(t *testing.T) {
+	config := HTTPAwareConfig{Fallback: "exponential", RetryStatus: "5..,408,429"}
+	assert.NoError(t, config.Validate())
+
+	config.RetryStatus = "5xx"
+	assert.ErrorContains(t, config.Validate(), `invalid HTTP status "5xx"`)
+}
+
+func TestHTTPAwareSuccessCriteriaValidation(t *testing.T) {
+	config := HTTPAwareConfig{Fallback: "exponential", SuccessStatus: "200", SuccessJSONEq: []string{"status=ready"}}
+	assert.NoError(t, config.Validate())
+
+	config.SuccessStatus = "ok"
+	assert.ErrorContains(t, config.Validate(), "invalid HTTP success criteria")
+
+	config.SuccessStatus = "200"
+	config.SuccessJSONEq = []string{"status"}
+	assert.ErrorContains(t, config.Validate(), "invalid HTTP success criteria")
+}
+
+func TestRegexTimeoutValidation(t *testing.T) {
+	config := NewCommonConfig()
+	config.RegexTimeout = 100 * time.Millisecond
+	assert.NoError(t, config.Validate())
+
+	config.RegexTimeout = -time.Second
+	assert.ErrorContains(t, config.Validate(), "regex-timeout must be non-negative")
+}
+
+func TestMonotonicWrapsStrategy(t *testing.T) {
+	// Given a decorrelated jitter strategy and --monotonic
+	config := NewCommonConfig()
+	config.Monotonic = true
+	strategy := backoff.NewDecorrelatedJitter(time.Second, 3.0, time.Minute)
+
+	// When the executor is created
+	exec, err := createExecutorFromConfig(strategy, config)
+	require.NoError(t, err)
+
+	// Then it runs the strategy behind the monotonic decorator
+	assert.NotSame(t, strategy, exec.BackoffStrategy)
+	assert.Same(t, strategy, backoff.Unwrap(exec.BackoffStrategy))
+}
+
+func TestDelayJSONPathValidation(t *testing.T) {
+	config := NewCommonConfig()
+	config.DelayJSONPath, config.DelayJSONUnit = "$.wait_ms", "ms"
+	assert.NoError(t, config.Validate())
+
+	exec, err := createExecutorFromConfig(backoff.NewExponential(time.Second, 2, 0), config)
+	require.NoError(t, err)
+	require.NotNil(t, exec.DelayJSON)
+	assert.Equal(t, time.Millisecond, exec.DelayJSON.Unit)
+
+	config.DelayJSONUnit = "minutes"
+	assert.ErrorContains(t, config.Validate(), `invalid delay JSON unit "minutes"`)
+
+	config.DelayJSONPath, config.DelayJSONUnit = "$", "s"
+	assert.ErrorContains(t, config.Validate(), "invalid delay JSON path")
+}
+
+func TestAttemptsFileWiring(t *testing.T) {
+	config := NewCommonConfig()
+	config.AttemptsFile = filepath.Join(t.TempDir(), "job.attempts")
+
+	exec, err := createExecutorFromConfig(backoff.NewFixed(time.Second), config)
+	require.NoError(t, err)
+	assert.Equal(t, config.AttemptsFile, exec.AttemptsFile)
+}
+
+func TestRetryIfValidation(t *testing.T) {
+	config := NewCommonConfig()
+	config.RetryIf = "exit != 0 and not match(out, 'done') or http == 503"
+	assert.NoError(t, config.Validate())
+
+	exec, err := createExecutorFromConfig(backoff.NewFixed(time.Second), config)
+	require.NoError(t, err)
+	require.NotNil(t, exec.RetryIf)
+	assert.Equal(t, config.RetryIf, exec.RetryIf.String())
+
+	config.RetryIf = "exit != 0 and"
+	assert.ErrorContains(t, config.Validate(), "invalid retry-if expression")
+}
+
+func TestStepsOptIn(t *testing.T) {
+	// Given a command containing "--and"
+	command := []string{"echo", "a", "--and", "b"}
+
+	// When steps are not requested, the executor should run it as one command
+	config := NewCommonConfig()
+	exec, err := createExecutorFromConfig(backoff.NewFixed(time.Second), config)
+	require.NoError(t, err)
+	assert.False(t, exec.Steps)
+
+	// And with --steps, it should split it into steps
+	config.Steps = true
+	exec, err = createExecutorFromConfig(backoff.NewFixed(time.Second), config)
+	require.NoError(t, err)
+	assert.True(t, exec.Steps)
+	steps, err := executor.SplitSteps(command)
+	require.NoError(t, err)
+	assert.Len(t, steps, 2)
+}
+
+func TestMergeStreams(t *testing.T) {
+	config := NewCommonConfig()
+	config.MergeStreams = true
+	assert.NoError(t, config.Validate())
+
+	exec, err := createExecutorFromConfig(backoff.NewFixed(time.Second), config)
+	require.NoError(t, err)
+	runner, ok := exec.Runner.(*executor.SystemCommandRunner)
+	require.True(t, ok)
+	assert.True(t, runner.MergeStreams)
+
+	config.PatternStream = conditions.PatternStreamStderr
+	assert.ErrorContains(t, config.Validate(), "--merge-streams captures stderr together with stdout")
+}
+
+func TestMetricsSyncWiring(t *testing.T) {
+	config := NewCommonConfig()
+	config.MetricsSync = true
+	config.MetricsFile = "runs.ndjson"
+
+	exec, err := createExecutorFromConfig(backoff.NewFixed(time.Second), config)
+	require.NoError(t, err)
+	assert.True(t, exec.MetricsSync)
+	assert.Equal(t, "runs.ndjson", exec.MetricsFile)
+}
+
+func TestStateMaxAge(t *testing.T) {
+	config := NewCommonConfig()
+	config.StateFile = "retry.state"
+	config.StateMaxAge = 2 * time.Hour
+	assert.NoError(t, config.Validate())
+
+	exec, err := createExecutorFromConfig(backoff.NewFixed(time.Second), config)
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Hour, exec.StateMaxAge)
+
+	config.StateMaxAge = -time.Second
+	assert.ErrorContains(t, config.Validate(), "state-max-age must be non-negative")
+}
+
+func TestDaemonMaxRegistrationsValidation(t *testing.T) {
+	config := NewCommonConfig()
+	config.DaemonMaxRegistrations = 50
+	assert.NoError(t, config.Validate())
+
+	config.DaemonMaxRegistrations = -1
+	assert.ErrorContains(t, config.Validate(), "daemon-max-registrations must be non-negative")
+}
+
+func TestProgressResetValidation(t *testing.T) {
+	config := NewCommonConfig()
+	config.ResetBackoffOnProgress, config.ProgressPattern = true, `chunk \d+`
+	assert.NoError(t, config.Validate())
+
+	exec, err := createExecutorFromConfig(backoff.NewExponential(time.Second, 2, 0), config)
+	require.NoError(t, err)
+	assert.True(t, exec.ProgressPattern.MatchString("uploaded chunk 3"))
+	assert.False(t, exec.ProgressPattern.MatchString("Uploaded Chunk 3"))
+
+	// --case-insensitive applies to the progress pattern too
+	config.CaseInsensitive = true
+	exec, err = createExecutorFromConfig(backoff.NewExponential(time.Second, 2, 0), config)
+	require.NoError(t, err)
+	assert.True(t, exec.ProgressPattern.MatchString("Uploaded Chunk 3"))
+	config.CaseInsensitive = false
+
+	config.ProgressPattern = ""
+	assert.ErrorContains(t, config.Validate(), "--reset-backoff-on-progress requires --progress-pattern")
+
+	config.ResetBackoffOnProgress, config.ProgressPattern = false, "chunk"
+	assert.ErrorContains(t, config.Validate(), "--progress-pattern has no effect without --reset-backoff-on-progress")
+
+	config.ResetBackoffOnProgress, config.ProgressPattern = true, "chunk ("
+	assert.ErrorContains(t, config.Validate(), "invalid progress pattern")
+}
+
+func TestNewRunSummary(t *testing.T) {
+	// Given a finished run with metrics
+	attempts := []metrics.AttemptMetric{{ExitCode: 1}, {ExitCode: 0, Success: true}}
+	result := &executor.Result{
+		Success:      true,
+		AttemptCount: 2,
+		Reason:       "exit code 0",
+		Metrics:      metrics.NewRunMetrics([]string{"deploy", "--prod"}, true, time.Second, attempts),
+
+		MetricsDelivered: true,
+	}
+
+	// When it is summarized for ndjson output
+	summary := newRunSummary(result)
+
+	// Then the summary carries the outcome, every attempt and the metrics delivery
+	assert.Equal(t, "deploy --prod", summary.Command)
+	assert.True(t, summary.Success)
+	assert.Equal(t, 2, summary.TotalAttempts)
+	assert.Equal(t, attempts, summary.Attempts)
+	assert.True(t, summary.MetricsDelivered)
+}
+
+func TestPIDSubcommand(t *testing.T) {
+	t.Run("runs with controller alias", func(t *testing.T) {
+		// Given a root command using the pid strategy via its alias
+		rootCmd := createTestRootCommand()
+		rootCmd.SetArgs([]string{"controller", "--setpoint", "0.8", "--kp", "2.0", "--", "echo", "test"})
+
+		// When the command is executed
+		err := rootCmd.Execute()
+
+		// Then it should succeed
+		assert.NoError(t, err)
+	})
+
+	t.Run("rejects setpoint outside 0-1", func(t *testing.T) {
+		// Given a setpoint that is not a valid success rate
+		rootCmd := createTestRootCommand()
+		rootCmd.SetArgs([]string{"pid", "--setpoint", "1.5", "--", "echo", "test"})
+
+		// When the command is executed
+		err := rootCmd.Execute()
+
+		// Then the constructor error should be reported
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to create pid strategy")
+		assert.Contains(t, err.Error(), "setpoint")
+	})
+}
+
+func TestRequirePassesOf(t *testing.T) {
+	// Given --require-passes 2 --of 4 on a fixed command
+	cmd := createFixedCommand()
+	require.NoError(t, cmd.ParseFlags([]string{"--require-passes", "2", "--of", "4"}))
+	of, err := cmd.Flags().GetInt("of")
+	require.NoError(t, err)
+	attempts, err := cmd.Flags().GetInt("attempts")
+	require.NoError(t, err)
+
+	// Then --of should set the number of attempts
+	assert.Equal(t, 4, of)
+	assert.Equal(t, 4, attempts)
+
+	config := NewCommonConfig()
+	config.Attempts, config.RequirePasses = 3, 2
+	require.NoError(t, config.Validate())
+	exec, err := createExecutorFromConfig(nil, config)
+	require.NoError(t, err)
+	assert.Equal(t, 2, exec.RequirePasses)
+
+	config.RequirePasses = 4
+	assert.ErrorContains(t, config.Validate(), "require-passes (4) cannot exceed attempts (3)")
+
+	config.RequirePasses = -1
+	assert.ErrorContains(t, config.Validate(), "require-passes must be non-negative")
+}
+
+func TestRemoteScheduleValidation(t *testing.T) {
+	config := RemoteScheduleConfig{ScheduleURL: "https://config.example.com/retry.json", FetchTimeout: time.Second, Fallback: "fixed"}
+	assert.NoError(t, config.Validate())
+
+	config.ScheduleURL = ""
+	assert.ErrorContains(t, config.Validate(), "--schedule-url is required")
+
+	config.ScheduleURL = "file:///etc/retry.json"
+	assert.ErrorContains(t, config.Validate(), "expected an http or https URL")
+
+	config.ScheduleURL, config.FetchTimeout = "http://localhost/retry.json", 0
+	assert.ErrorContains(t, config.Validate(), "fetch-timeout must be positive")
+
+	config.FetchTimeout, config.Fallback = time.Second, "fixd"
+	assert.ErrorContains(t, config.Validate(), `did you mean "fixed"?`)
+}
+
+func TestRemoteScheduleCommand(t *testing.T) {
+	// Given an endpoint serving a short schedule
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[0.01, 0.01]`))
+	}))
+	defer server.Close()
+
+	// When a failing command is retried with the remote schedule
+	rootCmd := createTestRootCommand()
+	rootCmd.SetArgs([]string{"remote-schedule", "--schedule-url", server.URL,
+		"--attempts", "2", "--", "sh", "-c", "exit 0"})
+	err := rootCmd.Execute()
+
+	// Then it should run with the fetched schedule
+	assert.NoError(t, err)
+}
+
+func TestAllowedWindowValidation(t *testing.T) {
+	tests := []struct {
+		name         string
+		window       string
+		maxTotalTime time.Duration
+		align        time.Duration
+		errContains  string
+	}{
+		{name: "disabled"},
+		{name: "window with time zone and limit", window: "22:00-06:00 UTC", maxTotalTime: 12 * time.Hour},
+		{name: "bad window", window: "22:00-", errContains: "invalid allowed window"},
+		{name: "negative limit", maxTotalTime: -time.Second, errContains: "max-total-time must be non-negative"},
+		{name: "aligned to minutes", align: time.Minute},
+		{name: "negative alignment", align: -time.Minute, errContains: "align-interval must be non-negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given a config with an allowed window and total time limit
+			config := NewCommonConfig()
+			config.AllowedWindow = tt.window
+			config.MaxTotalTime = tt.maxTotalTime
+			config.AlignInterval = tt.align
+
+			// When it is validated
+			err := config.Validate()
+
+			// Then only malformed values should be rejected
+			if tt.errContains == "" {
+				assert.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+			}
+		})
+	}
+}
+
+func TestFatalPatternValidation(t *testing.T) {
+	config := NewCommonConfig()
+
+	// A fatal pattern with the default exit code is accepted
+	config.FatalPattern = "panic|fatal"
+	assert.NoError(t, config.Validate())
+	assert.Equal(t, 2, config.FatalExit)
+
+	// Exit codes outside 1-255 are rejected
+	config.FatalExit = 0
+	assert.ErrorContains(t, config.Validate(), "fatal-exit must be between 1 and 255")
+	config.FatalExit = 256
+	assert.ErrorContains(t, config.Validate(), "fatal-exit must be between 1 and 255")
+
+	// Invalid patterns are rejected
+	config.FatalExit = 3
+	config.FatalPattern = "panic("
+	assert.ErrorContains(t, config.Validate(), "invalid fatal pattern")
+}
+
+func TestReasonTemplateValidation(t *testing.T) {
+	config := NewCommonConfig()
+
+	// A template using ReasonData fields is accepted
+	config.ReasonTemplate = "{{if .HTTPStatus}}HTTP {{.HTTPStatus}}{{else}}{{.Reason}}{{end}}"
+	assert.NoError(t, config.Validate())
+
+	// Syntax errors and unknown fields are rejected
+	config.ReasonTemplate = "{{.Reason"
+	assert.ErrorContains(t, config.Validate(), "invalid reason template")
+	config.ReasonTemplate = "{{.Status}}"
+	assert.ErrorContains(t, config.Validate(), "invalid reason template")
+}
+
+func TestEnvPolicyValidation(t *testing.T) {
+	config := NewCommonConfig()
+
+	// Names and explicit values are accepted
+	config.EnvAllowlist = []string{"PATH", "REGION=eu-west-1"}
+	assert.NoError(t, config.Validate())
+
+	// Invalid names are rejected
+	config.EnvAllowlist = []string{"NOT-A-NAME"}
+	assert.ErrorContains(t, config.Validate(), "invalid environment variable")
+
+	// A cleared environment takes only explicit values
+	config.EnvClear = true
+	config.EnvAllowlist = []string{"PATH"}
+	assert.ErrorContains(t, config.Validate(), "--env-clear passes no parent variables")
+	config.EnvAllowlist = []string{"PATH=/usr/bin"}
+	assert.NoError(t, config.Validate())
+}
+
+func TestUnlimitedAttemptsValidation(t *testing.T) {
+	config := NewCommonConfig()
+	config.Attempts = 0
+
+	// Unlimited attempts without a bound are refused
+	assert.ErrorContains(t, config.Validate(), "--attempts 0 retries without limit and needs a bound")
+
+	// A total time limit or a cost budget bounds them
+	config.MaxTotalTime = 10 * time.Minute
+	assert.NoError(t, config.Validate())
+	config.MaxTotalTime = 0
+	config.CostPerAttempt, config.MaxCost = 0.1, 5
+	assert.NoError(t, config.Validate())
+
+	// Negative attempts are still rejected
+	config.Attempts = -1
+	assert.ErrorContains(t, config.Validate(), "attempts must be between 1 and 1000, or 0 for unlimited")
+}
+
+func TestProgressFIFOValidation(t *testing.T) {
+	config := NewCommonConfig()
+
+	// A path that doesn't exist yet is accepted, and validating doesn't create it
+	config.ProgressFIFO = filepath.Join(t.TempDir(), "progress")
+	assert.NoError(t, config.Validate())
+	assert.NoFileExists(t, config.ProgressFIFO)
+
+	// A regular file is rejected
+	require.NoError(t, os.WriteFile(config.ProgressFIFO, nil, 0o600))
+	assert.ErrorContains(t, config.Validate(), "is not a named pipe")
+}
+
+func TestDiscoveryCacheValidation(t *testing.T) {
+	config := NewCommonConfig()
+
+	// A cache file with a TTL is accepted
+	config.DiscoveryCache = filepath.Join(t.TempDir(), "discovery.json")
+	config.DiscoveryCacheTTL = 30 * time.Minute
+	assert.NoError(t, config.Validate())
+
+	// A negative TTL is rejected
+	config.DiscoveryCacheTTL = -time.Minute
+	assert.ErrorContains(t, config.Validate(), "discovery-cache-ttl must be non-negative")
+}
+
+func TestMetricFlagValidation(t *testing.T) {
+	config := NewCommonConfig()
+
+	// Numeric values are accepted
+	config.Metrics = []string{"build_number=1234", "artifact_mb=12.5"}
+	assert.NoError(t, config.Validate())
+
+	// Non-numeric values are rejected
+	config.Metrics = []string{"build_number=latest"}
+	assert.ErrorContains(t, config.Validate(), "value must be a finite number")
+}
+
+func TestDaemonCoordinationValidation(t *testing.T) {
+	config := NewCommonConfig()
+
+	// Best-effort, the default, needs nothing else
+	config.DaemonCoordination = executor.CoordinationBestEffort
+	assert.NoError(t, config.Validate())
+
+	// Required coordination needs the daemon enabled
+	config.DaemonCoordination = executor.CoordinationRequired
+	assert.ErrorContains(t, config.Validate(), "requires --daemon")
+	config.DaemonEnabled = true
+	assert.NoError(t, config.Validate())
+
+	// Unknown modes are rejected
+	config.DaemonCoordination = "sometimes"
+	assert.ErrorContains(t, config.Validate(), "invalid daemon-coordination")
+}
+
+func TestDiophantineRequiredCoordinationWithoutDaemon(t *testing.T) {
+	// Given required coordination with a daemon that is not running
+	socket := filepath.Join(t.TempDir(), "missing.sock")
+	marker := filepath.Join(t.TempDir(), "ran")
+	cmd := createDiophantineCommand()
+	cmd.SetArgs([]string{"--daemon", "--daemon-socket", socket, "--daemon-timeout", "100ms",
+		"--daemon-coordination", "required", "--", "touch", marker})
+
+	// When the command is executed
+	err := cmd.Execute()
+
+	// Then it should fail with a clear error without running the command
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "daemon coordination is required")
+	assert.NoFileExists(t, marker)
+}
+
+func TestRequireSuccessPatternValidation(t *testing.T) {
+	config := NewCommonConfig()
+
+	// Requiring a success pattern needs one
+	config.RequireSuccessPattern = true
+	assert.ErrorContains(t, config.Validate(), "requires --success-pattern")
+
+	config.SuccessPattern = "healthy"
+	assert.NoError(t, config.Validate())
+}
+
+func TestRunExecutor(t *testing.T) {
+	// Given a command that keeps failing
+	exec, err := createExecutorFromConfig(backoff.NewFixed(time.Millisecond), NewCommonConfig())
+	require.NoError(t, err)
+	exec.Reporter = nil
+
+	// When it runs out of attempts, the result reports the failure without an error
+	result, err := runExecutor(exec, []string{"sh", "-c", "exit 4"})
+	require.NoError(t, err)
+	assert.False(t, result.Success)
+	assert.Equal(t, 4, result.ExitCode)
+
+	// And a command that cannot be started is an execution error
+	_, err = runExecutor(exec, []string{"patience-no-such-command-xyz"})
+	assert.ErrorIs(t, err, executor.ErrCommandNotFound)
+	assert.ErrorContains(t, err, "execution error")
+}
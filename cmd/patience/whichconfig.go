@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/shaneisley/patience/pkg/config"
+)
+
+// discoverConfigFile finds the config file loaded when --config is not given,
+// checking the current directory and then the home directory
+func discoverConfigFile() (string, []string) {
+	cwd, _ := os.Getwd()
+	homeDir, _ := os.UserHomeDir()
+	return config.DiscoverConfigFile(cwd, homeDir)
+}
+
+// printWhichConfig writes the discovered config file path, or the search order
+// that was tried when no config file was found
+func printWhichConfig(w io.Writer, found string, searched []string) error {
+	if found != "" {
+		_, err := fmt.Fprintln(w, found)
+		return err
+	}
+
+	if _, err := fmt.Fprintln(w, "none; using defaults"); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(w, "searched:"); err != nil {
+		return err
+	}
+	for _, path := range searched {
+		if _, err := fmt.Fprintf(w, "  %s\n", path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -20,7 +20,12 @@ var (
 	pidFile     = flag.String("pid-file", "/var/run/patience/daemon.pid", "PID file path")
 	logLevel    = flag.String("log-level", "info", "Log level (debug, info, warn, error)")
 	enableHTTP  = flag.Bool("enable-http", true, "Enable HTTP API server")
-	enableProf  = flag.Bool("enable-profiling", false, "Enable profiling endpoints")
+	enableProf  = flag.Bool("enable-profiling", false, "Enable profiling endpoints (requires a profiling token)")
+	profAddr    = flag.String("prof-addr", "", "Serve profiling on this address instead of the HTTP API port (e.g. 127.0.0.1:6060)")
+	profToken   = flag.String("prof-token", "", "Bearer token for profiling endpoints (default $PATIENCE_PROFILING_TOKEN)")
+	persistPath = flag.String("persist-path", "", "File to persist metrics to across restarts (disabled if empty)")
+	persistInt  = flag.Duration("persist-interval", time.Minute, "How often to persist metrics")
+	haltToken   = flag.String("halt-token", "", "Token authorizing halt and resume requests (default $PATIENCE_HALT_TOKEN; halting is disabled without one)")
 	daemonize   = flag.Bool("daemon", false, "Run as daemon (background process)")
 	showVersion = flag.Bool("version", false, "Show version information")
 	showStatus  = flag.Bool("status", false, "Show daemon status")
@@ -121,6 +126,25 @@ func loadConfiguration() (*daemon.Config, error) {
 	if *configFile == "" || *enableProf != false {
 		config.EnableProfiling = *enableProf
 	}
+	if *configFile == "" || *profAddr != "" {
+		config.ProfilingAddr = *profAddr
+	}
+	if *profToken != "" {
+		config.ProfilingToken = *profToken
+	} else if token := os.Getenv("PATIENCE_PROFILING_TOKEN"); token != "" {
+		config.ProfilingToken = token
+	}
+	if *haltToken != "" {
+		config.HaltToken = *haltToken
+	} else if token := os.Getenv("PATIENCE_HALT_TOKEN"); token != "" {
+		config.HaltToken = token
+	}
+	if *configFile == "" || *persistPath != "" {
+		config.PersistPath = *persistPath
+	}
+	if *configFile == "" || *persistInt != time.Minute {
+		config.PersistInterval = *persistInt
+	}
 
 	return config, nil
 }
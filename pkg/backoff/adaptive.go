@@ -2,6 +2,7 @@ package backoff
 
 import (
 	"fmt"
+	"math"
 	"sync"
 	"time"
 )
@@ -28,6 +29,7 @@ type Adaptive struct {
 	fallbackStrategy Strategy
 	learningRate     float64
 	memoryWindow     int
+	decay            float64
 
 	// Learning data structures (protected by mutex)
 	mu             sync.RWMutex
@@ -57,12 +59,33 @@ func NewAdaptive(fallback Strategy, learningRate float64, memoryWindow int) (*Ad
 		fallbackStrategy: fallback,
 		learningRate:     learningRate,
 		memoryWindow:     memoryWindow,
+		decay:            1.0,
 		delayBuckets:     make(map[int]*DelayBucket),
 		recentOutcomes:   make([]OutcomeRecord, 0, memoryWindow),
 		totalOutcomes:    0,
 	}, nil
 }
 
+// SetDecay weights recent outcomes more heavily than older ones. Each outcome's
+// weight is multiplied by decay once for every outcome recorded after it, so
+// a decay of 0.9 halves an outcome's influence after about seven newer ones.
+// A decay of 1.0 (the default) weights every outcome in the memory window
+// equally; the window remains a hard cap on how many outcomes are kept.
+func (a *Adaptive) SetDecay(decay float64) error {
+	if decay <= 0 || decay > 1.0 {
+		return fmt.Errorf("decay must be greater than 0 and at most 1.0, got %f", decay)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.decay = decay
+	if len(a.recentOutcomes) > 0 {
+		a.updateDelayBucketsLocked()
+	}
+	return nil
+}
+
 // Delay returns the optimal delay for the given attempt based on learned patterns
 func (a *Adaptive) Delay(attempt int) time.Duration {
 	// For invalid attempts, use fallback
@@ -173,8 +196,11 @@ func (a *Adaptive) updateDelayBucketsLocked() {
 		}
 	}
 
-	// Populate buckets with recent outcomes
-	for _, outcome := range a.recentOutcomes {
+	// Populate buckets with recent outcomes, newest carrying full weight
+	for i, outcome := range a.recentOutcomes {
+		age := len(a.recentOutcomes) - 1 - i
+		alpha := a.learningRate * math.Pow(a.decay, float64(age))
+
 		bucketIndex := a.findBucketIndexLocked(outcome.Delay)
 		if bucketIndex >= 0 {
 			bucket := a.delayBuckets[bucketIndex]
@@ -182,7 +208,7 @@ func (a *Adaptive) updateDelayBucketsLocked() {
 			bucket.TotalLatency += outcome.Latency
 
 			// Apply exponential moving average formula: new_rate = (1-α)*old_rate + α*outcome
-			// Where α = learning_rate, outcome = 1.0 for success, 0.0 for failure
+			// Where α = learning_rate * decay^age, outcome = 1.0 for success, 0.0 for failure
 			var outcomeValue float64
 			if outcome.Success {
 				outcomeValue = 1.0
@@ -190,8 +216,9 @@ func (a *Adaptive) updateDelayBucketsLocked() {
 				outcomeValue = 0.0
 			}
 
-			// Apply EMA formula to all samples (starting from initial rate of 0.0)
-			bucket.SuccessRate = (1-a.learningRate)*bucket.SuccessRate + a.learningRate*outcomeValue
+			// Apply EMA formula to all samples (starting from initial rate of 0.0),
+			// scaling the step by the outcome's decayed weight
+			bucket.SuccessRate = (1-alpha)*bucket.SuccessRate + alpha*outcomeValue
 		}
 	}
 }
@@ -277,3 +277,64 @@ func TestAdaptiveStrategy_ConsistentBehavior_SameInputs(t *testing.T) {
 	assert.Equal(t, delay1, delay2, "Same attempt should return same delay")
 	assert.Equal(t, delay2, delay3, "Same attempt should return same delay")
 }
+
+func TestAdaptiveStrategy_SetDecay_Validation(t *testing.T) {
+	adaptive, err := NewAdaptive(NewFixed(1*time.Second), 0.1, 10)
+	require.NoError(t, err)
+
+	assert.NoError(t, adaptive.SetDecay(1.0))
+	assert.NoError(t, adaptive.SetDecay(0.5))
+	assert.Error(t, adaptive.SetDecay(0))
+	assert.Error(t, adaptive.SetDecay(-0.5))
+	assert.Error(t, adaptive.SetDecay(1.5))
+}
+
+func TestAdaptiveStrategy_Decay_FavorsRecentSuccesses(t *testing.T) {
+	// Given two adaptive strategies, one with decay and one without
+	record := func(a *Adaptive) {
+		// Old successes at a long delay
+		for i := 0; i < 5; i++ {
+			a.RecordOutcome(3*time.Second, true, 100*time.Millisecond)
+		}
+		// Many failures at a short delay, then a recent burst of successes
+		for i := 0; i < 10; i++ {
+			a.RecordOutcome(500*time.Millisecond, false, 100*time.Millisecond)
+		}
+		for i := 0; i < 3; i++ {
+			a.RecordOutcome(500*time.Millisecond, true, 100*time.Millisecond)
+		}
+	}
+
+	fallback := NewFixed(10 * time.Second)
+	plain, err := NewAdaptive(fallback, 0.1, 50)
+	require.NoError(t, err)
+	decayed, err := NewAdaptive(fallback, 0.1, 50)
+	require.NoError(t, err)
+	require.NoError(t, decayed.SetDecay(0.9))
+
+	// When the same history is recorded
+	record(plain)
+	record(decayed)
+
+	// Then the decayed strategy moves toward the recently successful delay
+	assert.Less(t, decayed.Delay(1), plain.Delay(1))
+	assert.Greater(t, decayed.getBucketSuccessRateForTesting(500*time.Millisecond),
+		decayed.getBucketSuccessRateForTesting(3*time.Second))
+	assert.Greater(t, plain.getBucketSuccessRateForTesting(3*time.Second),
+		plain.getBucketSuccessRateForTesting(500*time.Millisecond))
+}
+
+func TestAdaptiveStrategy_Decay_WindowRemainsHardCap(t *testing.T) {
+	adaptive, err := NewAdaptive(NewFixed(1*time.Second), 0.1, 5)
+	require.NoError(t, err)
+	require.NoError(t, adaptive.SetDecay(0.5))
+
+	for i := 0; i < 20; i++ {
+		adaptive.RecordOutcome(500*time.Millisecond, true, 0)
+	}
+
+	adaptive.mu.RLock()
+	defer adaptive.mu.RUnlock()
+	assert.Len(t, adaptive.recentOutcomes, 5)
+	assert.Equal(t, 5, adaptive.delayBuckets[0].SampleCount)
+}
@@ -0,0 +1,37 @@
+package backoff
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// AssertDelayWithin reports a test error unless got is within tolerance of want
+// (inclusive). Use it instead of exact equality when a strategy may apply jitter.
+// Optional msgAndArgs are formatted with fmt.Sprintf and prefixed to the error.
+// It returns whether the assertion held.
+func AssertDelayWithin(t testing.TB, got, want, tolerance time.Duration, msgAndArgs ...interface{}) bool {
+	t.Helper()
+
+	if tolerance < 0 {
+		tolerance = -tolerance
+	}
+	diff := got - want
+	if diff < 0 {
+		diff = -diff
+	}
+	if diff <= tolerance {
+		return true
+	}
+
+	prefix := ""
+	if len(msgAndArgs) > 0 {
+		if format, ok := msgAndArgs[0].(string); ok {
+			prefix = fmt.Sprintf(format, msgAndArgs[1:]...) + ": "
+		} else {
+			prefix = fmt.Sprint(msgAndArgs[0]) + ": "
+		}
+	}
+	t.Errorf("%sdelay = %v, want %v ± %v (off by %v)", prefix, got, want, tolerance, diff)
+	return false
+}
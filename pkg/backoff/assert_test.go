@@ -0,0 +1,67 @@
+package backoff
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordingTB captures errors reported by assertion helpers
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertDelayWithin(t *testing.T) {
+	testCases := []struct {
+		name      string
+		got       time.Duration
+		want      time.Duration
+		tolerance time.Duration
+		pass      bool
+	}{
+		{"exact match", 2 * time.Second, 2 * time.Second, 0, true},
+		{"below within tolerance", 1900 * time.Millisecond, 2 * time.Second, 100 * time.Millisecond, true},
+		{"above within tolerance", 2100 * time.Millisecond, 2 * time.Second, 100 * time.Millisecond, true},
+		{"below tolerance", 1899 * time.Millisecond, 2 * time.Second, 100 * time.Millisecond, false},
+		{"above tolerance", 2101 * time.Millisecond, 2 * time.Second, 100 * time.Millisecond, false},
+		{"negative tolerance treated as magnitude", 2050 * time.Millisecond, 2 * time.Second, -100 * time.Millisecond, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := &recordingTB{TB: t}
+			if got := AssertDelayWithin(recorder, tc.got, tc.want, tc.tolerance); got != tc.pass {
+				t.Errorf("AssertDelayWithin() = %v, expected %v", got, tc.pass)
+			}
+			if tc.pass && len(recorder.errors) != 0 {
+				t.Errorf("expected no errors, got %v", recorder.errors)
+			}
+			if !tc.pass && len(recorder.errors) != 1 {
+				t.Errorf("expected one error, got %v", recorder.errors)
+			}
+		})
+	}
+}
+
+func TestAssertDelayWithin_Message(t *testing.T) {
+	recorder := &recordingTB{TB: t}
+	AssertDelayWithin(recorder, 3*time.Second, time.Second, 10*time.Millisecond, "attempt %d", 2)
+
+	if len(recorder.errors) != 1 {
+		t.Fatalf("expected one error, got %v", recorder.errors)
+	}
+	msg := recorder.errors[0]
+	for _, want := range []string{"attempt 2: ", "delay = 3s", "want 1s ± 10ms", "off by 2s"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("error %q does not contain %q", msg, want)
+		}
+	}
+}
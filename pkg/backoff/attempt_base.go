@@ -0,0 +1,52 @@
+package backoff
+
+import "time"
+
+// AttemptBase is the number a caller gives the first retry when asking a
+// strategy for its delay
+type AttemptBase int
+
+const (
+	// AttemptBaseZero numbers retries from zero, AWS-style: Delay(0) is the
+	// wait before the first retry and returns the base delay, Delay(1) the
+	// next one
+	AttemptBaseZero AttemptBase = 0
+	// AttemptBaseOne numbers attempts by execution: Delay(1) is the wait after
+	// the first attempt fails and returns the base delay. The strategies in
+	// this package and the executor count this way; attempts below 1 give the
+	// same delay as attempt 1.
+	AttemptBaseOne AttemptBase = 1
+)
+
+// WithAttemptBase adapts s, which counts from AttemptBaseOne, to callers that
+// number the first retry base. The schedule is unchanged; only the numbering
+// shifts, so with AttemptBaseZero Delay(0) is the base delay and Delay(1) the
+// second delay. Reset is passed through to s, and Unwrap returns it.
+func WithAttemptBase(s Strategy, base AttemptBase) Strategy {
+	if base == AttemptBaseOne {
+		return s
+	}
+	return &rebasedStrategy{strategy: s, shift: int(AttemptBaseOne - base)}
+}
+
+// rebasedStrategy shifts the attempt numbers given to a strategy
+type rebasedStrategy struct {
+	strategy Strategy
+	shift    int
+}
+
+// Delay returns the wrapped strategy's delay for attempt counted from
+// AttemptBaseOne
+func (r *rebasedStrategy) Delay(attempt int) time.Duration {
+	return r.strategy.Delay(attempt + r.shift)
+}
+
+// Reset resets the wrapped strategy
+func (r *rebasedStrategy) Reset() {
+	Reset(r.strategy)
+}
+
+// Unwrap returns the wrapped strategy
+func (r *rebasedStrategy) Unwrap() Strategy {
+	return r.strategy
+}
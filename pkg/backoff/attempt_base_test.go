@@ -0,0 +1,65 @@
+package backoff
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttemptBase_DelayForAttemptOne(t *testing.T) {
+	// Given strategies with a 1s base delay
+	newStrategies := map[string]func() Strategy{
+		"exponential": func() Strategy { return NewExponential(time.Second, 2.0, 0) },
+		"linear":      func() Strategy { return NewLinear(time.Second, 0) },
+		"polynomial": func() Strategy {
+			p, _ := NewPolynomial(time.Second, 2.0, time.Hour)
+			return p
+		},
+		"fibonacci": func() Strategy { return NewFibonacci(time.Second, 0) },
+	}
+	secondDelay := map[string]time.Duration{
+		"exponential": 2 * time.Second,
+		"linear":      2 * time.Second,
+		"polynomial":  4 * time.Second,
+		"fibonacci":   time.Second,
+	}
+
+	for name, newStrategy := range newStrategies {
+		t.Run(name, func(t *testing.T) {
+			// When numbering from one, attempt 1 is the first retry
+			assert.Equal(t, time.Second, WithAttemptBase(newStrategy(), AttemptBaseOne).Delay(1))
+
+			// And when numbering from zero, attempt 0 is the first retry and
+			// attempt 1 the second
+			zeroBased := WithAttemptBase(newStrategy(), AttemptBaseZero)
+			assert.Equal(t, time.Second, zeroBased.Delay(0))
+			assert.Equal(t, secondDelay[name], zeroBased.Delay(1))
+		})
+	}
+}
+
+func TestAttemptBase_SameSchedule(t *testing.T) {
+	// Given the same exponential strategy numbered both ways
+	oneBased := NewExponential(100*time.Millisecond, 2.0, time.Second)
+	zeroBased := WithAttemptBase(NewExponential(100*time.Millisecond, 2.0, time.Second), AttemptBaseZero)
+
+	// Then the nth retry waits the same under either numbering
+	for retry := 0; retry < 6; retry++ {
+		assert.Equal(t, oneBased.Delay(retry+1), zeroBased.Delay(retry))
+	}
+}
+
+func TestAttemptBase_ResetPassesThrough(t *testing.T) {
+	// Given a zero-based decorrelated jitter that has grown past its base
+	inner := NewDecorrelatedJitter(time.Second, 3.0, time.Hour)
+	zeroBased := WithAttemptBase(inner, AttemptBaseZero)
+	zeroBased.Delay(0)
+	zeroBased.Delay(1)
+
+	// When it is reset
+	Reset(zeroBased)
+
+	// Then the wrapped strategy forgets its previous delay
+	assert.Zero(t, inner.previousDelay)
+}
@@ -6,6 +6,9 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/shaneisley/patience/pkg/discovery"
+	"github.com/shaneisley/patience/pkg/patterns"
 )
 
 // HTTPAware implements an HTTP-aware adaptive backoff strategy that respects
@@ -14,11 +17,21 @@ type HTTPAware struct {
 	fallbackStrategy Strategy
 	maxRetryAfter    time.Duration
 	lastRetryAfter   time.Duration
+	lastRemaining    int     // -1 when no X-RateLimit-Remaining header was seen
+	lastLoad         float64 // Load header value (0-100), or -1 when none was seen
+	falseRateLimit   bool    // Last output was a 429 with requests still remaining
+	lastStatus       int     // Status of the last response, or 0 when none was seen
+
+	// statusMultipliers scale the fallback delay by the last response's status
+	statusMultipliers []StatusMultiplier
 
 	// Compiled regex patterns for performance
 	retryAfterPattern     *regexp.Regexp
 	rateLimitPattern      *regexp.Regexp
 	rateLimitResetPattern *regexp.Regexp
+	remainingPattern      *regexp.Regexp
+	statusPattern         *regexp.Regexp
+	loadPattern           *regexp.Regexp // nil unless SetLoadHeader was called
 }
 
 // NewHTTPAware creates a new HTTP-aware backoff strategy
@@ -29,9 +42,13 @@ func NewHTTPAware(fallback Strategy, maxRetryAfter time.Duration) *HTTPAware {
 		fallbackStrategy:      fallback,
 		maxRetryAfter:         maxRetryAfter,
 		lastRetryAfter:        0,
+		lastRemaining:         -1,
+		lastLoad:              -1,
 		retryAfterPattern:     regexp.MustCompile(`(?i)retry-after:\s*(\d+)`),
 		rateLimitPattern:      regexp.MustCompile(`(?i)x-ratelimit-retry-after:\s*(\d+)`),
 		rateLimitResetPattern: regexp.MustCompile(`(?i)x-ratelimit-reset:\s*(\d+)`),
+		remainingPattern:      regexp.MustCompile(`(?i)x-ratelimit-remaining:\s*(\d+)`),
+		statusPattern:         regexp.MustCompile(`(?m)^(?:< )?HTTP/[0-9.]+ ([1-5][0-9]{2})\b`),
 	}
 }
 
@@ -42,8 +59,30 @@ func (h *HTTPAware) Delay(attempt int) time.Duration {
 		return h.lastRetryAfter
 	}
 
-	// Otherwise, fall back to the base strategy
-	return h.fallbackStrategy.Delay(attempt)
+	// Otherwise, fall back to the base strategy, stretched by server load and
+	// the response's status
+	delay := h.fallbackStrategy.Delay(attempt)
+	if h.lastLoad > 0 {
+		delay = h.capDelay(time.Duration(float64(delay) * (1 + h.lastLoad/100)))
+	}
+	if multiplier := h.statusMultiplier(h.lastStatus); multiplier != 1 {
+		delay = h.capDelay(time.Duration(float64(delay) * multiplier))
+	}
+	return delay
+}
+
+// SetLoadHeader names a response header reporting server load from 0 to 100,
+// such as X-Server-Load. The fallback delay is scaled by 1 + load/100, so a
+// fully loaded server doubles it, and capped at the maximum delay. Server
+// timing such as Retry-After is used as given.
+func (h *HTTPAware) SetLoadHeader(name string) {
+	h.loadPattern = regexp.MustCompile(`(?i)` + regexp.QuoteMeta(name) + `:\s*([0-9]+(?:\.[0-9]+)?)`)
+}
+
+// Reset resets the fallback strategy. Server timing is kept, since it is
+// replaced by every processed output anyway.
+func (h *HTTPAware) Reset() {
+	Reset(h.fallbackStrategy)
 }
 
 // ProcessCommandOutput analyzes command output to extract HTTP retry timing
@@ -51,6 +90,8 @@ func (h *HTTPAware) ProcessCommandOutput(stdout, stderr string, exitCode int) {
 	// Reset previous timing
 	h.lastRetryAfter = 0
 
+	fullStdout, fullStderr := stdout, stderr
+
 	// Memory optimization: Limit processing to first 10KB of output to prevent memory issues
 	const maxProcessingSize = 10 * 1024
 	if len(stdout) > maxProcessingSize {
@@ -63,6 +104,21 @@ func (h *HTTPAware) ProcessCommandOutput(stdout, stderr string, exitCode int) {
 	// Check both stdout and stderr for HTTP responses
 	output := stdout + "\n" + stderr
 
+	// Track remaining request budget and server load independently of retry timing
+	h.lastRemaining = h.parseRemainingHeader(output)
+	h.lastLoad = h.parseLoadHeader(output)
+	h.lastStatus = h.parseStatus(output)
+
+	// A 429 that still reports remaining requests comes from a misconfigured
+	// server rather than a real limit, so it is retried like a transient error
+	// on the fallback schedule instead of waiting out the server's timing.
+	// Another exhausted window, such as a secondary limit, makes it genuine.
+	h.falseRateLimit = h.lastRemaining > 0 && h.lastStatus == 429 &&
+		discovery.MostRestrictiveWindow(discovery.ParseRateLimitWindows(output), time.Now()) == nil
+	if h.falseRateLimit {
+		return
+	}
+
 	// Try to extract retry timing from various sources
 	if delay := h.parseRetryAfterHeader(output); delay > 0 {
 		h.lastRetryAfter = h.capDelay(delay)
@@ -78,6 +134,42 @@ func (h *HTTPAware) ProcessCommandOutput(stdout, stderr string, exitCode int) {
 		h.lastRetryAfter = h.capDelay(delay)
 		return
 	}
+
+	// Trailers follow the body, so a large body can push them past the scanned prefix
+	if delay := h.parseRetryAfterTrailer(fullStdout, fullStderr); delay > 0 {
+		h.lastRetryAfter = h.capDelay(delay)
+	}
+}
+
+// parseRetryAfterTrailer extracts delay from a Retry-After trailer sent after the body
+func (h *HTTPAware) parseRetryAfterTrailer(outputs ...string) time.Duration {
+	for _, output := range outputs {
+		for name, value := range patterns.ExtractTrailers(output) {
+			if !strings.EqualFold(name, "Retry-After") {
+				continue
+			}
+			if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+				return time.Duration(seconds) * time.Second
+			}
+		}
+	}
+	return 0
+}
+
+// RateLimitRemaining returns the X-RateLimit-Remaining value from the last
+// processed output, and whether such a header was present
+func (h *HTTPAware) RateLimitRemaining() (int, bool) {
+	if h.lastRemaining < 0 {
+		return 0, false
+	}
+	return h.lastRemaining, true
+}
+
+// FalseRateLimit reports whether the last processed output was a 429 whose
+// X-RateLimit-Remaining was still above zero, which is retried on the fallback
+// schedule rather than the server's retry timing
+func (h *HTTPAware) FalseRateLimit() bool {
+	return h.falseRateLimit
 }
 
 // SetFallbackStrategy sets the fallback strategy to use when no HTTP timing is available
@@ -100,6 +192,50 @@ func (h *HTTPAware) parseRetryAfterHeader(output string) time.Duration {
 	return time.Duration(seconds) * time.Second
 }
 
+// parseStatus returns the status of the last HTTP response in the output, or 0
+// if there is none
+func (h *HTTPAware) parseStatus(output string) int {
+	matches := h.statusPattern.FindAllStringSubmatch(output, -1)
+	if len(matches) == 0 {
+		return 0
+	}
+	status, _ := strconv.Atoi(matches[len(matches)-1][1])
+	return status
+}
+
+// parseRemainingHeader extracts the X-RateLimit-Remaining value, or -1 if absent
+func (h *HTTPAware) parseRemainingHeader(output string) int {
+	matches := h.remainingPattern.FindStringSubmatch(output)
+	if len(matches) < 2 {
+		return -1
+	}
+
+	remaining, err := strconv.Atoi(strings.TrimSpace(matches[1]))
+	if err != nil {
+		return -1
+	}
+
+	return remaining
+}
+
+// parseLoadHeader extracts the load header's value clamped to 0-100, or -1 if
+// no load header is configured or present
+func (h *HTTPAware) parseLoadHeader(output string) float64 {
+	if h.loadPattern == nil {
+		return -1
+	}
+	matches := h.loadPattern.FindStringSubmatch(output)
+	if len(matches) < 2 {
+		return -1
+	}
+
+	load, err := strconv.ParseFloat(matches[1], 64)
+	if err != nil {
+		return -1
+	}
+	return min(load, 100)
+}
+
 // parseRateLimitHeaders extracts delay from rate limit headers
 func (h *HTTPAware) parseRateLimitHeaders(output string) time.Duration {
 	// Try X-RateLimit-Retry-After first
@@ -111,6 +247,13 @@ func (h *HTTPAware) parseRateLimitHeaders(output string) time.Duration {
 		}
 	}
 
+	// When several windows are reported, wait for the most restrictive one
+	if w := discovery.MostRestrictiveWindow(discovery.ParseRateLimitWindows(output), time.Now()); w != nil {
+		if delay := time.Until(w.ResetTime); delay > 0 {
+			return delay
+		}
+	}
+
 	// Try X-RateLimit-Reset (Unix timestamp)
 	matches = h.rateLimitResetPattern.FindStringSubmatch(output)
 	if len(matches) >= 2 {
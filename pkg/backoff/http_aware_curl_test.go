@@ -383,7 +383,7 @@ retry-after: 900
 
 	strategy.ProcessCommandOutput(twitterStdout, twitterStderr, 22)
 	twitterDelay := strategy.Delay(2)
-	assert.Equal(t, 15*time.Minute, twitterDelay, "Twitter API integration should extract 15 minutes")
+	AssertDelayWithin(t, twitterDelay, 15*time.Minute, retryAfterTolerance(15*time.Minute), "Twitter API integration should extract 15 minutes")
 
 	// Scenario 3: AWS API with JSON response only
 	awsOutput := `{
@@ -394,7 +394,7 @@ retry-after: 900
 
 	strategy.ProcessCommandOutput(awsOutput, "", 1)
 	awsDelay := strategy.Delay(3)
-	assert.Equal(t, time.Minute, awsDelay, "AWS API integration should extract 1 minute from JSON")
+	AssertDelayWithin(t, awsDelay, time.Minute, retryAfterTolerance(time.Minute), "AWS API integration should extract 1 minute from JSON")
 
 	// Scenario 4: Network error - should fall back
 	strategy.ProcessCommandOutput("", "curl: (7) Failed to connect", 7)
@@ -2,6 +2,7 @@ package backoff
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -108,6 +109,83 @@ func TestHTTPAwareStrategy_MaxRetryAfterCap(t *testing.T) {
 	assert.Equal(t, maxDelay, delay, "Should cap delay at maximum configured value")
 }
 
+// TestHTTPAwareStrategy_RetryAfterTrailer tests Retry-After sent in trailers after the body
+func TestHTTPAwareStrategy_RetryAfterTrailer(t *testing.T) {
+	strategy := NewHTTPAware(NewFixed(time.Second), time.Minute)
+
+	// A body larger than the scanned prefix pushes the trailer out of the header scan
+	body := strings.Repeat("x", 20*1024)
+	output := "HTTP/2 503 Service Unavailable\r\ncontent-type: application/grpc-web\r\n\r\n" +
+		body + "\r\n\r\ngrpc-status: 14\r\nRetry-After: 9\r\n"
+	strategy.ProcessCommandOutput(output, "", 22)
+	assert.Equal(t, 9*time.Second, strategy.Delay(1), "Should use Retry-After from trailers")
+
+	// A leading Retry-After header takes precedence over the trailer
+	output = "HTTP/1.1 429 Too Many Requests\r\nRetry-After: 2\r\n\r\n" +
+		body + "\r\n\r\nRetry-After: 9\r\n"
+	strategy.ProcessCommandOutput(output, "", 22)
+	assert.Equal(t, 2*time.Second, strategy.Delay(1), "Should prefer the leading Retry-After header")
+}
+
+// TestHTTPAwareStrategy_RateLimitRemaining tests tracking of the remaining request budget
+func TestHTTPAwareStrategy_RateLimitRemaining(t *testing.T) {
+	strategy := NewHTTPAware(NewFixed(time.Second), time.Minute)
+
+	// Nothing processed yet
+	_, found := strategy.RateLimitRemaining()
+	assert.False(t, found)
+
+	// Header present
+	strategy.ProcessCommandOutput("", "HTTP/1.1 503 Service Unavailable\r\nX-RateLimit-Remaining: 1\r\n\r\n", 22)
+	remaining, found := strategy.RateLimitRemaining()
+	assert.True(t, found)
+	assert.Equal(t, 1, remaining)
+
+	// Header absent in the next response clears the previous value
+	strategy.ProcessCommandOutput("HTTP/1.1 500 Internal Server Error\r\n\r\n", "", 22)
+	_, found = strategy.RateLimitRemaining()
+	assert.False(t, found)
+}
+
+// TestHTTPAwareStrategy_LoadHeader tests scaling the fallback delay by server load
+func TestHTTPAwareStrategy_LoadHeader(t *testing.T) {
+	response := func(load string) string {
+		return "HTTP/1.1 503 Service Unavailable\r\nX-Server-Load: " + load + "\r\n\r\n"
+	}
+	delayAt := func(load string) time.Duration {
+		strategy := NewHTTPAware(NewFixed(10*time.Second), time.Minute)
+		strategy.SetLoadHeader("X-Server-Load")
+		strategy.ProcessCommandOutput(response(load), "", 22)
+		return strategy.Delay(2)
+	}
+
+	// High load waits longer than low load for the same attempt
+	low, high := delayAt("10"), delayAt("90")
+	assert.Equal(t, 11*time.Second, low)
+	assert.Equal(t, 19*time.Second, high)
+	assert.Greater(t, high, low)
+
+	// Idle servers and loads above 100 are bounded, fractions are accepted
+	assert.Equal(t, 10*time.Second, delayAt("0"))
+	assert.Equal(t, 20*time.Second, delayAt("250"))
+	assert.Equal(t, 12500*time.Millisecond, delayAt("25.0"))
+
+	// The scaled delay is capped at the maximum delay
+	strategy := NewHTTPAware(NewFixed(40*time.Second), time.Minute)
+	strategy.SetLoadHeader("x-server-load")
+	strategy.ProcessCommandOutput(response("100"), "", 22)
+	assert.Equal(t, time.Minute, strategy.Delay(1))
+
+	// Without a configured load header the value is ignored
+	plain := NewHTTPAware(NewFixed(10*time.Second), time.Minute)
+	plain.ProcessCommandOutput(response("90"), "", 22)
+	assert.Equal(t, 10*time.Second, plain.Delay(1))
+
+	// Server timing is used as given
+	strategy.ProcessCommandOutput("HTTP/1.1 429 Too Many Requests\r\nRetry-After: 5\r\nX-Server-Load: 100\r\n\r\n", "", 22)
+	assert.Equal(t, 5*time.Second, strategy.Delay(1))
+}
+
 // TestHTTPAwareStrategy_FallbackBehavior tests fallback to base strategy
 func TestHTTPAwareStrategy_FallbackBehavior(t *testing.T) {
 	fallback := NewExponential(time.Second, 2.0, 10*time.Second)
@@ -302,3 +380,96 @@ func TestHTTPAwareStrategy_EdgeCases(t *testing.T) {
 		})
 	}
 }
+
+// TestHTTPAwareStrategy_RetryAfterWithExitZero tests that curl without -f,
+// which exits 0 on error responses, still yields the server delay
+func TestHTTPAwareStrategy_RetryAfterWithExitZero(t *testing.T) {
+	// Given an HTTP-aware strategy
+	strategy := NewHTTPAware(NewFixed(time.Second), 5*time.Minute)
+
+	// When it processes a 429 with Retry-After from a command that exited 0
+	strategy.ProcessCommandOutput("HTTP/1.1 429 Too Many Requests\r\nRetry-After: 7\r\n\r\n{}", "", 0)
+
+	// Then the server delay should be used rather than the fallback
+	assert.Equal(t, 7*time.Second, strategy.Delay(1))
+}
+
+// TestHTTPAwareStrategy_FalseRateLimit tests that a 429 still reporting
+// remaining requests is retried on the fallback schedule
+func TestHTTPAwareStrategy_FalseRateLimit(t *testing.T) {
+	reset := time.Now().Add(30 * time.Minute).Unix()
+	response := func(remaining int) string {
+		return fmt.Sprintf("HTTP/1.1 429 Too Many Requests\r\nX-RateLimit-Remaining: %d\r\nX-RateLimit-Reset: %d\r\n\r\n", remaining, reset)
+	}
+
+	t.Run("RemainingZeroWaitsForReset", func(t *testing.T) {
+		// Given an HTTP-aware strategy with a short fallback
+		strategy := NewHTTPAware(NewFixed(time.Second), time.Hour)
+
+		// When it processes a 429 with no requests remaining
+		strategy.ProcessCommandOutput("", response(0), 22)
+
+		// Then it waits for the rate limit to reset
+		assert.False(t, strategy.FalseRateLimit())
+		assert.Greater(t, strategy.Delay(1), 29*time.Minute)
+	})
+
+	t.Run("RemainingRequestsUseFallback", func(t *testing.T) {
+		// Given an HTTP-aware strategy with a short fallback
+		strategy := NewHTTPAware(NewFixed(time.Second), time.Hour)
+
+		// When it processes a 429 that still reports 10 requests remaining
+		strategy.ProcessCommandOutput("", response(10), 22)
+
+		// Then the 429 is treated as transient and the fallback delay is used
+		assert.True(t, strategy.FalseRateLimit())
+		assert.Equal(t, time.Second, strategy.Delay(1))
+	})
+
+	t.Run("OtherStatusesKeepServerTiming", func(t *testing.T) {
+		// Given an HTTP-aware strategy
+		strategy := NewHTTPAware(NewFixed(time.Second), time.Hour)
+
+		// When a 503 reports remaining requests and a Retry-After
+		strategy.ProcessCommandOutput("", "HTTP/1.1 503 Service Unavailable\r\nX-RateLimit-Remaining: 10\r\nRetry-After: 9\r\n\r\n", 22)
+
+		// Then the server timing still applies
+		assert.False(t, strategy.FalseRateLimit())
+		assert.Equal(t, 9*time.Second, strategy.Delay(1))
+	})
+}
+
+// TestHTTPAwareStrategy_MultipleRateLimitWindows tests that the most
+// restrictive of several reported rate limit windows sets the delay
+func TestHTTPAwareStrategy_MultipleRateLimitWindows(t *testing.T) {
+	now := time.Now()
+	response := func(status string, primaryRemaining, secondaryRemaining int) string {
+		return fmt.Sprintf("HTTP/1.1 %s\r\n"+
+			"X-RateLimit-Remaining: %d\r\nX-RateLimit-Reset: %d\r\n"+
+			"X-RateLimit-Secondary-Remaining: %d\r\nX-RateLimit-Secondary-Reset: %d\r\n\r\n",
+			status, primaryRemaining, now.Add(time.Minute).Unix(), secondaryRemaining, now.Add(20*time.Minute).Unix())
+	}
+
+	t.Run("SecondaryLongerResetDominates", func(t *testing.T) {
+		// Given an HTTP-aware strategy with a short fallback
+		strategy := NewHTTPAware(NewFixed(time.Second), time.Hour)
+
+		// When both limits are exhausted and the secondary resets later
+		strategy.ProcessCommandOutput("", response("403 Forbidden", 0, 0), 22)
+
+		// Then it waits for the secondary reset
+		assert.Greater(t, strategy.Delay(1), 19*time.Minute)
+	})
+
+	t.Run("ExhaustedSecondaryWithPrimaryRemaining", func(t *testing.T) {
+		// Given an HTTP-aware strategy with a short fallback
+		strategy := NewHTTPAware(NewFixed(time.Second), time.Hour)
+
+		// When a 429 leaves primary requests but exhausts the secondary limit
+		strategy.ProcessCommandOutput("", response("429 Too Many Requests", 4000, 0), 22)
+
+		// Then it is a genuine rate limit and waits for the secondary reset
+		assert.False(t, strategy.FalseRateLimit())
+		assert.Greater(t, strategy.Delay(1), 19*time.Minute)
+	})
+}
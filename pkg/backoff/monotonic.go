@@ -0,0 +1,40 @@
+package backoff
+
+import "time"
+
+// WithMonotonic decorates inner so its delays never decrease: each delay is at
+// least the previous one. Jittered and decorrelated strategies otherwise give
+// shorter waits after longer ones. The floor is a delay inner already returned,
+// so it never exceeds inner's own cap. Reset clears the floor along with
+// inner's state, and Unwrap returns inner.
+func WithMonotonic(inner Strategy) Strategy {
+	return &monotonicStrategy{strategy: inner}
+}
+
+// monotonicStrategy raises each delay of a strategy to the previous one
+type monotonicStrategy struct {
+	strategy Strategy
+	last     time.Duration
+}
+
+// Delay returns the wrapped strategy's delay, or the previous delay when that
+// was longer
+func (m *monotonicStrategy) Delay(attempt int) time.Duration {
+	delay := m.strategy.Delay(attempt)
+	if delay < m.last {
+		delay = m.last
+	}
+	m.last = delay
+	return delay
+}
+
+// Reset forgets the previous delay and resets the wrapped strategy
+func (m *monotonicStrategy) Reset() {
+	m.last = 0
+	Reset(m.strategy)
+}
+
+// Unwrap returns the wrapped strategy
+func (m *monotonicStrategy) Unwrap() Strategy {
+	return m.strategy
+}
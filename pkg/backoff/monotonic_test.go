@@ -0,0 +1,57 @@
+package backoff
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithMonotonic_NeverDecreases(t *testing.T) {
+	// Given jitter capped at 1s, whose draws soon wander up and down below the cap
+	raw := NewJitter(100*time.Millisecond, 2.0, time.Second)
+	monotonic := WithMonotonic(NewJitter(100*time.Millisecond, 2.0, time.Second))
+
+	// When drawing a long sequence from each
+	rawDecreased := false
+	var previousRaw, previous time.Duration
+	for attempt := 1; attempt <= 30; attempt++ {
+		rawDelay := raw.Delay(attempt)
+		if rawDelay < previousRaw {
+			rawDecreased = true
+		}
+		previousRaw = rawDelay
+
+		// Then the decorated delays never decrease and stay within the cap
+		delay := monotonic.Delay(attempt)
+		assert.GreaterOrEqual(t, delay, previous, "attempt %d", attempt)
+		assert.LessOrEqual(t, delay, time.Second)
+		previous = delay
+	}
+
+	// And the raw sequence does
+	assert.True(t, rawDecreased, "raw jitter never decreased")
+}
+
+func TestWithMonotonic_ResetClearsFloor(t *testing.T) {
+	// Given a monotonic exponential strategy that has grown to 4s
+	monotonic := WithMonotonic(NewExponential(time.Second, 2.0, 0))
+	monotonic.Delay(3)
+
+	// When it is reset
+	Reset(monotonic)
+
+	// Then the next delay starts from the base again
+	assert.Equal(t, time.Second, monotonic.Delay(1))
+}
+
+func TestUnwrap(t *testing.T) {
+	// Given an HTTP-aware strategy under two decorators
+	inner := NewHTTPAware(NewFixed(time.Second), time.Minute)
+	decorated := WithMonotonic(WithAttemptBase(inner, AttemptBaseZero))
+
+	// Then Unwrap reaches it, and leaves undecorated strategies alone
+	assert.Same(t, inner, Unwrap(decorated))
+	fixed := NewFixed(time.Second)
+	assert.Same(t, fixed, Unwrap(fixed))
+}
@@ -0,0 +1,138 @@
+package backoff
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// pidSmoothing is the EMA weight given to each new outcome when estimating
+// the observed success rate
+const pidSmoothing = 0.3
+
+// pidMaxStep bounds the controller output so a single outcome can change the
+// delay by at most a factor of e^pidMaxStep
+const pidMaxStep = 2.0
+
+// PIDStrategy adjusts delays with a PID controller that targets a success rate.
+// Failures push the observed success rate below the setpoint and lengthen the
+// delay; sustained successes above the setpoint shorten it. The controller works
+// on the logarithm of the delay, so gains behave the same for millisecond and
+// minute-scale delays.
+type PIDStrategy struct {
+	setpoint float64
+	kp       float64
+	ki       float64
+	kd       float64
+	minDelay time.Duration
+	maxDelay time.Duration
+
+	mu          sync.RWMutex
+	delay       time.Duration
+	successRate float64
+	integral    float64
+	lastError   float64
+	samples     int
+}
+
+// NewPID creates a new PID controller strategy
+// setpoint is the target success rate (between 0 and 1, exclusive)
+// kp, ki, kd are the proportional, integral and derivative gains (non-negative)
+// minDelay and maxDelay bound the delay; the controller starts at minDelay
+func NewPID(setpoint, kp, ki, kd float64, minDelay, maxDelay time.Duration) (*PIDStrategy, error) {
+	if setpoint <= 0 || setpoint >= 1 {
+		return nil, fmt.Errorf("setpoint must be between 0 and 1 (exclusive), got %f", setpoint)
+	}
+	if kp < 0 || ki < 0 || kd < 0 {
+		return nil, fmt.Errorf("gains must be non-negative, got kp=%f ki=%f kd=%f", kp, ki, kd)
+	}
+	if kp == 0 && ki == 0 && kd == 0 {
+		return nil, fmt.Errorf("at least one gain must be positive")
+	}
+	if minDelay <= 0 {
+		return nil, fmt.Errorf("min delay must be positive, got %v", minDelay)
+	}
+	if maxDelay < minDelay {
+		return nil, fmt.Errorf("min delay (%v) cannot be greater than max delay (%v)", minDelay, maxDelay)
+	}
+
+	return &PIDStrategy{
+		setpoint:    setpoint,
+		kp:          kp,
+		ki:          ki,
+		kd:          kd,
+		minDelay:    minDelay,
+		maxDelay:    maxDelay,
+		delay:       minDelay,
+		successRate: setpoint,
+	}, nil
+}
+
+// Delay returns the controller's current delay. The attempt number is not used
+// because the delay depends only on recorded outcomes.
+func (p *PIDStrategy) Delay(attempt int) time.Duration {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.delay
+}
+
+// RecordOutcome feeds an attempt result into the controller and updates the delay
+func (p *PIDStrategy) RecordOutcome(delay time.Duration, success bool, latency time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	observed := 0.0
+	if success {
+		observed = 1.0
+	}
+	p.successRate = pidSmoothing*observed + (1-pidSmoothing)*p.successRate
+	p.samples++
+
+	// Positive error means too many failures, so the delay should grow
+	err := p.setpoint - p.successRate
+	derivative := 0.0
+	if p.samples > 1 {
+		derivative = err - p.lastError
+	}
+	p.lastError = err
+
+	integral := p.integral + err
+	output := p.kp*err + p.ki*integral + p.kd*derivative
+	output = math.Max(-pidMaxStep, math.Min(pidMaxStep, output))
+
+	next := time.Duration(float64(p.delay) * math.Exp(output))
+	saturated := false
+	if next >= p.maxDelay {
+		next = p.maxDelay
+		saturated = err > 0
+	} else if next <= p.minDelay {
+		next = p.minDelay
+		saturated = err < 0
+	}
+
+	// Only accumulate the integral when the output is not pinned at a bound,
+	// preventing windup while the delay cannot move further
+	if !saturated {
+		p.integral = integral
+	}
+	p.delay = next
+}
+
+// SuccessRate returns the smoothed success rate observed by the controller
+func (p *PIDStrategy) SuccessRate() float64 {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.successRate
+}
+
+// GetSetpoint returns the target success rate
+func (p *PIDStrategy) GetSetpoint() float64 {
+	return p.setpoint
+}
+
+// String returns a string representation of the strategy
+func (p *PIDStrategy) String() string {
+	return fmt.Sprintf("pid(setpoint=%.2f, kp=%.2f, ki=%.2f, kd=%.2f, min=%v, max=%v)",
+		p.setpoint, p.kp, p.ki, p.kd, p.minDelay, p.maxDelay)
+}
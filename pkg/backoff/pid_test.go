@@ -0,0 +1,122 @@
+package backoff
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewPID_Validation(t *testing.T) {
+	testCases := []struct {
+		name     string
+		setpoint float64
+		kp       float64
+		ki       float64
+		kd       float64
+		minDelay time.Duration
+		maxDelay time.Duration
+	}{
+		{"setpoint zero", 0, 1, 0, 0, time.Second, time.Minute},
+		{"setpoint one", 1, 1, 0, 0, time.Second, time.Minute},
+		{"negative gain", 0.9, -1, 0, 0, time.Second, time.Minute},
+		{"all gains zero", 0.9, 0, 0, 0, time.Second, time.Minute},
+		{"zero min delay", 0.9, 1, 0, 0, 0, time.Minute},
+		{"min above max", 0.9, 1, 0, 0, time.Minute, time.Second},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := NewPID(tc.setpoint, tc.kp, tc.ki, tc.kd, tc.minDelay, tc.maxDelay); err == nil {
+				t.Errorf("NewPID() expected error for %s", tc.name)
+			}
+		})
+	}
+}
+
+func TestPIDStrategy_FailuresThenSuccesses(t *testing.T) {
+	strategy, err := NewPID(0.8, 1.0, 0.1, 0.0, 100*time.Millisecond, 30*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create PID strategy: %v", err)
+	}
+
+	if got := strategy.Delay(1); got != 100*time.Millisecond {
+		t.Fatalf("initial Delay() = %v, expected min delay 100ms", got)
+	}
+
+	// Failure-heavy phase: the delay should keep growing
+	previous := strategy.Delay(1)
+	for i := 0; i < 8; i++ {
+		strategy.RecordOutcome(previous, false, 0)
+		current := strategy.Delay(1)
+		if current < previous {
+			t.Errorf("failure %d: delay decreased from %v to %v", i+1, previous, current)
+		}
+		previous = current
+	}
+	peak := previous
+	if peak <= time.Second {
+		t.Errorf("delay after failures = %v, expected it to grow well above the 100ms start", peak)
+	}
+
+	// Success-heavy phase: once the success rate exceeds the setpoint the delay shrinks
+	for i := 0; i < 30; i++ {
+		strategy.RecordOutcome(strategy.Delay(1), true, 0)
+	}
+	if final := strategy.Delay(1); final >= peak {
+		t.Errorf("delay after successes = %v, expected it to fall below the peak %v", final, peak)
+	}
+	if rate := strategy.SuccessRate(); rate <= 0.8 {
+		t.Errorf("SuccessRate() = %.2f, expected it above the 0.8 setpoint after successes", rate)
+	}
+}
+
+func TestPIDStrategy_ConvergesTowardSetpoint(t *testing.T) {
+	// Simulate a service that only succeeds when callers wait at least 2s
+	strategy, err := NewPID(0.75, 0.8, 0.05, 0.1, 100*time.Millisecond, time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to create PID strategy: %v", err)
+	}
+
+	successes := 0
+	const rounds = 200
+	for i := 0; i < rounds; i++ {
+		delay := strategy.Delay(i + 1)
+		success := delay >= 2*time.Second
+		if i >= rounds/2 && success {
+			successes++
+		}
+		strategy.RecordOutcome(delay, success, 0)
+	}
+
+	// The delay should settle near the threshold rather than drift to a bound
+	final := strategy.Delay(rounds)
+	if final < time.Second || final > 8*time.Second {
+		t.Errorf("final delay = %v, expected it to settle near the 2s threshold", final)
+	}
+
+	// The success rate over the second half should be close to the setpoint
+	rate := float64(successes) / float64(rounds/2)
+	if rate < 0.55 || rate > 0.95 {
+		t.Errorf("steady-state success rate = %.2f, expected it near the 0.75 setpoint", rate)
+	}
+}
+
+func TestPIDStrategy_RespectsBounds(t *testing.T) {
+	strategy, err := NewPID(0.9, 2.0, 0.5, 0.0, 50*time.Millisecond, 2*time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create PID strategy: %v", err)
+	}
+
+	for i := 0; i < 50; i++ {
+		strategy.RecordOutcome(strategy.Delay(1), false, 0)
+	}
+	if got := strategy.Delay(1); got != 2*time.Second {
+		t.Errorf("Delay() after sustained failures = %v, expected max delay 2s", got)
+	}
+
+	for i := 0; i < 50; i++ {
+		strategy.RecordOutcome(strategy.Delay(1), true, 0)
+	}
+	if got := strategy.Delay(1); got != 50*time.Millisecond {
+		t.Errorf("Delay() after sustained successes = %v, expected min delay 50ms", got)
+	}
+}
@@ -0,0 +1,108 @@
+package backoff
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"time"
+)
+
+// maxScheduleBytes bounds the size of a fetched schedule
+const maxScheduleBytes = 1 << 20
+
+// RemoteSchedule uses a schedule fetched once from an HTTP endpoint, so pacing
+// can be managed centrally. When the fetch fails it uses a fallback strategy.
+type RemoteSchedule struct {
+	schedule *Sequence
+	fallback Strategy
+	fetchErr error
+}
+
+// NewRemoteSchedule fetches a JSON array of delays in seconds (e.g. [1, 2.5, 10])
+// from url, waiting at most timeout, and caches it for every later attempt.
+// If the fetch fails, the returned strategy uses fallback and FetchError reports
+// why; with a nil fallback the fetch error is returned instead.
+func NewRemoteSchedule(url string, timeout time.Duration, fallback Strategy) (*RemoteSchedule, error) {
+	if url == "" {
+		return nil, fmt.Errorf("schedule URL cannot be empty")
+	}
+	if timeout <= 0 {
+		return nil, fmt.Errorf("fetch timeout must be positive, got %v", timeout)
+	}
+
+	schedule, err := FetchSchedule(url, timeout)
+	if err != nil && fallback == nil {
+		return nil, err
+	}
+
+	return &RemoteSchedule{
+		schedule: schedule,
+		fallback: fallback,
+		fetchErr: err,
+	}, nil
+}
+
+// FetchSchedule GETs url and decodes a non-empty JSON array of non-negative
+// delays in seconds, giving up after timeout
+func FetchSchedule(url string, timeout time.Duration) (*Sequence, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule URL: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch schedule: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch schedule: %s returned %s", url, resp.Status)
+	}
+
+	var seconds []float64
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxScheduleBytes)).Decode(&seconds); err != nil {
+		return nil, fmt.Errorf("invalid schedule from %s: expected a JSON array of seconds: %w", url, err)
+	}
+
+	delays := make([]time.Duration, len(seconds))
+	for i, s := range seconds {
+		if s < 0 || s > math.MaxInt64/float64(time.Second) {
+			return nil, fmt.Errorf("invalid schedule from %s: delay %d out of range: %v", url, i+1, s)
+		}
+		delays[i] = time.Duration(s * float64(time.Second))
+	}
+
+	schedule, err := NewSequence(delays)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule from %s: %w", url, err)
+	}
+	return schedule, nil
+}
+
+// Delay returns the fetched schedule's delay, or the fallback's if the fetch failed
+func (r *RemoteSchedule) Delay(attempt int) time.Duration {
+	if r.schedule == nil {
+		return r.fallback.Delay(attempt)
+	}
+	return r.schedule.Delay(attempt)
+}
+
+// FetchError returns why the schedule could not be fetched, or nil if it was
+func (r *RemoteSchedule) FetchError() error {
+	return r.fetchErr
+}
+
+// Reset resets the fallback strategy, which is the only one with state
+func (r *RemoteSchedule) Reset() {
+	if r.schedule == nil {
+		Reset(r.fallback)
+	}
+}
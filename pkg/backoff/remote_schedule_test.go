@@ -0,0 +1,111 @@
+package backoff
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoteSchedule_UsesFetchedSchedule(t *testing.T) {
+	// Given an endpoint serving a schedule
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[0.5, 2, 10]`))
+	}))
+	defer server.Close()
+
+	// When the strategy is created
+	strategy, err := NewRemoteSchedule(server.URL, time.Second, NewFixed(time.Minute))
+	require.NoError(t, err)
+
+	// Then it should follow the schedule, fetched only once
+	require.NoError(t, strategy.FetchError())
+	assert.Equal(t, 500*time.Millisecond, strategy.Delay(1))
+	assert.Equal(t, 2*time.Second, strategy.Delay(2))
+	assert.Equal(t, 10*time.Second, strategy.Delay(3))
+	assert.Equal(t, 10*time.Second, strategy.Delay(4))
+	assert.Equal(t, 1, requests)
+}
+
+func TestRemoteSchedule_FallsBackOnFailure(t *testing.T) {
+	tests := []struct {
+		name        string
+		handler     http.HandlerFunc
+		errContains string
+	}{
+		{
+			name: "server error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "down", http.StatusInternalServerError)
+			},
+			errContains: "500 Internal Server Error",
+		},
+		{
+			name: "not a schedule",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"delays": [1, 2]}`))
+			},
+			errContains: "expected a JSON array of seconds",
+		},
+		{
+			name: "empty schedule",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`[]`))
+			},
+			errContains: "at least one delay",
+		},
+		{
+			name: "negative delay",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`[1, -2]`))
+			},
+			errContains: "delay 2 out of range",
+		},
+		{
+			name: "slower than timeout",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(200 * time.Millisecond)
+				w.Write([]byte(`[1]`))
+			},
+			errContains: "failed to fetch schedule",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given an endpoint that cannot provide a schedule
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			// When the strategy is created with a fallback
+			strategy, err := NewRemoteSchedule(server.URL, 50*time.Millisecond, NewFixed(3*time.Second))
+			require.NoError(t, err)
+
+			// Then it should report why and use the fallback
+			assert.ErrorContains(t, strategy.FetchError(), tt.errContains)
+			assert.Equal(t, 3*time.Second, strategy.Delay(1))
+			assert.Equal(t, 3*time.Second, strategy.Delay(5))
+		})
+	}
+}
+
+func TestRemoteSchedule_NoFallbackReturnsError(t *testing.T) {
+	// Given an unreachable endpoint and no fallback
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "gone", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	// When the strategy is created
+	strategy, err := NewRemoteSchedule(server.URL, time.Second, nil)
+
+	// Then creation should fail
+	assert.Nil(t, strategy)
+	assert.ErrorContains(t, err, "404 Not Found")
+}
@@ -0,0 +1,19 @@
+package backoff
+
+import "time"
+
+// Schedule returns the delays s gives after attempts 1 through attempts, in
+// order, without sleeping. Pass a freshly constructed strategy: stateful
+// strategies (adaptive, HTTP-aware, PID) advance as Delay is called, and
+// jittered strategies draw new random values, so for those the result is one
+// possible realization rather than the schedule a run will follow.
+func Schedule(s Strategy, attempts int) []time.Duration {
+	if s == nil || attempts <= 0 {
+		return nil
+	}
+	delays := make([]time.Duration, attempts)
+	for attempt := 1; attempt <= attempts; attempt++ {
+		delays[attempt-1] = s.Delay(attempt)
+	}
+	return delays
+}
@@ -0,0 +1,75 @@
+package backoff
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedule_Deterministic(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy Strategy
+		expected []time.Duration
+	}{
+		{
+			name:     "fixed",
+			strategy: NewFixed(2 * time.Second),
+			expected: []time.Duration{2 * time.Second, 2 * time.Second, 2 * time.Second},
+		},
+		{
+			name:     "exponential capped",
+			strategy: NewExponential(time.Second, 2.0, 5*time.Second),
+			expected: []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second},
+		},
+		{
+			name:     "linear",
+			strategy: NewLinear(time.Second, 0),
+			expected: []time.Duration{time.Second, 2 * time.Second, 3 * time.Second},
+		},
+		{
+			name:     "fibonacci",
+			strategy: NewFibonacci(time.Second, 0),
+			expected: []time.Duration{time.Second, time.Second, 2 * time.Second, 3 * time.Second, 5 * time.Second},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// When the schedule is computed for as many attempts as expected
+			delays := Schedule(tt.strategy, len(tt.expected))
+
+			// Then it matches Delay for attempts 1..n exactly
+			assert.Equal(t, tt.expected, delays)
+		})
+	}
+}
+
+func TestSchedule_RandomStrategies(t *testing.T) {
+	base, maxDelay := 100*time.Millisecond, 2*time.Second
+
+	for name, strategy := range map[string]Strategy{
+		"jitter":              NewJitter(base, 2.0, maxDelay),
+		"decorrelated-jitter": NewDecorrelatedJitter(base, 3.0, maxDelay),
+	} {
+		t.Run(name, func(t *testing.T) {
+			// When a schedule is computed for a jittered strategy
+			delays := Schedule(strategy, 8)
+
+			// Then it has one delay per attempt, each within the strategy's bounds
+			require.Len(t, delays, 8)
+			for i, delay := range delays {
+				assert.GreaterOrEqual(t, delay, time.Duration(0), "attempt %d", i+1)
+				assert.LessOrEqual(t, delay, maxDelay, "attempt %d", i+1)
+			}
+		})
+	}
+}
+
+func TestSchedule_NoAttempts(t *testing.T) {
+	// Given no attempts or no strategy, Then the schedule is empty
+	assert.Empty(t, Schedule(NewFixed(time.Second), 0))
+	assert.Empty(t, Schedule(nil, 3))
+}
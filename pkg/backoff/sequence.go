@@ -0,0 +1,43 @@
+package backoff
+
+import (
+	"fmt"
+	"time"
+)
+
+// Sequence returns delays from a fixed list, one per attempt, repeating the
+// last delay once the list runs out
+type Sequence struct {
+	delays []time.Duration
+}
+
+// NewSequence creates a strategy that waits delays[0] after the first attempt,
+// delays[1] after the second, and so on
+func NewSequence(delays []time.Duration) (*Sequence, error) {
+	if len(delays) == 0 {
+		return nil, fmt.Errorf("sequence must contain at least one delay")
+	}
+	for i, delay := range delays {
+		if delay < 0 {
+			return nil, fmt.Errorf("sequence delay %d must be non-negative, got %v", i+1, delay)
+		}
+	}
+
+	return &Sequence{delays: append([]time.Duration(nil), delays...)}, nil
+}
+
+// Delay returns the delay listed for the attempt, or the last one past the end
+func (s *Sequence) Delay(attempt int) time.Duration {
+	if attempt <= 0 {
+		return s.delays[0]
+	}
+	if attempt > len(s.delays) {
+		return s.delays[len(s.delays)-1]
+	}
+	return s.delays[attempt-1]
+}
+
+// Delays returns a copy of the sequence's delays
+func (s *Sequence) Delays() []time.Duration {
+	return append([]time.Duration(nil), s.delays...)
+}
@@ -0,0 +1,54 @@
+package backoff
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSequence_RepeatsLastDelay(t *testing.T) {
+	// Given a sequence of three delays
+	sequence, err := NewSequence([]time.Duration{time.Second, 5 * time.Second, 30 * time.Second})
+	require.NoError(t, err)
+
+	// Then each attempt uses its delay and later attempts reuse the last one
+	assert.Equal(t, time.Second, sequence.Delay(1))
+	assert.Equal(t, 5*time.Second, sequence.Delay(2))
+	assert.Equal(t, 30*time.Second, sequence.Delay(3))
+	assert.Equal(t, 30*time.Second, sequence.Delay(10))
+}
+
+func TestSequence_Validation(t *testing.T) {
+	_, err := NewSequence(nil)
+	assert.ErrorContains(t, err, "at least one delay")
+
+	_, err = NewSequence([]time.Duration{time.Second, -time.Second})
+	assert.ErrorContains(t, err, "sequence delay 2 must be non-negative")
+}
+
+func TestSequence_FirstDelayBeforeFirstAttempt(t *testing.T) {
+	// Given a sequence
+	sequence, err := NewSequence([]time.Duration{time.Second, 2 * time.Second})
+	require.NoError(t, err)
+
+	// Then attempts before the first use the first delay
+	assert.Equal(t, time.Second, sequence.Delay(0))
+	assert.Equal(t, time.Second, sequence.Delay(-1))
+}
+
+func TestSequence_DelaysAreCopied(t *testing.T) {
+	// Given a sequence created from a slice
+	delays := []time.Duration{time.Second, 2 * time.Second}
+	sequence, err := NewSequence(delays)
+	require.NoError(t, err)
+
+	// When the input and the returned delays are changed
+	delays[0] = time.Hour
+	sequence.Delays()[1] = time.Hour
+
+	// Then the sequence keeps its own delays
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, sequence.Delays())
+	assert.Equal(t, time.Second, sequence.Delay(1))
+}
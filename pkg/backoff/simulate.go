@@ -0,0 +1,152 @@
+package backoff
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// SimulationConfig describes a synthetic failure model for Simulate
+type SimulationConfig struct {
+	Trials      int     // number of independent runs to simulate
+	MaxAttempts int     // attempts per run
+	FailureRate float64 // probability that any single attempt fails (0-1)
+
+	// RetryAfterRate is the probability that a failed attempt carries a
+	// Retry-After hint of RetryAfter, fed to strategies that process output
+	RetryAfterRate float64
+	RetryAfter     time.Duration
+
+	// AttemptDuration is the simulated run time of each attempt
+	AttemptDuration time.Duration
+
+	// Rand supplies randomness for the failure model (nil uses a time-seeded source)
+	Rand *rand.Rand
+}
+
+// SimulationResult aggregates the outcome of a simulation
+type SimulationResult struct {
+	Trials    int
+	Successes int
+
+	// AttemptsToSuccess counts successful trials by the attempt that succeeded;
+	// index 0 is unused
+	AttemptsToSuccess []int
+
+	// TotalTimes is the simulated wall time of every trial, sorted ascending
+	TotalTimes []time.Duration
+}
+
+// Simulate runs a fresh strategy from newStrategy against the failure model for
+// each trial, without sleeping or executing commands. Time is accumulated from
+// the strategy's delays plus AttemptDuration per attempt.
+func Simulate(newStrategy func() Strategy, config SimulationConfig) (*SimulationResult, error) {
+	if config.Trials <= 0 {
+		return nil, fmt.Errorf("trials must be positive, got %d", config.Trials)
+	}
+	if config.MaxAttempts <= 0 {
+		return nil, fmt.Errorf("attempts must be positive, got %d", config.MaxAttempts)
+	}
+	if config.FailureRate < 0 || config.FailureRate > 1 {
+		return nil, fmt.Errorf("failure rate must be between 0 and 1, got %v", config.FailureRate)
+	}
+	if config.RetryAfterRate < 0 || config.RetryAfterRate > 1 {
+		return nil, fmt.Errorf("retry-after rate must be between 0 and 1, got %v", config.RetryAfterRate)
+	}
+
+	rng := config.Rand
+	if rng == nil {
+		rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
+	result := &SimulationResult{
+		Trials:            config.Trials,
+		AttemptsToSuccess: make([]int, config.MaxAttempts+1),
+		TotalTimes:        make([]time.Duration, 0, config.Trials),
+	}
+
+	for trial := 0; trial < config.Trials; trial++ {
+		strategy := newStrategy()
+		var elapsed, lastDelay time.Duration
+
+		for attempt := 1; attempt <= config.MaxAttempts; attempt++ {
+			elapsed += config.AttemptDuration
+			success := rng.Float64() >= config.FailureRate
+
+			if adaptive, ok := strategy.(interface {
+				RecordOutcome(delay time.Duration, success bool, latency time.Duration)
+			}); ok {
+				adaptive.RecordOutcome(lastDelay, success, config.AttemptDuration)
+			}
+
+			if success {
+				result.Successes++
+				result.AttemptsToSuccess[attempt]++
+				break
+			}
+
+			if httpAware, ok := strategy.(HTTPAwareStrategy); ok {
+				stdout := "HTTP/1.1 503 Service Unavailable\r\n\r\n"
+				if config.RetryAfter > 0 && rng.Float64() < config.RetryAfterRate {
+					seconds := int(math.Ceil(config.RetryAfter.Seconds()))
+					stdout = fmt.Sprintf("HTTP/1.1 429 Too Many Requests\r\nRetry-After: %d\r\n\r\n", seconds)
+				}
+				httpAware.ProcessCommandOutput(stdout, "", 1)
+			}
+
+			if attempt < config.MaxAttempts {
+				lastDelay = strategy.Delay(attempt)
+				elapsed += lastDelay
+			}
+		}
+
+		result.TotalTimes = append(result.TotalTimes, elapsed)
+	}
+
+	sort.Slice(result.TotalTimes, func(i, j int) bool { return result.TotalTimes[i] < result.TotalTimes[j] })
+	return result, nil
+}
+
+// SuccessRate returns the fraction of trials that eventually succeeded
+func (r *SimulationResult) SuccessRate() float64 {
+	if r.Trials == 0 {
+		return 0
+	}
+	return float64(r.Successes) / float64(r.Trials)
+}
+
+// MeanAttempts returns the average number of attempts taken by successful trials
+func (r *SimulationResult) MeanAttempts() float64 {
+	if r.Successes == 0 {
+		return 0
+	}
+	total := 0
+	for attempt, count := range r.AttemptsToSuccess {
+		total += attempt * count
+	}
+	return float64(total) / float64(r.Successes)
+}
+
+// TimePercentile returns the p-th percentile (0-100) of total trial time using
+// the nearest-rank method
+func (r *SimulationResult) TimePercentile(p float64) time.Duration {
+	return DurationPercentile(r.TotalTimes, p)
+}
+
+// DurationPercentile returns the p-th percentile (0-100) of durations sorted
+// ascending using the nearest-rank method, or 0 when there are none
+func DurationPercentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return sorted[rank-1]
+}
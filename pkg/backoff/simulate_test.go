@@ -0,0 +1,74 @@
+package backoff
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulate_KnownFailureRate(t *testing.T) {
+	// Given a fixed 1s strategy and attempts that fail 30% of the time
+	config := SimulationConfig{
+		Trials:      50000,
+		MaxAttempts: 10,
+		FailureRate: 0.3,
+		Rand:        rand.New(rand.NewSource(1)),
+	}
+
+	// When simulating many trials
+	result, err := Simulate(func() Strategy { return NewFixed(time.Second) }, config)
+	require.NoError(t, err)
+
+	// Then success rate and attempt distribution should follow the geometric model
+	assert.Equal(t, 50000, result.Trials)
+	assert.InDelta(t, 1-0.0000059, result.SuccessRate(), 0.001)
+	assert.InDelta(t, 1/0.7, result.MeanAttempts(), 0.02)
+	assert.InDelta(t, 0.7, float64(result.AttemptsToSuccess[1])/float64(result.Trials), 0.01)
+	assert.InDelta(t, 0.21, float64(result.AttemptsToSuccess[2])/float64(result.Trials), 0.01)
+
+	// And time percentiles should reflect one second per failed attempt:
+	// 70% succeed at once, 91% within two attempts, 99.2% within four
+	assert.Equal(t, time.Duration(0), result.TimePercentile(50))
+	assert.Equal(t, time.Second, result.TimePercentile(90))
+	assert.Equal(t, 3*time.Second, result.TimePercentile(99))
+}
+
+func TestSimulate_RetryAfterInjection(t *testing.T) {
+	// Given an HTTP-aware strategy and failures that always carry Retry-After: 5
+	config := SimulationConfig{
+		Trials:          100,
+		MaxAttempts:     3,
+		FailureRate:     1,
+		RetryAfterRate:  1,
+		RetryAfter:      5 * time.Second,
+		AttemptDuration: 100 * time.Millisecond,
+		Rand:            rand.New(rand.NewSource(1)),
+	}
+
+	// When simulating
+	result, err := Simulate(func() Strategy {
+		return NewHTTPAware(NewFixed(time.Second), time.Minute)
+	}, config)
+	require.NoError(t, err)
+
+	// Then every trial fails and waits the server's delay between attempts
+	assert.Equal(t, 0.0, result.SuccessRate())
+	assert.Equal(t, 10*time.Second+300*time.Millisecond, result.TimePercentile(0))
+	assert.Equal(t, 10*time.Second+300*time.Millisecond, result.TimePercentile(100))
+}
+
+func TestSimulate_InvalidConfig(t *testing.T) {
+	newStrategy := func() Strategy { return NewFixed(time.Second) }
+	for _, config := range []SimulationConfig{
+		{Trials: 0, MaxAttempts: 3},
+		{Trials: 10, MaxAttempts: 0},
+		{Trials: 10, MaxAttempts: 3, FailureRate: 1.5},
+		{Trials: 10, MaxAttempts: 3, RetryAfterRate: -0.1},
+	} {
+		_, err := Simulate(newStrategy, config)
+		assert.Error(t, err, "config %+v", config)
+	}
+}
@@ -0,0 +1,59 @@
+package backoff
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// StatusMultiplier scales the fallback delay after a response whose HTTP status
+// matches Status, a code such as "429" or a class such as "5..", where each
+// "." stands for any digit
+type StatusMultiplier struct {
+	Status     string
+	Multiplier float64
+}
+
+// ParseStatusMultipliers parses a list such as "5..:1.5,429:3"; an empty spec
+// returns no multipliers, leaving every status at the uniform 1x
+func ParseStatusMultipliers(spec string) ([]StatusMultiplier, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, nil
+	}
+
+	var multipliers []StatusMultiplier
+	for _, entry := range strings.Split(spec, ",") {
+		status, value, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok || !ValidStatusPattern(status) {
+			return nil, fmt.Errorf("invalid status multiplier %q: expected STATUS:MULTIPLIER such as 429:3 or 5..:1.5", entry)
+		}
+		multiplier, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || multiplier <= 0 {
+			return nil, fmt.Errorf("invalid status multiplier %q: multiplier must be a positive number", entry)
+		}
+		multipliers = append(multipliers, StatusMultiplier{Status: status, Multiplier: multiplier})
+	}
+	return multipliers, nil
+}
+
+// SetStatusMultipliers scales the fallback delay by the multiplier for the
+// last response's status, capped at the maximum delay. When several entries
+// match, the most specific wins, so 503 beats 5..; unmatched statuses and
+// server timing such as Retry-After are used as given.
+func (h *HTTPAware) SetStatusMultipliers(multipliers []StatusMultiplier) {
+	h.statusMultipliers = multipliers
+}
+
+// statusMultiplier returns the multiplier for status, or 1 when no entry matches
+func (h *HTTPAware) statusMultiplier(status int) float64 {
+	if status == 0 {
+		return 1
+	}
+	multiplier, wildcards := 1.0, len(strconv.Itoa(status))+1
+	for _, m := range h.statusMultipliers {
+		if n, ok := MatchStatusPattern(m.Status, status); ok && n < wildcards {
+			multiplier, wildcards = m.Multiplier, n
+		}
+	}
+	return multiplier
+}
@@ -0,0 +1,81 @@
+package backoff
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPAwareStrategy_StatusMultipliers(t *testing.T) {
+	multipliers, err := ParseStatusMultipliers("5..:1.5,429:3")
+	require.NoError(t, err)
+	delayAfter := func(status string, attempt int) time.Duration {
+		strategy := NewHTTPAware(NewExponential(time.Second, 2.0, time.Minute), 10*time.Minute)
+		strategy.SetStatusMultipliers(multipliers)
+		strategy.ProcessCommandOutput("HTTP/1.1 "+status+"\r\nContent-Type: text/plain\r\n\r\n", "", 22)
+		return strategy.Delay(attempt)
+	}
+
+	// Given the same attempt, a 503 backs off gently and a 429 steeply
+	assert.Equal(t, 6*time.Second, delayAfter("503 Service Unavailable", 3))
+	assert.Equal(t, 12*time.Second, delayAfter("429 Too Many Requests", 3))
+
+	// And statuses without an entry keep the uniform schedule
+	assert.Equal(t, 4*time.Second, delayAfter("404 Not Found", 3))
+}
+
+func TestHTTPAwareStrategy_StatusMultiplierBounds(t *testing.T) {
+	// Given a class and a more specific status within it
+	multipliers, err := ParseStatusMultipliers("5..:2,503:4")
+	require.NoError(t, err)
+	strategy := NewHTTPAware(NewFixed(10*time.Second), time.Minute)
+	strategy.SetStatusMultipliers(multipliers)
+
+	// Then the exact status wins over the class
+	strategy.ProcessCommandOutput("HTTP/1.1 503 Service Unavailable\r\n\r\n", "", 22)
+	assert.Equal(t, 40*time.Second, strategy.Delay(1))
+	strategy.ProcessCommandOutput("HTTP/1.1 502 Bad Gateway\r\n\r\n", "", 22)
+	assert.Equal(t, 20*time.Second, strategy.Delay(1))
+
+	// And the scaled delay is capped at the maximum delay
+	slow := NewHTTPAware(NewFixed(20*time.Second), time.Minute)
+	slow.SetStatusMultipliers(multipliers)
+	slow.ProcessCommandOutput("HTTP/1.1 503 Service Unavailable\r\n\r\n", "", 22)
+	assert.Equal(t, time.Minute, slow.Delay(1))
+
+	// And server timing is used as given
+	strategy.ProcessCommandOutput("HTTP/1.1 503 Service Unavailable\r\nRetry-After: 5\r\n\r\n", "", 22)
+	assert.Equal(t, 5*time.Second, strategy.Delay(1))
+}
+
+func TestParseStatusMultipliers_Invalid(t *testing.T) {
+	for _, spec := range []string{"429", "6..:2", "5xx:2", "429:0", "429:-1", "429:fast"} {
+		_, err := ParseStatusMultipliers(spec)
+		assert.ErrorContains(t, err, "invalid status multiplier", spec)
+	}
+
+	multipliers, err := ParseStatusMultipliers("")
+	assert.NoError(t, err)
+	assert.Nil(t, multipliers)
+}
+
+func TestStatusPattern(t *testing.T) {
+	for _, pattern := range []string{"429", "5..", "1.0"} {
+		assert.True(t, ValidStatusPattern(pattern), pattern)
+	}
+	for _, pattern := range []string{"", "42", "4290", "6..", "0..", "5xx", "5.*"} {
+		assert.False(t, ValidStatusPattern(pattern), pattern)
+	}
+
+	// Matches report how many digits were wildcards
+	wildcards, ok := MatchStatusPattern("5..", 503)
+	assert.True(t, ok)
+	assert.Equal(t, 2, wildcards)
+	wildcards, ok = MatchStatusPattern("503", 503)
+	assert.True(t, ok)
+	assert.Equal(t, 0, wildcards)
+	_, ok = MatchStatusPattern("5..", 429)
+	assert.False(t, ok)
+}
@@ -0,0 +1,38 @@
+package backoff
+
+import "strconv"
+
+// ValidStatusPattern reports whether pattern is an HTTP status code or class
+// from 1.. to 5.., such as "429" or "5..", where each "." stands for any digit
+func ValidStatusPattern(pattern string) bool {
+	if len(pattern) != 3 || pattern[0] < '1' || pattern[0] > '5' {
+		return false
+	}
+	for i := 1; i < len(pattern); i++ {
+		if pattern[i] != '.' && (pattern[i] < '0' || pattern[i] > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchStatusPattern reports whether status matches pattern and how many of
+// the pattern's digits were wildcards, so callers can prefer the most specific
+// of several matching patterns
+func MatchStatusPattern(pattern string, status int) (int, bool) {
+	code := strconv.Itoa(status)
+	if len(pattern) != len(code) {
+		return 0, false
+	}
+	wildcards := 0
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '.':
+			wildcards++
+		case code[i]:
+		default:
+			return 0, false
+		}
+	}
+	return wildcards, true
+}
@@ -1,6 +1,7 @@
 package backoff
 
 import (
+	"fmt"
 	"math"
 	"math/rand"
 	"time"
@@ -8,7 +9,8 @@ import (
 
 // Strategy defines the interface for backoff strategies
 type Strategy interface {
-	// Delay returns the delay duration for the given attempt number
+	// Delay returns the delay duration for the given attempt number, counted
+	// from AttemptBaseOne: Delay(1) is the wait after the first attempt fails
 	Delay(attempt int) time.Duration
 }
 
@@ -19,6 +21,40 @@ type HTTPAwareStrategy interface {
 	ProcessCommandOutput(stdout, stderr string, exitCode int)
 }
 
+// Resetter is implemented by strategies that keep state between delays and can
+// return to their initial state, so the next delay starts again from the base
+type Resetter interface {
+	Reset()
+}
+
+// Reset returns strategy to its initial state. Strategies that derive every
+// delay from the attempt number alone have nothing to reset, so this is a no-op
+// for them.
+func Reset(strategy Strategy) {
+	if resetter, ok := strategy.(Resetter); ok {
+		resetter.Reset()
+	}
+}
+
+// Wrapper is implemented by strategies that decorate another, such as
+// WithMonotonic, so callers can reach the optional interfaces (Resetter,
+// ProcessCommandOutput, RecordOutcome) of the strategy underneath
+type Wrapper interface {
+	Unwrap() Strategy
+}
+
+// Unwrap returns the strategy beneath any decorators around strategy, or
+// strategy itself when it is not decorated
+func Unwrap(strategy Strategy) Strategy {
+	for {
+		wrapper, ok := strategy.(Wrapper)
+		if !ok {
+			return strategy
+		}
+		strategy = wrapper.Unwrap()
+	}
+}
+
 // Fixed implements a fixed delay strategy
 type Fixed struct {
 	Duration time.Duration
@@ -54,6 +90,15 @@ func NewExponential(baseDelay time.Duration, multiplier float64, maxDelay time.D
 	}
 }
 
+// HalfLifeMultiplier returns the exponential multiplier under which the delay
+// doubles every halfLife attempts, i.e. 2^(1/halfLife)
+func HalfLifeMultiplier(halfLife float64) (float64, error) {
+	if halfLife <= 0 || math.IsInf(halfLife, 0) || math.IsNaN(halfLife) {
+		return 0, fmt.Errorf("half-life must be a positive number of attempts, got %v", halfLife)
+	}
+	return math.Pow(2, 1/halfLife), nil
+}
+
 // Delay returns the exponentially increasing delay for the given attempt
 func (e *Exponential) Delay(attempt int) time.Duration {
 	if attempt <= 0 {
@@ -198,6 +243,11 @@ func (d *DecorrelatedJitter) Delay(attempt int) time.Duration {
 	return randomDelay
 }
 
+// Reset forgets the previous delay so the next one is drawn from the base range
+func (d *DecorrelatedJitter) Reset() {
+	d.previousDelay = 0
+}
+
 // Fibonacci implements a fibonacci backoff strategy that follows the fibonacci sequence
 // for delay calculation, providing a middle ground between linear and exponential growth
 type Fibonacci struct {
@@ -96,6 +96,42 @@ func TestExponential_NoMaxDelay(t *testing.T) {
 	assert.Equal(t, expected, delay10)
 }
 
+func TestExponential_HalfLifeDoublesDelay(t *testing.T) {
+	for _, halfLife := range []int{1, 2, 3, 5} {
+		// Given exponential backoff whose multiplier is derived from a half-life
+		multiplier, err := HalfLifeMultiplier(float64(halfLife))
+		assert.NoError(t, err)
+		exponential := NewExponential(time.Second, multiplier, 0)
+
+		// When comparing delays half-life attempts apart
+		// Then each should be double the earlier one
+		for attempt := 1; attempt <= 6; attempt++ {
+			want := 2 * exponential.Delay(attempt)
+			AssertDelayWithin(t, exponential.Delay(attempt+halfLife), want, time.Millisecond,
+				"half-life %d, attempt %d", halfLife, attempt)
+		}
+
+		// And over two half-lives the delay quadruples
+		AssertDelayWithin(t, exponential.Delay(1+2*halfLife), 4*time.Second, time.Millisecond,
+			"half-life %d over %d attempts", halfLife, 2*halfLife)
+	}
+}
+
+func TestHalfLifeMultiplier(t *testing.T) {
+	multiplier, err := HalfLifeMultiplier(1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2.0, multiplier)
+
+	multiplier, err = HalfLifeMultiplier(4)
+	assert.NoError(t, err)
+	assert.InDelta(t, 1.189207, multiplier, 1e-6)
+
+	for _, invalid := range []float64{0, -1, math.Inf(1), math.NaN()} {
+		_, err := HalfLifeMultiplier(invalid)
+		assert.Error(t, err, "half-life %v", invalid)
+	}
+}
+
 func TestJitter_DelayIsRandom(t *testing.T) {
 	// Given a jitter backoff strategy with 1000ms base delay
 	jitter := NewJitter(1000*time.Millisecond, 2.0, 0)
@@ -377,3 +413,33 @@ func TestFibonacci_NoMaxDelay(t *testing.T) {
 	// Then delay should not be capped (55 is the 10th fibonacci number)
 	assert.Equal(t, 2750*time.Millisecond, delay10)
 }
+
+func TestReset_DecorrelatedJitterStartsOver(t *testing.T) {
+	// Given decorrelated jitter that has grown past its base range
+	dj := NewDecorrelatedJitter(100*time.Millisecond, 10, time.Hour)
+	for attempt := 1; attempt <= 5; attempt++ {
+		dj.Delay(attempt)
+	}
+	dj.previousDelay = 10 * time.Second
+
+	// When it is reset
+	Reset(dj)
+
+	// Then the next delay is drawn from base..base*multiplier again
+	delay := dj.Delay(6)
+	assert.GreaterOrEqual(t, delay, 100*time.Millisecond)
+	assert.LessOrEqual(t, delay, time.Second)
+}
+
+func TestReset_StatelessStrategiesAreUnaffected(t *testing.T) {
+	// Resetting a strategy without state is a no-op
+	exponential := NewExponential(100*time.Millisecond, 2, 0)
+	Reset(exponential)
+	assert.Equal(t, 400*time.Millisecond, exponential.Delay(3))
+
+	// HTTP-aware strategies reset their fallback
+	dj := NewDecorrelatedJitter(100*time.Millisecond, 2, 0)
+	dj.previousDelay = time.Minute
+	Reset(NewHTTPAware(dj, time.Minute))
+	assert.Equal(t, time.Duration(0), dj.previousDelay)
+}
@@ -1,8 +1,14 @@
 package conditions
 
 import (
+	"encoding/json"
 	"fmt"
 	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shaneisley/patience/pkg/patterns"
 )
 
 // Result represents the outcome of a condition check
@@ -16,15 +22,51 @@ type Checker struct {
 	successPattern  *regexp.Regexp
 	failurePattern  *regexp.Regexp
 	caseInsensitive bool
+	jsonEquals      []jsonEquality
+
+	// normalizeNewlines converts \r\n and \r to \n before matching (default on)
+	normalizeNewlines bool
+
+	// patternStream selects the stream(s) success and failure patterns match
+	patternStream string
+
+	// Allowlist patterns that turn a nonzero exit into success
+	stdoutAllowPattern *regexp.Regexp
+	stderrAllowPattern *regexp.Regexp
+
+	// Match counts and timings of each pattern, reported by PatternMetrics
+	successMetrics     patterns.MatchMetrics
+	failureMetrics     patterns.MatchMetrics
+	stdoutAllowMetrics patterns.MatchMetrics
+	stderrAllowMetrics patterns.MatchMetrics
+
+	// matchTimeout bounds each pattern match (0 = unbounded)
+	matchTimeout time.Duration
 }
 
+// jsonEquality is a single key=value expectation checked against stdout JSON
+type jsonEquality struct {
+	key      string
+	path     []string
+	expected string
+}
+
+// Streams that success and failure patterns can be matched against
+const (
+	PatternStreamStdout = "stdout"
+	PatternStreamStderr = "stderr"
+	PatternStreamBoth   = "both"
+)
+
 // NewChecker creates a new condition checker
 // successPattern: regex pattern that indicates success in stdout/stderr
 // failurePattern: regex pattern that indicates failure in stdout/stderr
 // caseInsensitive: whether to ignore case when matching patterns
 func NewChecker(successPattern, failurePattern string, caseInsensitive bool) (*Checker, error) {
 	checker := &Checker{
-		caseInsensitive: caseInsensitive,
+		caseInsensitive:   caseInsensitive,
+		normalizeNewlines: true,
+		patternStream:     PatternStreamBoth,
 	}
 
 	// Compile success pattern if provided
@@ -58,12 +100,253 @@ func NewChecker(successPattern, failurePattern string, caseInsensitive bool) (*C
 	return checker, nil
 }
 
+// SuccessPattern returns the compiled success pattern, or "" when none is set
+func (c *Checker) SuccessPattern() string {
+	if c.successPattern == nil {
+		return ""
+	}
+	return c.successPattern.String()
+}
+
+// FailurePattern returns the compiled failure pattern, or "" when none is set
+func (c *Checker) FailurePattern() string {
+	if c.failurePattern == nil {
+		return ""
+	}
+	return c.failurePattern.String()
+}
+
+// SetSuccessIfStdoutMatches declares a nonzero exit successful when stdout
+// matches pattern, for commands that fail for benign reasons
+func (c *Checker) SetSuccessIfStdoutMatches(pattern string) error {
+	re, err := c.compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid success-if-stdout-matches pattern: %w", err)
+	}
+	c.stdoutAllowPattern = re
+	return nil
+}
+
+// SetSuccessIfStderrMatches declares a nonzero exit successful when stderr
+// matches pattern (e.g. "already exists")
+func (c *Checker) SetSuccessIfStderrMatches(pattern string) error {
+	re, err := c.compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid success-if-stderr-matches pattern: %w", err)
+	}
+	c.stderrAllowPattern = re
+	return nil
+}
+
+// SetNormalizeNewlines controls whether \r\n and \r line endings are converted
+// to \n before patterns are matched, so that anchors like (?m)done$ work on
+// output from Windows tools. Normalization is on by default.
+func (c *Checker) SetNormalizeNewlines(normalize bool) {
+	c.normalizeNewlines = normalize
+}
+
+// SetPatternStream restricts success and failure patterns to stdout or stderr,
+// for tools such as git that print progress on one stream and results on the
+// other. Patterns match either stream (PatternStreamBoth) by default.
+func (c *Checker) SetPatternStream(stream string) error {
+	switch stream {
+	case PatternStreamStdout, PatternStreamStderr, PatternStreamBoth:
+		c.patternStream = stream
+		return nil
+	}
+	return fmt.Errorf("invalid pattern stream %q: must be stdout, stderr or both", stream)
+}
+
+// matchStreams reports whether pattern matches the streams selected by SetPatternStream
+func (c *Checker) matchStreams(pattern *regexp.Regexp, stdout, stderr string) bool {
+	switch c.patternStream {
+	case PatternStreamStdout:
+		return pattern.MatchString(stdout)
+	case PatternStreamStderr:
+		return pattern.MatchString(stderr)
+	default:
+		return pattern.MatchString(stdout) || pattern.MatchString(stderr)
+	}
+}
+
+// NormalizeNewlines converts \r\n and lone \r line endings to \n
+func NormalizeNewlines(s string) string {
+	if !strings.Contains(s, "\r") {
+		return s
+	}
+	return strings.ReplaceAll(strings.ReplaceAll(s, "\r\n", "\n"), "\r", "\n")
+}
+
+// compile compiles pattern honoring the checker's case sensitivity
+func (c *Checker) compile(pattern string) (*regexp.Regexp, error) {
+	if c.caseInsensitive {
+		return regexp.Compile("(?i)" + pattern)
+	}
+	return regexp.Compile(pattern)
+}
+
+// allowlistReason reports which allowlist pattern, if any, matches the output.
+// A match that times out reports the timeout failure instead.
+func (c *Checker) allowlistReason(stdout, stderr string) (Result, bool) {
+	if c.stderrAllowPattern != nil {
+		matched, timedOut := c.timedMatch(&c.stderrAllowMetrics, func() bool { return c.stderrAllowPattern.MatchString(stderr) })
+		if timedOut {
+			return c.timeoutResult(MetricsStderrAllowPattern), true
+		}
+		if matched {
+			return Result{Success: true, Reason: "stderr allowlist matched"}, true
+		}
+	}
+	if c.stdoutAllowPattern != nil {
+		matched, timedOut := c.timedMatch(&c.stdoutAllowMetrics, func() bool { return c.stdoutAllowPattern.MatchString(stdout) })
+		if timedOut {
+			return c.timeoutResult(MetricsStdoutAllowPattern), true
+		}
+		if matched {
+			return Result{Success: true, Reason: "stdout allowlist matched"}, true
+		}
+	}
+	return Result{}, false
+}
+
+// AddJSONEquals adds a JSON field equality condition in the form key=value.
+// The key may be a top-level field or a dotted path (e.g. data.status). All
+// configured equalities must hold on stdout JSON for the attempt to succeed.
+func (c *Checker) AddJSONEquals(expr string) error {
+	eq, err := parseJSONEquality(expr)
+	if err != nil {
+		return err
+	}
+	c.jsonEquals = append(c.jsonEquals, eq)
+	return nil
+}
+
+// parseJSONEquality parses a key=value expectation
+func parseJSONEquality(expr string) (jsonEquality, error) {
+	idx := strings.Index(expr, "=")
+	if idx <= 0 {
+		return jsonEquality{}, fmt.Errorf("invalid JSON equality %q: expected key=value", expr)
+	}
+
+	key := strings.TrimSpace(expr[:idx])
+	path := strings.Split(key, ".")
+	for _, part := range path {
+		if part == "" {
+			return jsonEquality{}, fmt.Errorf("invalid JSON equality %q: empty key segment", expr)
+		}
+	}
+
+	return jsonEquality{
+		key:      key,
+		path:     path,
+		expected: expr[idx+1:],
+	}, nil
+}
+
+// checkJSONEquals verifies all JSON equalities against stdout.
+// It returns the key of the first expectation that did not hold.
+func (c *Checker) checkJSONEquals(stdout string) (bool, string) {
+	return matchJSONEqualities(c.jsonEquals, stdout)
+}
+
+// matchJSONEqualities verifies equalities against a JSON document, returning
+// the key of the first expectation that did not hold
+func matchJSONEqualities(equalities []jsonEquality, document string) (bool, string) {
+	var doc interface{}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(document)), &doc); err != nil {
+		return false, equalities[0].key
+	}
+
+	for _, eq := range equalities {
+		value, ok := LookupJSONPath(doc, eq.path)
+		if !ok || !jsonValueEquals(value, eq.expected) {
+			return false, eq.key
+		}
+	}
+	return true, ""
+}
+
+// JSONFieldMatcher checks key=value expectations, as AddJSONEquals does,
+// against any JSON document, such as the body of an HTTP response
+type JSONFieldMatcher struct {
+	equalities []jsonEquality
+}
+
+// NewJSONFieldMatcher parses key=value expectations; with none it returns
+// nil, which matches every document
+func NewJSONFieldMatcher(exprs []string) (*JSONFieldMatcher, error) {
+	if len(exprs) == 0 {
+		return nil, nil
+	}
+	m := &JSONFieldMatcher{}
+	for _, expr := range exprs {
+		eq, err := parseJSONEquality(expr)
+		if err != nil {
+			return nil, err
+		}
+		m.equalities = append(m.equalities, eq)
+	}
+	return m, nil
+}
+
+// Match reports whether document is JSON in which every expectation holds,
+// and otherwise the key of the first one that did not
+func (m *JSONFieldMatcher) Match(document string) (bool, string) {
+	if m == nil {
+		return true, ""
+	}
+	return matchJSONEqualities(m.equalities, document)
+}
+
+// LookupJSONPath walks a decoded JSON document along a dotted key path
+func LookupJSONPath(doc interface{}, path []string) (interface{}, bool) {
+	current := doc
+	for _, part := range path {
+		obj, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current, ok = obj[part]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// jsonValueEquals compares a decoded JSON scalar with the expected string form
+func jsonValueEquals(value interface{}, expected string) bool {
+	switch v := value.(type) {
+	case string:
+		return v == expected
+	case float64:
+		want, err := strconv.ParseFloat(expected, 64)
+		return err == nil && v == want
+	case bool:
+		want, err := strconv.ParseBool(expected)
+		return err == nil && v == want
+	case nil:
+		return expected == "null"
+	default:
+		return false
+	}
+}
+
 // CheckSuccess determines if a command execution was successful
-// It checks patterns first, then falls back to exit code
+// It checks patterns first, then allowlists for nonzero exits, then falls back to exit code
 func (c *Checker) CheckSuccess(exitCode int, stdout, stderr string) Result {
+	if c.normalizeNewlines {
+		stdout = NormalizeNewlines(stdout)
+		stderr = NormalizeNewlines(stderr)
+	}
+
 	// Check failure pattern first (takes precedence)
 	if c.failurePattern != nil {
-		if c.failurePattern.MatchString(stdout) || c.failurePattern.MatchString(stderr) {
+		matched, timedOut := c.timedMatch(&c.failureMetrics, func() bool { return c.matchStreams(c.failurePattern, stdout, stderr) })
+		if timedOut {
+			return c.timeoutResult(MetricsFailurePattern)
+		}
+		if matched {
 			return Result{
 				Success: false,
 				Reason:  "failure pattern matched",
@@ -73,7 +356,11 @@ func (c *Checker) CheckSuccess(exitCode int, stdout, stderr string) Result {
 
 	// Check success pattern
 	if c.successPattern != nil {
-		if c.successPattern.MatchString(stdout) || c.successPattern.MatchString(stderr) {
+		matched, timedOut := c.timedMatch(&c.successMetrics, func() bool { return c.matchStreams(c.successPattern, stdout, stderr) })
+		if timedOut {
+			return c.timeoutResult(MetricsSuccessPattern)
+		}
+		if matched {
 			return Result{
 				Success: true,
 				Reason:  "success pattern matched",
@@ -81,6 +368,27 @@ func (c *Checker) CheckSuccess(exitCode int, stdout, stderr string) Result {
 		}
 	}
 
+	// Treat benign nonzero exits as success when an allowlist pattern matches
+	if exitCode != 0 {
+		if result, ok := c.allowlistReason(stdout, stderr); ok {
+			return result
+		}
+	}
+
+	// Check JSON field equalities
+	if len(c.jsonEquals) > 0 {
+		if ok, key := c.checkJSONEquals(stdout); !ok {
+			return Result{
+				Success: false,
+				Reason:  fmt.Sprintf("json field mismatch (%s)", key),
+			}
+		}
+		return Result{
+			Success: true,
+			Reason:  "json field matched",
+		}
+	}
+
 	// Fall back to exit code
 	if exitCode == 0 {
 		return Result{
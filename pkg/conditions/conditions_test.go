@@ -1,7 +1,9 @@
 package conditions
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -83,3 +85,246 @@ func TestConditions_NoPatterns(t *testing.T) {
 	assert.False(t, result.Success)
 	assert.Equal(t, "exit code 1", result.Reason)
 }
+
+func TestConditions_JSONEqualsTopLevelKey(t *testing.T) {
+	// Given a condition checker with a top-level JSON equality
+	checker, err := NewChecker("", "", false)
+	require.NoError(t, err)
+	require.NoError(t, checker.AddJSONEquals("status=ok"))
+
+	// When checking stdout where the field matches
+	result := checker.CheckSuccess(1, `{"status": "ok"}`, "")
+
+	// Then it should indicate success regardless of exit code
+	assert.True(t, result.Success)
+	assert.Equal(t, "json field matched", result.Reason)
+}
+
+func TestConditions_JSONEqualsNestedKey(t *testing.T) {
+	// Given a condition checker with dotted keys for string and number values
+	checker, err := NewChecker("", "", false)
+	require.NoError(t, err)
+	require.NoError(t, checker.AddJSONEquals("data.state=ready"))
+	require.NoError(t, checker.AddJSONEquals("data.replicas=3"))
+
+	// When checking stdout where both nested fields match
+	result := checker.CheckSuccess(0, `{"data": {"state": "ready", "replicas": 3.0}}`, "")
+
+	// Then it should indicate success
+	assert.True(t, result.Success)
+	assert.Equal(t, "json field matched", result.Reason)
+}
+
+func TestConditions_JSONEqualsMismatch(t *testing.T) {
+	// Given a condition checker with a JSON equality
+	checker, err := NewChecker("", "", false)
+	require.NoError(t, err)
+	require.NoError(t, checker.AddJSONEquals("status=ok"))
+
+	// When the field differs, is missing, or stdout is not JSON
+	mismatch := checker.CheckSuccess(0, `{"status": "pending"}`, "")
+	missing := checker.CheckSuccess(0, `{"other": "ok"}`, "")
+	notJSON := checker.CheckSuccess(0, "status=ok", "")
+
+	// Then each should fail with a retryable reason naming the key
+	for _, result := range []Result{mismatch, missing, notJSON} {
+		assert.False(t, result.Success)
+		assert.Equal(t, "json field mismatch (status)", result.Reason)
+	}
+}
+
+func TestConditions_JSONEqualsInvalidExpression(t *testing.T) {
+	// Given a condition checker
+	checker, err := NewChecker("", "", false)
+	require.NoError(t, err)
+
+	// When adding malformed expressions
+	// Then they should be rejected
+	assert.Error(t, checker.AddJSONEquals("status"))
+	assert.Error(t, checker.AddJSONEquals("=ok"))
+	assert.Error(t, checker.AddJSONEquals("data..status=ok"))
+}
+
+func TestConditions_SuccessIfStderrMatches(t *testing.T) {
+	// Given a checker that treats "already exists" on stderr as success
+	checker, err := NewChecker("", "", false)
+	require.NoError(t, err)
+	require.NoError(t, checker.SetSuccessIfStderrMatches("already exists"))
+
+	// When the command exits 1 with the benign message
+	benign := checker.CheckSuccess(1, "", "error: bucket already exists")
+
+	// Then it should be declared successful
+	assert.True(t, benign.Success)
+	assert.Equal(t, "stderr allowlist matched", benign.Reason)
+
+	// When the message appears only on stdout or a different error occurs
+	wrongStream := checker.CheckSuccess(1, "already exists", "")
+	otherError := checker.CheckSuccess(1, "", "error: permission denied")
+
+	// Then the exit code should decide as usual
+	assert.False(t, wrongStream.Success)
+	assert.False(t, otherError.Success)
+	assert.Equal(t, "exit code 1", otherError.Reason)
+}
+
+func TestConditions_SuccessIfStdoutMatches(t *testing.T) {
+	// Given a checker with a case-insensitive stdout allowlist and a failure pattern
+	checker, err := NewChecker("", "fatal", true)
+	require.NoError(t, err)
+	require.NoError(t, checker.SetSuccessIfStdoutMatches("nothing to commit"))
+
+	// When the command exits nonzero with the benign message
+	result := checker.CheckSuccess(1, "Nothing to commit, working tree clean", "")
+
+	// Then it should be declared successful
+	assert.True(t, result.Success)
+	assert.Equal(t, "stdout allowlist matched", result.Reason)
+
+	// When the failure pattern also matches, it still takes precedence
+	result = checker.CheckSuccess(1, "nothing to commit", "FATAL: repository corrupt")
+	assert.False(t, result.Success)
+	assert.Equal(t, "failure pattern matched", result.Reason)
+
+	// And invalid patterns are rejected
+	assert.Error(t, checker.SetSuccessIfStdoutMatches("[invalid"))
+}
+
+func TestConditions_NormalizeNewlines(t *testing.T) {
+	crlf := "deploying\r\ndone\r\n"
+	tests := []struct {
+		name        string
+		normalize   bool
+		wantSuccess bool
+		wantReason  string
+	}{
+		{name: "normalized by default", normalize: true, wantSuccess: true, wantReason: "success pattern matched"},
+		{name: "raw CRLF output", normalize: false, wantSuccess: false, wantReason: "exit code 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given a success pattern anchored at line end
+			checker, err := NewChecker(`(?m)^done$`, "", false)
+			require.NoError(t, err)
+			checker.SetNormalizeNewlines(tt.normalize)
+
+			// When checking CRLF output from a failing command
+			result := checker.CheckSuccess(1, crlf, "")
+
+			// Then the anchor should only match once \r\n is normalized
+			assert.Equal(t, tt.wantSuccess, result.Success)
+			assert.Equal(t, tt.wantReason, result.Reason)
+		})
+	}
+}
+
+func TestNormalizeNewlines(t *testing.T) {
+	assert.Equal(t, "a\nb\nc\n", NormalizeNewlines("a\r\nb\rc\n"))
+	assert.Equal(t, "plain\n", NormalizeNewlines("plain\n"))
+}
+
+func TestConditions_PatternStream(t *testing.T) {
+	tests := []struct {
+		stream      string
+		wantSuccess bool
+	}{
+		{stream: PatternStreamStdout, wantSuccess: true},
+		{stream: PatternStreamBoth, wantSuccess: true},
+		{stream: PatternStreamStderr, wantSuccess: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.stream, func(t *testing.T) {
+			// Given a success pattern present only on stdout
+			checker, err := NewChecker("Already up to date", "", false)
+			require.NoError(t, err)
+			require.NoError(t, checker.SetPatternStream(tt.stream))
+
+			// When checking a failed run whose progress went to stderr
+			result := checker.CheckSuccess(1, "Already up to date.\n", "remote: Counting objects: 100%\n")
+
+			// Then it should only match when stdout is searched
+			assert.Equal(t, tt.wantSuccess, result.Success)
+		})
+	}
+}
+
+func TestConditions_PatternStreamFailurePattern(t *testing.T) {
+	// Given a failure pattern restricted to stdout
+	checker, err := NewChecker("", "error", false)
+	require.NoError(t, err)
+	require.NoError(t, checker.SetPatternStream(PatternStreamStdout))
+
+	// When "error" only appears on stderr
+	result := checker.CheckSuccess(0, "ok\n", "warning: error budget low\n")
+
+	// Then the failure pattern should not match
+	assert.True(t, result.Success)
+	assert.Equal(t, "exit code 0", result.Reason)
+
+	// And unknown streams are rejected
+	assert.Error(t, checker.SetPatternStream("stdin"))
+}
+
+func TestConditions_PatternMetricsCountMatchCalls(t *testing.T) {
+	// Given a checker with a failure pattern and a success pattern
+	checker, err := NewChecker("deployed", "fatal", false)
+	require.NoError(t, err)
+
+	// When three attempts are checked, the last one succeeding
+	checker.CheckSuccess(1, "retrying\n", "")
+	checker.CheckSuccess(1, "retrying\n", "")
+	checker.CheckSuccess(0, "deployed\n", "")
+
+	// Then the failure pattern was tried three times without matching
+	metrics := checker.PatternMetrics()
+	require.Len(t, metrics, 2)
+	failure := metrics[MetricsFailurePattern]
+	assert.Equal(t, 3, failure.TotalMatches)
+	assert.Equal(t, 0, failure.SuccessfulMatches)
+	assert.Equal(t, 3, failure.FailedMatches)
+
+	// And the success pattern matched once in three calls
+	success := metrics[MetricsSuccessPattern]
+	assert.Equal(t, 3, success.TotalMatches)
+	assert.Equal(t, 1, success.SuccessfulMatches)
+	assert.Equal(t, success.TotalMatchTime/3, success.AverageMatchTime)
+	assert.False(t, success.LastMatchTime.IsZero())
+}
+
+func TestConditions_MatchTimeoutStopsSlowPattern(t *testing.T) {
+	// Given a success pattern that takes far longer than 20ms over 8MB of output
+	checker, err := NewChecker("(a|aa)*b", "", false)
+	require.NoError(t, err)
+	require.NoError(t, checker.SetMatchTimeout(20*time.Millisecond))
+	output := strings.Repeat("a", 8<<20)
+
+	// When checking the attempt
+	start := time.Now()
+	result := checker.CheckSuccess(0, output, "")
+	elapsed := time.Since(start)
+
+	// Then the match is abandoned at the timeout and fails the attempt
+	assert.False(t, result.Success)
+	assert.Equal(t, "success-pattern timed out after 20ms", result.Reason)
+	assert.Less(t, elapsed, 500*time.Millisecond)
+	assert.Equal(t, 1, checker.PatternMetrics()[MetricsSuccessPattern].ErrorCount)
+}
+
+func TestConditions_MatchTimeoutAllowsFastPattern(t *testing.T) {
+	// Given a failure pattern well within its timeout
+	checker, err := NewChecker("", "fatal", false)
+	require.NoError(t, err)
+	require.NoError(t, checker.SetMatchTimeout(time.Second))
+
+	// When the output contains it
+	result := checker.CheckSuccess(0, "fatal: disk full\n", "")
+
+	// Then it matches as without a timeout
+	assert.False(t, result.Success)
+	assert.Equal(t, "failure pattern matched", result.Reason)
+
+	// And negative timeouts are rejected
+	assert.Error(t, checker.SetMatchTimeout(-time.Second))
+}
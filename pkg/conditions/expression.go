@@ -0,0 +1,486 @@
+package conditions
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// AttemptContext is what a retry-if expression can see of a finished attempt
+type AttemptContext struct {
+	Attempt    int           // attempt, numbered from 1
+	ExitCode   int           // exit; -1 when the attempt timed out
+	Stdout     string        // out
+	Stderr     string        // err
+	HTTPStatus int           // http; the final response's status, 0 without one
+	Duration   time.Duration // duration, compared in seconds
+}
+
+// RetryExpression is a compiled retry-if expression, such as
+//
+//	exit != 0 and not match(out, 'done') or http == 503
+//
+// Operands are the variables exit, out, err, http, duration and attempt,
+// numbers, durations such as 1.5s (compared as seconds), quoted strings and
+// true/false. Operators, loosest first: or (||), and (&&), not (!), then the
+// comparisons == != < <= > >=, of which strings and booleans only support ==
+// and !=. match(text, 'regex') and contains(text, 'substring') test strings,
+// and parentheses group.
+type RetryExpression struct {
+	source string
+	root   exprNode
+}
+
+// CompileRetryIf parses and type-checks a retry-if expression, which must be
+// true or false
+func CompileRetryIf(source string) (*RetryExpression, error) {
+	tokens, err := lexExpression(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid retry-if expression: %w", err)
+	}
+	p := &exprParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err == nil && p.peek().kind != tokenEOF {
+		err = p.unexpected()
+	}
+	if err == nil && root.kind() != kindBool {
+		err = fmt.Errorf("expression is a %s, expected true or false", root.kind())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid retry-if expression: %w", err)
+	}
+	return &RetryExpression{source: source, root: root}, nil
+}
+
+// Eval reports whether the expression holds for the attempt
+func (r *RetryExpression) Eval(ctx AttemptContext) bool {
+	return r.root.eval(&ctx).(bool)
+}
+
+// String returns the expression's source
+func (r *RetryExpression) String() string {
+	return r.source
+}
+
+// valueKind is the static type of an expression
+type valueKind int
+
+const (
+	kindBool valueKind = iota
+	kindNumber
+	kindString
+)
+
+// String names the kind for error messages
+func (k valueKind) String() string {
+	switch k {
+	case kindBool:
+		return "boolean"
+	case kindNumber:
+		return "number"
+	default:
+		return "string"
+	}
+}
+
+// exprNode is a type-checked expression node; eval returns a bool, float64
+// or string according to kind
+type exprNode interface {
+	kind() valueKind
+	eval(ctx *AttemptContext) interface{}
+}
+
+type literalNode struct{ value interface{} }
+
+func (n literalNode) kind() valueKind {
+	switch n.value.(type) {
+	case bool:
+		return kindBool
+	case float64:
+		return kindNumber
+	default:
+		return kindString
+	}
+}
+
+func (n literalNode) eval(*AttemptContext) interface{} { return n.value }
+
+// variables maps each variable to its kind and how to read it from an attempt
+var variables = map[string]struct {
+	kind valueKind
+	read func(ctx *AttemptContext) interface{}
+}{
+	"attempt":  {kindNumber, func(ctx *AttemptContext) interface{} { return float64(ctx.Attempt) }},
+	"exit":     {kindNumber, func(ctx *AttemptContext) interface{} { return float64(ctx.ExitCode) }},
+	"out":      {kindString, func(ctx *AttemptContext) interface{} { return ctx.Stdout }},
+	"err":      {kindString, func(ctx *AttemptContext) interface{} { return ctx.Stderr }},
+	"http":     {kindNumber, func(ctx *AttemptContext) interface{} { return float64(ctx.HTTPStatus) }},
+	"duration": {kindNumber, func(ctx *AttemptContext) interface{} { return ctx.Duration.Seconds() }},
+}
+
+type variableNode struct {
+	name string
+	k    valueKind
+	read func(ctx *AttemptContext) interface{}
+}
+
+func (n variableNode) kind() valueKind                      { return n.k }
+func (n variableNode) eval(ctx *AttemptContext) interface{} { return n.read(ctx) }
+
+type notNode struct{ operand exprNode }
+
+func (n notNode) kind() valueKind                      { return kindBool }
+func (n notNode) eval(ctx *AttemptContext) interface{} { return !n.operand.eval(ctx).(bool) }
+
+// logicNode is "and" or "or", evaluated left to right with short-circuiting
+type logicNode struct {
+	and         bool
+	left, right exprNode
+}
+
+func (n logicNode) kind() valueKind { return kindBool }
+
+func (n logicNode) eval(ctx *AttemptContext) interface{} {
+	if n.left.eval(ctx).(bool) != n.and {
+		return !n.and
+	}
+	return n.right.eval(ctx).(bool)
+}
+
+type compareNode struct {
+	op          string
+	left, right exprNode
+}
+
+func (n compareNode) kind() valueKind { return kindBool }
+
+func (n compareNode) eval(ctx *AttemptContext) interface{} {
+	left, right := n.left.eval(ctx), n.right.eval(ctx)
+	switch n.op {
+	case "==":
+		return left == right
+	case "!=":
+		return left != right
+	}
+	l, r := left.(float64), right.(float64)
+	switch n.op {
+	case "<":
+		return l < r
+	case "<=":
+		return l <= r
+	case ">":
+		return l > r
+	default:
+		return l >= r
+	}
+}
+
+type matchNode struct {
+	text    exprNode
+	pattern *regexp.Regexp
+}
+
+func (n matchNode) kind() valueKind { return kindBool }
+func (n matchNode) eval(ctx *AttemptContext) interface{} {
+	return n.pattern.MatchString(n.text.eval(ctx).(string))
+}
+
+type containsNode struct{ text, substring exprNode }
+
+func (n containsNode) kind() valueKind { return kindBool }
+func (n containsNode) eval(ctx *AttemptContext) interface{} {
+	return strings.Contains(n.text.eval(ctx).(string), n.substring.eval(ctx).(string))
+}
+
+// Token kinds of the expression language
+const (
+	tokenEOF = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenOperator
+)
+
+type exprToken struct {
+	kind  int
+	text  string
+	value interface{} // float64 for numbers, string for strings
+	pos   int         // byte offset in the source, for error messages
+}
+
+// expressionOperators lists the operators, two-character ones first so they
+// win over their one-character prefixes
+var expressionOperators = []string{"==", "!=", "<=", ">=", "&&", "||", "<", ">", "!", "(", ")", ","}
+
+// lexExpression splits source into tokens
+func lexExpression(source string) ([]exprToken, error) {
+	var tokens []exprToken
+	for i := 0; i < len(source); {
+		c := rune(source[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '\'' || c == '"':
+			text, next, err := lexString(source, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, exprToken{kind: tokenString, text: source[i:next], value: text, pos: i})
+			i = next
+		case unicode.IsDigit(c) || c == '.':
+			start := i
+			for i < len(source) && (unicode.IsLetter(rune(source[i])) || unicode.IsDigit(rune(source[i])) || source[i] == '.') {
+				i++
+			}
+			value, err := parseNumberLiteral(source[start:i])
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q at position %d", source[start:i], start)
+			}
+			tokens = append(tokens, exprToken{kind: tokenNumber, text: source[start:i], value: value, pos: start})
+		case unicode.IsLetter(c) || c == '_':
+			start := i
+			for i < len(source) && (unicode.IsLetter(rune(source[i])) || unicode.IsDigit(rune(source[i])) || source[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, exprToken{kind: tokenIdent, text: source[start:i], pos: start})
+		default:
+			op := ""
+			for _, candidate := range expressionOperators {
+				if strings.HasPrefix(source[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected character %q at position %d", c, i)
+			}
+			tokens = append(tokens, exprToken{kind: tokenOperator, text: op, pos: i})
+			i += len(op)
+		}
+	}
+	return append(tokens, exprToken{kind: tokenEOF, pos: len(source)}), nil
+}
+
+// lexString reads the quoted string starting at source[start], where a
+// backslash escapes the next character, returning its text and the offset
+// after the closing quote
+func lexString(source string, start int) (string, int, error) {
+	quote := source[start]
+	var text strings.Builder
+	for i := start + 1; i < len(source); i++ {
+		switch source[i] {
+		case quote:
+			return text.String(), i + 1, nil
+		case '\\':
+			if i+1 < len(source) {
+				i++
+			}
+		}
+		text.WriteByte(source[i])
+	}
+	return "", 0, fmt.Errorf("unterminated string at position %d", start)
+}
+
+// parseNumberLiteral parses a number, or a duration such as 1.5s or 200ms as
+// seconds
+func parseNumberLiteral(literal string) (float64, error) {
+	if n, err := strconv.ParseFloat(literal, 64); err == nil {
+		return n, nil
+	}
+	d, err := time.ParseDuration(literal)
+	if err != nil {
+		return 0, err
+	}
+	return d.Seconds(), nil
+}
+
+// exprParser is a recursive descent parser over the tokens of an expression
+type exprParser struct {
+	tokens []exprToken
+	pos    int
+}
+
+func (p *exprParser) peek() exprToken { return p.tokens[p.pos] }
+
+func (p *exprParser) next() exprToken {
+	token := p.tokens[p.pos]
+	if token.kind != tokenEOF {
+		p.pos++
+	}
+	return token
+}
+
+// accept consumes the next token if it is one of the given words or operators
+func (p *exprParser) accept(texts ...string) (string, bool) {
+	token := p.peek()
+	if token.kind != tokenIdent && token.kind != tokenOperator {
+		return "", false
+	}
+	for _, text := range texts {
+		if token.text == text {
+			p.pos++
+			return text, true
+		}
+	}
+	return "", false
+}
+
+func (p *exprParser) expect(text string) error {
+	if _, ok := p.accept(text); !ok {
+		return fmt.Errorf("expected %q at position %d", text, p.peek().pos)
+	}
+	return nil
+}
+
+func (p *exprParser) unexpected() error {
+	token := p.peek()
+	if token.kind == tokenEOF {
+		return fmt.Errorf("unexpected end of expression")
+	}
+	return fmt.Errorf("unexpected %q at position %d", token.text, token.pos)
+}
+
+// parseOr parses: and ("or" and)*
+func (p *exprParser) parseOr() (exprNode, error) {
+	return p.parseLogic(false, p.parseAnd, "or", "||")
+}
+
+// parseAnd parses: not ("and" not)*
+func (p *exprParser) parseAnd() (exprNode, error) {
+	return p.parseLogic(true, p.parseNot, "and", "&&")
+}
+
+func (p *exprParser) parseLogic(and bool, operand func() (exprNode, error), ops ...string) (exprNode, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept(ops...)
+		if !ok {
+			return left, nil
+		}
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		if left.kind() != kindBool || right.kind() != kindBool {
+			return nil, fmt.Errorf("%q needs true/false operands, got %s and %s", op, left.kind(), right.kind())
+		}
+		left = logicNode{and: and, left: left, right: right}
+	}
+}
+
+// parseNot parses: "not" not | comparison
+func (p *exprParser) parseNot() (exprNode, error) {
+	if op, ok := p.accept("not", "!"); ok {
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		if operand.kind() != kindBool {
+			return nil, fmt.Errorf("%q needs a true/false operand, got %s", op, operand.kind())
+		}
+		return notNode{operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+// parseComparison parses: primary (op primary)?
+func (p *exprParser) parseComparison() (exprNode, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	op, ok := p.accept("==", "!=", "<", "<=", ">", ">=")
+	if !ok {
+		return left, nil
+	}
+	right, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	if left.kind() != right.kind() {
+		return nil, fmt.Errorf("cannot compare %s with %s using %q", left.kind(), right.kind(), op)
+	}
+	if left.kind() != kindNumber && op != "==" && op != "!=" {
+		return nil, fmt.Errorf("%q needs numbers, got %s", op, left.kind())
+	}
+	return compareNode{op: op, left: left, right: right}, nil
+}
+
+// parsePrimary parses a literal, variable, function call or parenthesized
+// expression
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	start := p.pos
+	token := p.next()
+	switch token.kind {
+	case tokenNumber, tokenString:
+		return literalNode{value: token.value}, nil
+	case tokenOperator:
+		if token.text != "(" {
+			break
+		}
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return inner, p.expect(")")
+	case tokenIdent:
+		switch token.text {
+		case "true", "false":
+			return literalNode{value: token.text == "true"}, nil
+		case "match", "contains":
+			return p.parseCall(token)
+		}
+		if v, ok := variables[token.text]; ok {
+			return variableNode{name: token.text, k: v.kind, read: v.read}, nil
+		}
+		return nil, fmt.Errorf("unknown name %q at position %d (expected exit, out, err, http, duration or attempt)", token.text, token.pos)
+	}
+	p.pos = start
+	return nil, p.unexpected()
+}
+
+// parseCall parses the arguments of match(text, 'regex') or
+// contains(text, substring); match's pattern must be a literal so it is
+// compiled once
+func (p *exprParser) parseCall(name exprToken) (exprNode, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	text, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(","); err != nil {
+		return nil, err
+	}
+	argument := p.peek()
+	second, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	if text.kind() != kindString || second.kind() != kindString {
+		return nil, fmt.Errorf("%s() needs string arguments, got %s and %s", name.text, text.kind(), second.kind())
+	}
+
+	if name.text == "contains" {
+		return containsNode{text: text, substring: second}, nil
+	}
+	literal, ok := second.(literalNode)
+	if !ok {
+		return nil, fmt.Errorf("match() needs a quoted pattern at position %d", argument.pos)
+	}
+	pattern, err := regexp.Compile(literal.value.(string))
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern in match() at position %d: %w", argument.pos, err)
+	}
+	return matchNode{text: text, pattern: pattern}, nil
+}
@@ -0,0 +1,101 @@
+package conditions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryExpression_Eval(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		ctx  AttemptContext
+		want bool
+	}{
+		{"exit code comparison", "exit != 0", AttemptContext{ExitCode: 1}, true},
+		{"exit code zero", "exit != 0", AttemptContext{ExitCode: 0}, false},
+		{"and binds tighter than or", "false and false or true", AttemptContext{}, true},
+		{"and binds tighter than or on the right", "true or false and false", AttemptContext{}, true},
+		{"parentheses override precedence", "(true or false) and false", AttemptContext{}, false},
+		{"not binds tighter than and", "not false and false", AttemptContext{}, false},
+		{"not applies to a comparison", "not exit == 0", AttemptContext{ExitCode: 3}, true},
+		{"double negation", "not not true", AttemptContext{}, true},
+		{"symbolic operators", "!(exit == 0) && http >= 500 || false", AttemptContext{ExitCode: 1, HTTPStatus: 502}, true},
+		{
+			name: "request example retries a failure without done",
+			expr: "exit != 0 and not match(out, 'done') or http == 503",
+			ctx:  AttemptContext{ExitCode: 1, Stdout: "still working"},
+			want: true,
+		},
+		{
+			name: "request example stops once done",
+			expr: "exit != 0 and not match(out, 'done') or http == 503",
+			ctx:  AttemptContext{ExitCode: 1, Stdout: "job done"},
+			want: false,
+		},
+		{
+			name: "request example retries a 503 despite success",
+			expr: "exit != 0 and not match(out, 'done') or http == 503",
+			ctx:  AttemptContext{ExitCode: 0, Stdout: "done", HTTPStatus: 503},
+			want: true,
+		},
+		{"match regex", `match(err, "(?i)timeout|reset")`, AttemptContext{Stderr: "Connection RESET"}, true},
+		{"contains", `contains(out, "retry later")`, AttemptContext{Stdout: "please retry later"}, true},
+		{"string equality with escaped quote", `out == 'it\'s'`, AttemptContext{Stdout: "it's"}, true},
+		{"duration literal in seconds", "duration < 2s", AttemptContext{Duration: 1500 * time.Millisecond}, true},
+		{"duration against milliseconds", "duration > 200ms", AttemptContext{Duration: 100 * time.Millisecond}, false},
+		{"attempt number", "attempt <= 3 and exit != 0", AttemptContext{Attempt: 4, ExitCode: 1}, false},
+		{"no http response is zero", "http == 0", AttemptContext{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given a compiled expression
+			expr, err := CompileRetryIf(tt.expr)
+			require.NoError(t, err)
+
+			// When it is evaluated against the attempt
+			got := expr.Eval(tt.ctx)
+
+			// Then it gives the expected answer
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRetryExpression_CompileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		wantErr string
+	}{
+		{"empty", "", "unexpected end of expression"},
+		{"not a boolean", "exit", "expression is a number"},
+		{"unknown variable", "status == 1", `unknown name "status"`},
+		{"mismatched comparison", "out == 1", "cannot compare string with number"},
+		{"ordering strings", "out < 'b'", `"<" needs numbers`},
+		{"and over numbers", "exit and true", `"and" needs true/false operands`},
+		{"not over a string", "not out", `"not" needs a true/false operand`},
+		{"unterminated string", "out == 'abc", "unterminated string at position 7"},
+		{"unclosed parenthesis", "(exit == 0", `expected ")"`},
+		{"trailing tokens", "exit == 0 exit", `unexpected "exit" at position 10`},
+		{"invalid regex", "match(out, '(')", "invalid pattern in match()"},
+		{"non-literal pattern", "match(out, err)", "match() needs a quoted pattern"},
+		{"bad character", "exit = 0", "unexpected character '='"},
+		{"bad number", "duration > 2x", `invalid number "2x"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// When the expression is compiled
+			_, err := CompileRetryIf(tt.expr)
+
+			// Then it is rejected with a pointed message
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package conditions
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/shaneisley/patience/pkg/patterns"
+)
+
+// Names PatternMetrics reports each configured pattern under, after the flags
+// that set them
+const (
+	MetricsSuccessPattern     = "success-pattern"
+	MetricsFailurePattern     = "failure-pattern"
+	MetricsStdoutAllowPattern = "success-if-stdout-matches"
+	MetricsStderrAllowPattern = "success-if-stderr-matches"
+)
+
+// timedMatch runs match under the checker's match timeout and records its
+// outcome and duration in m. A match still running at the timeout is
+// abandoned: it reports timedOut and counts as an error.
+func (c *Checker) timedMatch(m *patterns.MatchMetrics, match func() bool) (matched, timedOut bool) {
+	start := time.Now()
+	if c.matchTimeout > 0 {
+		matched, timedOut = matchWithin(c.matchTimeout, match)
+	} else {
+		matched = match()
+	}
+	now := time.Now()
+
+	m.TotalMatches++
+	switch {
+	case timedOut:
+		m.ErrorCount++
+	case matched:
+		m.SuccessfulMatches++
+	default:
+		m.FailedMatches++
+	}
+	m.TotalMatchTime += now.Sub(start)
+	m.AverageMatchTime = m.TotalMatchTime / time.Duration(m.TotalMatches)
+	m.LastMatchTime = now
+	return matched, timedOut
+}
+
+// PatternMetrics returns the match counts and timings accumulated by each
+// configured pattern, keyed by the Metrics* names, for profiling expensive
+// regular expressions
+func (c *Checker) PatternMetrics() map[string]patterns.MatchMetrics {
+	result := make(map[string]patterns.MatchMetrics)
+	add := func(name string, re *regexp.Regexp, m patterns.MatchMetrics) {
+		if re != nil {
+			result[name] = m
+		}
+	}
+	add(MetricsSuccessPattern, c.successPattern, c.successMetrics)
+	add(MetricsFailurePattern, c.failurePattern, c.failureMetrics)
+	add(MetricsStdoutAllowPattern, c.stdoutAllowPattern, c.stdoutAllowMetrics)
+	add(MetricsStderrAllowPattern, c.stderrAllowPattern, c.stderrAllowMetrics)
+	return result
+}
@@ -0,0 +1,42 @@
+package conditions
+
+import (
+	"fmt"
+	"time"
+)
+
+// SetMatchTimeout bounds how long a single pattern match may run. A match
+// that takes longer fails the attempt with a "timed out" reason instead of
+// holding up the run; 0 (the default) lets matches run to completion.
+func (c *Checker) SetMatchTimeout(timeout time.Duration) error {
+	if timeout < 0 {
+		return fmt.Errorf("regex timeout must be non-negative, got %v", timeout)
+	}
+	c.matchTimeout = timeout
+	return nil
+}
+
+// matchWithin runs match in its own goroutine and waits at most timeout for
+// it. Go cannot stop a running match, so one that times out is left to finish
+// in the background and its result is discarded.
+func matchWithin(timeout time.Duration, match func() bool) (matched, timedOut bool) {
+	done := make(chan bool, 1)
+	go func() { done <- match() }()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case matched = <-done:
+		return matched, false
+	case <-timer.C:
+		return false, true
+	}
+}
+
+// timeoutResult fails an attempt whose named pattern exceeded the match timeout
+func (c *Checker) timeoutResult(name string) Result {
+	return Result{
+		Success: false,
+		Reason:  fmt.Sprintf("%s timed out after %v", name, c.matchTimeout),
+	}
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
@@ -66,6 +67,18 @@ func (s ConfigSource) String() string {
 	}
 }
 
+// MarshalText encodes the source by name, such as "config file"
+func (s ConfigSource) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// configKeys lists the configuration keys in display order
+var configKeys = []string{
+	"attempts", "delay", "timeout", "backoff", "max_delay",
+	"multiplier", "success_pattern", "failure_pattern", "case_insensitive",
+	"daemon_enabled", "daemon_socket", "daemon_timeout", "daemon_auto_start",
+}
+
 // ConfigDebugInfo holds debugging information about configuration resolution
 type ConfigDebugInfo struct {
 	Sources map[string]ConfigSource
@@ -229,8 +242,54 @@ func LoadWithPrecedence(configFile string, flagConfig *Config, debug bool) (*Con
 	return &config, debugInfo, nil
 }
 
+// StdinPath is the --config value that reads the configuration from standard input
+const StdinPath = "-"
+
+// ConfigTypes are the formats a configuration read from stdin may use
+var ConfigTypes = []string{"toml", "yaml", "yml", "json"}
+
+// ValidConfigType reports whether configType is a supported configuration format
+func ValidConfigType(configType string) bool {
+	for _, valid := range ConfigTypes {
+		if configType == valid {
+			return true
+		}
+	}
+	return false
+}
+
 // LoadWithPrecedenceAndExplicitFlags loads configuration with full precedence support and explicit flag handling
 func LoadWithPrecedenceAndExplicitFlags(configFile string, flagConfig *Config, explicitFields map[string]bool, debug bool) (*Config, *ConfigDebugInfo, error) {
+	var readConfig func(v *viper.Viper) error
+	if configFile != "" {
+		readConfig = func(v *viper.Viper) error {
+			v.SetConfigFile(configFile)
+			v.SetConfigType("toml")
+			return v.ReadInConfig()
+		}
+	}
+	return loadWithExplicitFlags(readConfig, flagConfig, explicitFields, debug)
+}
+
+// LoadReaderWithPrecedenceAndExplicitFlags is LoadWithPrecedenceAndExplicitFlags
+// with the configuration read from r, such as stdin, in the given format
+// (empty means TOML)
+func LoadReaderWithPrecedenceAndExplicitFlags(r io.Reader, configType string, flagConfig *Config, explicitFields map[string]bool, debug bool) (*Config, *ConfigDebugInfo, error) {
+	if configType == "" {
+		configType = "toml"
+	}
+	if !ValidConfigType(configType) {
+		return nil, nil, fmt.Errorf("unsupported config type %q (valid: %s)", configType, strings.Join(ConfigTypes, ", "))
+	}
+	return loadWithExplicitFlags(func(v *viper.Viper) error {
+		v.SetConfigType(configType)
+		return v.ReadConfig(r)
+	}, flagConfig, explicitFields, debug)
+}
+
+// loadWithExplicitFlags layers defaults, the configuration read by readConfig
+// (nil for none), environment variables and explicitly set flags
+func loadWithExplicitFlags(readConfig func(v *viper.Viper) error, flagConfig *Config, explicitFields map[string]bool, debug bool) (*Config, *ConfigDebugInfo, error) {
 	var debugInfo *ConfigDebugInfo
 	if debug {
 		debugInfo = &ConfigDebugInfo{
@@ -248,10 +307,8 @@ func LoadWithPrecedenceAndExplicitFlags(configFile string, flagConfig *Config, e
 	}
 
 	// Load config file if specified
-	if configFile != "" {
-		v.SetConfigFile(configFile)
-		v.SetConfigType("toml")
-		if err := v.ReadInConfig(); err != nil {
+	if readConfig != nil {
+		if err := readConfig(v); err != nil {
 			return nil, debugInfo, fmt.Errorf("failed to read config file: %w", err)
 		}
 		if debug {
@@ -430,12 +487,13 @@ func (c *Config) MergeWithExplicitFlags(flags *Config, explicitFields map[string
 	return &result
 }
 
+// ConfigFileNames lists the file names FindConfigFile looks for, in order
+var ConfigFileNames = []string{".patience.toml", "patience.toml", ".patience.yaml", "patience.yaml"}
+
 // FindConfigFile searches for a configuration file in the given directory
-// It looks for .patience.toml, patience.toml, .patience.yaml, patience.yaml files
+// Returns the path to the first config file found, or empty string if none found
 func FindConfigFile(dir string) string {
-	configNames := []string{".patience.toml", "patience.toml", ".patience.yaml", "patience.yaml"}
-
-	for _, name := range configNames {
+	for _, name := range ConfigFileNames {
 		configPath := filepath.Join(dir, name)
 		if _, err := os.Stat(configPath); err == nil {
 			return configPath
@@ -445,6 +503,27 @@ func FindConfigFile(dir string) string {
 	return ""
 }
 
+// DiscoverConfigFile searches cwd and then homeDir for a configuration file,
+// returning the first one found and every path that was checked, in order.
+// An empty directory is skipped, so callers can pass "" when one is unknown.
+func DiscoverConfigFile(cwd, homeDir string) (string, []string) {
+	var searched []string
+	for _, dir := range []string{cwd, homeDir} {
+		if dir == "" {
+			continue
+		}
+		for _, name := range ConfigFileNames {
+			configPath := filepath.Join(dir, name)
+			searched = append(searched, configPath)
+			if _, err := os.Stat(configPath); err == nil {
+				return configPath, searched
+			}
+		}
+	}
+
+	return "", searched
+}
+
 // validateCombinations validates flag combinations and returns validation errors
 func (c *Config) validateCombinations() []ValidationError {
 	var errors []ValidationError
@@ -507,12 +586,12 @@ func (c *Config) validateCombinations() []ValidationError {
 func (c *Config) Validate() error {
 	var errors []ValidationError
 
-	// Validate attempts
-	if c.Attempts < 1 || c.Attempts > 1000 {
+	// Validate attempts; 0 is unlimited, which the command bounds by time or cost
+	if c.Attempts < 0 || c.Attempts > 1000 {
 		errors = append(errors, ValidationError{
 			Field:   "attempts",
 			Value:   c.Attempts,
-			Message: "must be between 1 and 1000",
+			Message: "must be between 1 and 1000, or 0 for unlimited",
 		})
 	}
 
@@ -549,7 +628,7 @@ func (c *Config) Validate() error {
 	}
 
 	// Validate backoff type
-	validBackoffTypes := []string{"fixed", "exponential", "jitter", "linear", "decorrelated-jitter", "fibonacci", "adaptive", "polynomial", "http-aware"}
+	validBackoffTypes := []string{"fixed", "exponential", "jitter", "linear", "decorrelated-jitter", "fibonacci", "adaptive", "polynomial", "http-aware", "pid"}
 	if c.BackoffType != "" {
 		isValid := false
 		for _, validType := range validBackoffTypes {
@@ -562,7 +641,7 @@ func (c *Config) Validate() error {
 			errors = append(errors, ValidationError{
 				Field:   "backoff",
 				Value:   c.BackoffType,
-				Message: "must be one of: fixed, exponential, jitter, linear, decorrelated-jitter, fibonacci, adaptive, polynomial, http-aware",
+				Message: "must be one of: fixed, exponential, jitter, linear, decorrelated-jitter, fibonacci, adaptive, polynomial, http-aware, pid",
 			})
 		}
 	}
@@ -654,14 +733,8 @@ func recordDefaults(debug *ConfigDebugInfo) {
 
 // recordConfigFile records config file values in debug info
 func recordConfigFile(debug *ConfigDebugInfo, v *viper.Viper) {
-	configKeys := []string{
-		"attempts", "delay", "timeout", "backoff", "max_delay",
-		"multiplier", "success_pattern", "failure_pattern", "case_insensitive",
-		"daemon_enabled", "daemon_socket", "daemon_timeout", "daemon_auto_start",
-	}
-
 	for _, key := range configKeys {
-		if v.IsSet(key) {
+		if v.InConfig(key) {
 			debug.Sources[key] = SourceConfigFile
 			debug.Values[key] = v.Get(key)
 		}
@@ -786,12 +859,6 @@ func (debug *ConfigDebugInfo) PrintDebugInfo() {
 
 	logger.Info("Configuration Resolution Debug Info")
 
-	configKeys := []string{
-		"attempts", "delay", "timeout", "backoff", "max_delay",
-		"multiplier", "success_pattern", "failure_pattern", "case_insensitive",
-		"daemon_enabled", "daemon_socket", "daemon_timeout", "daemon_auto_start",
-	}
-
 	for _, key := range configKeys {
 		source := debug.Sources[key]
 		value := debug.Values[key]
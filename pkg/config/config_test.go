@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -190,6 +191,66 @@ func TestConfig_FindConfigFileNotFound(t *testing.T) {
 	assert.Equal(t, "", found)
 }
 
+func TestConfig_DiscoverConfigFile(t *testing.T) {
+	t.Run("file in cwd", func(t *testing.T) {
+		// Given config files in both the working and home directories
+		cwd, home := t.TempDir(), t.TempDir()
+		cwdConfig := filepath.Join(cwd, "patience.toml")
+		require.NoError(t, os.WriteFile(cwdConfig, []byte("attempts = 5"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(home, ".patience.toml"), []byte("attempts = 3"), 0644))
+
+		// When discovering the config file
+		found, searched := DiscoverConfigFile(cwd, home)
+
+		// Then the working directory should win, stopping the search there
+		assert.Equal(t, cwdConfig, found)
+		assert.Equal(t, []string{filepath.Join(cwd, ".patience.toml"), cwdConfig}, searched)
+	})
+
+	t.Run("file in home", func(t *testing.T) {
+		// Given a config file only in the home directory
+		cwd, home := t.TempDir(), t.TempDir()
+		homeConfig := filepath.Join(home, ".patience.yaml")
+		require.NoError(t, os.WriteFile(homeConfig, []byte("attempts: 5"), 0644))
+
+		// When discovering the config file
+		found, searched := DiscoverConfigFile(cwd, home)
+
+		// Then the home directory should be used as a fallback
+		assert.Equal(t, homeConfig, found)
+		assert.Len(t, searched, len(ConfigFileNames)+3)
+	})
+
+	t.Run("none present", func(t *testing.T) {
+		// Given no config files anywhere
+		cwd, home := t.TempDir(), t.TempDir()
+
+		// When discovering the config file
+		found, searched := DiscoverConfigFile(cwd, home)
+
+		// Then nothing is found and every candidate is listed in search order
+		assert.Equal(t, "", found)
+		var expected []string
+		for _, dir := range []string{cwd, home} {
+			for _, name := range ConfigFileNames {
+				expected = append(expected, filepath.Join(dir, name))
+			}
+		}
+		assert.Equal(t, expected, searched)
+	})
+
+	t.Run("unknown home directory", func(t *testing.T) {
+		// Given no home directory
+		cwd := t.TempDir()
+
+		// When discovering the config file
+		_, searched := DiscoverConfigFile(cwd, "")
+
+		// Then only the working directory is searched
+		assert.Len(t, searched, len(ConfigFileNames))
+	})
+}
+
 func TestConfig_Validate(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -213,12 +274,14 @@ func TestConfig_Validate(t *testing.T) {
 			expectError: false,
 		},
 		{
-			name: "invalid attempts - zero",
+			name: "zero attempts - unlimited",
 			config: Config{
-				Attempts: 0,
+				Attempts:    0,
+				Delay:       time.Second,
+				BackoffType: "exponential",
+				Multiplier:  2.0,
 			},
-			expectError: true,
-			errorMsg:    "must be between 1 and 1000",
+			expectError: false,
 		},
 		{
 			name: "invalid attempts - too high",
@@ -507,6 +570,33 @@ backoff = "exponential"
 	assert.Equal(t, SourceConfigFile, debugInfo.Sources["backoff"])
 }
 
+func TestConfig_LoadReaderWithPrecedence(t *testing.T) {
+	// Given the same settings in each supported format
+	inputs := map[string]string{
+		"":     "attempts = 4\ntimeout = \"5s\"\n",
+		"toml": "attempts = 4\ntimeout = \"5s\"\n",
+		"yaml": "attempts: 4\ntimeout: 5s\n",
+		"json": `{"attempts": 4, "timeout": "5s"}`,
+	}
+
+	for configType, content := range inputs {
+		t.Run("type "+configType, func(t *testing.T) {
+			// When loading from a reader with an explicit timeout flag
+			config, _, err := LoadReaderWithPrecedenceAndExplicitFlags(strings.NewReader(content), configType,
+				&Config{Timeout: time.Second}, map[string]bool{"timeout": true}, false)
+
+			// Then the reader's values apply below explicit flags
+			require.NoError(t, err)
+			assert.Equal(t, 4, config.Attempts)
+			assert.Equal(t, time.Second, config.Timeout)
+		})
+	}
+
+	// And unsupported formats are rejected
+	_, _, err := LoadReaderWithPrecedenceAndExplicitFlags(strings.NewReader(""), "xml", nil, nil, false)
+	assert.ErrorContains(t, err, `unsupported config type "xml"`)
+}
+
 func TestConfig_LoadWithPrecedence_ValidationError(t *testing.T) {
 	// Create config with invalid values
 	flagConfig := &Config{
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// FieldDiff describes a configuration key that resolves to different values
+// in two configurations, and where each value came from
+type FieldDiff struct {
+	Key        string       `json:"key"`
+	From       string       `json:"from"`
+	FromSource ConfigSource `json:"from_source"`
+	To         string       `json:"to"`
+	ToSource   ConfigSource `json:"to_source"`
+}
+
+// Values returns the resolved value of each configuration key
+func (c *Config) Values() map[string]interface{} {
+	return map[string]interface{}{
+		"attempts":          c.Attempts,
+		"delay":             c.Delay,
+		"timeout":           c.Timeout,
+		"backoff":           c.BackoffType,
+		"max_delay":         c.MaxDelay,
+		"multiplier":        c.Multiplier,
+		"success_pattern":   c.SuccessPattern,
+		"failure_pattern":   c.FailurePattern,
+		"case_insensitive":  c.CaseInsensitive,
+		"daemon_enabled":    c.DaemonEnabled,
+		"daemon_socket":     c.DaemonSocket,
+		"daemon_timeout":    c.DaemonTimeout,
+		"daemon_auto_start": c.DaemonAutoStart,
+	}
+}
+
+// Diff compares two resolved configurations key by key and returns the keys
+// whose values differ, in display order. The sources come from the
+// ConfigDebugInfo of each resolution; a nil info reports every value as a
+// default.
+func Diff(from *Config, fromInfo *ConfigDebugInfo, to *Config, toInfo *ConfigDebugInfo) []FieldDiff {
+	fromValues, toValues := from.Values(), to.Values()
+
+	var diffs []FieldDiff
+	for _, key := range configKeys {
+		fromValue, toValue := formatValue(fromValues[key]), formatValue(toValues[key])
+		if fromValue == toValue {
+			continue
+		}
+		diffs = append(diffs, FieldDiff{
+			Key:        key,
+			From:       fromValue,
+			FromSource: fromInfo.source(key),
+			To:         toValue,
+			ToSource:   toInfo.source(key),
+		})
+	}
+	return diffs
+}
+
+// source returns where key's value came from, or SourceDefault when unknown
+func (debug *ConfigDebugInfo) source(key string) ConfigSource {
+	if debug == nil {
+		return SourceDefault
+	}
+	return debug.Sources[key]
+}
+
+// formatValue renders a configuration value for display, quoting strings so
+// an empty pattern is visible
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return fmt.Sprintf("%q", v)
+	case time.Duration:
+		return v.String()
+	}
+	return fmt.Sprint(value)
+}
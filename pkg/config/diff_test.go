@@ -0,0 +1,39 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff_FlagsOverConfigFile(t *testing.T) {
+	// Given a config file setting attempts and timeout
+	configPath := filepath.Join(t.TempDir(), "patience.toml")
+	require.NoError(t, os.WriteFile(configPath, []byte("attempts = 5\ntimeout = \"10s\"\n"), 0o644))
+
+	// When it is resolved without flags and with attempts and a success pattern set
+	from, fromInfo, err := LoadWithPrecedenceAndExplicitFlags(configPath, nil, nil, true)
+	require.NoError(t, err)
+	flags := &Config{Attempts: 8, SuccessPattern: "deployed"}
+	explicit := map[string]bool{"attempts": true, "success_pattern": true}
+	to, toInfo, err := LoadWithPrecedenceAndExplicitFlags(configPath, flags, explicit, true)
+	require.NoError(t, err)
+
+	// Then only the flagged fields differ, each with both origins
+	assert.Equal(t, []FieldDiff{
+		{Key: "attempts", From: "5", FromSource: SourceConfigFile, To: "8", ToSource: SourceCLIFlag},
+		{Key: "success_pattern", From: `""`, FromSource: SourceDefault, To: `"deployed"`, ToSource: SourceCLIFlag},
+	}, Diff(from, fromInfo, to, toInfo))
+}
+
+func TestDiff_IdenticalConfigs(t *testing.T) {
+	// Given the same configuration twice
+	config := &Config{Attempts: 3, Timeout: 5 * time.Second}
+
+	// Then there is nothing to report
+	assert.Empty(t, Diff(config, nil, config, nil))
+}
@@ -199,6 +199,68 @@ func (c *DaemonClient) RegisterScheduledRequests(ctx context.Context, requests [
 	return nil
 }
 
+// CheckHalt asks the daemon whether retries for resourceID have been halted,
+// returning the operator's reason if so
+func (c *DaemonClient) CheckHalt(ctx context.Context, resourceID string) (bool, string, error) {
+	select {
+	case <-ctx.Done():
+		return false, "", ctx.Err()
+	default:
+	}
+
+	if err := c.connect(); err != nil {
+		return false, "", err
+	}
+
+	response, err := c.sendRequest(HaltCheckRequestJSON{Type: "halt_check", ResourceID: resourceID})
+	if err != nil {
+		return false, "", fmt.Errorf("failed to send halt check: %w", err)
+	}
+	if message, ok := response["error"].(string); ok {
+		return false, "", fmt.Errorf("halt check failed: %s", message)
+	}
+
+	halted, _ := response["halted"].(bool)
+	reason, _ := response["reason"].(string)
+	return halted, reason, nil
+}
+
+// Halt tells the daemon to stop retries for resourceID; token must match the
+// daemon's halt token
+func (c *DaemonClient) Halt(ctx context.Context, resourceID, reason, token string) error {
+	return c.sendHaltRequest(ctx, HaltRequestJSON{Type: "halt", ResourceID: resourceID, Reason: reason, Token: token})
+}
+
+// Resume clears a halt set with Halt
+func (c *DaemonClient) Resume(ctx context.Context, resourceID, token string) error {
+	return c.sendHaltRequest(ctx, HaltRequestJSON{Type: "resume", ResourceID: resourceID, Token: token})
+}
+
+// sendHaltRequest sends a halt or resume request and checks it succeeded
+func (c *DaemonClient) sendHaltRequest(ctx context.Context, req HaltRequestJSON) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	if err := c.connect(); err != nil {
+		return err
+	}
+
+	response, err := c.sendRequest(req)
+	if err != nil {
+		return fmt.Errorf("failed to send %s request: %w", req.Type, err)
+	}
+	if success, ok := response["success"].(bool); !ok || !success {
+		if message, ok := response["message"].(string); ok {
+			return fmt.Errorf("%s request failed: %s", req.Type, message)
+		}
+		return fmt.Errorf("%s request failed", req.Type)
+	}
+	return nil
+}
+
 // Close closes the client connection
 func (c *DaemonClient) Close() error {
 	c.mu.Lock()
@@ -29,6 +29,7 @@ type Daemon struct {
 	wg            sync.WaitGroup
 	connectionSem chan struct{}
 	workerPool    *WorkerPool
+	profiling     *profilingServer
 }
 
 // Config holds daemon configuration
@@ -42,6 +43,23 @@ type Config struct {
 	EnableHTTP      bool          `json:"enable_http"`
 	EnableProfiling bool          `json:"enable_profiling"`
 	MaxConnections  int           `json:"max_connections"`
+
+	// ProfilingToken must be sent as "Authorization: Bearer <token>" to reach
+	// the pprof endpoints; it is required when EnableProfiling is set
+	ProfilingToken string `json:"profiling_token,omitempty"`
+	// ProfilingAddr serves pprof on its own address (e.g. 127.0.0.1:6060)
+	// instead of the HTTP API port
+	ProfilingAddr string `json:"profiling_addr,omitempty"`
+
+	// PersistPath, when set, is where stored metrics are snapshotted so they
+	// survive restarts; the snapshot is loaded on start
+	PersistPath string `json:"persist_path,omitempty"`
+	// PersistInterval is how often the snapshot is rewritten while running
+	PersistInterval time.Duration `json:"persist_interval,omitempty"`
+
+	// HaltToken authorizes halt and resume requests on the socket; halting is
+	// disabled without one
+	HaltToken string `json:"halt_token,omitempty"`
 }
 
 // DefaultConfig returns a default daemon configuration
@@ -56,6 +74,7 @@ func DefaultConfig() *Config {
 		EnableHTTP:      true,
 		EnableProfiling: false,
 		MaxConnections:  100,
+		PersistInterval: time.Minute,
 	}
 }
 
@@ -73,6 +92,16 @@ func NewDaemon(config *Config) (*Daemon, error) {
 		config.MaxConnections = 10000 // Reasonable upper limit
 	}
 
+	// Profiling is never served unauthenticated
+	if config.EnableProfiling {
+		if config.ProfilingToken == "" {
+			return nil, fmt.Errorf("profiling requires a profiling token")
+		}
+		if config.ProfilingAddr == "" && !config.EnableHTTP {
+			return nil, fmt.Errorf("profiling requires the HTTP server or a profiling address")
+		}
+	}
+
 	// Create context for graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -82,8 +111,19 @@ func NewDaemon(config *Config) (*Daemon, error) {
 	// Create metrics storage
 	metricsStorage := storage.NewMetricsStorage(config.MaxMetrics, config.MetricsMaxAge)
 
+	// Restore metrics persisted by a previous run
+	if config.PersistPath != "" {
+		loaded, err := metricsStorage.LoadSnapshot(config.PersistPath)
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("failed to load persisted metrics: %w", err)
+		}
+		logger.Info("loaded persisted metrics", "path", config.PersistPath, "count", loaded)
+	}
+
 	// Create worker pool for handling connections
 	workerPool := NewWorkerPool(config.MaxConnections, metricsStorage, logger)
+	workerPool.protocol.SetHaltToken(config.HaltToken)
 
 	daemon := &Daemon{
 		config:        config,
@@ -131,9 +171,17 @@ func (d *Daemon) Start() error {
 	// Start worker pool
 	d.workerPool.Start()
 
-	// Start HTTP server if enabled
+	// Start HTTP server if enabled, binding synchronously so readiness is accurate
 	if d.config.EnableHTTP {
 		d.server = NewServer(d.storage, d.config.HTTPPort, d.logger)
+		if d.config.EnableProfiling && d.config.ProfilingAddr == "" {
+			d.server.EnableProfiling(d.config.ProfilingToken)
+		}
+		if err := d.server.Listen(); err != nil {
+			d.listener.Close()
+			d.workerPool.Stop()
+			return err
+		}
 		d.wg.Add(1)
 		go func() {
 			defer d.wg.Done()
@@ -143,6 +191,18 @@ func (d *Daemon) Start() error {
 		}()
 	}
 
+	// Serve profiling on its own address when one is configured
+	if d.config.EnableProfiling && d.config.ProfilingAddr != "" {
+		profiling, err := startProfilingServer(d.config.ProfilingAddr, d.config.ProfilingToken, d.logger)
+		if err != nil {
+			d.cancel() // stops the HTTP server, if started
+			d.listener.Close()
+			d.workerPool.Stop()
+			return err
+		}
+		d.profiling = profiling
+	}
+
 	// Start socket server
 	d.wg.Add(1)
 	go func() {
@@ -150,9 +210,23 @@ func (d *Daemon) Start() error {
 		d.handleConnections()
 	}()
 
+	// Periodically persist metrics if enabled
+	if d.config.PersistPath != "" {
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			d.persistLoop()
+		}()
+	}
+
 	// Setup signal handling
 	d.setupSignalHandling()
 
+	// Both listeners are bound; report ready
+	if d.server != nil {
+		d.server.SetReady(true)
+	}
+
 	d.logger.Info("daemon started successfully")
 	return nil
 }
@@ -161,6 +235,11 @@ func (d *Daemon) Start() error {
 func (d *Daemon) Stop() error {
 	d.logger.Info("stopping daemon")
 
+	// Stop reporting ready before tearing anything down
+	if d.server != nil {
+		d.server.SetReady(false)
+	}
+
 	// Cancel context to signal shutdown
 	d.cancel()
 
@@ -178,10 +257,16 @@ func (d *Daemon) Stop() error {
 	if d.server != nil {
 		d.server.Stop()
 	}
+	if d.profiling != nil {
+		d.profiling.Stop()
+	}
 
 	// Wait for all goroutines to finish
 	d.wg.Wait()
 
+	// Persist everything received before shutdown
+	d.persistMetrics()
+
 	// Cleanup
 	d.cleanupSocket()
 	d.removePidFile()
@@ -195,6 +280,35 @@ func (d *Daemon) Wait() {
 	d.wg.Wait()
 }
 
+// persistLoop snapshots metrics every PersistInterval until shutdown
+func (d *Daemon) persistLoop() {
+	interval := d.config.PersistInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-ticker.C:
+			d.persistMetrics()
+		}
+	}
+}
+
+// persistMetrics writes a snapshot of stored metrics if persistence is enabled
+func (d *Daemon) persistMetrics() {
+	if d.config.PersistPath == "" {
+		return
+	}
+	if err := d.storage.SaveSnapshot(d.config.PersistPath); err != nil {
+		d.logger.Error("failed to persist metrics", "path", d.config.PersistPath, "error", err)
+	}
+}
+
 // handleConnections handles incoming socket connections
 func (d *Daemon) handleConnections() {
 	for {
@@ -242,9 +356,15 @@ func (d *Daemon) handleConnection(conn net.Conn) {
 		return
 	}
 
-	// Parse metrics
+	// Decompress if needed, then parse metrics
+	payload, err := metrics.DecodePayload(data)
+	if err != nil {
+		d.logger.Error("error decoding metrics", "error", err)
+		return
+	}
+
 	var runMetrics metrics.RunMetrics
-	if err := json.Unmarshal(data, &runMetrics); err != nil {
+	if err := json.Unmarshal(payload, &runMetrics); err != nil {
 		d.logger.Error("error parsing metrics", "error", err)
 		return
 	}
@@ -369,5 +489,8 @@ func (d *Daemon) Close() error {
 	// Wait for all goroutines to finish
 	d.wg.Wait()
 
+	// Persist everything received before shutdown
+	d.persistMetrics()
+
 	return nil
 }
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"testing"
@@ -320,6 +322,211 @@ func TestDaemon_GracefulShutdown(t *testing.T) {
 	assert.True(t, os.IsNotExist(statErr), "Socket file should be cleaned up after shutdown")
 }
 
+func TestDaemon_ReadinessEndpoints(t *testing.T) {
+	// Given a daemon with HTTP enabled on a random port
+	tmpDir := t.TempDir()
+	config := &Config{
+		SocketPath:    filepath.Join(tmpDir, "test-daemon.sock"),
+		HTTPPort:      0,
+		MaxMetrics:    100,
+		MetricsMaxAge: time.Hour,
+		LogLevel:      "error",
+		PidFile:       filepath.Join(tmpDir, "test-daemon.pid"),
+		EnableHTTP:    true,
+	}
+
+	daemon, err := NewDaemon(config)
+	require.NoError(t, err)
+
+	// When the daemon has started
+	require.NoError(t, daemon.Start())
+	addr := daemon.server.Addr()
+	require.NotNil(t, addr, "HTTP listener should be bound once Start returns")
+
+	// Then /healthz and /readyz should respond 200 over HTTP
+	var readyCode, healthCode int
+	require.Eventually(t, func() bool {
+		ready, err := http.Get(fmt.Sprintf("http://%s/readyz", addr))
+		if err != nil {
+			return false
+		}
+		ready.Body.Close()
+		health, err := http.Get(fmt.Sprintf("http://%s/healthz", addr))
+		if err != nil {
+			return false
+		}
+		health.Body.Close()
+		readyCode, healthCode = ready.StatusCode, health.StatusCode
+		return true
+	}, 2*time.Second, 20*time.Millisecond)
+	assert.Equal(t, http.StatusOK, readyCode)
+	assert.Equal(t, http.StatusOK, healthCode)
+
+	// When the daemon shuts down gracefully
+	require.NoError(t, daemon.Stop())
+
+	// Then readiness should have flipped to unavailable
+	w := httptest.NewRecorder()
+	daemon.server.handleReadyz(w, httptest.NewRequest("GET", "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestDaemon_ReceivesTaggedMetrics(t *testing.T) {
+	// Given a running daemon
+	tmpDir := t.TempDir()
+	socketPath := "/tmp/test-daemon-tags.sock"
+	os.Remove(socketPath)
+	defer os.Remove(socketPath)
+
+	config := &Config{
+		SocketPath:    socketPath,
+		HTTPPort:      0,
+		MaxMetrics:    100,
+		MetricsMaxAge: time.Hour,
+		LogLevel:      "info",
+		PidFile:       filepath.Join(tmpDir, "test-daemon.pid"),
+		EnableHTTP:    false,
+	}
+
+	daemon, err := NewDaemon(config)
+	require.NoError(t, err)
+	require.NoError(t, daemon.Start())
+	defer daemon.Stop()
+
+	// When a client sends tagged run metrics
+	runMetrics := createTestRunMetrics("deploy", true, 1.0, 1)
+	runMetrics.Tags = map[string]string{"env": "prod", "team": "payments"}
+	require.NoError(t, metrics.NewClient(socketPath).SendMetrics(runMetrics))
+
+	var recent []storage.StoredMetric
+	for i := 0; i < 20; i++ {
+		time.Sleep(25 * time.Millisecond)
+		recent = daemon.storage.GetRecent(1)
+		if len(recent) > 0 {
+			break
+		}
+	}
+
+	// Then the tags should be stored and usable as aggregate filters
+	require.Len(t, recent, 1)
+	assert.Equal(t, runMetrics.Tags, recent[0].Metrics.Tags)
+
+	stats := daemon.storage.GetAggregatedStatsWithTags(time.Now().Add(-time.Hour), time.Now().Add(time.Hour),
+		map[string]string{"team": "payments"})
+	assert.Equal(t, 1, stats.TotalRuns)
+}
+
+func TestDaemon_ReceivesCustomMetrics(t *testing.T) {
+	// Given a running daemon
+	tmpDir := t.TempDir()
+	socketPath := "/tmp/test-daemon-custom-metrics.sock"
+	os.Remove(socketPath)
+	defer os.Remove(socketPath)
+
+	config := &Config{
+		SocketPath:    socketPath,
+		HTTPPort:      0,
+		MaxMetrics:    100,
+		MetricsMaxAge: time.Hour,
+		LogLevel:      "info",
+		PidFile:       filepath.Join(tmpDir, "test-daemon.pid"),
+		EnableHTTP:    false,
+	}
+
+	daemon, err := NewDaemon(config)
+	require.NoError(t, err)
+	require.NoError(t, daemon.Start())
+	defer daemon.Stop()
+
+	// When a client sends run metrics with custom numeric fields
+	runMetrics := createTestRunMetrics("build", true, 1.0, 1)
+	runMetrics.CustomMetrics = map[string]float64{"build_number": 1234, "artifact_mb": 12.5}
+	require.NoError(t, metrics.NewClient(socketPath).SendMetrics(runMetrics))
+
+	var recent []storage.StoredMetric
+	for i := 0; i < 20; i++ {
+		time.Sleep(25 * time.Millisecond)
+		recent = daemon.storage.GetRecent(1)
+		if len(recent) > 0 {
+			break
+		}
+	}
+
+	// Then the stored record should carry them and the stats summarize them
+	require.Len(t, recent, 1)
+	assert.Equal(t, runMetrics.CustomMetrics, recent[0].Metrics.CustomMetrics)
+
+	stats := daemon.storage.GetAggregatedStats(time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	require.Contains(t, stats.MetricBreakdown, "build_number")
+	assert.Equal(t, 1234.0, stats.MetricBreakdown["build_number"].Max)
+}
+
+func TestDaemon_ReceivesCompressedAndLegacyMetrics(t *testing.T) {
+	// Given a running daemon
+	tmpDir := t.TempDir()
+	socketPath := "/tmp/test-daemon-gzip.sock"
+	os.Remove(socketPath)
+	defer os.Remove(socketPath)
+
+	config := &Config{
+		SocketPath:    socketPath,
+		HTTPPort:      0,
+		MaxMetrics:    100,
+		MetricsMaxAge: time.Hour,
+		LogLevel:      "info",
+		PidFile:       filepath.Join(tmpDir, "test-daemon.pid"),
+		EnableHTTP:    false,
+	}
+
+	daemon, err := NewDaemon(config)
+	require.NoError(t, err)
+	require.NoError(t, daemon.Start())
+	defer daemon.Stop()
+
+	// And a run with enough attempts to be compressed on the wire
+	large := createTestRunMetrics("large-run", true, 50.0, 500)
+	large.Tags = map[string]string{"env": "prod"}
+	data, err := json.Marshal(large)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(data), metrics.CompressThreshold)
+
+	waitForStored := func(count int) []storage.StoredMetric {
+		var recent []storage.StoredMetric
+		for i := 0; i < 40; i++ {
+			time.Sleep(25 * time.Millisecond)
+			recent = daemon.storage.GetRecent(count)
+			if len(recent) >= count {
+				break
+			}
+		}
+		return recent
+	}
+
+	// When a current client sends it compressed
+	require.NoError(t, metrics.NewClient(socketPath).SendMetrics(large))
+	recent := waitForStored(1)
+
+	// Then the daemon should decode it identically
+	require.Len(t, recent, 1)
+	stored, err := json.Marshal(recent[0].Metrics)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(data), string(stored))
+
+	// When an older client sends the same metrics as bare JSON
+	conn, err := net.Dial("unix", socketPath)
+	require.NoError(t, err)
+	_, err = conn.Write(data)
+	require.NoError(t, err)
+	conn.Close()
+	recent = waitForStored(2)
+
+	// Then it should still be accepted
+	require.Len(t, recent, 2)
+	stored, err = json.Marshal(recent[0].Metrics)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(data), string(stored))
+}
+
 // createTestRunMetrics creates a test RunMetrics instance
 func createTestRunMetrics(command string, success bool, durationSeconds float64, attemptCount int) *metrics.RunMetrics {
 	attempts := make([]metrics.AttemptMetric, attemptCount)
@@ -360,3 +567,102 @@ func createTestRunMetrics(command string, success bool, durationSeconds float64,
 		Timestamp: time.Now().Unix(),
 	}
 }
+
+func TestDaemon_PersistsMetricsAcrossRestarts(t *testing.T) {
+	// Given a daemon with persistence enabled
+	tmpDir := t.TempDir()
+	socketPath := "/tmp/test-daemon-persist.sock"
+	os.Remove(socketPath)
+	defer os.Remove(socketPath)
+
+	newConfig := func() *Config {
+		return &Config{
+			SocketPath:      socketPath,
+			MaxMetrics:      100,
+			MetricsMaxAge:   time.Hour,
+			LogLevel:        "error",
+			PidFile:         filepath.Join(tmpDir, "test-daemon.pid"),
+			PersistPath:     filepath.Join(tmpDir, "metrics.json"),
+			PersistInterval: time.Hour,
+		}
+	}
+
+	first, err := NewDaemon(newConfig())
+	require.NoError(t, err)
+	require.NoError(t, first.Start())
+
+	// When metrics are posted and the daemon restarts
+	client := metrics.NewClient(socketPath)
+	require.NoError(t, client.SendMetrics(createTestRunMetrics("deploy", true, 1.0, 1)))
+	require.NoError(t, client.SendMetrics(createTestRunMetrics("deploy", false, 2.0, 3)))
+	for i := 0; i < 40 && len(first.storage.GetRecent(0)) < 2; i++ {
+		time.Sleep(25 * time.Millisecond)
+	}
+	require.NoError(t, first.Stop())
+
+	second, err := NewDaemon(newConfig())
+	require.NoError(t, err)
+
+	// Then the aggregates should survive the restart
+	stats := second.storage.GetAggregatedStats(time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	assert.Equal(t, 2, stats.TotalRuns)
+	assert.Equal(t, 1, stats.SuccessfulRuns)
+	assert.Equal(t, 1, stats.FailedRuns)
+}
+
+func TestDaemon_ProfilingConfigValidation(t *testing.T) {
+	// Given profiling enabled without a token
+	config := DefaultConfig()
+	config.EnableProfiling = true
+
+	// Then the daemon should refuse to start it unauthenticated
+	_, err := NewDaemon(config)
+	assert.ErrorContains(t, err, "profiling requires a profiling token")
+
+	// And profiling needs somewhere to be served
+	config.ProfilingToken = "s3cret"
+	config.EnableHTTP = false
+	_, err = NewDaemon(config)
+	assert.ErrorContains(t, err, "profiling requires the HTTP server or a profiling address")
+}
+
+func TestDaemon_ProfilingOnSeparateAddress(t *testing.T) {
+	// Given a daemon serving profiling on its own address
+	tmpDir := t.TempDir()
+	config := &Config{
+		SocketPath:      filepath.Join(tmpDir, "test-daemon.sock"),
+		HTTPPort:        0,
+		MaxMetrics:      100,
+		MetricsMaxAge:   time.Hour,
+		LogLevel:        "error",
+		PidFile:         filepath.Join(tmpDir, "test-daemon.pid"),
+		EnableHTTP:      true,
+		EnableProfiling: true,
+		ProfilingToken:  "s3cret",
+		ProfilingAddr:   "127.0.0.1:0",
+	}
+	daemon, err := NewDaemon(config)
+	require.NoError(t, err)
+	require.NoError(t, daemon.Start())
+	defer daemon.Stop()
+
+	get := func(addr net.Addr, token string) int {
+		req, err := http.NewRequest("GET", fmt.Sprintf("http://%s/debug/pprof/", addr), nil)
+		require.NoError(t, err)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// Then pprof should require the token on the profiling address
+	require.NotNil(t, daemon.profiling)
+	assert.Equal(t, http.StatusUnauthorized, get(daemon.profiling.Addr(), ""))
+	assert.Equal(t, http.StatusOK, get(daemon.profiling.Addr(), "s3cret"))
+
+	// And it should not be served on the API port
+	assert.Equal(t, http.StatusNotFound, get(daemon.server.Addr(), "s3cret"))
+}
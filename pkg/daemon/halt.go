@@ -0,0 +1,97 @@
+package daemon
+
+import (
+	"crypto/subtle"
+	"sort"
+	"sync"
+)
+
+// HaltRegistry records the resources whose retries have been halted. Executors
+// coordinating with the daemon check it before each attempt and stop when
+// their resource is halted.
+type HaltRegistry struct {
+	mu     sync.RWMutex
+	halted map[string]string // resource ID -> operator's reason
+}
+
+// NewHaltRegistry creates an empty halt registry
+func NewHaltRegistry() *HaltRegistry {
+	return &HaltRegistry{halted: make(map[string]string)}
+}
+
+// Halt marks resourceID as halted; reason is passed on to executors
+func (h *HaltRegistry) Halt(resourceID, reason string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.halted[resourceID] = reason
+}
+
+// Resume clears the halt on resourceID
+func (h *HaltRegistry) Resume(resourceID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.halted, resourceID)
+}
+
+// IsHalted reports whether resourceID is halted, and the reason given
+func (h *HaltRegistry) IsHalted(resourceID string) (bool, string) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	reason, ok := h.halted[resourceID]
+	return ok, reason
+}
+
+// Halted returns the halted resource IDs in sorted order
+func (h *HaltRegistry) Halted() []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	ids := make([]string, 0, len(h.halted))
+	for id := range h.halted {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// HaltCheckRequestJSON asks whether a resource has been halted
+type HaltCheckRequestJSON struct {
+	Type       string `json:"type"`
+	ResourceID string `json:"resource_id"`
+}
+
+// HaltCheckResponseJSON answers a halt check
+type HaltCheckResponseJSON struct {
+	Type   string `json:"type"`
+	Halted bool   `json:"halted"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// HaltRequestJSON halts ("halt") or resumes ("resume") retries for a resource;
+// Token must match the server's halt token
+type HaltRequestJSON struct {
+	Type       string `json:"type"`
+	ResourceID string `json:"resource_id"`
+	Reason     string `json:"reason,omitempty"`
+	Token      string `json:"token"`
+}
+
+// HaltResponseJSON answers a halt or resume request
+type HaltResponseJSON struct {
+	Type    string `json:"type"`
+	Success bool   `json:"success"`
+	Message string `json:"message,omitempty"`
+}
+
+func (m HaltCheckRequestJSON) GetType() string  { return m.Type }
+func (m HaltCheckResponseJSON) GetType() string { return m.Type }
+func (m HaltRequestJSON) GetType() string       { return m.Type }
+func (m HaltResponseJSON) GetType() string      { return m.Type }
+
+// validHaltToken reports whether got matches the configured token in constant
+// time; no token is valid when none is configured
+func validHaltToken(configured, got string) bool {
+	if configured == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(configured), []byte(got)) == 1
+}
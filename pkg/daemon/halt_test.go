@@ -0,0 +1,147 @@
+package daemon
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startHaltServer starts a Unix server with the given halt token on a short
+// socket path and returns a connected client
+func startHaltServer(t *testing.T, token string) (*UnixServer, *DaemonClient) {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "halt")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	socketPath := filepath.Join(dir, "d.sock")
+
+	server := NewUnixServer(socketPath)
+	server.SetHaltToken(token)
+	require.NoError(t, server.Start(context.Background()))
+	t.Cleanup(func() { server.Stop() })
+
+	client := NewDaemonClient(socketPath)
+	t.Cleanup(func() { client.Close() })
+	return server, client
+}
+
+func TestHaltRegistry_HaltAndResume(t *testing.T) {
+	// Given an empty registry
+	registry := NewHaltRegistry()
+
+	// When two resources are halted and one resumed
+	registry.Halt("payments-api", "incident 42")
+	registry.Halt("search-api", "")
+	registry.Resume("search-api")
+
+	// Then only the remaining halt should be reported, with its reason
+	halted, reason := registry.IsHalted("payments-api")
+	assert.True(t, halted)
+	assert.Equal(t, "incident 42", reason)
+	halted, _ = registry.IsHalted("search-api")
+	assert.False(t, halted)
+	assert.Equal(t, []string{"payments-api"}, registry.Halted())
+}
+
+func TestDaemonClient_HaltCheck(t *testing.T) {
+	// Given a server with a halt token and a client
+	_, client := startHaltServer(t, "s3cret")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// When the resource is not halted, Then the check should say so
+	halted, _, err := client.CheckHalt(ctx, "payments-api")
+	require.NoError(t, err)
+	assert.False(t, halted)
+
+	// When it is halted with the token
+	require.NoError(t, client.Halt(ctx, "payments-api", "incident 42", "s3cret"))
+
+	// Then checks should report the halt and its reason
+	halted, reason, err := client.CheckHalt(ctx, "payments-api")
+	require.NoError(t, err)
+	assert.True(t, halted)
+	assert.Equal(t, "incident 42", reason)
+
+	// And resuming should clear it
+	require.NoError(t, client.Resume(ctx, "payments-api", "s3cret"))
+	halted, _, err = client.CheckHalt(ctx, "payments-api")
+	require.NoError(t, err)
+	assert.False(t, halted)
+}
+
+func TestDaemonClient_HaltRequiresToken(t *testing.T) {
+	tests := []struct {
+		name        string
+		serverToken string
+		clientToken string
+	}{
+		{name: "wrong token", serverToken: "s3cret", clientToken: "guess"},
+		{name: "missing token", serverToken: "s3cret", clientToken: ""},
+		{name: "halting disabled", serverToken: "", clientToken: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given a server and a client without the right token
+			server, client := startHaltServer(t, tt.serverToken)
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			// When the client tries to halt a resource
+			err := client.Halt(ctx, "payments-api", "", tt.clientToken)
+
+			// Then the request should be refused and nothing halted
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "unauthorized")
+			halted, _ := server.Halts().IsHalted("payments-api")
+			assert.False(t, halted)
+		})
+	}
+}
+
+func TestDaemon_HaltOverSocket(t *testing.T) {
+	// Given a running daemon with a halt token
+	dir, err := os.MkdirTemp("", "halt")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	d, err := NewDaemon(&Config{
+		SocketPath:    filepath.Join(dir, "d.sock"),
+		MaxMetrics:    100,
+		MetricsMaxAge: time.Hour,
+		LogLevel:      "error",
+		PidFile:       filepath.Join(dir, "d.pid"),
+		HaltToken:     "s3cret",
+	})
+	require.NoError(t, err)
+	require.NoError(t, d.Start())
+	t.Cleanup(func() { d.Stop() })
+
+	operator := NewDaemonClient(d.config.SocketPath)
+	t.Cleanup(func() { operator.Close() })
+	executor := NewDaemonClient(d.config.SocketPath)
+	t.Cleanup(func() { executor.Close() })
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// When an operator halts a resource, a wrong token is refused
+	assert.Error(t, operator.Halt(ctx, "payments-api", "incident 42", "wrong"))
+	require.NoError(t, operator.Halt(ctx, "payments-api", "incident 42", "s3cret"))
+
+	// Then another client sees the halt on its existing connection
+	halted, reason, err := executor.CheckHalt(ctx, "payments-api")
+	require.NoError(t, err)
+	assert.True(t, halted)
+	assert.Equal(t, "incident 42", reason)
+
+	// And resuming clears it
+	require.NoError(t, operator.Resume(ctx, "payments-api", "s3cret"))
+	halted, _, err = executor.CheckHalt(ctx, "payments-api")
+	require.NoError(t, err)
+	assert.False(t, halted)
+}
@@ -0,0 +1,78 @@
+package daemon
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"time"
+)
+
+// profilingHandler serves the pprof endpoints under /debug/pprof/, rejecting
+// requests that don't carry token as a bearer token
+func profilingHandler(token string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	return requireBearerToken(token, mux)
+}
+
+// requireBearerToken wraps next so only requests with "Authorization: Bearer
+// <token>" reach it; the comparison is constant time
+func requireBearerToken(token string, next http.Handler) http.Handler {
+	expected := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="patienced profiling"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// profilingServer serves pprof on its own address, apart from the HTTP API
+type profilingServer struct {
+	httpServer *http.Server
+	listener   net.Listener
+	logger     *Logger
+}
+
+// startProfilingServer binds addr and serves the token-protected pprof
+// endpoints on it until Stop is called
+func startProfilingServer(addr, token string, logger *Logger) (*profilingServer, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind profiling listener: %w", err)
+	}
+
+	s := &profilingServer{
+		httpServer: &http.Server{Handler: profilingHandler(token)},
+		listener:   listener,
+		logger:     logger,
+	}
+	logger.Info("starting profiling server", "addr", listener.Addr().String())
+	go func() {
+		if err := s.httpServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+			logger.Error("profiling server error", "error", err)
+		}
+	}()
+	return s, nil
+}
+
+// Addr returns the bound profiling address
+func (s *profilingServer) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Stop shuts the profiling server down
+func (s *profilingServer) Stop() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return s.httpServer.Shutdown(ctx)
+}
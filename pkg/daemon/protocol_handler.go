@@ -0,0 +1,246 @@
+package daemon
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// protocolHandler answers the daemon's line-delimited JSON protocol messages
+// (handshake, scheduling and halts). It is shared by UnixServer and the
+// daemon's connection handler so both speak the same protocol.
+type protocolHandler struct {
+	// halts is the kill switch executors check before each attempt; haltToken
+	// authorizes halt and resume requests, which are refused without one
+	halts     *HaltRegistry
+	haltToken string
+}
+
+// newProtocolHandler creates a protocol handler with halting disabled
+func newProtocolHandler() *protocolHandler {
+	return &protocolHandler{halts: NewHaltRegistry()}
+}
+
+// SetHaltToken enables halt and resume requests from clients presenting token
+func (h *protocolHandler) SetHaltToken(token string) {
+	h.haltToken = token
+}
+
+// Halts returns the registry of halted resources
+func (h *protocolHandler) Halts() *HaltRegistry {
+	return h.halts
+}
+
+// handleProtocolMessage processes a protocol message and returns a response
+func (h *protocolHandler) handleProtocolMessage(message string) map[string]interface{} {
+	// Use type-safe version and convert back to map for backward compatibility
+	response := h.handleProtocolMessageTypeSafe(message)
+
+	// Convert type-safe response back to map[string]interface{} for existing callers
+	responseData, err := json.Marshal(response)
+	if err != nil {
+		return map[string]interface{}{
+			"type":  "error",
+			"error": "failed to serialize response",
+		}
+	}
+
+	var responseMap map[string]interface{}
+	if err := json.Unmarshal(responseData, &responseMap); err != nil {
+		return map[string]interface{}{
+			"type":  "error",
+			"error": "failed to convert response",
+		}
+	}
+
+	return responseMap
+}
+
+// handleProtocolMessageTypeSafe processes a protocol message using type-safe structs
+func (h *protocolHandler) handleProtocolMessageTypeSafe(message string) ProtocolMessageJSON {
+	// First, parse just to get the message type
+	var typeCheck struct {
+		Type string `json:"type"`
+	}
+
+	if err := json.Unmarshal([]byte(message), &typeCheck); err != nil {
+		return ErrorResponseJSON{
+			Type:  "error",
+			Error: "invalid JSON",
+		}
+	}
+
+	// Handle different message types with type-safe parsing
+	switch typeCheck.Type {
+	case "handshake":
+		var request HandshakeRequestJSON
+		if err := json.Unmarshal([]byte(message), &request); err != nil {
+			return ErrorResponseJSON{
+				Type:  "error",
+				Error: "invalid handshake request format",
+			}
+		}
+		return h.handleHandshakeTypeSafe(request)
+
+	case "schedule_request":
+		var request ScheduleRequestJSON
+		if err := json.Unmarshal([]byte(message), &request); err != nil {
+			return ErrorResponseJSON{
+				Type:  "error",
+				Error: "invalid schedule request format",
+			}
+		}
+		return h.handleScheduleRequestTypeSafe(request)
+
+	case "register_request":
+		var request RegisterRequestJSON
+		if err := json.Unmarshal([]byte(message), &request); err != nil {
+			return ErrorResponseJSON{
+				Type:  "error",
+				Error: "invalid register request format",
+			}
+		}
+		return h.handleRegisterRequestTypeSafe(request)
+
+	case "halt_check":
+		var request HaltCheckRequestJSON
+		if err := json.Unmarshal([]byte(message), &request); err != nil {
+			return ErrorResponseJSON{
+				Type:  "error",
+				Error: "invalid halt check format",
+			}
+		}
+		return h.handleHaltCheck(request)
+
+	case "halt", "resume":
+		var request HaltRequestJSON
+		if err := json.Unmarshal([]byte(message), &request); err != nil {
+			return ErrorResponseJSON{
+				Type:  "error",
+				Error: "invalid halt request format",
+			}
+		}
+		return h.handleHaltRequest(request)
+
+	default:
+		return ErrorResponseJSON{
+			Type:  "error",
+			Error: "unknown message type",
+		}
+	}
+}
+
+// handleHandshake processes handshake messages
+func (h *protocolHandler) handleHandshake(request map[string]interface{}) map[string]interface{} {
+	version, ok := request["version"].(string)
+	if !ok {
+		return map[string]interface{}{
+			"type":  "error",
+			"error": "missing version",
+		}
+	}
+
+	// Only support version 1.0 for now
+	if version != "1.0" {
+		return map[string]interface{}{
+			"type":  "error",
+			"error": "unsupported protocol version",
+		}
+	}
+
+	return map[string]interface{}{
+		"type":    "handshake_response",
+		"status":  "ok",
+		"version": "1.0",
+	}
+}
+
+// handleScheduleRequest processes schedule request messages
+func (h *protocolHandler) handleScheduleRequest(request map[string]interface{}) map[string]interface{} {
+	// For now, always allow scheduling (simple implementation)
+	return map[string]interface{}{
+		"type":         "schedule_response",
+		"can_schedule": true,
+		"wait_until":   time.Now().Format(time.RFC3339),
+		"reason":       "test implementation",
+	}
+}
+
+// handleRegisterRequest processes register request messages
+func (h *protocolHandler) handleRegisterRequest(request map[string]interface{}) map[string]interface{} {
+	// For now, always succeed (simple implementation)
+	return map[string]interface{}{
+		"type":    "register_response",
+		"success": true,
+		"message": "requests registered successfully",
+	}
+}
+
+// Type-safe protocol handlers
+
+// handleHandshakeTypeSafe handles handshake using type-safe protocol
+func (h *protocolHandler) handleHandshakeTypeSafe(req HandshakeRequestJSON) ProtocolMessageJSON {
+	// Validate protocol version
+	if req.Version != "" && req.Version != "1.0" {
+		return ErrorResponseJSON{
+			Type:  "error",
+			Error: "unsupported protocol version: " + req.Version,
+		}
+	}
+	return HandshakeResponseJSON{
+		Type:    "handshake_response",
+		Status:  "ok",
+		Message: "handshake successful",
+	}
+}
+
+// handleScheduleRequestTypeSafe handles schedule request using type-safe protocol
+func (h *protocolHandler) handleScheduleRequestTypeSafe(req ScheduleRequestJSON) ScheduleResponseJSON {
+	// For now, just return a successful response
+	return ScheduleResponseJSON{
+		Type:        "schedule_response",
+		Status:      "ok",
+		CanSchedule: true,
+		Reason:      "request scheduled",
+		Message:     "request scheduled",
+		ScheduledAt: time.Now(),
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+}
+
+// handleRegisterRequestTypeSafe handles register request using type-safe protocol
+func (h *protocolHandler) handleRegisterRequestTypeSafe(req RegisterRequestJSON) RegisterResponseJSON {
+	// For now, just return a successful response
+	return RegisterResponseJSON{
+		Type:    "register_response",
+		Status:  "ok",
+		Success: true,
+		Message: "requests registered successfully",
+	}
+}
+
+// handleHaltCheck reports whether a resource's retries have been halted
+func (h *protocolHandler) handleHaltCheck(req HaltCheckRequestJSON) HaltCheckResponseJSON {
+	halted, reason := h.halts.IsHalted(req.ResourceID)
+	return HaltCheckResponseJSON{
+		Type:   "halt_check_response",
+		Halted: halted,
+		Reason: reason,
+	}
+}
+
+// handleHaltRequest halts or resumes a resource for a client with the halt token
+func (h *protocolHandler) handleHaltRequest(req HaltRequestJSON) HaltResponseJSON {
+	if !validHaltToken(h.haltToken, req.Token) {
+		return HaltResponseJSON{Type: "halt_response", Message: "unauthorized"}
+	}
+	if req.ResourceID == "" {
+		return HaltResponseJSON{Type: "halt_response", Message: "missing resource_id"}
+	}
+
+	if req.Type == "resume" {
+		h.halts.Resume(req.ResourceID)
+		return HaltResponseJSON{Type: "halt_response", Success: true, Message: "resumed " + req.ResourceID}
+	}
+	h.halts.Halt(req.ResourceID, req.Reason)
+	return HaltResponseJSON{Type: "halt_response", Success: true, Message: "halted " + req.ResourceID}
+}
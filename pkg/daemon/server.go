@@ -4,12 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
-	_ "net/http/pprof"
 	"runtime"
 	"strconv"
+	"sync/atomic"
 	"time"
 
+	"github.com/shaneisley/patience/pkg/metrics"
 	"github.com/shaneisley/patience/pkg/storage"
 )
 
@@ -19,6 +21,15 @@ type Server struct {
 	port       int
 	logger     *Logger
 	httpServer *http.Server
+	listener   net.Listener
+
+	// Readiness signaling for /readyz and /healthz
+	ready        atomic.Bool
+	shuttingDown atomic.Bool
+
+	// profilingToken, when set, serves pprof under /debug/pprof/ to requests
+	// bearing it; profiling is absent otherwise
+	profilingToken string
 }
 
 // NewServer creates a new HTTP server instance
@@ -30,27 +41,49 @@ func NewServer(storage *storage.MetricsStorage, port int, logger *Logger) *Serve
 	}
 }
 
-// Start starts the HTTP server
-func (s *Server) Start(ctx context.Context) error {
+// EnableProfiling serves the pprof endpoints alongside the API, requiring
+// token as a bearer token. Call it before Start.
+func (s *Server) EnableProfiling(token string) {
+	s.profilingToken = token
+}
+
+// routes returns the handler for the API, health checks, dashboard and, when
+// enabled, profiling
+func (s *Server) routes() http.Handler {
 	mux := http.NewServeMux()
 
 	// API routes
 	mux.HandleFunc("/api/metrics/recent", s.handleRecentMetrics)
 	mux.HandleFunc("/api/metrics/stats", s.handleAggregatedStats)
+	mux.HandleFunc("/api/metrics/failures", s.handleFailureStats)
 	mux.HandleFunc("/api/metrics/export", s.handleExportMetrics)
 	mux.HandleFunc("/api/daemon/stats", s.handleDaemonStats)
 	mux.HandleFunc("/api/daemon/performance", s.handlePerformanceStats)
 	mux.HandleFunc("/api/health", s.handleHealth)
+	mux.HandleFunc("/healthz", s.handleHealthz)
+	mux.HandleFunc("/readyz", s.handleReadyz)
 
-	// Profiling endpoints (pprof is automatically registered)
-	// Available at /debug/pprof/ when profiling is enabled
+	// Profiling endpoints, only when enabled and always behind the token
+	if s.profilingToken != "" {
+		mux.Handle("/debug/pprof/", profilingHandler(s.profilingToken))
+	}
 
 	// Static file serving for dashboard
 	mux.HandleFunc("/", s.handleDashboard)
 
+	return mux
+}
+
+// Start starts the HTTP server
+func (s *Server) Start(ctx context.Context) error {
 	s.httpServer = &http.Server{
 		Addr:    fmt.Sprintf(":%d", s.port),
-		Handler: mux,
+		Handler: s.routes(),
+	}
+
+	// Bind the listener unless Listen was already called
+	if err := s.Listen(); err != nil {
+		return err
 	}
 
 	s.logger.Info("starting HTTP server", "port", s.port)
@@ -58,7 +91,7 @@ func (s *Server) Start(ctx context.Context) error {
 	// Start server in goroutine
 	errChan := make(chan error, 1)
 	go func() {
-		if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := s.httpServer.Serve(s.listener); err != nil && err != http.ErrServerClosed {
 			errChan <- err
 		}
 	}()
@@ -66,14 +99,43 @@ func (s *Server) Start(ctx context.Context) error {
 	// Wait for context cancellation or server error
 	select {
 	case <-ctx.Done():
+		s.shuttingDown.Store(true)
 		return s.httpServer.Shutdown(context.Background())
 	case err := <-errChan:
 		return err
 	}
 }
 
+// Listen binds the HTTP listener so bind errors surface before serving starts
+func (s *Server) Listen() error {
+	if s.listener != nil {
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", s.port))
+	if err != nil {
+		return fmt.Errorf("failed to bind HTTP listener: %w", err)
+	}
+	s.listener = listener
+	return nil
+}
+
+// Addr returns the bound HTTP address, or nil before Listen
+func (s *Server) Addr() net.Addr {
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+// SetReady marks whether the daemon is ready to accept work (reported by /readyz)
+func (s *Server) SetReady(ready bool) {
+	s.ready.Store(ready)
+}
+
 // Stop stops the HTTP server
 func (s *Server) Stop() error {
+	s.shuttingDown.Store(true)
 	if s.httpServer != nil {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
@@ -115,7 +177,60 @@ func (s *Server) handleAggregatedStats(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Parse time range parameters
+	start, end, tags, err := parseStatsQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Get aggregated stats
+	stats := s.storage.GetAggregatedStatsWithTags(start, end, tags)
+
+	// Return JSON response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+// failureStats is the response of GET /api/metrics/failures
+type failureStats struct {
+	TimeRange  storage.TimeRange `json:"time_range"`
+	TotalRuns  int               `json:"total_runs"`
+	FailedRuns int               `json:"failed_runs"`
+	Categories map[string]int    `json:"categories"`
+}
+
+// handleFailureStats handles GET /api/metrics/failures, counting failed runs
+// by category so alerts can single out failures that won't heal on their own
+func (s *Server) handleFailureStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	start, end, tags, err := parseStatsQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	stats := s.storage.GetAggregatedStatsWithTags(start, end, tags)
+	categories := stats.FailureBreakdown
+	if categories == nil {
+		categories = map[string]int{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(failureStats{
+		TimeRange:  stats.TimeRange,
+		TotalRuns:  stats.TotalRuns,
+		FailedRuns: stats.FailedRuns,
+		Categories: categories,
+	})
+}
+
+// parseStatsQuery reads the time range (start and end in RFC 3339, defaulting
+// to the last 24 hours) and tag filters of a stats request
+func parseStatsQuery(r *http.Request) (time.Time, time.Time, map[string]string, error) {
 	now := time.Now()
 	start := now.Add(-24 * time.Hour) // Default to last 24 hours
 	end := now
@@ -132,12 +247,12 @@ func (s *Server) handleAggregatedStats(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Get aggregated stats
-	stats := s.storage.GetAggregatedStats(start, end)
-
-	// Return JSON response
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(stats)
+	// Optional tag filters, e.g. ?tag=env=prod&tag=team=payments
+	tags, err := metrics.ParseTags(r.URL.Query()["tag"])
+	if err != nil {
+		return time.Time{}, time.Time{}, nil, err
+	}
+	return start, end, tags, nil
 }
 
 // handleExportMetrics handles GET /api/metrics/export
@@ -234,6 +349,26 @@ func (s *Server) handlePerformanceStats(w http.ResponseWriter, r *http.Request)
 	json.NewEncoder(w).Encode(perfStats)
 }
 
+// handleHealthz handles GET /healthz (liveness); it fails once shutdown begins
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	if s.shuttingDown.Load() {
+		http.Error(w, "shutting down", http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintln(w, "ok")
+}
+
+// handleReadyz handles GET /readyz; it succeeds only while listeners are up and not shutting down
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	if s.shuttingDown.Load() || !s.ready.Load() {
+		http.Error(w, "not ready", http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintln(w, "ready")
+}
+
 // handleHealth handles GET /api/health
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
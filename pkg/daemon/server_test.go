@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/shaneisley/patience/pkg/metrics"
 	"github.com/shaneisley/patience/pkg/storage"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -132,6 +133,79 @@ func TestServer_HandleAggregatedStatsWithTimeRange(t *testing.T) {
 	assert.Equal(t, 1, stats.TotalRuns)
 }
 
+func TestServer_HandleAggregatedStatsWithTagFilter(t *testing.T) {
+	// Given a server with metrics tagged by environment
+	metricsStorage := storage.NewMetricsStorage(100, time.Hour)
+	logger := NewLogger("test", LogLevelInfo)
+	server := NewServer(metricsStorage, 8080, logger)
+
+	prod := createTestRunMetrics("deploy", true, 1.0, 1)
+	prod.Tags = map[string]string{"env": "prod"}
+	staging := createTestRunMetrics("deploy", false, 1.0, 2)
+	staging.Tags = map[string]string{"env": "staging"}
+	metricsStorage.Store(prod)
+	metricsStorage.Store(staging)
+
+	// When requesting stats filtered by a tag value
+	req := httptest.NewRequest("GET", "/api/metrics/stats?tag=env=prod", nil)
+	w := httptest.NewRecorder()
+	server.handleAggregatedStats(w, req)
+
+	// Then only runs with that tag should be aggregated
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var stats storage.AggregatedStats
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &stats))
+	assert.Equal(t, 1, stats.TotalRuns)
+	assert.Equal(t, 1.0, stats.SuccessRate)
+	assert.Equal(t, map[string]string{"env": "prod"}, stats.TagFilter)
+
+	// When the tag filter is malformed
+	req = httptest.NewRequest("GET", "/api/metrics/stats?tag=env", nil)
+	w = httptest.NewRecorder()
+	server.handleAggregatedStats(w, req)
+
+	// Then the request should be rejected
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestServer_HandleFailureStats(t *testing.T) {
+	// Given a server with mixed failure categories
+	metricsStorage := storage.NewMetricsStorage(100, time.Hour)
+	logger := NewLogger("test", LogLevelInfo)
+	server := NewServer(metricsStorage, 8080, logger)
+
+	auth := createTestRunMetrics("deploy", false, 1.0, 1)
+	auth.FailureKind, auth.FailureCategory = "max_attempts", "auth"
+	transient := createTestRunMetrics("deploy", false, 1.0, 3)
+	transient.FailureKind = "max_attempts"
+	for _, metric := range []*metrics.RunMetrics{auth, auth, transient, createTestRunMetrics("deploy", true, 1.0, 1)} {
+		metricsStorage.Store(metric)
+	}
+
+	// When requesting failure counts
+	req := httptest.NewRequest("GET", "/api/metrics/failures", nil)
+	w := httptest.NewRecorder()
+	server.routes().ServeHTTP(w, req)
+
+	// Then failed runs are counted by category
+	assert.Equal(t, http.StatusOK, w.Code)
+	var stats failureStats
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &stats))
+	assert.Equal(t, 4, stats.TotalRuns)
+	assert.Equal(t, 3, stats.FailedRuns)
+	assert.Equal(t, map[string]int{"auth": 2, "max_attempts": 1}, stats.Categories)
+
+	// When no run failed in the filtered range
+	req = httptest.NewRequest("GET", "/api/metrics/failures?tag=env=prod", nil)
+	w = httptest.NewRecorder()
+	server.handleFailureStats(w, req)
+
+	// Then the categories are empty rather than null
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"categories":{}`)
+}
+
 func TestServer_HandleExportMetrics(t *testing.T) {
 	// Given a server with test metrics
 	metricsStorage := storage.NewMetricsStorage(100, time.Hour)
@@ -244,6 +318,37 @@ func TestServer_HandleHealth(t *testing.T) {
 	assert.Contains(t, health, "version")
 }
 
+func TestServer_HealthzAndReadyz(t *testing.T) {
+	// Given a server that is listening but not yet marked ready
+	metricsStorage := storage.NewMetricsStorage(100, time.Hour)
+	logger := NewLogger("test", LogLevelInfo)
+	server := NewServer(metricsStorage, 0, logger)
+
+	probe := func(handler http.HandlerFunc, path string) int {
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest("GET", path, nil))
+		return w.Code
+	}
+
+	// Then it should be live but not ready
+	assert.Equal(t, http.StatusOK, probe(server.handleHealthz, "/healthz"))
+	assert.Equal(t, http.StatusServiceUnavailable, probe(server.handleReadyz, "/readyz"))
+
+	// When marked ready
+	server.SetReady(true)
+
+	// Then both endpoints should succeed
+	assert.Equal(t, http.StatusOK, probe(server.handleHealthz, "/healthz"))
+	assert.Equal(t, http.StatusOK, probe(server.handleReadyz, "/readyz"))
+
+	// When shutdown begins
+	require.NoError(t, server.Stop())
+
+	// Then both endpoints should report unavailable
+	assert.Equal(t, http.StatusServiceUnavailable, probe(server.handleHealthz, "/healthz"))
+	assert.Equal(t, http.StatusServiceUnavailable, probe(server.handleReadyz, "/readyz"))
+}
+
 func TestServer_HandleDashboard(t *testing.T) {
 	// Given a server
 	metricsStorage := storage.NewMetricsStorage(100, time.Hour)
@@ -291,3 +396,52 @@ func TestServer_NotFound(t *testing.T) {
 	// Then it should return not found
 	assert.Equal(t, http.StatusNotFound, w.Code)
 }
+
+func TestServer_ProfilingRequiresToken(t *testing.T) {
+	// Given a server with profiling enabled
+	server := NewServer(storage.NewMetricsStorage(100, time.Hour), 8080, NewLogger("test", LogLevelError))
+	server.EnableProfiling("s3cret")
+	handler := server.routes()
+
+	tests := []struct {
+		name          string
+		authorization string
+		expectedCode  int
+	}{
+		{"no credentials", "", http.StatusUnauthorized},
+		{"wrong token", "Bearer nope", http.StatusUnauthorized},
+		{"valid token", "Bearer s3cret", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// When requesting the pprof index
+			req := httptest.NewRequest("GET", "/debug/pprof/", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			// Then only the correct bearer token should get through
+			assert.Equal(t, tt.expectedCode, w.Code)
+		})
+	}
+}
+
+func TestServer_ProfilingAbsentWhenDisabled(t *testing.T) {
+	// Given a server without profiling
+	server := NewServer(storage.NewMetricsStorage(100, time.Hour), 8080, NewLogger("test", LogLevelError))
+	handler := server.routes()
+
+	// When requesting pprof endpoints, even with credentials
+	for _, path := range []string{"/debug/pprof/", "/debug/pprof/heap", "/debug/pprof/cmdline"} {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Authorization", "Bearer s3cret")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		// Then they should not exist
+		assert.Equal(t, http.StatusNotFound, w.Code, path)
+	}
+}
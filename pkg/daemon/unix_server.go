@@ -31,6 +31,9 @@ type UnixServer struct {
 	ctx               context.Context
 	cancel            context.CancelFunc
 	wg                sync.WaitGroup
+
+	// protocolHandler answers the line-delimited protocol messages
+	*protocolHandler
 }
 
 // NewUnixServer creates a new Unix socket server
@@ -39,6 +42,7 @@ func NewUnixServer(socketPath string) *UnixServer {
 		socketPath:        socketPath,
 		connectionTimeout: DefaultConnectionTimeout,
 		maxConnections:    DefaultMaxConnections,
+		protocolHandler:   newProtocolHandler(),
 	}
 }
 
@@ -182,171 +186,3 @@ func (s *UnixServer) handleConnection(conn net.Conn) {
 		conn.Write(append(responseData, '\n'))
 	}
 }
-
-// handleProtocolMessage processes a protocol message and returns a response
-func (s *UnixServer) handleProtocolMessage(message string) map[string]interface{} {
-	// Use type-safe version and convert back to map for backward compatibility
-	response := s.handleProtocolMessageTypeSafe(message)
-
-	// Convert type-safe response back to map[string]interface{} for existing callers
-	responseData, err := json.Marshal(response)
-	if err != nil {
-		return map[string]interface{}{
-			"type":  "error",
-			"error": "failed to serialize response",
-		}
-	}
-
-	var responseMap map[string]interface{}
-	if err := json.Unmarshal(responseData, &responseMap); err != nil {
-		return map[string]interface{}{
-			"type":  "error",
-			"error": "failed to convert response",
-		}
-	}
-
-	return responseMap
-}
-
-// handleProtocolMessageTypeSafe processes a protocol message using type-safe structs
-func (s *UnixServer) handleProtocolMessageTypeSafe(message string) ProtocolMessageJSON {
-	// First, parse just to get the message type
-	var typeCheck struct {
-		Type string `json:"type"`
-	}
-
-	if err := json.Unmarshal([]byte(message), &typeCheck); err != nil {
-		return ErrorResponseJSON{
-			Type:  "error",
-			Error: "invalid JSON",
-		}
-	}
-
-	// Handle different message types with type-safe parsing
-	switch typeCheck.Type {
-	case "handshake":
-		var request HandshakeRequestJSON
-		if err := json.Unmarshal([]byte(message), &request); err != nil {
-			return ErrorResponseJSON{
-				Type:  "error",
-				Error: "invalid handshake request format",
-			}
-		}
-		return s.handleHandshakeTypeSafe(request)
-
-	case "schedule_request":
-		var request ScheduleRequestJSON
-		if err := json.Unmarshal([]byte(message), &request); err != nil {
-			return ErrorResponseJSON{
-				Type:  "error",
-				Error: "invalid schedule request format",
-			}
-		}
-		return s.handleScheduleRequestTypeSafe(request)
-
-	case "register_request":
-		var request RegisterRequestJSON
-		if err := json.Unmarshal([]byte(message), &request); err != nil {
-			return ErrorResponseJSON{
-				Type:  "error",
-				Error: "invalid register request format",
-			}
-		}
-		return s.handleRegisterRequestTypeSafe(request)
-
-	default:
-		return ErrorResponseJSON{
-			Type:  "error",
-			Error: "unknown message type",
-		}
-	}
-}
-
-// handleHandshake processes handshake messages
-func (s *UnixServer) handleHandshake(request map[string]interface{}) map[string]interface{} {
-	version, ok := request["version"].(string)
-	if !ok {
-		return map[string]interface{}{
-			"type":  "error",
-			"error": "missing version",
-		}
-	}
-
-	// Only support version 1.0 for now
-	if version != "1.0" {
-		return map[string]interface{}{
-			"type":  "error",
-			"error": "unsupported protocol version",
-		}
-	}
-
-	return map[string]interface{}{
-		"type":    "handshake_response",
-		"status":  "ok",
-		"version": "1.0",
-	}
-}
-
-// handleScheduleRequest processes schedule request messages
-func (s *UnixServer) handleScheduleRequest(request map[string]interface{}) map[string]interface{} {
-	// For now, always allow scheduling (simple implementation)
-	return map[string]interface{}{
-		"type":         "schedule_response",
-		"can_schedule": true,
-		"wait_until":   time.Now().Format(time.RFC3339),
-		"reason":       "test implementation",
-	}
-}
-
-// handleRegisterRequest processes register request messages
-func (s *UnixServer) handleRegisterRequest(request map[string]interface{}) map[string]interface{} {
-	// For now, always succeed (simple implementation)
-	return map[string]interface{}{
-		"type":    "register_response",
-		"success": true,
-		"message": "requests registered successfully",
-	}
-}
-
-// Type-safe protocol handlers
-
-// handleHandshakeTypeSafe handles handshake using type-safe protocol
-func (s *UnixServer) handleHandshakeTypeSafe(req HandshakeRequestJSON) ProtocolMessageJSON {
-	// Validate protocol version
-	if req.Version != "" && req.Version != "1.0" {
-		return ErrorResponseJSON{
-			Type:  "error",
-			Error: "unsupported protocol version: " + req.Version,
-		}
-	}
-	return HandshakeResponseJSON{
-		Type:    "handshake_response",
-		Status:  "ok",
-		Message: "handshake successful",
-	}
-}
-
-// handleScheduleRequestTypeSafe handles schedule request using type-safe protocol
-func (s *UnixServer) handleScheduleRequestTypeSafe(req ScheduleRequestJSON) ScheduleResponseJSON {
-	// For now, just return a successful response
-	return ScheduleResponseJSON{
-		Type:        "schedule_response",
-		Status:      "ok",
-		CanSchedule: true,
-		Reason:      "request scheduled",
-		Message:     "request scheduled",
-		ScheduledAt: time.Now(),
-		ExpiresAt:   time.Now().Add(time.Hour),
-	}
-}
-
-// handleRegisterRequestTypeSafe handles register request using type-safe protocol
-func (s *UnixServer) handleRegisterRequestTypeSafe(req RegisterRequestJSON) RegisterResponseJSON {
-	// For now, just return a successful response
-	return RegisterResponseJSON{
-		Type:    "register_response",
-		Status:  "ok",
-		Success: true,
-		Message: "requests registered successfully",
-	}
-}
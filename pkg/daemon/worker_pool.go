@@ -1,6 +1,8 @@
 package daemon
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
 	"io"
@@ -21,6 +23,7 @@ type WorkerPool struct {
 	cancel   context.CancelFunc
 	storage  *storage.MetricsStorage
 	logger   *Logger
+	protocol *protocolHandler
 	started  bool
 	mu       sync.RWMutex
 }
@@ -43,6 +46,7 @@ func NewWorkerPool(workers int, storage *storage.MetricsStorage, logger *Logger)
 		cancel:   cancel,
 		storage:  storage,
 		logger:   logger,
+		protocol: newProtocolHandler(),
 	}
 }
 
@@ -142,15 +146,28 @@ func (wp *WorkerPool) worker(id int) {
 	}
 }
 
-// handleConnection handles a single connection
+// handleConnection handles a single connection: a metrics payload, or a
+// session of line-delimited protocol messages from a DaemonClient
 func (wp *WorkerPool) handleConnection(conn net.Conn, workerID int) {
 	defer conn.Close()
 
 	// Set read timeout to prevent hanging connections
 	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
 
-	// Read metrics data
-	data, err := io.ReadAll(conn)
+	// Protocol messages are newline-terminated JSON with a type; metrics
+	// payloads carry no newline and are read to EOF
+	reader := bufio.NewReader(conn)
+	first, err := reader.ReadBytes('\n')
+	if err == nil && isProtocolMessage(first) {
+		wp.serveProtocol(conn, reader, first, workerID)
+		return
+	}
+	var data []byte
+	if err == nil || err == io.EOF {
+		var rest []byte
+		rest, err = io.ReadAll(reader)
+		data = append(first, rest...)
+	}
 	if err != nil {
 		if err != io.EOF && !isTimeoutError(err) {
 			wp.logger.Error("error reading from connection",
@@ -164,9 +181,16 @@ func (wp *WorkerPool) handleConnection(conn net.Conn, workerID int) {
 		return
 	}
 
-	// Parse metrics
+	// Decompress if needed, then parse metrics
+	payload, err := metrics.DecodePayload(data)
+	if err != nil {
+		wp.logger.Error("error decoding metrics",
+			"error", err, "worker_id", workerID, "data_length", len(data))
+		return
+	}
+
 	var runMetrics metrics.RunMetrics
-	if err := json.Unmarshal(data, &runMetrics); err != nil {
+	if err := json.Unmarshal(payload, &runMetrics); err != nil {
 		wp.logger.Error("error parsing metrics",
 			"error", err, "worker_id", workerID, "data_length", len(data))
 		return
@@ -183,6 +207,42 @@ func (wp *WorkerPool) handleConnection(conn net.Conn, workerID int) {
 		"command", runMetrics.Command, "worker_id", workerID)
 }
 
+// isProtocolMessage reports whether line is a protocol message rather than the
+// start of a metrics payload
+func isProtocolMessage(line []byte) bool {
+	var message struct {
+		Type string `json:"type"`
+	}
+	return json.Unmarshal(bytes.TrimSpace(line), &message) == nil && message.Type != ""
+}
+
+// serveProtocol answers protocol messages, starting with first, until the
+// client disconnects or stays idle past DefaultConnectionTimeout
+func (wp *WorkerPool) serveProtocol(conn net.Conn, reader *bufio.Reader, first []byte, workerID int) {
+	line := first
+	for {
+		response, err := json.Marshal(wp.protocol.handleProtocolMessageTypeSafe(string(bytes.TrimSpace(line))))
+		if err != nil {
+			wp.logger.Error("error encoding protocol response", "error", err, "worker_id", workerID)
+			return
+		}
+		conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+		if _, err := conn.Write(append(response, '\n')); err != nil {
+			return
+		}
+
+		select {
+		case <-wp.ctx.Done():
+			return
+		default:
+		}
+		conn.SetReadDeadline(time.Now().Add(DefaultConnectionTimeout))
+		if line, err = reader.ReadBytes('\n'); err != nil {
+			return
+		}
+	}
+}
+
 // GetStats returns worker pool statistics
 func (wp *WorkerPool) GetStats() map[string]interface{} {
 	wp.mu.RLock()
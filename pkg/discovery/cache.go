@@ -0,0 +1,110 @@
+package discovery
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/shaneisley/patience/pkg/storage"
+)
+
+// DefaultCacheTTL is how long a cached rate limit stays usable after it was
+// last seen
+const DefaultCacheTTL = time.Hour
+
+// Cache persists discovered rate limits to a file, keyed by resource ID, so a
+// later invocation can respect limits an earlier one learned. Entries not seen
+// within the TTL are treated as missing and dropped on save.
+type Cache struct {
+	path string
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]RateLimitInfo
+}
+
+// NewCache creates an empty cache that Save writes to path; ttl <= 0 means
+// DefaultCacheTTL
+func NewCache(path string, ttl time.Duration) *Cache {
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	return &Cache{path: path, ttl: ttl, now: time.Now, entries: make(map[string]RateLimitInfo)}
+}
+
+// LoadCache reads the cache file at path. A missing file gives an empty cache
+// that Save will create.
+func LoadCache(path string, ttl time.Duration) (*Cache, error) {
+	cache := NewCache(path, ttl)
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read discovery cache: %w", err)
+	}
+	if err := json.Unmarshal(data, &cache.entries); err != nil {
+		return nil, fmt.Errorf("failed to parse discovery cache: %w", err)
+	}
+	if cache.entries == nil {
+		cache.entries = make(map[string]RateLimitInfo)
+	}
+	return cache, nil
+}
+
+// expired reports whether info was last seen longer ago than the TTL
+func (c *Cache) expired(info RateLimitInfo) bool {
+	return c.now().Sub(info.LastSeen) > c.ttl
+}
+
+// Lookup returns a copy of the cached rate limit for resourceID, or nil when
+// there is none or it has expired
+func (c *Cache) Lookup(resourceID string) *RateLimitInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	info, ok := c.entries[resourceID]
+	if !ok || c.expired(info) {
+		return nil
+	}
+	return &info
+}
+
+// Store records info under its resource ID, replacing any earlier entry
+func (c *Cache) Store(info *RateLimitInfo) {
+	if info == nil || info.ResourceID == "" {
+		return
+	}
+	entry := *info
+	if entry.LastSeen.IsZero() {
+		entry.LastSeen = c.now()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[entry.ResourceID] = entry
+}
+
+// Save atomically writes the unexpired entries back to the cache file
+func (c *Cache) Save() error {
+	c.mu.Lock()
+	for id, info := range c.entries {
+		if c.expired(info) {
+			delete(c.entries, id)
+		}
+	}
+	data, err := json.MarshalIndent(c.entries, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode discovery cache: %w", err)
+	}
+
+	if err := storage.WriteFileAtomic(c.path, data); err != nil {
+		return fmt.Errorf("failed to write discovery cache: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package discovery
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCache_PersistsEnhancedParserResults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "discovery.json")
+	cache, err := LoadCache(path, time.Hour)
+	if err != nil {
+		t.Fatalf("LoadCache() error = %v", err)
+	}
+	parser := NewEnhancedParser()
+	parser.UseCache(cache)
+
+	// A response that used up the GitHub rate limit
+	reset := time.Now().Add(30 * time.Minute).Truncate(time.Second)
+	stdout := fmt.Sprintf("HTTP/1.1 403 Forbidden\nX-RateLimit-Limit: 5000\nX-RateLimit-Remaining: 0\nX-RateLimit-Reset: %d\n", reset.Unix())
+	command := []string{"curl", "-i", "https://api.github.com/user"}
+
+	result := parser.ParseFromCommandOutputEnhanced(stdout, "", 0, command)
+	if !result.Found {
+		t.Fatal("expected rate limit info to be found")
+	}
+	if err := cache.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// A new parser loading the same file knows the limit
+	reloaded, err := LoadCache(path, time.Hour)
+	if err != nil {
+		t.Fatalf("LoadCache() error = %v", err)
+	}
+	fresh := NewEnhancedParser()
+	fresh.UseCache(reloaded)
+
+	info := fresh.KnownRateLimit(command)
+	if info == nil {
+		t.Fatal("expected the cached rate limit to be known")
+	}
+	if info.ResourceID != result.Info.ResourceID {
+		t.Errorf("ResourceID = %s, want %s", info.ResourceID, result.Info.ResourceID)
+	}
+	if info.Host != "api.github.com" {
+		t.Errorf("Host = %s, want api.github.com", info.Host)
+	}
+	if info.Limit != 5000 || info.Remaining != 0 {
+		t.Errorf("Limit/Remaining = %d/%d, want 5000/0", info.Limit, info.Remaining)
+	}
+	if !info.ResetTime.Equal(reset) {
+		t.Errorf("ResetTime = %v, want %v", info.ResetTime, reset)
+	}
+	if info.Window != result.Info.Window {
+		t.Errorf("Window = %v, want %v", info.Window, result.Info.Window)
+	}
+}
+
+func TestCache_ExpiresEntriesAfterTTL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "discovery.json")
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	cache := NewCache(path, 10*time.Minute)
+	cache.now = func() time.Time { return now }
+	cache.Store(&RateLimitInfo{ResourceID: "fresh", Limit: 10, LastSeen: now.Add(-5 * time.Minute)})
+	cache.Store(&RateLimitInfo{ResourceID: "stale", Limit: 10, LastSeen: now.Add(-15 * time.Minute)})
+
+	tests := []struct {
+		resourceID string
+		wantKnown  bool
+	}{
+		{resourceID: "fresh", wantKnown: true},
+		{resourceID: "stale", wantKnown: false},
+	}
+
+	for _, tt := range tests {
+		if known := cache.Lookup(tt.resourceID) != nil; known != tt.wantKnown {
+			t.Errorf("Lookup(%q) known = %v, want %v", tt.resourceID, known, tt.wantKnown)
+		}
+	}
+
+	if err := cache.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	for _, tt := range tests {
+		if saved := strings.Contains(string(data), `"`+tt.resourceID+`"`); saved != tt.wantKnown {
+			t.Errorf("%q saved = %v, want %v", tt.resourceID, saved, tt.wantKnown)
+		}
+	}
+}
+
+func TestLoadCache(t *testing.T) {
+	dir := t.TempDir()
+	corrupt := filepath.Join(dir, "corrupt.json")
+	if err := os.WriteFile(corrupt, []byte("not json"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		ttl     time.Duration
+		wantTTL time.Duration
+		wantErr string
+	}{
+		{name: "missing file", path: filepath.Join(dir, "missing.json"), wantTTL: DefaultCacheTTL},
+		{name: "corrupt file", path: corrupt, ttl: time.Hour, wantErr: "failed to parse discovery cache"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache, err := LoadCache(tt.path, tt.ttl)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadCache() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadCache() error = %v", err)
+			}
+			if cache.Lookup("anything") != nil {
+				t.Error("expected an empty cache")
+			}
+			if cache.ttl != tt.wantTTL {
+				t.Errorf("ttl = %v, want %v", cache.ttl, tt.wantTTL)
+			}
+		})
+	}
+}
+
+func TestEnhancedParser_KnownRateLimitWithoutCache(t *testing.T) {
+	if info := NewEnhancedParser().KnownRateLimit([]string{"curl", "https://api.github.com/user"}); info != nil {
+		t.Errorf("KnownRateLimit() = %+v, want nil without a cache", info)
+	}
+}
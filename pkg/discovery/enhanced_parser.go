@@ -15,6 +15,8 @@ type EnhancedParser struct {
 	enhancedPatterns map[string]*regexp.Regexp
 	// Common API patterns for better resource identification
 	apiPatterns map[string]*regexp.Regexp
+	// Cache, when set, receives every rate limit found
+	cache *Cache
 }
 
 // NewEnhancedParser creates a new enhanced parser with additional capabilities
@@ -107,6 +109,22 @@ func compileAPIPatterns() map[string]*regexp.Regexp {
 	return patterns
 }
 
+// UseCache makes the parser store every rate limit it finds in cache, so
+// KnownRateLimit can answer from results of earlier invocations
+func (ep *EnhancedParser) UseCache(cache *Cache) {
+	ep.cache = cache
+}
+
+// KnownRateLimit returns the cached rate limit for the resource command
+// targets, or nil when there is no cache or no unexpired entry
+func (ep *EnhancedParser) KnownRateLimit(command []string) *RateLimitInfo {
+	if ep.cache == nil {
+		return nil
+	}
+	resourceID, _, _ := ep.extractEnhancedResourceInfo(command, "")
+	return ep.cache.Lookup(resourceID)
+}
+
 // ParseFromCommandOutputEnhanced provides enhanced parsing with better resource identification
 func (ep *EnhancedParser) ParseFromCommandOutputEnhanced(stdout, stderr string, exitCode int, command []string) *DiscoveryResult {
 	// Use base parser first
@@ -120,6 +138,11 @@ func (ep *EnhancedParser) ParseFromCommandOutputEnhanced(stdout, stderr string,
 		result = ep.parseWithEnhancedPatterns(stdout, stderr, exitCode, command)
 	}
 
+	// Remember what was found for later invocations
+	if ep.cache != nil && result.Found {
+		ep.cache.Store(result.Info)
+	}
+
 	return result
 }
 
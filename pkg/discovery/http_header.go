@@ -0,0 +1,74 @@
+package discovery
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// ParseFromHTTPHeader extracts rate limit information from a net/http header map.
+// It applies the same extraction rules as ParseFromCommandOutput, so Go services
+// can reuse discovery without scraping command output.
+func ParseFromHTTPHeader(h http.Header) *DiscoveryResult {
+	return parseHeaderMap(NewParser(), h, "unknown", "unknown", "/")
+}
+
+// ParseFromResponse extracts rate limit information from an HTTP response.
+// When the response carries its request, the URL is used to identify the resource.
+func ParseFromResponse(resp *http.Response) *DiscoveryResult {
+	if resp == nil {
+		return notFoundResult()
+	}
+
+	resourceID, host, path := "unknown", "unknown", "/"
+	if resp.Request != nil && resp.Request.URL != nil {
+		u := resp.Request.URL
+		if u.Host != "" {
+			host = u.Host
+		}
+		if u.Path != "" {
+			path = u.Path
+		}
+		resourceID = host + path
+	}
+
+	return parseHeaderMap(NewParser(), resp.Header, resourceID, host, path)
+}
+
+// parseHeaderMap renders headers in wire format and reuses the text header parser
+func parseHeaderMap(p *Parser, h http.Header, resourceID, host, path string) *DiscoveryResult {
+	if len(h) == 0 {
+		return notFoundResult()
+	}
+
+	// Sort names so that repeated headers resolve deterministically
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var builder strings.Builder
+	for _, name := range names {
+		for _, value := range h[name] {
+			builder.WriteString(name)
+			builder.WriteString(": ")
+			builder.WriteString(value)
+			builder.WriteByte('\n')
+		}
+	}
+
+	if result := p.parseHTTPHeaders(builder.String(), resourceID, host, path); result.Found {
+		return result
+	}
+	return notFoundResult()
+}
+
+// notFoundResult returns the result used when no rate limit information is present
+func notFoundResult() *DiscoveryResult {
+	return &DiscoveryResult{
+		Found:      false,
+		Source:     SourceHTTPHeader,
+		Confidence: 0.0,
+	}
+}
@@ -0,0 +1,129 @@
+package discovery
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestParseFromHTTPHeader(t *testing.T) {
+	reset := time.Now().Add(30 * time.Minute).Unix()
+
+	tests := []struct {
+		name          string
+		header        http.Header
+		wantFound     bool
+		wantLimit     int
+		wantRemaining int
+		wantReset     int64
+		wantWindow    time.Duration
+	}{
+		{
+			name: "GitHub-style headers",
+			header: http.Header{
+				"X-Ratelimit-Limit":     []string{"5000"},
+				"X-Ratelimit-Remaining": []string{"4999"},
+				"X-Ratelimit-Reset":     []string{formatUnix(reset)},
+			},
+			wantFound:     true,
+			wantLimit:     5000,
+			wantRemaining: 4999,
+			wantReset:     reset,
+		},
+		{
+			name: "Twitter-style headers",
+			header: http.Header{
+				"X-Rate-Limit-Limit":     []string{"900"},
+				"X-Rate-Limit-Remaining": []string{"12"},
+				"X-Rate-Limit-Reset":     []string{formatUnix(reset)},
+			},
+			wantFound:     true,
+			wantLimit:     900,
+			wantRemaining: 12,
+			wantReset:     reset,
+		},
+		{
+			name: "standard Retry-After header",
+			header: http.Header{
+				"Retry-After": []string{"30"},
+			},
+			wantFound:  true,
+			wantWindow: 30 * time.Second,
+		},
+		{
+			name: "no rate limit headers",
+			header: http.Header{
+				"Content-Type": []string{"application/json"},
+			},
+			wantFound: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ParseFromHTTPHeader(tt.header)
+
+			if result.Found != tt.wantFound {
+				t.Fatalf("Found = %v, want %v", result.Found, tt.wantFound)
+			}
+			if !tt.wantFound {
+				return
+			}
+
+			info := result.Info
+			if result.Source != SourceHTTPHeader {
+				t.Errorf("Source = %v, want %v", result.Source, SourceHTTPHeader)
+			}
+			if info.Limit != tt.wantLimit {
+				t.Errorf("Limit = %d, want %d", info.Limit, tt.wantLimit)
+			}
+			if info.Remaining != tt.wantRemaining {
+				t.Errorf("Remaining = %d, want %d", info.Remaining, tt.wantRemaining)
+			}
+			if tt.wantReset != 0 && info.ResetTime.Unix() != tt.wantReset {
+				t.Errorf("ResetTime = %d, want %d", info.ResetTime.Unix(), tt.wantReset)
+			}
+			if tt.wantWindow != 0 && info.Window != tt.wantWindow {
+				t.Errorf("Window = %v, want %v", info.Window, tt.wantWindow)
+			}
+		})
+	}
+}
+
+func TestParseFromResponse(t *testing.T) {
+	resp := &http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Header: http.Header{
+			"X-Ratelimit-Limit":     []string{"60"},
+			"X-Ratelimit-Remaining": []string{"0"},
+		},
+		Request: &http.Request{
+			URL: &url.URL{Scheme: "https", Host: "api.github.com", Path: "/repos"},
+		},
+	}
+
+	result := ParseFromResponse(resp)
+
+	if !result.Found {
+		t.Fatal("expected rate limit info to be found")
+	}
+	if result.Info.Limit != 60 || result.Info.Remaining != 0 {
+		t.Errorf("Limit/Remaining = %d/%d, want 60/0", result.Info.Limit, result.Info.Remaining)
+	}
+	if result.Info.Host != "api.github.com" || result.Info.Path != "/repos" {
+		t.Errorf("Host/Path = %s%s, want api.github.com/repos", result.Info.Host, result.Info.Path)
+	}
+	if result.Info.ResourceID != "api.github.com/repos" {
+		t.Errorf("ResourceID = %s, want api.github.com/repos", result.Info.ResourceID)
+	}
+
+	if ParseFromResponse(nil).Found {
+		t.Error("expected nil response to yield no rate limit info")
+	}
+}
+
+func formatUnix(ts int64) string {
+	return strconv.FormatInt(ts, 10)
+}
@@ -0,0 +1,65 @@
+package discovery
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// ExtractJSONObject parses the JSON object in command output, spanning from the
+// first "{" to the last "}" so headers or log lines around a body are ignored
+func ExtractJSONObject(output string) (map[string]interface{}, bool) {
+	start := strings.Index(output, "{")
+	if start == -1 {
+		return nil, false
+	}
+	end := strings.LastIndex(output, "}")
+	if end <= start {
+		return nil, false
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(output[start:end+1]), &data); err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// ExtractErrorCode returns the API error code in a JSON body, looking at
+// error.code, errors[0].code, code, error_code, errorCode and a string error
+// field, in that order. Numeric codes are returned in decimal form.
+func ExtractErrorCode(data map[string]interface{}) (string, bool) {
+	if errObj, ok := data["error"].(map[string]interface{}); ok {
+		if code, ok := errorCodeString(errObj["code"]); ok {
+			return code, true
+		}
+	}
+	if errs, ok := data["errors"].([]interface{}); ok && len(errs) > 0 {
+		if first, ok := errs[0].(map[string]interface{}); ok {
+			if code, ok := errorCodeString(first["code"]); ok {
+				return code, true
+			}
+		}
+	}
+	for _, key := range []string{"code", "error_code", "errorCode"} {
+		if code, ok := errorCodeString(data[key]); ok {
+			return code, true
+		}
+	}
+	if code, ok := data["error"].(string); ok && code != "" {
+		return code, true
+	}
+	return "", false
+}
+
+// errorCodeString converts a string or numeric JSON value to a code
+func errorCodeString(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, v != ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	default:
+		return "", false
+	}
+}
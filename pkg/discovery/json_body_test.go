@@ -0,0 +1,39 @@
+package discovery
+
+import "testing"
+
+func TestExtractErrorCode(t *testing.T) {
+	tests := []struct {
+		name      string
+		output    string
+		wantCode  string
+		wantFound bool
+	}{
+		{name: "nested error code", output: `{"error":{"code":"TRY_AGAIN","message":"busy"}}`, wantCode: "TRY_AGAIN", wantFound: true},
+		{name: "numeric code", output: `{"error":{"code":503}}`, wantCode: "503", wantFound: true},
+		{name: "errors array", output: `{"errors":[{"code":"throttled"},{"code":"other"}]}`, wantCode: "throttled", wantFound: true},
+		{name: "top-level code", output: `{"code":"RESOURCE_EXHAUSTED"}`, wantCode: "RESOURCE_EXHAUSTED", wantFound: true},
+		{name: "error_code", output: `{"error_code":1015}`, wantCode: "1015", wantFound: true},
+		{name: "string error", output: `{"ok":false,"error":"ratelimited"}`, wantCode: "ratelimited", wantFound: true},
+		{name: "body after headers", output: "HTTP/1.1 429 Too Many Requests\r\n\r\n{\"code\":\"SLOW_DOWN\"}\n", wantCode: "SLOW_DOWN", wantFound: true},
+		{name: "no code", output: `{"message":"busy"}`},
+		{name: "not JSON", output: "Service Unavailable"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var code string
+			var found bool
+			if data, ok := ExtractJSONObject(tt.output); ok {
+				code, found = ExtractErrorCode(data)
+			}
+
+			if found != tt.wantFound {
+				t.Errorf("found = %v, want %v", found, tt.wantFound)
+			}
+			if code != tt.wantCode {
+				t.Errorf("code = %q, want %q", code, tt.wantCode)
+			}
+		})
+	}
+}
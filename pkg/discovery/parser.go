@@ -1,7 +1,6 @@
 package discovery
 
 import (
-	"encoding/json"
 	"net/url"
 	"regexp"
 	"strconv"
@@ -78,11 +77,7 @@ func (p *Parser) ParseFromCommandOutput(stdout, stderr string, exitCode int, com
 	}
 
 	// No rate limit information found
-	return &DiscoveryResult{
-		Found:      false,
-		Source:     SourceHTTPHeader,
-		Confidence: 0.0,
-	}
+	return notFoundResult()
 }
 
 // extractResourceInfo extracts resource identification from command
@@ -241,6 +236,9 @@ func (p *Parser) parseHTTPHeaders(output, resourceID, host, path string) *Discov
 		info.ResetTime = time.Now().Add(info.Window)
 	}
 
+	// When several windows are reported, the most restrictive one governs
+	applyMostRestrictiveWindow(info, output)
+
 	// Set default window if not determined
 	if info.Window == 0 {
 		info.Window = time.Hour // Default to 1 hour
@@ -258,6 +256,21 @@ func (p *Parser) parseHTTPHeaders(output, resourceID, host, path string) *Discov
 	}
 }
 
+// applyMostRestrictiveWindow overrides info with the most restrictive of the
+// rate limit windows reported in output, if any
+func applyMostRestrictiveWindow(info *RateLimitInfo, output string) {
+	w := MostRestrictiveWindow(ParseRateLimitWindows(output), time.Now())
+	if w == nil {
+		return
+	}
+	if w.Limit >= 0 {
+		info.Limit = w.Limit
+	}
+	info.Remaining = max(w.Remaining, 0)
+	info.ResetTime = w.ResetTime
+	info.Window = time.Until(w.ResetTime)
+}
+
 // extractRateLimitHeaders extracts rate limit headers from HTTP response text
 func (p *Parser) extractRateLimitHeaders(output string) *RateLimitHeaders {
 	headers := &RateLimitHeaders{}
@@ -332,27 +345,8 @@ func (p *Parser) hasRateLimitInfo(headers *RateLimitHeaders) bool {
 
 // parseJSONResponse extracts rate limit information from JSON response bodies
 func (p *Parser) parseJSONResponse(output, resourceID, host, path string) *DiscoveryResult {
-	// Look for JSON content
-	if !strings.Contains(output, "{") {
-		return &DiscoveryResult{Found: false}
-	}
-
-	// Find JSON in the output
-	start := strings.Index(output, "{")
-	if start == -1 {
-		return &DiscoveryResult{Found: false}
-	}
-
-	end := strings.LastIndex(output, "}")
-	if end == -1 || end <= start {
-		return &DiscoveryResult{Found: false}
-	}
-
-	jsonStr := output[start : end+1]
-
-	// Parse JSON
-	var data map[string]interface{}
-	if err := json.Unmarshal([]byte(jsonStr), &data); err != nil {
+	data, ok := ExtractJSONObject(output)
+	if !ok {
 		return &DiscoveryResult{Found: false}
 	}
 
@@ -409,6 +403,9 @@ func (p *Parser) parseJSONResponse(output, resourceID, host, path string) *Disco
 		}
 	}
 
+	// When several windows are reported, the most restrictive one governs
+	applyMostRestrictiveWindow(info, output)
+
 	// Set default window if not determined
 	if info.Window == 0 {
 		info.Window = time.Hour
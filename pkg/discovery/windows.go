@@ -0,0 +1,79 @@
+package discovery
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimitWindow is one rate limit reported by a response. Some services
+// report several at once, each with its own header family and reset time;
+// GitHub, for example, sends X-RateLimit-* for its primary limit alongside
+// X-RateLimit-Secondary-* headers.
+type RateLimitWindow struct {
+	Family    string    // Lowercased header prefix, such as "x-ratelimit-secondary"
+	Limit     int       // -1 when not reported
+	Remaining int       // -1 when not reported
+	ResetTime time.Time // Zero when not reported
+}
+
+// windowHeaderPattern matches the limit, remaining and reset headers of any
+// X-RateLimit-* or X-Rate-Limit-* family, capturing the family prefix
+var windowHeaderPattern = regexp.MustCompile(`(?i)(?:^|[^a-z0-9-])(x-rate-?limit(?:-[a-z0-9]+)*?)-(limit|remaining|reset):[ \t]*(\d+)`)
+
+// ParseRateLimitWindows extracts every rate limit window present in output, in
+// the order their families first appear. When a header repeats, as in a chain
+// of redirected responses, its first value is used.
+func ParseRateLimitWindows(output string) []RateLimitWindow {
+	var windows []RateLimitWindow
+	index := make(map[string]int)
+	seen := make(map[string]bool)
+
+	for _, match := range windowHeaderPattern.FindAllStringSubmatch(output, -1) {
+		family, field := strings.ToLower(match[1]), strings.ToLower(match[2])
+		if seen[family+"-"+field] {
+			continue
+		}
+		value, err := strconv.ParseInt(match[3], 10, 64)
+		if err != nil {
+			continue
+		}
+		seen[family+"-"+field] = true
+
+		i, ok := index[family]
+		if !ok {
+			i = len(windows)
+			index[family] = i
+			windows = append(windows, RateLimitWindow{Family: family, Limit: -1, Remaining: -1})
+		}
+		switch field {
+		case "limit":
+			windows[i].Limit = int(value)
+		case "remaining":
+			windows[i].Remaining = int(value)
+		case "reset":
+			windows[i].ResetTime = time.Unix(value, 0)
+		}
+	}
+	return windows
+}
+
+// MostRestrictiveWindow returns the window that holds requests back longest:
+// of the windows that reset after now and report no remaining requests (or
+// don't report remaining at all), the one resetting last, since requests are
+// only allowed again once every exhausted window has reset. It returns nil when
+// no window blocks.
+func MostRestrictiveWindow(windows []RateLimitWindow, now time.Time) *RateLimitWindow {
+	var restrictive *RateLimitWindow
+	for i := range windows {
+		w := &windows[i]
+		if w.Remaining > 0 || !w.ResetTime.After(now) {
+			continue
+		}
+		if restrictive == nil || w.ResetTime.After(restrictive.ResetTime) {
+			restrictive = w
+		}
+	}
+	return restrictive
+}
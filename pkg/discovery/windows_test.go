@@ -0,0 +1,113 @@
+package discovery
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// githubLimits renders a response carrying both GitHub's primary and
+// secondary rate limit headers
+func githubLimits(primaryRemaining int, primaryReset time.Time, secondaryRemaining int, secondaryReset time.Time) string {
+	return fmt.Sprintf("HTTP/1.1 403 Forbidden\r\n"+
+		"X-RateLimit-Limit: 5000\r\nX-RateLimit-Remaining: %d\r\nX-RateLimit-Reset: %d\r\n"+
+		"X-RateLimit-Secondary-Limit: 100\r\nX-RateLimit-Secondary-Remaining: %d\r\nX-RateLimit-Secondary-Reset: %d\r\n\r\n",
+		primaryRemaining, primaryReset.Unix(), secondaryRemaining, secondaryReset.Unix())
+}
+
+func TestParseRateLimitWindows(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+
+	tests := []struct {
+		name   string
+		output string
+		want   []RateLimitWindow
+	}{
+		{
+			name:   "primary and secondary limits",
+			output: githubLimits(0, now.Add(time.Minute), 0, now.Add(10*time.Minute)),
+			want: []RateLimitWindow{
+				{Family: "x-ratelimit", Limit: 5000, Remaining: 0, ResetTime: now.Add(time.Minute)},
+				{Family: "x-ratelimit-secondary", Limit: 100, Remaining: 0, ResetTime: now.Add(10 * time.Minute)},
+			},
+		},
+		{
+			name:   "retry hint only",
+			output: "HTTP/1.1 429 Too Many Requests\r\nX-RateLimit-Retry-After: 30\r\nX-RateLimit-Used: 5\r\n\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			windows := ParseRateLimitWindows(tt.output)
+
+			if len(windows) != len(tt.want) {
+				t.Fatalf("ParseRateLimitWindows() = %d windows, want %d", len(windows), len(tt.want))
+			}
+			for i, want := range tt.want {
+				got := windows[i]
+				if got.Family != want.Family || got.Limit != want.Limit || got.Remaining != want.Remaining || !got.ResetTime.Equal(want.ResetTime) {
+					t.Errorf("window %d = %+v, want %+v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestMostRestrictiveWindow(t *testing.T) {
+	now := time.Now()
+	primary := RateLimitWindow{Family: "x-ratelimit", Remaining: 0, ResetTime: now.Add(time.Minute)}
+	secondary := RateLimitWindow{Family: "x-ratelimit-secondary", Remaining: 0, ResetTime: now.Add(10 * time.Minute)}
+
+	tests := []struct {
+		name     string
+		windows  []RateLimitWindow
+		expected string
+	}{
+		{"LongerResetDominates", []RateLimitWindow{primary, secondary}, "x-ratelimit-secondary"},
+		{"OrderDoesNotMatter", []RateLimitWindow{secondary, primary}, "x-ratelimit-secondary"},
+		{"WindowWithRemainingDoesNotBlock", []RateLimitWindow{primary, {Family: "x-ratelimit-secondary", Remaining: 3, ResetTime: now.Add(10 * time.Minute)}}, "x-ratelimit"},
+		{"PastResetDoesNotBlock", []RateLimitWindow{{Family: "x-ratelimit", Remaining: 0, ResetTime: now.Add(-time.Minute)}}, ""},
+		{"NoWindows", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := MostRestrictiveWindow(tt.windows, now)
+
+			if tt.expected == "" {
+				if w != nil {
+					t.Errorf("MostRestrictiveWindow() = %s, want none", w.Family)
+				}
+				return
+			}
+			if w == nil {
+				t.Fatalf("MostRestrictiveWindow() = none, want %s", tt.expected)
+			}
+			if w.Family != tt.expected {
+				t.Errorf("MostRestrictiveWindow() = %s, want %s", w.Family, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParser_SecondaryLimitDominates(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	secondaryReset := now.Add(10 * time.Minute)
+	output := githubLimits(0, now.Add(time.Minute), 0, secondaryReset)
+
+	result := NewParser().ParseFromCommandOutput(output, "", 22, []string{"curl", "-i", "https://api.github.com/user"})
+
+	if !result.Found {
+		t.Fatal("expected rate limit info to be found")
+	}
+	if result.Info.Limit != 100 || result.Info.Remaining != 0 {
+		t.Errorf("Limit/Remaining = %d/%d, want 100/0", result.Info.Limit, result.Info.Remaining)
+	}
+	if !result.Info.ResetTime.Equal(secondaryReset) {
+		t.Errorf("ResetTime = %v, want %v", result.Info.ResetTime, secondaryReset)
+	}
+	if result.Info.Window <= 9*time.Minute {
+		t.Errorf("Window = %v, want more than 9m", result.Info.Window)
+	}
+}
@@ -0,0 +1,17 @@
+package executor
+
+import "fmt"
+
+// abortMatched reports whether a failed attempt's output matches AbortPattern,
+// warning that retrying stops. The attempt's own exit code and reason are kept,
+// unlike a failure pattern, which replaces them.
+func (e *Executor) abortMatched(attempt int, output CommandOutput) bool {
+	if e.AbortPattern == nil {
+		return false
+	}
+	if !e.AbortPattern.MatchString(output.Stdout) && !e.AbortPattern.MatchString(output.Stderr) {
+		return false
+	}
+	e.warn(fmt.Sprintf("attempt %d matched abort pattern %q; not retrying", attempt, e.AbortPattern.String()))
+	return true
+}
@@ -0,0 +1,58 @@
+package executor
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+
+	"github.com/shaneisley/patience/pkg/ui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutor_AbortPatternStopsWithOriginalExitCode(t *testing.T) {
+	// Given a command that fails with exit code 3 and an unrecoverable error
+	runner := &MockHTTPCommandRunner{responses: []MockHTTPResponse{
+		{ExitCode: 3, Stderr: "error: invalid credentials"},
+		{ExitCode: 0, Stdout: "ok"},
+	}}
+	var buf bytes.Buffer
+	executor := &Executor{
+		MaxAttempts:  5,
+		Runner:       runner,
+		Reporter:     ui.NewReporter(&buf),
+		AbortPattern: regexp.MustCompile(`invalid credentials`),
+	}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"deploy"})
+
+	// Then retrying should stop after attempt 1 with the child's exit code and reason
+	require.ErrorIs(t, err, ErrFailurePattern)
+	assert.False(t, result.Success)
+	assert.Equal(t, 1, result.AttemptCount)
+	assert.Equal(t, 3, result.ExitCode)
+	assert.Equal(t, "exit code 3", result.Reason)
+	assert.Contains(t, buf.String(), `attempt 1 matched abort pattern "invalid credentials"; not retrying`)
+}
+
+func TestExecutor_AbortPatternIgnoresOtherFailures(t *testing.T) {
+	// Given failures that don't match the abort pattern
+	runner := &MockHTTPCommandRunner{responses: []MockHTTPResponse{
+		{ExitCode: 3, Stderr: "error: connection refused"},
+		{ExitCode: 0, Stdout: "invalid credentials are not checked on success"},
+	}}
+	executor := &Executor{
+		MaxAttempts:  3,
+		Runner:       runner,
+		AbortPattern: regexp.MustCompile(`invalid credentials`),
+	}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"deploy"})
+
+	// Then the run should retry and succeed as usual
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, 2, result.AttemptCount)
+}
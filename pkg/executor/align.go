@@ -0,0 +1,37 @@
+package executor
+
+import "time"
+
+// ClockAlignment spaces retries to land on wall-clock boundaries: every
+// Interval from Base, or from the zero time (whole minutes, hours and so on in
+// UTC) when Base is unset. It turns the strategy's relative delay into a wait
+// for the first boundary at or after it, so attempts stay on the boundaries
+// however long each one runs.
+type ClockAlignment struct {
+	Interval time.Duration
+	Base     time.Time
+}
+
+// Delay returns how long to wait from now until the next boundary that is
+// later than now and no earlier than now+minDelay
+func (a ClockAlignment) Delay(now time.Time, minDelay time.Duration) time.Duration {
+	if a.Interval <= 0 {
+		return minDelay
+	}
+
+	earliest := now.Add(minDelay)
+	var boundary time.Time
+	if a.Base.IsZero() {
+		boundary = earliest.Truncate(a.Interval)
+	} else {
+		steps := earliest.Sub(a.Base) / a.Interval
+		boundary = a.Base.Add(steps * a.Interval)
+		if boundary.After(earliest) {
+			boundary = boundary.Add(-a.Interval)
+		}
+	}
+	if boundary.Before(earliest) || !boundary.After(now) {
+		boundary = boundary.Add(a.Interval)
+	}
+	return boundary.Sub(now)
+}
@@ -0,0 +1,92 @@
+package executor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shaneisley/patience/pkg/backoff"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClockAlignment_Delay(t *testing.T) {
+	noon := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		alignment ClockAlignment
+		now       time.Time
+		minDelay  time.Duration
+		expected  time.Duration
+	}{
+		{"waits for the next minute", ClockAlignment{Interval: time.Minute}, noon.Add(20 * time.Second), 0, 40 * time.Second},
+		{"skips the current boundary", ClockAlignment{Interval: time.Minute}, noon, 0, time.Minute},
+		{"boundary after the strategy delay", ClockAlignment{Interval: time.Minute}, noon.Add(50 * time.Second), 30 * time.Second, 70 * time.Second},
+		{"strategy delay ending on a boundary", ClockAlignment{Interval: time.Minute}, noon.Add(30 * time.Second), 30 * time.Second, 30 * time.Second},
+		{"offset base", ClockAlignment{Interval: time.Minute, Base: noon.Add(15 * time.Second)}, noon.Add(20 * time.Second), 0, 55 * time.Second},
+		{"base in the future", ClockAlignment{Interval: time.Minute, Base: noon.Add(10 * time.Minute)}, noon.Add(20 * time.Second), 0, 40 * time.Second},
+		{"no interval keeps the delay", ClockAlignment{}, noon.Add(20 * time.Second), 5 * time.Second, 5 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// When computing the aligned delay
+			delay := tt.alignment.Delay(tt.now, tt.minDelay)
+
+			// Then it should end on the expected boundary
+			assert.Equal(t, tt.expected, delay)
+		})
+	}
+}
+
+func TestExecutor_AlignedAttemptsLandOnBoundaries(t *testing.T) {
+	for _, duration := range []time.Duration{0, 7 * time.Second, 34 * time.Second} {
+		t.Run(duration.String(), func(t *testing.T) {
+			// Given a run starting mid-minute whose attempts each take duration,
+			// aligned to whole minutes
+			start := time.Date(2024, 3, 1, 12, 0, 25, 0, time.UTC)
+			clock := &fakeClock{now: start}
+			runner := &clockAdvancingRunner{clock: clock, duration: duration, failures: 3}
+			executor := &Executor{
+				MaxAttempts: 5,
+				Runner:      runner,
+				Alignment:   ClockAlignment{Interval: time.Minute},
+				Clock:       clock,
+			}
+
+			// When Run() is called
+			result, err := executor.Run([]string{"any", "command"})
+
+			// Then every retry should start on a consecutive minute boundary
+			require.NoError(t, err)
+			assert.True(t, result.Success)
+			require.Len(t, runner.starts, 4)
+			assert.Equal(t, start, runner.starts[0])
+			for i, started := range runner.starts[1:] {
+				assert.Equal(t, time.Date(2024, 3, 1, 12, 1+i, 0, 0, time.UTC), started)
+			}
+		})
+	}
+}
+
+func TestExecutor_AlignmentRespectsStrategyDelay(t *testing.T) {
+	// Given attempts aligned to whole minutes with a 90s fixed backoff
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	clock := &fakeClock{now: start}
+	runner := &clockAdvancingRunner{clock: clock, duration: 5 * time.Second, failures: 1}
+	executor := &Executor{
+		MaxAttempts:     3,
+		Runner:          runner,
+		BackoffStrategy: backoff.NewFixed(90 * time.Second),
+		Alignment:       ClockAlignment{Interval: time.Minute},
+		Clock:           clock,
+	}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"any", "command"})
+
+	// Then the retry should wait for the first boundary after the backoff delay
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	require.Len(t, runner.starts, 2)
+	assert.Equal(t, start.Add(2*time.Minute), runner.starts[1])
+}
@@ -0,0 +1,77 @@
+package executor
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AttemptCap limits the delay after failed attempts from Attempt onwards
+type AttemptCap struct {
+	Attempt int
+	Max     time.Duration
+}
+
+// AttemptCaps is a step schedule of per-attempt delay caps, sorted by attempt.
+// Each cap applies from its attempt until the next entry takes over.
+type AttemptCaps []AttemptCap
+
+// ParseAttemptCaps parses a schedule such as "1:1s,3:10s,5:60s". Attempts must
+// be positive and unique; caps must be positive durations.
+func ParseAttemptCaps(spec string) (AttemptCaps, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, nil
+	}
+
+	var caps AttemptCaps
+	seen := make(map[int]bool)
+	for _, entry := range strings.Split(spec, ",") {
+		attemptStr, capStr, found := strings.Cut(strings.TrimSpace(entry), ":")
+		if !found {
+			return nil, fmt.Errorf("invalid attempt cap %q: expected attempt:duration", entry)
+		}
+
+		attempt, err := strconv.Atoi(strings.TrimSpace(attemptStr))
+		if err != nil || attempt < 1 {
+			return nil, fmt.Errorf("invalid attempt cap %q: attempt must be a positive integer", entry)
+		}
+		if seen[attempt] {
+			return nil, fmt.Errorf("invalid attempt cap %q: attempt %d listed twice", entry, attempt)
+		}
+		seen[attempt] = true
+
+		max, err := time.ParseDuration(strings.TrimSpace(capStr))
+		if err != nil || max <= 0 {
+			return nil, fmt.Errorf("invalid attempt cap %q: cap must be a positive duration", entry)
+		}
+
+		caps = append(caps, AttemptCap{Attempt: attempt, Max: max})
+	}
+
+	sort.Slice(caps, func(i, j int) bool { return caps[i].Attempt < caps[j].Attempt })
+	return caps, nil
+}
+
+// Limit returns the cap in effect after the given attempt fails, and false when
+// the attempt precedes the first entry
+func (c AttemptCaps) Limit(attempt int) (time.Duration, bool) {
+	var limit time.Duration
+	found := false
+	for _, entry := range c {
+		if entry.Attempt > attempt {
+			break
+		}
+		limit, found = entry.Max, true
+	}
+	return limit, found
+}
+
+// Apply clamps delay to the cap in effect for attempt
+func (c AttemptCaps) Apply(attempt int, delay time.Duration) time.Duration {
+	if limit, ok := c.Limit(attempt); ok && delay > limit {
+		return limit
+	}
+	return delay
+}
@@ -0,0 +1,72 @@
+package executor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shaneisley/patience/pkg/backoff"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAttemptCaps(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected AttemptCaps
+		wantErr  bool
+	}{
+		{spec: "", expected: nil},
+		{spec: "1:1s,3:10s,5:60s", expected: AttemptCaps{{1, time.Second}, {3, 10 * time.Second}, {5, time.Minute}}},
+		{spec: "5:1m, 2:500ms", expected: AttemptCaps{{2, 500 * time.Millisecond}, {5, time.Minute}}},
+		{spec: "1", wantErr: true},
+		{spec: "0:1s", wantErr: true},
+		{spec: "x:1s", wantErr: true},
+		{spec: "1:soon", wantErr: true},
+		{spec: "1:0s", wantErr: true},
+		{spec: "2:1s,2:5s", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			caps, err := ParseAttemptCaps(tt.spec)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, caps)
+		})
+	}
+}
+
+func TestExecutor_AttemptCapsStepUp(t *testing.T) {
+	// Given a 30s strategy delay and caps stepping up at attempts 3 and 5
+	caps, err := ParseAttemptCaps("3:10s,5:60s,1:1s")
+	require.NoError(t, err)
+	executor := &Executor{
+		BackoffStrategy: backoff.NewFixed(30 * time.Second),
+		AttemptCaps:     caps,
+	}
+
+	// When the delay after each attempt is computed
+	// Then it is clamped by the cap in effect at that attempt
+	expected := []time.Duration{
+		time.Second, time.Second, // attempts 1-2 capped at 1s
+		10 * time.Second, 10 * time.Second, // attempts 3-4 capped at 10s
+		30 * time.Second, 30 * time.Second, // attempt 5+ cap exceeds the strategy delay
+	}
+	for i, want := range expected {
+		assert.Equal(t, want, executor.nextDelay(i+1, i+1, CommandOutput{}), "attempt %d", i+1)
+	}
+}
+
+func TestAttemptCaps_BeforeFirstEntry(t *testing.T) {
+	// Given caps starting at attempt 3
+	caps := AttemptCaps{{Attempt: 3, Max: time.Second}}
+
+	// Then earlier attempts are uncapped
+	_, ok := caps.Limit(2)
+	assert.False(t, ok)
+	assert.Equal(t, time.Minute, caps.Apply(2, time.Minute))
+	assert.Equal(t, time.Second, caps.Apply(3, time.Minute))
+}
@@ -0,0 +1,82 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/shaneisley/patience/pkg/storage"
+)
+
+// attemptBudget counts the attempts used across invocations sharing an
+// AttemptsFile, so that MaxAttempts caps their total
+type attemptBudget struct {
+	path  string
+	used  int // Attempts used by earlier invocations and this one so far
+	limit int // MaxAttempts, or 0 when unlimited
+}
+
+// LoadAttemptsFile reads the number of attempts recorded in path. A missing
+// file has used none.
+func LoadAttemptsFile(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read attempts file: %w", err)
+	}
+
+	used, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || used < 0 {
+		return 0, fmt.Errorf("invalid attempts file %s: expected a non-negative attempt count, got %q", path, strings.TrimSpace(string(data)))
+	}
+	return used, nil
+}
+
+// loadAttemptBudget reads the attempts file before a run. It returns nil when
+// no file is configured, and an early result when earlier invocations have
+// already used every attempt.
+func (e *Executor) loadAttemptBudget() (*attemptBudget, *Result, error) {
+	if e.AttemptsFile == "" {
+		return nil, nil, nil
+	}
+
+	used, err := LoadAttemptsFile(e.AttemptsFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	budget := &attemptBudget{path: e.AttemptsFile, used: used, limit: e.MaxAttempts}
+	if budget.limit > 0 && used >= budget.limit {
+		reason := fmt.Sprintf("attempt budget exhausted: %d of %d attempts used (%s)", used, budget.limit, e.AttemptsFile)
+		e.warn(reason)
+		return nil, &Result{Success: false, Reason: reason, Kind: FailureMaxAttempts}, nil
+	}
+	if used > 0 {
+		e.note(fmt.Sprintf("%d attempt(s) already used from %s", used, e.AttemptsFile))
+	}
+	return budget, nil, nil
+}
+
+// cap lowers the last attempt of a run starting at startAttempt to what is
+// left of the budget
+func (b *attemptBudget) cap(startAttempt, maxAttempts int) int {
+	if b == nil || b.limit == 0 {
+		return maxAttempts
+	}
+	return min(maxAttempts, startAttempt-1+b.limit-b.used)
+}
+
+// spendAttempt records that an attempt is starting. Writing before the attempt runs
+// means a killed invocation still counts it; a failed write is warned about.
+func (e *Executor) spendAttempt(b *attemptBudget) {
+	if b == nil {
+		return
+	}
+	b.used++
+	if err := storage.WriteFileAtomic(b.path, []byte(strconv.Itoa(b.used)+"\n")); err != nil {
+		e.warn(fmt.Sprintf("failed to write attempts file: %v", err))
+	}
+}
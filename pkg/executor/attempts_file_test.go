@@ -0,0 +1,67 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutor_AttemptsFileSharedAcrossInvocations(t *testing.T) {
+	// Given an attempts file shared by invocations allowed 5 attempts in total
+	path := filepath.Join(t.TempDir(), "sync.attempts")
+	newExecutor := func(runner CommandRunner) *Executor {
+		return &Executor{MaxAttempts: 5, Runner: runner, AttemptsFile: path}
+	}
+
+	// When the first invocation succeeds on its third attempt
+	first := &FakeCommandRunnerWithSequence{ExitCodes: []int{1, 1, 0}}
+	result, err := newExecutor(first).Run([]string{"./sync.sh"})
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+
+	// Then the file records the attempts used
+	used, err := LoadAttemptsFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 3, used)
+
+	// When a second invocation keeps failing
+	second := &FakeCommandRunner{ExitCode: 1}
+	result, err = newExecutor(second).Run([]string{"./sync.sh"})
+
+	// Then it only gets the 2 attempts left of the budget
+	require.ErrorIs(t, err, ErrMaxAttempts)
+	assert.False(t, result.Success)
+	assert.Equal(t, 2, second.CallCount)
+	assert.Equal(t, 2, result.AttemptCount)
+
+	// And a third invocation stops without running the command
+	third := &FakeCommandRunner{ExitCode: 0}
+	result, err = newExecutor(third).Run([]string{"./sync.sh"})
+	require.ErrorIs(t, err, ErrMaxAttempts)
+	assert.False(t, result.Success)
+	assert.Equal(t, FailureMaxAttempts, result.Kind)
+	assert.Contains(t, result.Reason, "attempt budget exhausted: 5 of 5 attempts used")
+	assert.Zero(t, third.CallCount)
+}
+
+func TestLoadAttemptsFile(t *testing.T) {
+	dir := t.TempDir()
+
+	// A missing file has used no attempts
+	used, err := LoadAttemptsFile(filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+	assert.Zero(t, used)
+
+	// A file that is not a count is rejected rather than resetting the budget
+	garbage := filepath.Join(dir, "garbage")
+	require.NoError(t, os.WriteFile(garbage, []byte("three\n"), 0o644))
+	_, err = LoadAttemptsFile(garbage)
+	assert.ErrorContains(t, err, "expected a non-negative attempt count")
+
+	result, err := (&Executor{MaxAttempts: 3, Runner: &FakeCommandRunner{}, AttemptsFile: garbage}).Run([]string{"true"})
+	assert.Nil(t, result)
+	assert.ErrorContains(t, err, "invalid attempts file")
+}
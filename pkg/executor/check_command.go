@@ -0,0 +1,56 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/shaneisley/patience/pkg/conditions"
+)
+
+// Exit codes of the check command
+const (
+	checkExitSuccess = 0
+	checkExitRetry   = 2
+)
+
+// runCheckCommand asks the external check command whether an attempt
+// succeeded. The command runs as a hook (see runHook) and answers with its exit
+// code: 0 for success, 2 to retry, anything else to fail without retrying. The
+// returned bool reports whether retrying should stop.
+func (e *Executor) runCheckCommand(attempt int, output CommandOutput) (conditions.Result, bool, error) {
+	_, stderr, err := runHook(e.CheckCommand, attempt, output)
+	if err == nil {
+		return conditions.Result{Success: true, Reason: "check command succeeded"}, true, nil
+	}
+
+	// A check command that was killed or timed out gave no verdict
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() < 0 {
+		if msg := strings.TrimSpace(stderr); msg != "" {
+			return conditions.Result{}, false, fmt.Errorf("check command failed: %w: %s", err, msg)
+		}
+		return conditions.Result{}, false, fmt.Errorf("check command failed: %w", err)
+	}
+
+	if exitErr.ExitCode() == checkExitRetry {
+		return conditions.Result{Success: false, Reason: "check command requested retry"}, false, nil
+	}
+	return conditions.Result{
+		Success: false,
+		Reason:  fmt.Sprintf("check command failed (exit code %d)", exitErr.ExitCode()),
+	}, true, nil
+}
+
+// checkAttempt replaces the default condition result with the check command's
+// verdict. If the check command cannot be run, the default result is kept with
+// a warning.
+func (e *Executor) checkAttempt(attempt int, output CommandOutput, result conditions.Result, shouldStop bool) (conditions.Result, bool) {
+	checked, stop, err := e.runCheckCommand(attempt, output)
+	if err != nil {
+		e.warn(fmt.Sprintf("%v; using default conditions", err))
+		return result, shouldStop
+	}
+	return checked, stop
+}
@@ -0,0 +1,114 @@
+package executor
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shaneisley/patience/pkg/ui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeCheckScript creates an executable check command that records its stdin
+// and exits with the given code
+func writeCheckScript(t *testing.T, exitCode string) (script, inputLog string) {
+	t.Helper()
+	dir := t.TempDir()
+	inputLog = filepath.Join(dir, "inputs.log")
+	script = filepath.Join(dir, "check.sh")
+	body := `#!/bin/sh
+cat >> "` + inputLog + `"
+echo >> "` + inputLog + `"
+exit ` + exitCode + `
+`
+	require.NoError(t, os.WriteFile(script, []byte(body), 0755))
+	return script, inputLog
+}
+
+func TestExecutor_CheckCommandOutcomes(t *testing.T) {
+	tests := []struct {
+		name         string
+		checkExit    string
+		commandExit  int
+		wantSuccess  bool
+		wantAttempts int
+		wantReason   string
+	}{
+		{
+			name:         "success overrides failing exit code",
+			checkExit:    "0",
+			commandExit:  1,
+			wantSuccess:  true,
+			wantAttempts: 1,
+			wantReason:   "check command succeeded",
+		},
+		{
+			name:         "retry overrides successful exit code",
+			checkExit:    "2",
+			commandExit:  0,
+			wantSuccess:  false,
+			wantAttempts: 3,
+			wantReason:   "max retries reached (check command requested retry)",
+		},
+		{
+			name:         "terminal failure stops retrying",
+			checkExit:    "5",
+			commandExit:  0,
+			wantSuccess:  false,
+			wantAttempts: 1,
+			wantReason:   "check command failed (exit code 5)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given a check command that always exits with the given code
+			script, inputLog := writeCheckScript(t, tt.checkExit)
+			runner := &FakeCommandRunner{ExitCode: tt.commandExit}
+			executor := &Executor{
+				MaxAttempts:  3,
+				Runner:       runner,
+				CheckCommand: script,
+			}
+
+			// When Run() is called
+			result, err := executor.Run([]string{"deploy"})
+
+			// Then the check command should decide the outcome
+			require.NotNil(t, result)
+			assert.Equal(t, result.Err(), err)
+			assert.Equal(t, tt.wantSuccess, result.Success)
+			assert.Equal(t, tt.wantAttempts, runner.CallCount)
+			assert.Equal(t, tt.wantReason, result.Reason)
+
+			// And it should receive the attempt context as JSON
+			inputs, err := os.ReadFile(inputLog)
+			require.NoError(t, err)
+			assert.Contains(t, string(inputs), fmt.Sprintf(`"attempt":1,"exit_code":%d`, tt.commandExit))
+		})
+	}
+}
+
+func TestExecutor_CheckCommandUnavailableFallsBack(t *testing.T) {
+	// Given a check command that is killed before giving a verdict
+	var buf bytes.Buffer
+	executor := &Executor{
+		MaxAttempts:  3,
+		Runner:       &FakeCommandRunner{ExitCode: 0},
+		Reporter:     ui.NewReporter(&buf),
+		CheckCommand: "kill -9 $$",
+	}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"deploy"})
+
+	// Then the default conditions should decide, with a warning
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, "exit code 0", result.Reason)
+	assert.Contains(t, buf.String(), "check command failed")
+	assert.Contains(t, buf.String(), "using default conditions")
+}
@@ -0,0 +1,26 @@
+package executor
+
+import "time"
+
+// Clock tells the time and waits; tests substitute a fake to control both
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+}
+
+// SystemClock is the real clock
+type SystemClock struct{}
+
+// Now returns the current time
+func (SystemClock) Now() time.Time { return time.Now() }
+
+// Sleep pauses for d
+func (SystemClock) Sleep(d time.Duration) { time.Sleep(d) }
+
+// clock returns the configured Clock, or SystemClock when none is set
+func (e *Executor) clock() Clock {
+	if e.Clock == nil {
+		return SystemClock{}
+	}
+	return e.Clock
+}
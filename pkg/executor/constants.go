@@ -1,9 +1,25 @@
 package executor
 
+import "time"
+
 const (
 	DefaultMaxBufferSize = 10 * 1024 * 1024
 	MemoryThreshold      = 1024 * 1024
 	MaxAttemptsLimit     = 1000
 	SocketPermissions    = 0600
 	DaemonMaxMinutes     = 1440
+
+	// Default timeout overhead is proportional to the timeout, capped for long timeouts
+	DefaultTimeoutOverheadRatio = 0.02
+	MaxTimeoutOverhead          = 50 * time.Millisecond
+
+	// Default per-stream cap on output retained for cumulative pattern matching
+	DefaultMaxCumulativeOutput = 1024 * 1024
 )
+
+// ReasonCostBudgetExceeded is the result reason when MaxCost stops a run
+const ReasonCostBudgetExceeded = "cost budget exceeded"
+
+// costTolerance absorbs floating-point error when summing attempt costs, so a
+// budget of 0.3 covers three attempts costing 0.1
+const costTolerance = 1e-9
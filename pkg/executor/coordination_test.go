@@ -0,0 +1,72 @@
+package executor
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shaneisley/patience/pkg/backoff"
+	"github.com/shaneisley/patience/pkg/daemon"
+	"github.com/shaneisley/patience/pkg/ui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutor_DaemonCoordinationBestEffortFallsBack(t *testing.T) {
+	// Given a Diophantine run whose daemon is not running, in best-effort mode
+	runner := &FakeCommandRunner{ExitCode: 0}
+	var buf bytes.Buffer
+	executor := &Executor{
+		MaxAttempts:        3,
+		Runner:             runner,
+		BackoffStrategy:    backoff.NewDiophantine(5, time.Hour, []time.Duration{0}),
+		Reporter:           ui.NewReporter(&buf),
+		DaemonClient:       daemon.NewDaemonClient(filepath.Join(t.TempDir(), "missing.sock")),
+		DaemonCoordination: CoordinationBestEffort,
+	}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"deploy"})
+
+	// Then it should warn and run the command with local scheduling
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, 1, runner.CallCount)
+	assert.Contains(t, buf.String(), "Daemon unavailable, using local scheduling")
+}
+
+func TestExecutor_DaemonCoordinationRequiredAborts(t *testing.T) {
+	tests := []struct {
+		name   string
+		client *daemon.DaemonClient
+		errMsg string
+	}{
+		{name: "daemon not running", client: daemon.NewDaemonClient(filepath.Join(t.TempDir(), "missing.sock")), errMsg: "daemon unavailable"},
+		{name: "no daemon configured", errMsg: "no daemon is configured"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given a Diophantine run that requires daemon coordination
+			runner := &FakeCommandRunner{ExitCode: 0}
+			executor := &Executor{
+				MaxAttempts:        3,
+				Runner:             runner,
+				BackoffStrategy:    backoff.NewDiophantine(5, time.Hour, []time.Duration{0}),
+				DaemonClient:       tt.client,
+				DaemonCoordination: CoordinationRequired,
+			}
+
+			// When Run() is called
+			result, err := executor.Run([]string{"deploy"})
+
+			// Then the run should abort without running the command
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+			assert.False(t, result.Success)
+			assert.Contains(t, result.Reason, "daemon coordination failed")
+			assert.Equal(t, 0, runner.CallCount)
+		})
+	}
+}
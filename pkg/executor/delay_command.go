@@ -0,0 +1,82 @@
+package executor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// runDelayCommand asks the external delay command for the wait after a failed
+// attempt. The command runs as a hook (see runHook) and must print a duration
+// (e.g. "1.5s") or a number of seconds.
+func (e *Executor) runDelayCommand(attempt int, output CommandOutput) (time.Duration, error) {
+	stdout, stderr, err := runHook(e.DelayCommand, attempt, output)
+	if err != nil {
+		if msg := strings.TrimSpace(stderr); msg != "" {
+			return 0, fmt.Errorf("delay command failed: %w: %s", err, msg)
+		}
+		return 0, fmt.Errorf("delay command failed: %w", err)
+	}
+
+	return parseDelayOutput(stdout)
+}
+
+// parseDelayOutput parses the delay printed by the delay command
+func parseDelayOutput(raw string) (time.Duration, error) {
+	value := strings.TrimSpace(raw)
+	if value == "" {
+		return 0, fmt.Errorf("delay command printed no delay")
+	}
+
+	delay, err := time.ParseDuration(value)
+	if err != nil {
+		seconds, numErr := strconv.ParseFloat(value, 64)
+		if numErr != nil {
+			return 0, fmt.Errorf("delay command printed %q: expected a duration like 1.5s", value)
+		}
+		delay = time.Duration(seconds * float64(time.Second))
+	}
+	if delay < 0 {
+		return 0, fmt.Errorf("delay command printed negative delay %v", delay)
+	}
+	return delay, nil
+}
+
+// nextDelay returns the wait before the attempt following a failed one, asking
+// the backoff strategy for backoffAttempt's delay. The delay command, when
+// configured, overrides the strategy; if it fails the strategy's delay is used
+// with a warning. A delay found in the response body by DelayJSON overrides
+// both. The result is bounded by AttemptCaps and CapDelay, then stretched to
+// the next Alignment boundary.
+func (e *Executor) nextDelay(attempt, backoffAttempt int, output CommandOutput) time.Duration {
+	var delay time.Duration
+	if e.BackoffStrategy != nil {
+		delay = e.BackoffStrategy.Delay(backoffAttempt)
+	}
+
+	if e.DelayCommand != "" {
+		commanded, err := e.runDelayCommand(attempt, output)
+		if err != nil {
+			e.warn(fmt.Sprintf("%v; using strategy delay %v", err, delay))
+		} else {
+			delay = commanded
+		}
+	}
+
+	if e.DelayJSON != nil {
+		if hinted, ok := e.DelayJSON.Delay(output); ok {
+			e.note(fmt.Sprintf("using delay %v from response field %s", hinted, e.DelayJSON.Path))
+			delay = hinted
+		}
+	}
+
+	delay = e.AttemptCaps.Apply(attempt, delay)
+	if e.CapDelay > 0 && delay > e.CapDelay {
+		delay = e.CapDelay
+	}
+	if e.Alignment.Interval > 0 {
+		delay = e.Alignment.Delay(e.clock().Now(), delay)
+	}
+	return delay
+}
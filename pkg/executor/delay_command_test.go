@@ -0,0 +1,128 @@
+package executor
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shaneisley/patience/pkg/backoff"
+	"github.com/shaneisley/patience/pkg/ui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeDelayScript creates an executable helper that records its stdin and
+// prints a delay of attempt*100ms
+func writeDelayScript(t *testing.T) (script, inputLog string) {
+	t.Helper()
+	dir := t.TempDir()
+	inputLog = filepath.Join(dir, "inputs.log")
+	script = filepath.Join(dir, "delay.sh")
+	body := `#!/bin/sh
+input=$(cat)
+echo "$input" >> "` + inputLog + `"
+attempt=$(echo "$input" | sed 's/.*"attempt":\([0-9]*\).*/\1/')
+echo "${attempt}00ms"
+`
+	require.NoError(t, os.WriteFile(script, []byte(body), 0755))
+	return script, inputLog
+}
+
+func TestExecutor_DelayCommandControlsDelay(t *testing.T) {
+	// Given a delay command returning increasing delays and a command that always fails
+	script, inputLog := writeDelayScript(t)
+	var buf bytes.Buffer
+	executor := &Executor{
+		MaxAttempts:     3,
+		Runner:          &FakeCommandRunner{ExitCode: 7},
+		BackoffStrategy: backoff.NewFixed(5 * time.Second),
+		Reporter:        ui.NewReporter(&buf),
+		DelayCommand:    script,
+	}
+
+	// When Run() is called
+	start := time.Now()
+	result, err := executor.Run([]string{"flaky"})
+	elapsed := time.Since(start)
+
+	// Then the helper's delays should replace the strategy's 5s delay
+	require.ErrorIs(t, err, ErrMaxAttempts)
+	assert.False(t, result.Success)
+	assert.Contains(t, buf.String(), "Attempt 1/3 failed (exit code 7). Retrying in 100ms.")
+	assert.Contains(t, buf.String(), "Attempt 2/3 failed (exit code 7). Retrying in 200ms.")
+	assert.GreaterOrEqual(t, elapsed, 300*time.Millisecond)
+	assert.Less(t, elapsed, 5*time.Second)
+
+	// And the helper should receive the attempt context as JSON
+	inputs, err := os.ReadFile(inputLog)
+	require.NoError(t, err)
+	assert.Contains(t, string(inputs), `{"attempt":1,"exit_code":7,"stdout_tail":"","stderr_tail":""}`)
+	assert.Contains(t, string(inputs), `{"attempt":2,"exit_code":7,"stdout_tail":"","stderr_tail":""}`)
+}
+
+func TestExecutor_DelayCommandBoundedByCapDelay(t *testing.T) {
+	// Given a delay command whose delays exceed the cap
+	script, _ := writeDelayScript(t)
+	executor := &Executor{
+		MaxAttempts:  3,
+		Runner:       &FakeCommandRunner{ExitCode: 1},
+		DelayCommand: script,
+		CapDelay:     150 * time.Millisecond,
+	}
+
+	// When the next delays are computed
+	first := executor.nextDelay(1, 1, CommandOutput{ExitCode: 1})
+	second := executor.nextDelay(2, 2, CommandOutput{ExitCode: 1})
+
+	// Then delays above the cap should be clamped
+	assert.Equal(t, 100*time.Millisecond, first)
+	assert.Equal(t, 150*time.Millisecond, second)
+}
+
+func TestExecutor_DelayCommandFailureFallsBackToStrategy(t *testing.T) {
+	// Given a delay command that prints garbage
+	var buf bytes.Buffer
+	executor := &Executor{
+		MaxAttempts:     2,
+		BackoffStrategy: backoff.NewFixed(250 * time.Millisecond),
+		Reporter:        ui.NewReporter(&buf),
+		DelayCommand:    "echo soon",
+	}
+
+	// When the next delay is computed
+	delay := executor.nextDelay(1, 1, CommandOutput{ExitCode: 1})
+
+	// Then the strategy's delay should be used and a warning shown
+	assert.Equal(t, 250*time.Millisecond, delay)
+	assert.Contains(t, buf.String(), `delay command printed "soon"`)
+}
+
+func TestParseDelayOutput(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected time.Duration
+		wantErr  bool
+	}{
+		{"1.5s\n", 1500 * time.Millisecond, false},
+		{"250ms", 250 * time.Millisecond, false},
+		{"2", 2 * time.Second, false},
+		{"0.5", 500 * time.Millisecond, false},
+		{"", 0, true},
+		{"-1s", 0, true},
+		{"later", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			delay, err := parseDelayOutput(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, delay)
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package executor
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shaneisley/patience/pkg/conditions"
+	"github.com/shaneisley/patience/pkg/discovery"
+)
+
+// Units for the numeric field DelayJSON reads
+const (
+	DelayJSONUnitMilliseconds = "ms"
+	DelayJSONUnitSeconds      = "s"
+)
+
+// DelayJSON reads the wait before the next attempt from a numeric field of the
+// attempt's JSON response body, such as {"wait_ms": 1500}
+type DelayJSON struct {
+	// Path is the dotted field path, e.g. "$.retry.wait_ms"
+	Path string
+	// Unit is what one unit of the field is worth
+	Unit time.Duration
+
+	fields []string
+}
+
+// NewDelayJSON parses a field path such as "$.wait_ms" or "retry.wait_ms" and
+// a unit, DelayJSONUnitMilliseconds or DelayJSONUnitSeconds
+func NewDelayJSON(path, unit string) (*DelayJSON, error) {
+	trimmed := strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(path), "$"), ".")
+	if trimmed == "" {
+		return nil, fmt.Errorf("invalid delay JSON path %q: expected a field path such as $.wait_ms", path)
+	}
+	fields := strings.Split(trimmed, ".")
+	for _, field := range fields {
+		if field == "" {
+			return nil, fmt.Errorf("invalid delay JSON path %q: empty field name", path)
+		}
+	}
+
+	d := &DelayJSON{Path: path, fields: fields}
+	switch unit {
+	case DelayJSONUnitMilliseconds:
+		d.Unit = time.Millisecond
+	case DelayJSONUnitSeconds:
+		d.Unit = time.Second
+	default:
+		return nil, fmt.Errorf("invalid delay JSON unit %q: expected %s or %s", unit, DelayJSONUnitMilliseconds, DelayJSONUnitSeconds)
+	}
+	return d, nil
+}
+
+// Delay returns the delay in the attempt's JSON response body, preferring
+// stdout over stderr. The field may be a non-negative number or a string
+// holding one; anything else, or a missing field, gives no delay.
+func (d *DelayJSON) Delay(output CommandOutput) (time.Duration, bool) {
+	for _, stream := range []string{output.Stdout, output.Stderr} {
+		data, ok := discovery.ExtractJSONObject(stream)
+		if !ok {
+			continue
+		}
+		value, ok := conditions.LookupJSONPath(data, d.fields)
+		if !ok {
+			continue
+		}
+		return d.duration(value)
+	}
+	return 0, false
+}
+
+// duration converts the field's value to a delay in Unit
+func (d *DelayJSON) duration(value interface{}) (time.Duration, bool) {
+	var amount float64
+	switch v := value.(type) {
+	case float64:
+		amount = v
+	case string:
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return 0, false
+		}
+		amount = parsed
+	default:
+		return 0, false
+	}
+
+	delay := amount * float64(d.Unit)
+	if amount < 0 || math.IsNaN(delay) || delay >= math.MaxInt64 {
+		return 0, false
+	}
+	return time.Duration(delay), true
+}
@@ -0,0 +1,104 @@
+package executor
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/shaneisley/patience/pkg/backoff"
+	"github.com/shaneisley/patience/pkg/ui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutor_DelayJSONControlsDelay(t *testing.T) {
+	// Given a command whose error body asks to wait 150ms and a slow strategy
+	delayJSON, err := NewDelayJSON("$.wait_ms", DelayJSONUnitMilliseconds)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	executor := &Executor{
+		MaxAttempts:     2,
+		Runner:          &FakeCommandRunnerWithOutput{ExitCode: 1, Stdout: `{"error":"busy","wait_ms":150}`},
+		BackoffStrategy: backoff.NewFixed(5 * time.Second),
+		Reporter:        ui.NewReporter(&buf),
+		DelayJSON:       delayJSON,
+	}
+
+	// When Run() is called
+	start := time.Now()
+	result, err := executor.Run([]string{"curl", "api"})
+	elapsed := time.Since(start)
+
+	// Then the body's wait replaces the strategy's 5s delay
+	require.ErrorIs(t, err, ErrMaxAttempts)
+	assert.False(t, result.Success)
+	assert.Contains(t, buf.String(), "Retrying in 150ms.")
+	assert.GreaterOrEqual(t, elapsed, 150*time.Millisecond)
+	assert.Less(t, elapsed, 5*time.Second)
+}
+
+func TestExecutor_DelayJSON(t *testing.T) {
+	strategyDelay := 250 * time.Millisecond
+	tests := []struct {
+		name     string
+		path     string
+		unit     string
+		capDelay time.Duration
+		output   CommandOutput
+		expected time.Duration
+	}{
+		{"milliseconds", "$.wait_ms", DelayJSONUnitMilliseconds, 0,
+			CommandOutput{Stdout: `{"wait_ms": 1500}`}, 1500 * time.Millisecond},
+		{"seconds", "wait", DelayJSONUnitSeconds, 0,
+			CommandOutput{Stdout: `{"wait": 2.5}`}, 2500 * time.Millisecond},
+		{"nested field as string", "$.retry.wait_ms", DelayJSONUnitMilliseconds, 0,
+			CommandOutput{Stdout: `{"retry": {"wait_ms": "40"}}`}, 40 * time.Millisecond},
+		{"body on stderr after headers", "$.wait_ms", DelayJSONUnitMilliseconds, 0,
+			CommandOutput{Stderr: "HTTP/1.1 503\r\n\r\n{\"wait_ms\": 300}"}, 300 * time.Millisecond},
+		{"bounded by cap", "$.wait_ms", DelayJSONUnitMilliseconds, time.Second,
+			CommandOutput{Stdout: `{"wait_ms": 60000}`}, time.Second},
+		{"missing field keeps strategy delay", "$.wait_ms", DelayJSONUnitMilliseconds, 0,
+			CommandOutput{Stdout: `{"error": "busy"}`}, strategyDelay},
+		{"negative value keeps strategy delay", "$.wait_ms", DelayJSONUnitMilliseconds, 0,
+			CommandOutput{Stdout: `{"wait_ms": -5}`}, strategyDelay},
+		{"non-JSON output keeps strategy delay", "$.wait_ms", DelayJSONUnitMilliseconds, 0,
+			CommandOutput{Stdout: "service unavailable"}, strategyDelay},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given a fixed strategy and a delay read from the response body
+			delayJSON, err := NewDelayJSON(tt.path, tt.unit)
+			require.NoError(t, err)
+			executor := &Executor{
+				BackoffStrategy: backoff.NewFixed(strategyDelay),
+				DelayJSON:       delayJSON,
+				CapDelay:        tt.capDelay,
+			}
+
+			// When the next delay is computed
+			delay := executor.nextDelay(1, 1, tt.output)
+
+			// Then it comes from the body when the field holds a delay
+			assert.Equal(t, tt.expected, delay)
+		})
+	}
+}
+
+func TestNewDelayJSON_Invalid(t *testing.T) {
+	tests := []struct {
+		path, unit, expected string
+	}{
+		{"$", DelayJSONUnitSeconds, "expected a field path"},
+		{"$.retry..wait", DelayJSONUnitSeconds, "empty field name"},
+		{"$.wait", "minutes", `invalid delay JSON unit "minutes"`},
+	}
+
+	for _, tt := range tests {
+		// When parsing an invalid path or unit
+		_, err := NewDelayJSON(tt.path, tt.unit)
+
+		// Then it is rejected
+		assert.ErrorContains(t, err, tt.expected, tt.path)
+	}
+}
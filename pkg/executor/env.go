@@ -0,0 +1,73 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// envNamePattern matches a portable environment variable name
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// EnvPolicy limits the environment child processes see, for retries that
+// must not depend on whatever happens to be set in the caller's shell. A nil
+// policy passes the parent's environment.
+type EnvPolicy struct {
+	// Clear starts from an empty environment: no parent variable is passed,
+	// not even proxy settings, and only explicit NAME=VALUE entries are set
+	Clear bool
+
+	allow []string          // names passed from the parent
+	set   map[string]string // explicit values, overriding the parent's
+}
+
+// NewEnvPolicy builds a policy from allowlist entries, each either NAME, which
+// passes the parent's value, or NAME=VALUE, which sets the variable. With
+// clear, bare names are rejected since no parent variable is passed.
+func NewEnvPolicy(allowlist []string, clear bool) (*EnvPolicy, error) {
+	policy := &EnvPolicy{Clear: clear, set: make(map[string]string)}
+	for _, entry := range allowlist {
+		name, value, explicit := strings.Cut(strings.TrimSpace(entry), "=")
+		if !envNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid environment variable %q: name must be letters, digits and underscores", entry)
+		}
+		switch {
+		case explicit:
+			policy.set[name] = value
+		case clear:
+			return nil, fmt.Errorf("invalid environment variable %q: --env-clear passes no parent variables, give NAME=VALUE", entry)
+		default:
+			policy.allow = append(policy.allow, name)
+		}
+	}
+	return policy, nil
+}
+
+// environment returns the child environment for the parent's variables,
+// looked up with lookup. Allowlisted names missing from the parent are
+// left unset.
+func (p *EnvPolicy) environment(lookup func(string) (string, bool)) []string {
+	env := make([]string, 0, len(p.allow)+len(p.set))
+	for _, name := range p.allow {
+		if _, overridden := p.set[name]; overridden {
+			continue
+		}
+		if value, ok := lookup(name); ok {
+			env = append(env, name+"="+value)
+		}
+	}
+	for name, value := range p.set {
+		env = append(env, name+"="+value)
+	}
+	return env
+}
+
+// childEnvironment returns the environment for child processes: the parent's,
+// with proxy settings preserved, or only what the policy allows when one is set
+func childEnvironment(policy *EnvPolicy) []string {
+	if policy == nil {
+		return preserveProxyEnv(os.Environ(), os.LookupEnv)
+	}
+	return policy.environment(os.LookupEnv)
+}
@@ -0,0 +1,99 @@
+package executor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEnvPolicy_Invalid(t *testing.T) {
+	// Names must be portable variable names
+	_, err := NewEnvPolicy([]string{"BAD-NAME"}, false)
+	assert.ErrorContains(t, err, "invalid environment variable")
+	_, err = NewEnvPolicy([]string{"=value"}, false)
+	assert.ErrorContains(t, err, "invalid environment variable")
+
+	// With --env-clear, parent variables can't be passed by name
+	_, err = NewEnvPolicy([]string{"PATH"}, true)
+	assert.ErrorContains(t, err, "--env-clear passes no parent variables")
+}
+
+func TestEnvPolicy_Environment(t *testing.T) {
+	parent := map[string]string{"PATH": "/usr/bin", "HOME": "/home/ci", "SECRET": "s3cr3t", "HTTPS_PROXY": "http://proxy:3128"}
+	lookup := func(name string) (string, bool) {
+		value, ok := parent[name]
+		return value, ok
+	}
+
+	t.Run("AllowlistPassesNamedVariables", func(t *testing.T) {
+		// Given an allowlist of parent variables plus one explicit value
+		policy, err := NewEnvPolicy([]string{"PATH", "MISSING", "REGION=eu-west-1"}, false)
+		require.NoError(t, err)
+
+		// Then only those variables are passed, and missing ones stay unset
+		assert.ElementsMatch(t, []string{"PATH=/usr/bin", "REGION=eu-west-1"}, policy.environment(lookup))
+	})
+
+	t.Run("ExplicitValueOverridesParent", func(t *testing.T) {
+		// Given a name that is both allowlisted and set explicitly
+		policy, err := NewEnvPolicy([]string{"HOME", "HOME=/tmp"}, false)
+		require.NoError(t, err)
+
+		// Then the explicit value wins
+		assert.Equal(t, []string{"HOME=/tmp"}, policy.environment(lookup))
+	})
+
+	t.Run("ClearKeepsOnlyExplicitValues", func(t *testing.T) {
+		// Given a cleared environment with one explicit variable
+		policy, err := NewEnvPolicy([]string{"MODE=ci"}, true)
+		require.NoError(t, err)
+
+		// Then nothing from the parent is passed, not even proxy settings
+		assert.Equal(t, []string{"MODE=ci"}, policy.environment(lookup))
+	})
+}
+
+func TestSystemCommandRunner_EnvPolicy(t *testing.T) {
+	t.Setenv("PATIENCE_TEST_ALLOWED", "yes")
+	t.Setenv("PATIENCE_TEST_HIDDEN", "no")
+	t.Setenv("HTTPS_PROXY", "http://proxy.internal:3128")
+
+	runEnv := func(t *testing.T, policy *EnvPolicy) []string {
+		var discard strings.Builder
+		runner := &SystemCommandRunner{Stdout: &discard, Stderr: &discard, Env: policy}
+		output, err := runner.RunWithOutput([]string{"env"})
+		require.NoError(t, err)
+		require.Equal(t, 0, output.ExitCode)
+		return strings.Fields(output.Stdout)
+	}
+
+	t.Run("Allowlist", func(t *testing.T) {
+		// Given a policy allowing one parent variable and setting another
+		policy, err := NewEnvPolicy([]string{"PATIENCE_TEST_ALLOWED", "PATIENCE_TEST_SET=1"}, false)
+		require.NoError(t, err)
+
+		// When the child prints its environment
+		env := runEnv(t, policy)
+
+		// Then it sees exactly the allowed variables, without proxy settings
+		assert.ElementsMatch(t, []string{"PATIENCE_TEST_ALLOWED=yes", "PATIENCE_TEST_SET=1"}, env)
+	})
+
+	t.Run("Clear", func(t *testing.T) {
+		// Given a cleared environment
+		policy, err := NewEnvPolicy(nil, true)
+		require.NoError(t, err)
+
+		// When the child prints its environment, Then it is empty
+		assert.Empty(t, runEnv(t, policy))
+	})
+
+	t.Run("NoPolicy", func(t *testing.T) {
+		// Without a policy, the parent's environment is passed
+		env := runEnv(t, nil)
+		assert.Contains(t, env, "PATIENCE_TEST_HIDDEN=no")
+		assert.Contains(t, env, "HTTPS_PROXY=http://proxy.internal:3128")
+	})
+}
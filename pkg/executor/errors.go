@@ -0,0 +1,157 @@
+package executor
+
+import (
+	"errors"
+	"io/fs"
+	"os/exec"
+)
+
+// Sentinel errors for the ways a run can end without succeeding. Result.Err and
+// the errors Run returns wrap them, so callers can test outcomes with errors.Is.
+var (
+	ErrTimeout         = errors.New("attempt timed out")
+	ErrMaxAttempts     = errors.New("max attempts reached")
+	ErrBudgetExceeded  = errors.New(ReasonCostBudgetExceeded)
+	ErrCommandNotFound = errors.New("command not found")
+	ErrInterrupted     = errors.New("interrupted")
+	ErrFailurePattern  = errors.New("failure pattern matched")
+	ErrFatalPattern    = errors.New(ReasonFatalPattern)
+
+	// ErrUnbounded is returned by Run, before any attempt, for unlimited
+	// attempts without a total time limit or cost budget
+	ErrUnbounded = errors.New("unlimited attempts need a bound")
+)
+
+// Condition reasons the executor checks for
+const (
+	failurePatternReason = "failure pattern matched" // FailurePattern matched
+	successPatternReason = "success pattern matched" // SuccessPattern matched
+	exitCodeZeroReason   = "exit code 0"             // Succeeded on the exit code alone
+)
+
+// FailureKind classifies why a run ended without succeeding
+type FailureKind int
+
+const (
+	// FailureNone means the run succeeded (or was skipped)
+	FailureNone FailureKind = iota
+	// FailureTimeout means a single-attempt run hit its timeout, or the run
+	// would have outlasted MaxTotalTime
+	FailureTimeout
+	// FailureMaxAttempts means every attempt was used without success
+	FailureMaxAttempts
+	// FailureBudgetExceeded means the next attempt would have exceeded MaxCost
+	FailureBudgetExceeded
+	// FailureCommandNotFound means the command could not be found to start it
+	FailureCommandNotFound
+	// FailureInterrupted means the run was stopped before it finished, e.g. by
+	// declining a long wait
+	FailureInterrupted
+	// FailurePattern means a failure or abort pattern matched the output
+	FailurePattern
+	// FailureFatal means FatalPattern matched an attempt's output
+	FailureFatal
+	// FailureOther covers any other reason to stop, such as a check command
+	// failing or low resources
+	FailureOther
+)
+
+// String returns a short name for the kind
+func (k FailureKind) String() string {
+	switch k {
+	case FailureNone:
+		return "none"
+	case FailureTimeout:
+		return "timeout"
+	case FailureMaxAttempts:
+		return "max_attempts"
+	case FailureBudgetExceeded:
+		return "budget_exceeded"
+	case FailureCommandNotFound:
+		return "command_not_found"
+	case FailureInterrupted:
+		return "interrupted"
+	case FailurePattern:
+		return "failure_pattern"
+	case FailureFatal:
+		return "fatal_pattern"
+	default:
+		return "other"
+	}
+}
+
+// Sentinel returns the sentinel error for the kind, or nil for FailureNone and
+// FailureOther, which have none
+func (k FailureKind) Sentinel() error {
+	switch k {
+	case FailureTimeout:
+		return ErrTimeout
+	case FailureMaxAttempts:
+		return ErrMaxAttempts
+	case FailureBudgetExceeded:
+		return ErrBudgetExceeded
+	case FailureCommandNotFound:
+		return ErrCommandNotFound
+	case FailureInterrupted:
+		return ErrInterrupted
+	case FailurePattern:
+		return ErrFailurePattern
+	case FailureFatal:
+		return ErrFatalPattern
+	default:
+		return nil
+	}
+}
+
+// RunError describes a run that ended without succeeding. It matches the
+// sentinel for its Kind, ErrTimeout when an attempt timed out, and Err.
+type RunError struct {
+	Kind     FailureKind
+	Reason   string
+	TimedOut bool
+	Err      error // underlying error, if any
+}
+
+// Error returns the reason, followed by the underlying error if there is one
+func (e *RunError) Error() string {
+	switch {
+	case e.Err == nil:
+		return e.Reason
+	case e.Reason == "":
+		return e.Err.Error()
+	default:
+		return e.Reason + ": " + e.Err.Error()
+	}
+}
+
+// Unwrap returns the errors the run error matches with errors.Is and errors.As
+func (e *RunError) Unwrap() []error {
+	var errs []error
+	if sentinel := e.Kind.Sentinel(); sentinel != nil {
+		errs = append(errs, sentinel)
+	}
+	if e.TimedOut && e.Kind != FailureTimeout {
+		errs = append(errs, ErrTimeout)
+	}
+	if e.Err != nil {
+		errs = append(errs, e.Err)
+	}
+	return errs
+}
+
+// Err returns nil for a successful run, or a *RunError describing the failure
+func (r *Result) Err() error {
+	if r.Success {
+		return nil
+	}
+	return &RunError{Kind: r.Kind, Reason: r.Reason, TimedOut: r.TimedOut}
+}
+
+// commandError wraps an error from starting a command, marking a missing
+// executable with ErrCommandNotFound
+func commandError(err error) error {
+	if errors.Is(err, exec.ErrNotFound) || errors.Is(err, fs.ErrNotExist) {
+		return &RunError{Kind: FailureCommandNotFound, Err: err}
+	}
+	return err
+}
@@ -0,0 +1,192 @@
+package executor
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shaneisley/patience/pkg/backoff"
+	"github.com/shaneisley/patience/pkg/conditions"
+	"github.com/shaneisley/patience/pkg/ui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutor_ResultErrMatchesSentinels(t *testing.T) {
+	failurePattern, err := conditions.NewChecker("", "(?i)error", false)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		executor func() *Executor
+		command  []string
+		kind     FailureKind
+		matches  []error
+	}{
+		{
+			name: "timeout",
+			executor: func() *Executor {
+				return &Executor{MaxAttempts: 1, Runner: &SystemCommandRunner{}, Timeout: 20 * time.Millisecond}
+			},
+			command: []string{"sleep", "0.1"},
+			kind:    FailureTimeout,
+			matches: []error{ErrTimeout},
+		},
+		{
+			name: "max attempts",
+			executor: func() *Executor {
+				return &Executor{MaxAttempts: 2, Runner: &FakeCommandRunner{ExitCode: 1}}
+			},
+			kind:    FailureMaxAttempts,
+			matches: []error{ErrMaxAttempts},
+		},
+		{
+			name: "max attempts with timeouts",
+			executor: func() *Executor {
+				return &Executor{MaxAttempts: 2, Runner: &SystemCommandRunner{}, Timeout: 20 * time.Millisecond}
+			},
+			command: []string{"sleep", "0.1"},
+			kind:    FailureMaxAttempts,
+			matches: []error{ErrMaxAttempts, ErrTimeout},
+		},
+		{
+			name: "budget exceeded",
+			executor: func() *Executor {
+				return &Executor{
+					MaxAttempts:    5,
+					Runner:         &FakeCommandRunner{ExitCode: 1},
+					CostPerAttempt: 1,
+					MaxCost:        2,
+				}
+			},
+			kind:    FailureBudgetExceeded,
+			matches: []error{ErrBudgetExceeded},
+		},
+		{
+			name: "interrupted",
+			executor: func() *Executor {
+				reporter := ui.NewReporter(&strings.Builder{})
+				reporter.SetInput(strings.NewReader("n\n"), true)
+				return &Executor{
+					MaxAttempts:      3,
+					Runner:           &FakeCommandRunnerWithSequence{ExitCodes: []int{1, 0}},
+					BackoffStrategy:  backoff.NewFixed(20 * time.Millisecond),
+					Reporter:         reporter,
+					ConfirmWaitsOver: 10 * time.Millisecond,
+				}
+			},
+			kind:    FailureInterrupted,
+			matches: []error{ErrInterrupted},
+		},
+		{
+			name: "failure pattern",
+			executor: func() *Executor {
+				return &Executor{
+					MaxAttempts: 3,
+					Runner:      &FakeCommandRunnerWithOutput{Stderr: "Error: connection failed"},
+					Conditions:  failurePattern,
+				}
+			},
+			kind:    FailurePattern,
+			matches: []error{ErrFailurePattern},
+		},
+		{
+			name: "abort pattern",
+			executor: func() *Executor {
+				return &Executor{
+					MaxAttempts:  3,
+					Runner:       &FakeCommandRunnerWithOutput{ExitCode: 1, Stderr: "permission denied"},
+					AbortPattern: regexp.MustCompile("permission denied"),
+					Reporter:     ui.NewReporter(&strings.Builder{}),
+				}
+			},
+			kind:    FailurePattern,
+			matches: []error{ErrFailurePattern},
+		},
+		{
+			name: "fatal pattern",
+			executor: func() *Executor {
+				return &Executor{
+					MaxAttempts:  3,
+					Runner:       &FakeCommandRunnerWithOutput{ExitCode: 0, Stdout: "panic: nil map"},
+					FatalPattern: regexp.MustCompile("panic"),
+					Reporter:     ui.NewReporter(&strings.Builder{}),
+				}
+			},
+			kind:    FailureFatal,
+			matches: []error{ErrFatalPattern},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given an executor set up to stop for the case's reason
+			command := tt.command
+			if command == nil {
+				command = []string{"any", "command"}
+			}
+
+			// When Run() is called
+			result, err := tt.executor().Run(command)
+
+			// Then the run should fail with an error matching the sentinels
+			require.Error(t, err)
+			require.False(t, result.Success)
+			assert.Equal(t, tt.kind, result.Kind)
+			assert.Equal(t, result.Reason, err.Error())
+			for _, sentinel := range tt.matches {
+				assert.ErrorIs(t, err, sentinel)
+			}
+			var asRunErr *RunError
+			require.True(t, errors.As(err, &asRunErr))
+			assert.Equal(t, tt.kind, asRunErr.Kind)
+
+			// And the result should describe the same failure
+			assert.Equal(t, err, result.Err())
+		})
+	}
+}
+
+func TestExecutor_ResultErrNilOnSuccess(t *testing.T) {
+	// Given a command that succeeds
+	executor := &Executor{MaxAttempts: 1, Runner: &FakeCommandRunner{ExitCode: 0}}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"any", "command"})
+
+	// Then there should be no failure to report
+	require.NoError(t, err)
+	assert.Equal(t, FailureNone, result.Kind)
+	assert.NoError(t, result.Err())
+}
+
+func TestExecutor_RunCommandNotFound(t *testing.T) {
+	// Given a command that does not exist
+	executor := &Executor{MaxAttempts: 3, Runner: &SystemCommandRunner{}}
+
+	// When Run() is called
+	_, err := executor.Run([]string{"patience-no-such-command-xyz"})
+
+	// Then the error should match ErrCommandNotFound and keep the exec error
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrCommandNotFound)
+	assert.Contains(t, err.Error(), "patience-no-such-command-xyz")
+	var runErr *RunError
+	require.True(t, errors.As(err, &runErr))
+	assert.Equal(t, FailureCommandNotFound, runErr.Kind)
+}
+
+func TestFailureKind_Sentinel(t *testing.T) {
+	// Given each failure kind, Then it should map to its sentinel (or none)
+	assert.Nil(t, FailureNone.Sentinel())
+	assert.Equal(t, ErrTimeout, FailureTimeout.Sentinel())
+	assert.Equal(t, ErrMaxAttempts, FailureMaxAttempts.Sentinel())
+	assert.Equal(t, ErrBudgetExceeded, FailureBudgetExceeded.Sentinel())
+	assert.Equal(t, ErrCommandNotFound, FailureCommandNotFound.Sentinel())
+	assert.Equal(t, ErrInterrupted, FailureInterrupted.Sentinel())
+	assert.Equal(t, ErrFailurePattern, FailurePattern.Sentinel())
+	assert.Equal(t, ErrFatalPattern, FailureFatal.Sentinel())
+	assert.Nil(t, FailureOther.Sentinel())
+}
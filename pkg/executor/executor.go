@@ -8,13 +8,16 @@ import (
 	"net/url"
 	"os"
 	"os/exec"
+	"regexp"
 	"strings"
 	"syscall"
+	"text/template"
 	"time"
 
 	"github.com/shaneisley/patience/pkg/backoff"
 	"github.com/shaneisley/patience/pkg/conditions"
 	"github.com/shaneisley/patience/pkg/daemon"
+	"github.com/shaneisley/patience/pkg/discovery"
 	"github.com/shaneisley/patience/pkg/metrics"
 	"github.com/shaneisley/patience/pkg/ui"
 )
@@ -37,11 +40,144 @@ func (lb *limitedBuffer) Write(p []byte) (n int, err error) {
 	return lb.Buffer.Write(p)
 }
 
+// outputHistory accumulates output across attempts for cumulative pattern matching.
+// Only the most recent limit bytes of each stream are retained.
+type outputHistory struct {
+	stdout string
+	stderr string
+	limit  int
+}
+
+// add appends an attempt's output, trimming the oldest bytes beyond the limit
+func (h *outputHistory) add(output CommandOutput) {
+	h.stdout = keepTail(joinAttemptOutput(h.stdout, output.Stdout), h.limit)
+	h.stderr = keepTail(joinAttemptOutput(h.stderr, output.Stderr), h.limit)
+}
+
+// joinAttemptOutput appends next to prev, keeping attempts on separate lines
+func joinAttemptOutput(prev, next string) string {
+	if prev == "" || next == "" || strings.HasSuffix(prev, "\n") {
+		return prev + next
+	}
+	return prev + "\n" + next
+}
+
+// combined returns the accumulated output with the given exit code
+func (h *outputHistory) combined(exitCode int) CommandOutput {
+	return CommandOutput{ExitCode: exitCode, Stdout: h.stdout, Stderr: h.stderr}
+}
+
+// keepTail returns the last limit bytes of s (no limit when limit <= 0)
+func keepTail(s string, limit int) string {
+	if limit > 0 && len(s) > limit {
+		return s[len(s)-limit:]
+	}
+	return s
+}
+
+// stabilityTracker counts consecutive successful attempts whose normalized
+// output matched the previous attempt
+type stabilityTracker struct {
+	required int
+	previous string
+	seen     bool
+	streak   int
+}
+
+// observe records an attempt and returns the current unchanged streak.
+// Failed attempts reset the streak and are not used as a baseline.
+func (s *stabilityTracker) observe(output CommandOutput, success bool) int {
+	if !success {
+		s.streak = 0
+		s.seen = false
+		return s.streak
+	}
+
+	normalized := normalizeOutput(output)
+	if s.seen && normalized == s.previous {
+		s.streak++
+	} else {
+		s.streak = 0
+	}
+	s.previous = normalized
+	s.seen = true
+	return s.streak
+}
+
+// check turns a successful result into a retryable failure until the output
+// has been unchanged for the required number of consecutive attempts
+func (s *stabilityTracker) check(result conditions.Result) conditions.Result {
+	if !result.Success {
+		return result
+	}
+	if s.streak >= s.required {
+		return conditions.Result{
+			Success: true,
+			Reason:  fmt.Sprintf("output stable (unchanged %d/%d)", s.streak, s.required),
+		}
+	}
+	return conditions.Result{
+		Success: false,
+		Reason:  fmt.Sprintf("output not yet stable (unchanged %d/%d)", s.streak, s.required),
+	}
+}
+
+// normalizeOutput strips trailing whitespace from each line so that
+// insignificant formatting differences do not count as changes
+func normalizeOutput(output CommandOutput) string {
+	lines := strings.Split(output.Stdout+"\n"+output.Stderr, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\r")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// proxyEnvVars are passed to child processes exactly as set in the parent
+var proxyEnvVars = []string{
+	"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "ALL_PROXY",
+	"http_proxy", "https_proxy", "no_proxy", "all_proxy",
+}
+
+// preserveProxyEnv replaces any proxy entries in env with the parent's values,
+// so later environment tweaks can never drop or alter proxy settings
+func preserveProxyEnv(env []string, lookup func(string) (string, bool)) []string {
+	isProxy := make(map[string]bool, len(proxyEnvVars))
+	for _, name := range proxyEnvVars {
+		isProxy[name] = true
+	}
+
+	result := make([]string, 0, len(env)+len(proxyEnvVars))
+	for _, entry := range env {
+		name, _, _ := strings.Cut(entry, "=")
+		if !isProxy[name] {
+			result = append(result, entry)
+		}
+	}
+
+	for _, name := range proxyEnvVars {
+		if value, ok := lookup(name); ok {
+			result = append(result, name+"="+value)
+		}
+	}
+	return result
+}
+
 // CommandOutput holds the output from a command execution
 type CommandOutput struct {
 	ExitCode int
 	Stdout   string
 	Stderr   string
+
+	// Signal is the signal that terminated the command, or 0 when it exited;
+	// ExitCode is then 128 plus the signal number
+	Signal syscall.Signal
+
+	// Steps holds each step's output when the attempt ran several (see
+	// Executor.Steps), and is nil otherwise
+	Steps []StepOutput
+
+	// Usage is the peak memory and CPU time the command used
+	Usage ResourceUsage
 }
 
 // CommandRunner defines the interface for executing commands
@@ -53,7 +189,20 @@ type CommandRunner interface {
 }
 
 // SystemCommandRunner implements CommandRunner using os/exec
-type SystemCommandRunner struct{}
+type SystemCommandRunner struct {
+	// Stdout and Stderr receive the command's live output (nil forwards to
+	// os.Stdout and os.Stderr); output is captured for conditions either way
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// Env limits the command's environment (nil passes the parent's)
+	Env *EnvPolicy
+
+	// MergeStreams sends stdout and stderr through one pipe, like 2>&1, so the
+	// capture keeps the order the command wrote in. CommandOutput.Stdout then
+	// holds both streams and Stderr is empty, and live output all goes to Stdout.
+	MergeStreams bool
+}
 
 // Run executes a command using os/exec and returns the exit code
 func (r *SystemCommandRunner) Run(command []string) (int, error) {
@@ -71,6 +220,18 @@ func (r *SystemCommandRunner) RunWithOutput(command []string) (CommandOutput, er
 	return r.RunWithOutputAndContext(context.Background(), command)
 }
 
+// outputs returns the writers live output is forwarded to
+func (r *SystemCommandRunner) outputs() (io.Writer, io.Writer) {
+	var stdout, stderr io.Writer = os.Stdout, os.Stderr
+	if r.Stdout != nil {
+		stdout = r.Stdout
+	}
+	if r.Stderr != nil {
+		stderr = r.Stderr
+	}
+	return stdout, stderr
+}
+
 // RunWithOutputAndContext executes a command with context and captures output
 func (r *SystemCommandRunner) RunWithOutputAndContext(ctx context.Context, command []string) (CommandOutput, error) {
 	if len(command) == 0 {
@@ -82,18 +243,25 @@ func (r *SystemCommandRunner) RunWithOutputAndContext(ctx context.Context, comma
 	// Process cleanup improvement: Set process group for better signal handling
 	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
 
-	// Inherit parent environment without modifications
+	// Inherit the parent environment, limited by any Env policy
 	// Note: Previously set CURL_CA_BUNDLE="" which disabled TLS certificate validation,
 	// creating a security vulnerability. Users should configure curl timeouts explicitly
 	// via command arguments if needed (e.g., curl --connect-timeout 10)
-	cmd.Env = os.Environ()
+	cmd.Env = childEnvironment(r.Env)
 
 	// Capture stdout and stderr while also forwarding to terminal
 	// Use limited buffers for large outputs
 	stdoutBuf := &limitedBuffer{limit: DefaultMaxBufferSize}
 	stderrBuf := &limitedBuffer{limit: DefaultMaxBufferSize}
-	cmd.Stdout = io.MultiWriter(os.Stdout, stdoutBuf)
-	cmd.Stderr = io.MultiWriter(os.Stderr, stderrBuf)
+	stdout, stderr := r.outputs()
+	if r.MergeStreams {
+		// os/exec gives the command a single pipe when both are the same writer
+		merged := io.MultiWriter(stdout, stdoutBuf)
+		cmd.Stdout, cmd.Stderr = merged, merged
+	} else {
+		cmd.Stdout = io.MultiWriter(stdout, stdoutBuf)
+		cmd.Stderr = io.MultiWriter(stderr, stderrBuf)
+	}
 
 	// Ensure process group cleanup on context cancellation.
 	// Using cmd.Cancel avoids the data race that occurs when accessing
@@ -111,6 +279,7 @@ func (r *SystemCommandRunner) RunWithOutputAndContext(ctx context.Context, comma
 	output := CommandOutput{
 		Stdout: stdoutBuf.String(),
 		Stderr: stderrBuf.String(),
+		Usage:  processUsage(cmd.ProcessState),
 	}
 
 	// Memory management optimization: Clear buffers after copying strings
@@ -127,7 +296,7 @@ func (r *SystemCommandRunner) RunWithOutputAndContext(ctx context.Context, comma
 			return output, context.DeadlineExceeded
 		}
 		if exitError, ok := err.(*exec.ExitError); ok {
-			output.ExitCode = exitError.ExitCode()
+			output.ExitCode, output.Signal = exitStatus(exitError)
 			return output, nil
 		}
 		output.ExitCode = -1
@@ -140,6 +309,8 @@ func (r *SystemCommandRunner) RunWithOutputAndContext(ctx context.Context, comma
 
 // Executor handles command execution with retry logic
 type Executor struct {
+	// MaxAttempts limits the attempts; 0 retries until success or until
+	// MaxTotalTime or MaxCost stops the run, and Run refuses it without either
 	MaxAttempts     int
 	Runner          CommandRunner
 	BackoffStrategy backoff.Strategy
@@ -148,6 +319,241 @@ type Executor struct {
 	Reporter        *ui.Reporter
 	DaemonClient    *daemon.DaemonClient // Optional daemon client for coordination
 	ResourceID      string               // Resource identifier for rate limiting
+
+	// DaemonCoordination is CoordinationBestEffort (the default when empty) to
+	// fall back to local scheduling when coordinating with the daemon fails, or
+	// CoordinationRequired to abort the run instead
+	DaemonCoordination string
+
+	// MaxPlannedRegistrations bounds how many planned requests are registered
+	// with the daemon ahead of the current attempt; more are registered as
+	// attempts proceed (0 means DefaultMaxPlannedRegistrations)
+	MaxPlannedRegistrations int
+
+	// RespectRemaining caps attempts to the X-RateLimit-Remaining budget reported
+	// by HTTP-aware strategies, avoiding attempts that are certain to be rejected
+	RespectRemaining bool
+
+	// ConfirmWaitsOver prompts via the Reporter before any delay longer than this
+	// threshold, aborting the run if declined (0 disables confirmation)
+	ConfirmWaitsOver time.Duration
+
+	// Minimum captured output required for an attempt to count as successful
+	// (0 disables the check); stdout and stderr are counted together
+	MinOutputLines int
+	MinOutputBytes int
+
+	// TimeoutOverhead is extra time added to Timeout before cancelling an attempt.
+	// When nil, a proportional default is used (see timeoutOverhead).
+	TimeoutOverhead *time.Duration
+
+	// CumulativePatterns evaluates success/failure patterns against the output of
+	// all attempts so far, keeping at most MaxOutputSize bytes per stream
+	// (0 uses DefaultMaxCumulativeOutput)
+	CumulativePatterns bool
+	MaxOutputSize      int
+
+	// Tags label the run's metrics (e.g. env=prod) for aggregation by the daemon
+	Tags map[string]string
+
+	// CustomMetrics are numeric fields (e.g. build_number=1234) recorded in the
+	// run's metrics
+	CustomMetrics map[string]float64
+
+	// StateFile persists progress after each failed attempt so a killed run
+	// resumes its schedule on restart (empty disables)
+	StateFile string
+
+	// StateMaxAge is how long after its last update a state file may still be
+	// resumed; older state is ignored (0 uses DefaultStateMaxAge)
+	StateMaxAge time.Duration
+
+	// AttemptsFile counts attempts across invocations that share it, so that
+	// MaxAttempts caps their total rather than each invocation's (empty
+	// disables); a run finding the budget used up stops without an attempt
+	AttemptsFile string
+
+	// FailureCategories classify a failed run for alerting, e.g. auth failures
+	// that won't heal on their own; the first match is recorded in its metrics
+	FailureCategories []FailureCategory
+
+	// StableAttempts requires successful output to be unchanged from the previous
+	// attempt this many consecutive times before the run succeeds (0 disables)
+	StableAttempts int
+
+	// TimingMarkers receives one machine-readable line per attempt, e.g.
+	// "PATIENCE attempt=1 start_ns=... end_ns=... exit_code=0 success=true" (nil disables)
+	TimingMarkers io.Writer
+
+	// InitialDelay is waited once before the first attempt, for servers that
+	// shouldn't be hit straight away; a resumed run doesn't wait it again (0 disables)
+	InitialDelay time.Duration
+
+	// ProgressFIFO receives a compact record as each attempt starts and ends,
+	// for dashboards reading a named pipe (nil disables)
+	ProgressFIFO *ProgressFIFO
+
+	// IfRunning holds a local lock for the run and decides what to do when another
+	// invocation holds it: IfRunningWait, IfRunningSkip or IfRunningFail (empty disables).
+	// The lock is LockFile, or one derived from ResourceID or the command.
+	IfRunning string
+	LockFile  string
+
+	// DelayCommand is a shell command asked for the delay after each failed
+	// attempt, overriding the backoff strategy (empty disables); see runDelayCommand
+	DelayCommand string
+
+	// DelayJSON, when set, takes the delay after a failed attempt from a field
+	// of its JSON response body, overriding the strategy and DelayCommand for
+	// that attempt; attempts without the field keep their usual delay
+	DelayJSON *DelayJSON
+
+	// CapDelay is an upper bound on any delay between attempts (0 = no cap)
+	CapDelay time.Duration
+
+	// Shell runs the command through sh -c so pipes, redirects and other shell
+	// syntax take effect. A single argument is the script; several are quoted
+	// where needed and joined, keeping bare operators such as "|".
+	Shell bool
+
+	// Steps splits the command at StepSeparator and runs the parts in sequence
+	// within each attempt; the attempt fails at the first step that fails, and
+	// the whole sequence is retried. With Shell, each step runs through sh -c.
+	Steps bool
+
+	// ReportPatternMetrics adds the match counts and timings of the
+	// Conditions patterns to the run's stats, for profiling expensive regexes
+	ReportPatternMetrics bool
+
+	// DiscoverRateLimits parses each attempt's output for rate limit headers or
+	// JSON fields and reports the most informative result in the run's stats and metrics
+	DiscoverRateLimits bool
+
+	// DiscoveryCache, when set along with DiscoverRateLimits, keeps discovered
+	// rate limits across runs: the first attempt waits for a cached limit that
+	// is used up to reset, and the run's findings are saved back
+	DiscoveryCache *discovery.Cache
+
+	// AttemptCaps bounds delays with a per-attempt step schedule (nil = no caps)
+	AttemptCaps AttemptCaps
+
+	// HTTPStatusActions decides attempts by the HTTP status of their response,
+	// e.g. retrying 202 Accepted until a 200 (nil disables)
+	HTTPStatusActions HTTPStatusActions
+
+	// HTTPSuccess requires a successful attempt's final HTTP response to have
+	// a matching status and body fields (nil disables)
+	HTTPSuccess *HTTPSuccessCriteria
+
+	// RetryHTTPStatus retries failed attempts only when their HTTP status
+	// matches, e.g. "5..,408", and stops on any other status (nil disables)
+	RetryHTTPStatus *HTTPStatusMatcher
+
+	// CostPerAttempt is charged for every attempt; the run stops before an attempt
+	// that would take the total past MaxCost (0 disables the budget). The first
+	// attempt always runs.
+	CostPerAttempt float64
+	MaxCost        float64
+
+	// CheckCommand is a shell command that decides each attempt's outcome from its
+	// exit code, overriding the success conditions (empty disables); see runCheckCommand
+	CheckCommand string
+
+	// MetricsSync makes DispatchMetrics wait until the run's metrics are written
+	// to the daemon socket and report it in Result.MetricsDelivered, instead of
+	// sending them in the background
+	MetricsSync bool
+
+	// MetricsFile is an NDJSON file DispatchMetrics appends the run's metrics to,
+	// for replaying into the daemon later (empty disables)
+	MetricsFile string
+
+	// RetryIf decides after each attempt whether to retry it, taking over from
+	// the success conditions and the check command (nil disables); see
+	// applyRetryIf
+	RetryIf *conditions.RetryExpression
+
+	// ProgressPattern marks failed attempts that still made progress, such as a
+	// resumable upload sending more chunks; when it matches, the backoff restarts
+	// from the base delay (nil disables)
+	ProgressPattern *regexp.Regexp
+
+	// AbortPattern stops retrying when a failed attempt's output matches it, for
+	// errors that will never recover such as invalid credentials. Unlike a failure
+	// pattern the attempt's exit code and reason are reported unchanged (nil disables).
+	AbortPattern *regexp.Regexp
+
+	// FatalPattern stops the run as soon as any attempt's output matches it,
+	// even one that otherwise succeeded, for output such as "panic" that must
+	// fail a deploy gate. The run reports ReasonFatalPattern and FatalExitCode
+	// (0 means DefaultFatalExitCode) instead of the attempt's exit code (nil disables).
+	FatalPattern  *regexp.Regexp
+	FatalExitCode int
+
+	// ReasonTemplate, when set, renders the reason reported for failed
+	// attempts and the run's final reason from the attempt's ReasonData, for
+	// wording such as "HTTP 503" in place of "exit code 22" (nil keeps the defaults)
+	ReasonTemplate *template.Template
+
+	// RetryJSONErrorCodes, when set, decides failed attempts by the error code in
+	// their JSON response body: listed codes are retried and any other code stops
+	// the run. Attempts without a code are judged as usual.
+	RetryJSONErrorCodes []string
+
+	// IdempotencyHeader names a header added to curl commands with a key that is
+	// generated once per run and sent on every attempt (empty disables)
+	IdempotencyHeader string
+
+	// HeaderRedaction hides sensitive header values in the command recorded in
+	// run metrics; the zero value redacts DefaultRedactedHeaders
+	HeaderRedaction HeaderRedaction
+
+	// WarnAfter warns when an attempt is still running after this long, leaving
+	// it to finish or reach Timeout (0 disables)
+	WarnAfter time.Duration
+
+	// RequirePasses runs up to MaxAttempts attempts and succeeds once this many
+	// have passed, in any order, for flaky commands such as test suites. The run
+	// stops early when the remaining attempts can no longer reach it (0 disables).
+	RequirePasses int
+
+	// PrefixOutput prepends "[attempt N] " to each line of the command's live
+	// stdout and stderr, so runs sharing a log stay readable (binary output is
+	// passed through unchanged)
+	PrefixOutput bool
+
+	// MinFreeDisk and MinFreeMemory, in bytes, stop the run before an attempt
+	// when free disk space in the working directory or free memory is lower
+	// (0 disables each). Resources is nil for SystemResources.
+	MinFreeDisk   uint64
+	MinFreeMemory uint64
+	Resources     ResourceChecker
+
+	// FirstSuccessExitFast returns a minimal Result, without Stats or Metrics,
+	// when the first attempt succeeds and no Conditions are configured,
+	// skipping the summary work for latency-sensitive scripts
+	FirstSuccessExitFast bool
+
+	// RequireSuccessPattern makes an attempt succeed only when the success
+	// pattern in Conditions matches, instead of falling back to the exit code;
+	// it also stops HTTP-aware runs treating an empty 2xx response as success
+	RequireSuccessPattern bool
+
+	// AllowedWindow, when set, holds each attempt until the time of day is
+	// inside the window
+	AllowedWindow *AllowedWindow
+
+	// MaxTotalTime stops the run instead of waiting, for a delay or the allowed
+	// window, past this long after it started (0 = no limit)
+	MaxTotalTime time.Duration
+
+	// Alignment, when its Interval is set, stretches each delay to end on the
+	// next wall-clock boundary
+	Alignment ClockAlignment
+
+	// Clock tells the time for AllowedWindow, MaxTotalTime and Alignment and
+	// performs the waits between attempts; nil means SystemClock
+	Clock Clock
 }
 
 // NewExecutor creates a new Executor with default SystemCommandRunner and no backoff
@@ -197,19 +603,50 @@ type Result struct {
 	ExitCode     int
 	TimedOut     bool
 	Reason       string
+	Kind         FailureKind // why an unsuccessful run stopped; see Err
 	Stats        *ui.RunStats
 	Metrics      *metrics.RunMetrics
+
+	// TotalDelay is the time spent waiting between attempts (including a resumed
+	// wait and waits for the allowed window), TotalExecution the time spent in
+	// attempts; the rest of the run's duration is patience's own overhead
+	TotalDelay     time.Duration
+	TotalExecution time.Duration
+
+	// MetricsDelivered reports that DispatchMetrics wrote Metrics to the daemon
+	// socket synchronously; it is false after an asynchronous send or a failed
+	// write
+	MetricsDelivered bool
 }
 
 // executeAttempt runs a single command attempt and returns the output, error, and timeout status
-func (e *Executor) executeAttempt(command []string) (CommandOutput, error, bool) {
+func (e *Executor) executeAttempt(attempt int, command []string) (CommandOutput, error, bool) {
+	runner := e.attemptRunner(attempt)
+
+	var ctx context.Context
 	if e.Timeout > 0 {
-		// Network timeout reliability: Add small buffer to account for context switching overhead
-		adjustedTimeout := e.Timeout + (50 * time.Millisecond)
-		ctx, cancel := context.WithTimeout(context.Background(), adjustedTimeout)
+		// Add a small buffer to account for process startup and scheduling overhead
+		adjustedTimeout := e.Timeout + e.timeoutOverhead()
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.Background(), adjustedTimeout)
 		defer cancel()
+	}
 
-		output, err := e.Runner.RunWithOutputAndContext(ctx, command)
+	if e.Steps {
+		return e.executeSteps(ctx, runner, command)
+	}
+	return e.runCommand(ctx, runner, command)
+}
+
+// runCommand runs a single command, bounded by ctx when it is not nil, and
+// reports whether the timeout occurred
+func (e *Executor) runCommand(ctx context.Context, runner CommandRunner, command []string) (CommandOutput, error, bool) {
+	if e.Shell {
+		command = []string{"sh", "-c", shellScript(command)}
+	}
+
+	if ctx != nil {
+		output, err := runner.RunWithOutputAndContext(ctx, command)
 		if err == context.DeadlineExceeded {
 			return CommandOutput{ExitCode: -1}, nil, true // Timeout occurred
 		}
@@ -217,22 +654,45 @@ func (e *Executor) executeAttempt(command []string) (CommandOutput, error, bool)
 	}
 
 	// No timeout configured, use regular run
-	output, err := e.Runner.RunWithOutput(command)
+	output, err := runner.RunWithOutput(command)
 	return output, err, false
 }
 
-// coordinateWithDaemon handles scheduling coordination with the daemon for Diophantine strategy
-func (e *Executor) coordinateWithDaemon(strategy *backoff.DiophantineStrategy, command []string) error {
+// timeoutOverhead returns the configured overhead, or a default proportional to
+// the timeout (DefaultTimeoutOverheadRatio, capped at MaxTimeoutOverhead)
+func (e *Executor) timeoutOverhead() time.Duration {
+	if e.TimeoutOverhead != nil {
+		return *e.TimeoutOverhead
+	}
+
+	overhead := time.Duration(float64(e.Timeout) * DefaultTimeoutOverheadRatio)
+	if overhead > MaxTimeoutOverhead {
+		overhead = MaxTimeoutOverhead
+	}
+	return overhead
+}
+
+// Policies for a run whose daemon coordination fails
+const (
+	CoordinationBestEffort = "best-effort" // Warn and schedule locally
+	CoordinationRequired   = "required"    // Abort the run
+)
+
+// coordinateWithDaemon handles scheduling coordination with the daemon for
+// Diophantine strategy. It returns the run's planned requests, of which only
+// the first batch has been registered; nil means coordination was skipped.
+func (e *Executor) coordinateWithDaemon(strategy *backoff.DiophantineStrategy, command []string) (*plannedRegistrations, error) {
+	required := e.DaemonCoordination == CoordinationRequired
+
 	// If no daemon client is configured, skip coordination (fallback mode)
 	if e.DaemonClient == nil {
-		return nil
+		if required {
+			return nil, fmt.Errorf("daemon coordination is required but no daemon is configured")
+		}
+		return nil, nil
 	}
 
-	// Determine resource ID (use configured ResourceID or derive from command)
-	resourceID := e.ResourceID
-	if resourceID == "" {
-		resourceID = e.deriveResourceID(command)
-	}
+	resourceID := e.resourceID(command)
 
 	// Create schedule request
 	scheduleReq := &daemon.ScheduleRequest{
@@ -249,11 +709,14 @@ func (e *Executor) coordinateWithDaemon(strategy *backoff.DiophantineStrategy, c
 
 	response, err := e.DaemonClient.CanScheduleRequest(ctx, scheduleReq)
 	if err != nil {
+		if required {
+			return nil, fmt.Errorf("daemon unavailable: %w", err)
+		}
 		// If daemon communication fails, fall back to local-only mode
 		if e.Reporter != nil {
 			e.Reporter.ShowWarning("Daemon unavailable, using local scheduling")
 		}
-		return nil
+		return nil, nil
 	}
 
 	// If we can't schedule now, wait until we can
@@ -267,17 +730,28 @@ func (e *Executor) coordinateWithDaemon(strategy *backoff.DiophantineStrategy, c
 		}
 	}
 
-	// Register our planned requests with the daemon
-	plannedRequests := e.createPlannedRequests(resourceID, scheduleReq.RequestTime, strategy.GetRetryOffsets())
-	err = e.DaemonClient.RegisterScheduledRequests(ctx, plannedRequests)
-	if err != nil {
+	// Register the first batch of our planned requests with the daemon; the
+	// rest follow as attempts proceed (see registerPlannedAhead)
+	planned := newPlannedRegistrations(resourceID, scheduleReq.RequestTime, strategy.GetRetryOffsets(), e.MaxPlannedRegistrations)
+	if err := planned.register(ctx, e.DaemonClient, 1); err != nil {
+		if required {
+			return nil, fmt.Errorf("failed to register requests with daemon: %w", err)
+		}
 		// Registration failure is not critical, continue with execution
 		if e.Reporter != nil {
-			e.Reporter.ShowWarning("Failed to register requests with daemon")
+			e.Reporter.ShowWarning(fmt.Sprintf("Failed to register requests with daemon: %v", err))
 		}
 	}
 
-	return nil
+	return planned, nil
+}
+
+// resourceID returns the configured ResourceID, or one derived from the command
+func (e *Executor) resourceID(command []string) string {
+	if e.ResourceID != "" {
+		return e.ResourceID
+	}
+	return e.deriveResourceID(command)
 }
 
 // deriveResourceID attempts to derive a resource identifier from the command
@@ -313,50 +787,43 @@ func (e *Executor) deriveResourceID(command []string) string {
 	}
 }
 
-// createPlannedRequests creates scheduled request entries for daemon registration
-func (e *Executor) createPlannedRequests(resourceID string, baseTime time.Time, retryOffsets []time.Duration) []*daemon.ScheduledRequest {
-	requests := make([]*daemon.ScheduledRequest, len(retryOffsets))
-
-	for i, offset := range retryOffsets {
-		requests[i] = &daemon.ScheduledRequest{
-			ID:          fmt.Sprintf("%s-%d-%d", resourceID, baseTime.Unix(), i),
-			ResourceID:  resourceID,
-			ScheduledAt: baseTime.Add(offset),
-			ExpiresAt:   baseTime.Add(offset).Add(time.Hour), // Expire after 1 hour
-		}
-	}
-
-	return requests
-}
-
 // Run executes the given command with retry logic and returns the result
 // coordinateDaemon handles Diophantine strategy coordination with daemon
-func (e *Executor) coordinateDaemon(strategy backoff.Strategy, command []string) error {
+func (e *Executor) coordinateDaemon(strategy backoff.Strategy, command []string) (*plannedRegistrations, error) {
 	if diophantineStrategy, ok := strategy.(*backoff.DiophantineStrategy); ok {
 		return e.coordinateWithDaemon(diophantineStrategy, command)
 	}
-	return nil
+	return nil, nil
 }
 
 // initializeExecution sets up stats, metrics, and variables for a run
 func (e *Executor) initializeExecution(command []string) (*ui.RunStats, []metrics.AttemptMetric, time.Time) {
 	stats := ui.NewRunStats()
 	var attemptMetrics []metrics.AttemptMetric
-	runStartTime := time.Now()
+	runStartTime := e.clock().Now()
 	return stats, attemptMetrics, runStartTime
 }
 
 // processAttemptResult evaluates success conditions and determines if retry should stop
-func (e *Executor) processAttemptResult(output CommandOutput, attempt int) (conditions.Result, bool) {
+// When history is non-nil, patterns are matched against the accumulated output.
+func (e *Executor) processAttemptResult(output CommandOutput, attempt int, history *outputHistory) (conditions.Result, bool) {
+	patternOutput := output
+	if history != nil {
+		patternOutput = history.combined(output.ExitCode)
+	}
+
 	var conditionResult conditions.Result
 	if e.Conditions != nil {
-		conditionResult = e.Conditions.CheckSuccess(output.ExitCode, output.Stdout, output.Stderr)
+		conditionResult = e.Conditions.CheckSuccess(patternOutput.ExitCode, patternOutput.Stdout, patternOutput.Stderr)
+		if e.RequireSuccessPattern && conditionResult.Success && conditionResult.Reason != successPatternReason {
+			conditionResult = conditions.Result{Success: false, Reason: "success pattern not matched"}
+		}
 	} else {
 		// Default behavior: success if exit code is 0
 		if output.ExitCode == 0 {
 			conditionResult = conditions.Result{
 				Success: true,
-				Reason:  "exit code 0",
+				Reason:  exitCodeZeroReason,
 			}
 		} else {
 			conditionResult = conditions.Result{
@@ -366,14 +833,68 @@ func (e *Executor) processAttemptResult(output CommandOutput, attempt int) (cond
 		}
 	}
 
+	// Name the signal that killed the command, then let the HTTP status of
+	// the response refine an exit-code verdict
+	conditionResult = signalResult(output, conditionResult)
+	conditionResult = e.httpResponseResult(output, conditionResult)
+
+	// Treat too little output as a retryable failure, even on exit 0
+	if conditionResult.Success {
+		if reason, ok := e.checkOutputThresholds(output); !ok {
+			conditionResult = conditions.Result{Success: false, Reason: reason}
+		}
+	}
+
+	// A compound HTTP condition must hold on top of the others
+	conditionResult = e.HTTPSuccess.Apply(output, conditionResult)
+
 	// Stop retrying if successful or if failure pattern matched
 	shouldStop := conditionResult.Success || conditionResult.Reason == "failure pattern matched"
+
+	// Explicit actions for the response's HTTP status take precedence over
+	// the statuses listed as retryable
+	conditionResult, shouldStop = e.RetryHTTPStatus.Apply(output, conditionResult, shouldStop)
+	conditionResult, shouldStop = e.HTTPStatusActions.Apply(output, conditionResult, shouldStop)
+	conditionResult, shouldStop = e.applyJSONErrorCode(output, conditionResult, shouldStop)
 	return conditionResult, shouldStop
 }
 
+// checkOutputThresholds verifies the attempt produced the minimum required output
+func (e *Executor) checkOutputThresholds(output CommandOutput) (string, bool) {
+	if e.MinOutputLines <= 0 && e.MinOutputBytes <= 0 {
+		return "", true
+	}
+
+	combined := output.Stdout + output.Stderr
+	if e.MinOutputBytes > 0 && len(combined) < e.MinOutputBytes {
+		return fmt.Sprintf("insufficient output (%d bytes, need %d)", len(combined), e.MinOutputBytes), false
+	}
+
+	if e.MinOutputLines > 0 {
+		lines := countLines(output.Stdout) + countLines(output.Stderr)
+		if lines < e.MinOutputLines {
+			return fmt.Sprintf("insufficient output (%d lines, need %d)", lines, e.MinOutputLines), false
+		}
+	}
+
+	return "", true
+}
+
+// countLines counts lines in s, including a final line without a trailing newline
+func countLines(s string) int {
+	if s == "" {
+		return 0
+	}
+	lines := strings.Count(s, "\n")
+	if !strings.HasSuffix(s, "\n") {
+		lines++
+	}
+	return lines
+}
+
 // recordStrategyOutcome updates adaptive strategies with attempt results
 func (e *Executor) recordStrategyOutcome(attempt int, success bool, duration time.Duration) {
-	if adaptiveStrategy, ok := e.BackoffStrategy.(interface {
+	if adaptiveStrategy, ok := backoff.Unwrap(e.BackoffStrategy).(interface {
 		RecordOutcome(delay time.Duration, success bool, latency time.Duration)
 	}); ok {
 		// Calculate the delay that was actually used for this attempt
@@ -385,7 +906,7 @@ func (e *Executor) recordStrategyOutcome(attempt int, success bool, duration tim
 	}
 
 	// Process command output for HTTP-aware strategies
-	if httpAware, ok := e.BackoffStrategy.(interface {
+	if httpAware, ok := backoff.Unwrap(e.BackoffStrategy).(interface {
 		ProcessCommandOutput(stdout, stderr string, exitCode int)
 	}); ok {
 		// This would need the output, but we'll handle it in the main loop
@@ -393,160 +914,535 @@ func (e *Executor) recordStrategyOutcome(attempt int, success bool, duration tim
 	}
 }
 
-// determineFinalReason calculates the final failure reason
-func (e *Executor) determineFinalReason(lastOutput CommandOutput, timedOut bool) string {
-	if timedOut {
-		if e.MaxAttempts == 1 {
-			return "timeout"
-		}
-		return "max retries reached (timeout)"
+// writeTimingMarker emits a single-line, greppable marker for a finished attempt.
+// The end timestamp is derived from the monotonic duration so it never precedes start.
+func (e *Executor) writeTimingMarker(attempt int, start time.Time, duration time.Duration, exitCode int, success bool) {
+	if e.TimingMarkers == nil {
+		return
 	}
+	startNs := start.UnixNano()
+	fmt.Fprintf(e.TimingMarkers, "PATIENCE attempt=%d start_ns=%d end_ns=%d exit_code=%d success=%t\n",
+		attempt, startNs, startNs+duration.Nanoseconds(), exitCode, success)
+}
 
-	if e.Conditions != nil {
-		conditionResult := e.Conditions.CheckSuccess(lastOutput.ExitCode, lastOutput.Stdout, lastOutput.Stderr)
-		if e.MaxAttempts == 1 {
-			return conditionResult.Reason
-		}
-		return "max retries reached (" + conditionResult.Reason + ")"
+// capAttemptsToRemaining lowers the attempt limit when the strategy reports a
+// remaining request budget R, allowing at most R+1 attempts counting the current one
+func (e *Executor) capAttemptsToRemaining(attempt, maxAttempts int) int {
+	if !e.RespectRemaining {
+		return maxAttempts
+	}
+
+	budget, ok := backoff.Unwrap(e.BackoffStrategy).(interface {
+		RateLimitRemaining() (int, bool)
+	})
+	if !ok {
+		return maxAttempts
+	}
+
+	remaining, found := budget.RateLimitRemaining()
+	if !found || attempt+remaining >= maxAttempts {
+		return maxAttempts
+	}
+
+	capped := attempt + remaining
+	e.warn(fmt.Sprintf("rate limit remaining is %d, capping attempts at %d", remaining, capped))
+	return capped
+}
+
+// warn shows a warning through the reporter when one is configured
+func (e *Executor) warn(message string) {
+	if e.Reporter != nil {
+		e.Reporter.ShowWarning(message)
+	}
+}
+
+// note reports an informational message, shown only in verbose mode
+func (e *Executor) note(message string) {
+	if e.Reporter != nil {
+		e.Reporter.ShowNote(message)
+	}
+}
+
+// noteFalseRateLimit reports when the strategy treated a 429 as a transient
+// error because the server still reported remaining requests
+func (e *Executor) noteFalseRateLimit() {
+	limit, ok := backoff.Unwrap(e.BackoffStrategy).(interface {
+		FalseRateLimit() bool
+		RateLimitRemaining() (int, bool)
+	})
+	if !ok || !limit.FalseRateLimit() {
+		return
 	}
+	remaining, _ := limit.RateLimitRemaining()
+	e.note(fmt.Sprintf("HTTP 429 with %d requests remaining; retrying as a transient error", remaining))
+}
 
-	// Default exit code based reasoning
-	exitReason := fmt.Sprintf("exit code %d", lastOutput.ExitCode)
-	if lastOutput.ExitCode == 0 {
-		exitReason = "exit code 0"
+// determineFinalReason calculates the final failure reason from lastResult,
+// the verdict on the last attempt, so its conditions are not evaluated (and
+// counted in pattern metrics) twice. Only when no attempt ran is lastResult nil.
+func (e *Executor) determineFinalReason(attempt int, lastOutput CommandOutput, timedOut bool, history *outputHistory, stability *stabilityTracker, lastResult *conditions.Result) string {
+	reason := "timeout"
+	if !timedOut {
+		var conditionResult conditions.Result
+		if lastResult != nil {
+			conditionResult = *lastResult
+		} else {
+			conditionResult, _ = e.processAttemptResult(lastOutput, 0, history)
+		}
+		if stability != nil {
+			conditionResult = stability.check(conditionResult)
+		}
+		reason = conditionResult.Reason
 	}
 
+	reason = e.renderReason(attempt, lastOutput, reason, timedOut)
 	if e.MaxAttempts == 1 {
-		return exitReason
+		return reason
 	}
-	return "max retries reached (" + exitReason + ")"
+	return "max retries reached (" + reason + ")"
+}
+
+// fastSuccess reports whether a successful attempt can take the
+// FirstSuccessExitFast path, which nothing configured would miss
+func (e *Executor) fastSuccess(attempt int, success bool) bool {
+	return e.FirstSuccessExitFast && success && attempt == 1 && e.Conditions == nil
 }
 
 // buildFinalResult constructs the final Result object
-func (e *Executor) buildFinalResult(success bool, attemptCount int, lastOutput CommandOutput, timedOut bool, reason string, stats *ui.RunStats, attemptMetrics []metrics.AttemptMetric, runStartTime time.Time, command []string, lastError error) *Result {
-	totalDuration := time.Since(runStartTime)
-	runMetrics := metrics.NewRunMetrics(command, success, totalDuration, attemptMetrics)
+func (e *Executor) buildFinalResult(success bool, attemptCount int, lastOutput CommandOutput, timedOut bool, reason string, kind FailureKind, stats *ui.RunStats, attemptMetrics []metrics.AttemptMetric, runStartTime time.Time, totalDelay time.Duration, command []string, lastError error) *Result {
+	totalDuration := e.clock().Now().Sub(runStartTime)
+	var totalExecution time.Duration
+	for _, attempt := range attemptMetrics {
+		totalExecution += attempt.Duration
+	}
+	runMetrics := metrics.NewRunMetrics(e.HeaderRedaction.Command(command), success, totalDuration, attemptMetrics)
+	runMetrics.TotalDelaySeconds = totalDelay.Seconds()
+	runMetrics.TotalExecutionSeconds = totalExecution.Seconds()
+	runMetrics.Tags = e.Tags
+	runMetrics.CustomMetrics = e.CustomMetrics
+	if stats != nil {
+		runMetrics.RateLimit = stats.RateLimit
+		if e.ReportPatternMetrics && e.Conditions != nil {
+			stats.PatternMetrics = e.Conditions.PatternMetrics()
+		}
+	}
+	if !success {
+		runMetrics.FailureKind = kind.String()
+		runMetrics.FailureCategory = e.failureCategory(reason, lastOutput)
+	}
+
+	exitCode := lastOutput.ExitCode
+	if kind == FailureFatal {
+		exitCode = e.fatalExitCode()
+	}
 
 	return &Result{
-		AttemptCount: attemptCount,
-		ExitCode:     lastOutput.ExitCode,
-		Success:      success,
-		TimedOut:     timedOut,
-		Reason:       reason,
-		Stats:        stats,
-		Metrics:      runMetrics,
+		AttemptCount:   attemptCount,
+		ExitCode:       exitCode,
+		Success:        success,
+		TimedOut:       timedOut,
+		Reason:         reason,
+		Kind:           kind,
+		Stats:          stats,
+		Metrics:        runMetrics,
+		TotalDelay:     totalDelay,
+		TotalExecution: totalExecution,
 	}
 }
 
+// Run runs command until it succeeds or a stop condition is met. The Result is
+// returned for every run that got under way; the error is nil only when the run
+// succeeded, and otherwise is the Result's Err, so callers can test the outcome
+// with errors.Is, or an error that kept the run from finishing.
 func (e *Executor) Run(command []string) (*Result, error) {
-	// Handle Diophantine strategy coordination with daemon
-	if err := e.coordinateDaemon(e.BackoffStrategy, command); err != nil {
-		return &Result{
-			Success:      false,
-			AttemptCount: 0,
-			ExitCode:     -1,
-			TimedOut:     false,
-			Reason:       fmt.Sprintf("daemon coordination failed: %v", err),
-		}, err
+	result, err := e.run(command)
+	if err != nil || result == nil {
+		return result, err
+	}
+	return result, result.Err()
+}
+
+// runState is what a run tracks across its attempts
+type runState struct {
+	command        []string
+	attemptCommand []string // The command as each attempt runs it, with any idempotency key
+	budget         *attemptBudget
+	planned        *plannedRegistrations
+	stats          *ui.RunStats
+	attemptMetrics []metrics.AttemptMetric
+	runStartTime   time.Time
+	totalDelay     time.Duration
+	deadline       time.Time // MaxTotalTime on the configured clock; zero when unlimited
+	startAttempt   int
+
+	lastOutput CommandOutput
+	lastError  error
+	timedOut   bool
+	lastResult *conditions.Result // The verdict on the last attempt, which explains a final failure
+
+	history      *outputHistory    // Output across attempts for cumulative pattern matching
+	stability    *stabilityTracker // Output changes between attempts when waiting for stable output
+	tally        *passTally        // Passes, when a number of them rather than the first is required
+	rateLimits   *rateLimitTracker // What the server says about its rate limit
+	backoffStart int               // Attempt after which backoff counting last restarted on progress
+}
+
+// attemptVerdict is how an attempt was judged
+type attemptVerdict struct {
+	result  conditions.Result // The run's verdict, after any pass tally
+	stop    bool              // Whether retrying should stop
+	kind    FailureKind       // Why a failed run stops
+	success bool              // The attempt's own outcome, before any pass tally
+}
+
+// newRunState sets up the tracking for a run of command
+func (e *Executor) newRunState(command []string) *runState {
+	rs := &runState{command: command}
+	rs.stats, rs.attemptMetrics, rs.runStartTime = e.initializeExecution(command)
+	if e.CumulativePatterns {
+		limit := e.MaxOutputSize
+		if limit <= 0 {
+			limit = DefaultMaxCumulativeOutput
+		}
+		rs.history = &outputHistory{limit: limit}
+	}
+	if e.StableAttempts > 0 {
+		rs.stability = &stabilityTracker{required: e.StableAttempts}
+	}
+	if e.RequirePasses > 0 {
+		rs.tally = &passTally{required: e.RequirePasses}
+	}
+	if e.DiscoverRateLimits {
+		rs.rateLimits = newRateLimitTracker(e.DiscoveryCache)
 	}
+	return rs
+}
 
-	var lastOutput CommandOutput
-	var lastError error
-	var timedOut bool
+// finish ends the run after attemptCount attempts, clearing any saved state
+func (e *Executor) finish(rs *runState, success bool, attemptCount int, reason string, kind FailureKind) *Result {
+	e.clearState()
+	rs.stats.Finalize(success, reason)
+	return e.buildFinalResult(success, attemptCount, rs.lastOutput, rs.timedOut, reason, kind, rs.stats, rs.attemptMetrics,
+		rs.runStartTime, rs.totalDelay, rs.command, rs.lastError)
+}
 
-	// Initialize execution tracking
-	stats, attemptMetrics, runStartTime := e.initializeExecution(command)
+// beforeAttempt waits out any initial delay, then for the allowed window and,
+// before the first attempt, for a cached rate limit to reset. It returns the
+// time waited and, when the attempt must not start because a wait would pass
+// the deadline, the daemon has halted the run or the machine lacks the
+// resources, why and the failure kind to end the run with.
+func (e *Executor) beforeAttempt(rs *runState, attempt int) (time.Duration, string, FailureKind) {
+	wait, reason := e.waitInitialDelay(attempt, rs.deadline)
+	if reason != "" {
+		return wait, reason, FailureTimeout
+	}
+	windowWait, reason := e.waitForWindow(attempt, rs.deadline)
+	wait += windowWait
+	if reason != "" {
+		return wait, reason, FailureTimeout
+	}
+	if attempt == rs.startAttempt {
+		resetWait, reason := e.waitForKnownReset(rs.rateLimits, rs.command, rs.deadline)
+		wait += resetWait
+		if reason != "" {
+			return wait, reason, FailureTimeout
+		}
+	}
+	if reason := e.checkHalt(rs.command); reason != "" {
+		return wait, reason, FailureInterrupted
+	}
+	return wait, e.checkResources(attempt), FailureOther
+}
 
-	// Retry loop
-	for attempt := 1; attempt <= e.MaxAttempts; attempt++ {
-		// Report attempt start
+// startSchedule resumes an interrupted schedule, honoring any remaining wait,
+// and starts the clock on MaxTotalTime
+func (e *Executor) startSchedule(rs *runState) {
+	var resumeWait time.Duration
+	rs.startAttempt, resumeWait = e.resumeState(rs.command)
+	if resumeWait > 0 {
 		if e.Reporter != nil {
-			e.Reporter.AttemptStart(attempt, e.MaxAttempts)
+			e.Reporter.ShowWaiting(resumeWait, "resuming retry schedule")
 		}
-		stats.RecordAttemptStart()
+		e.clock().Sleep(resumeWait)
+	}
+	rs.totalDelay = resumeWait
+	if e.MaxTotalTime > 0 {
+		rs.deadline = e.clock().Now().Add(e.MaxTotalTime)
+	}
+}
 
-		// Record attempt start time for metrics
-		attemptStartTime := time.Now()
+// beginAttempt reports and counts the start of an attempt
+func (e *Executor) beginAttempt(rs *runState, attempt, maxAttempts int) {
+	if e.Reporter != nil {
+		e.Reporter.AttemptStart(attempt, reportedLimit(maxAttempts))
+	}
+	e.recordProgress(attempt, maxAttempts, ProgressRunning)
+	rs.stats.RecordAttemptStart()
+	rs.stats.TotalCost += e.CostPerAttempt
+	e.spendAttempt(rs.budget)
+}
 
-		output, err, timeout := e.executeAttempt(command)
-		lastOutput = output
-		lastError = err
-		if timeout {
-			timedOut = true
+// judgeAttempt decides an attempt's outcome from its output: the success and
+// failure conditions, the check command and retry-if, stable output, fatal and
+// abort patterns and, last, any pass tally
+func (e *Executor) judgeAttempt(rs *runState, attempt, maxAttempts int, output CommandOutput, duration time.Duration) attemptVerdict {
+	if rs.history != nil {
+		rs.history.add(output)
+	}
+	result, stop := e.processAttemptResult(output, attempt, rs.history)
+	if e.CheckCommand != "" {
+		result, stop = e.checkAttempt(attempt, output, result, stop)
+	}
+	if e.RetryIf != nil {
+		result, stop = e.applyRetryIf(attempt, output, duration, result, stop)
+	}
+	judged := result
+	rs.lastResult = &judged
+	if rs.stability != nil {
+		rs.stability.observe(output, result.Success)
+		if result.Success {
+			result = rs.stability.check(result)
+			stop = result.Success
 		}
+	}
+	kind := FailureOther
+	if result.Reason == failurePatternReason {
+		kind = FailurePattern
+	}
+	if fatal, ok := e.fatalMatched(attempt, output); ok {
+		result, stop, kind = fatal, true, FailureFatal
+	}
+	if !result.Success && !stop && e.abortMatched(attempt, output) {
+		stop, kind = true, FailurePattern
+	}
 
-		// Record attempt duration for metrics
-		attemptDuration := time.Since(attemptStartTime)
+	verdict := attemptVerdict{result: result, stop: stop, kind: kind, success: result.Success}
+	if rs.tally != nil {
+		verdict.result, verdict.stop = rs.tally.check(result, stop, attempt, maxAttempts)
+	}
+	return verdict
+}
 
-		if err != nil {
+// recordAttempt records a judged attempt in the run's statistics and metrics,
+// the timing marker and progress FIFO, and feeds its outcome to the strategy
+func (e *Executor) recordAttempt(rs *runState, attempt, maxAttempts int, output CommandOutput, start time.Time, duration time.Duration, verdict attemptVerdict) {
+	rs.stats.RecordAttemptEnd(verdict.success, verdict.result.Reason)
+	rs.stats.RecordAttemptDuration(duration)
+	rs.stats.RecordAttemptUsage(output.Usage.PeakRSS, output.Usage.CPUTime)
+
+	// Emit machine-readable timing marker
+	e.writeTimingMarker(attempt, start, duration, output.ExitCode, verdict.success)
+	if verdict.success {
+		e.recordProgress(attempt, maxAttempts, ProgressSucceeded)
+	} else {
+		e.recordProgress(attempt, maxAttempts, ProgressFailed)
+	}
+
+	rs.attemptMetrics = append(rs.attemptMetrics, metrics.AttemptMetric{
+		Duration:      duration,
+		ExitCode:      output.ExitCode,
+		Success:       verdict.success,
+		StepExitCodes: output.stepExitCodes(),
+		Signal:        output.signalName(),
+		PeakRSSBytes:  output.Usage.PeakRSS,
+		CPUTime:       output.Usage.CPUTime,
+	})
+
+	if rs.rateLimits != nil {
+		rs.rateLimits.observe(output, rs.command)
+		rs.stats.RateLimit = rs.rateLimits.summary()
+	}
+
+	// Record outcome for adaptive and HTTP-aware strategies
+	e.recordStrategyOutcome(attempt, verdict.success, duration)
+
+	// Process command output for HTTP-aware strategies
+	if httpAware, ok := backoff.Unwrap(e.BackoffStrategy).(interface {
+		ProcessCommandOutput(stdout, stderr string, exitCode int)
+	}); ok {
+		httpAware.ProcessCommandOutput(output.Stdout, output.Stderr, output.ExitCode)
+	}
+	e.noteFalseRateLimit()
+}
+
+// stopped ends the run at an attempt whose verdict stopped retrying
+func (e *Executor) stopped(rs *runState, attempt int, verdict attemptVerdict, duration time.Duration) *Result {
+	if e.fastSuccess(attempt, verdict.result.Success) {
+		e.clearState()
+		return &Result{Success: true, AttemptCount: attempt, ExitCode: rs.lastOutput.ExitCode, Reason: verdict.result.Reason, TotalExecution: duration}
+	}
+	if verdict.result.Success {
+		return e.finish(rs, true, attempt, verdict.result.Reason, FailureNone)
+	}
+	return e.finish(rs, false, attempt, e.renderReason(attempt, rs.lastOutput, verdict.result.Reason, rs.timedOut), verdict.kind)
+}
+
+// reportFailure reports a failed attempt and the delay before the next one
+func (e *Executor) reportFailure(rs *runState, attempt, maxAttempts int, reason string, delay time.Duration) {
+	if e.Reporter != nil {
+		e.Reporter.AttemptFailure(attempt, reportedLimit(maxAttempts), e.attemptReason(attempt, rs.lastOutput, reason, rs.timedOut), delay)
+	}
+}
+
+// nextRetry reports an attempt that failed for reason and returns the delay
+// before the next one. When no retry should follow, because the cost budget
+// cannot cover it, the wait would pass the total time limit or the user
+// declined it, it returns why and the failure kind to end the run with.
+func (e *Executor) nextRetry(rs *runState, attempt, maxAttempts int, reason string) (time.Duration, string, FailureKind) {
+	// Stop before an attempt the cost budget cannot cover
+	if e.MaxCost > 0 && rs.stats.TotalCost+e.CostPerAttempt > e.MaxCost+costTolerance {
+		e.warn(fmt.Sprintf("attempt %d failed (%s); stopping: %s (spent %.6g of %g, next attempt costs %g)",
+			attempt, reason, ReasonCostBudgetExceeded, rs.stats.TotalCost, e.MaxCost, e.CostPerAttempt))
+		return 0, ReasonCostBudgetExceeded, FailureBudgetExceeded
+	}
+
+	delay := e.nextDelay(attempt, e.backoffAttempt(attempt, rs.lastOutput, &rs.backoffStart), rs.lastOutput)
+
+	// Stop rather than wait past the total time limit
+	if !rs.deadline.IsZero() && e.clock().Now().Add(delay).After(rs.deadline) {
+		e.warn(fmt.Sprintf("attempt %d failed (%s); stopping: %s (next retry in %s would pass the %s limit)",
+			attempt, reason, ReasonMaxTotalTime, ui.FormatDuration(delay), ui.FormatDuration(e.MaxTotalTime)))
+		return delay, ReasonMaxTotalTime, FailureTimeout
+	}
+
+	e.reportFailure(rs, attempt, maxAttempts, reason, delay)
+	if !e.confirmWait(delay) {
+		return delay, fmt.Sprintf("aborted: wait of %s declined", delay), FailureInterrupted
+	}
+	return delay, "", FailureNone
+}
+
+// confirmWait asks before a wait longer than ConfirmWaitsOver, reporting
+// whether to go ahead; without a reporter to ask through it goes ahead
+func (e *Executor) confirmWait(delay time.Duration) bool {
+	if e.ConfirmWaitsOver <= 0 || delay <= e.ConfirmWaitsOver || e.Reporter == nil {
+		return true
+	}
+	return e.Reporter.ConfirmWait(delay)
+}
+
+// run runs command and returns its Result; see Run
+func (e *Executor) run(command []string) (*Result, error) {
+	if err := e.checkBounded(); err != nil {
+		return nil, err
+	}
+	if e.Steps {
+		if _, err := SplitSteps(command); err != nil {
 			return nil, err
 		}
+	}
+
+	// Guard against overlapping invocations of the same command
+	lock, early, err := e.lockRun(command)
+	if err != nil {
+		return nil, err
+	}
+	if early != nil {
+		return early, nil
+	}
+	defer lock.release()
+
+	// Count attempts against the budget shared with earlier invocations
+	budget, early, err := e.loadAttemptBudget()
+	if err != nil {
+		return nil, err
+	}
+	if early != nil {
+		return early, nil
+	}
 
-		// Check success conditions and determine if we should stop retrying
-		conditionResult, shouldStop := e.processAttemptResult(output, attempt)
+	// Give every attempt the same idempotency key; metrics keep the original command
+	attemptCommand, err := e.withIdempotencyKey(command)
+	if err != nil {
+		return nil, err
+	}
 
-		// Record attempt result
-		stats.RecordAttemptEnd(conditionResult.Success, conditionResult.Reason)
+	// Handle Diophantine strategy coordination with daemon
+	planned, err := e.coordinateDaemon(backoff.Unwrap(e.BackoffStrategy), command)
+	if err != nil {
+		return &Result{
+			Success:      false,
+			AttemptCount: 0,
+			ExitCode:     -1,
+			TimedOut:     false,
+			Reason:       fmt.Sprintf("daemon coordination failed: %v", err),
+			Kind:         FailureOther,
+		}, err
+	}
 
-		// Record attempt metrics
-		attemptMetrics = append(attemptMetrics, metrics.AttemptMetric{
-			Duration: attemptDuration,
-			ExitCode: output.ExitCode,
-			Success:  conditionResult.Success,
-		})
+	rs := e.newRunState(command)
+	rs.attemptCommand, rs.budget, rs.planned = attemptCommand, budget, planned
+	if rs.rateLimits != nil {
+		defer e.saveDiscoveryCache()
+	}
+	e.startSchedule(rs)
+	return e.runAttempts(rs)
+}
 
-		// Record outcome for adaptive and HTTP-aware strategies
-		e.recordStrategyOutcome(attempt, conditionResult.Success, attemptDuration)
+// runAttempts runs the retry loop until a verdict, budget or check stops it or
+// the attempts run out
+func (e *Executor) runAttempts(rs *runState) (*Result, error) {
+	// Attempt limit may shrink during the run when respecting rate limit budgets
+	maxAttempts := rs.budget.cap(rs.startAttempt, e.attemptLimit())
+
+	for attempt := rs.startAttempt; attempt <= maxAttempts; attempt++ {
+		wait, reason, kind := e.beforeAttempt(rs, attempt)
+		rs.totalDelay += wait
+		if reason != "" {
+			e.warn(fmt.Sprintf("not starting attempt %d: %s", attempt, reason))
+			return e.finish(rs, false, attempt-1, reason, kind), nil
+		}
+		if err := e.registerPlannedAhead(rs.planned, attempt); err != nil {
+			return e.finish(rs, false, attempt-1, fmt.Sprintf("daemon coordination failed: %v", err), FailureOther), err
+		}
 
-		// Process command output for HTTP-aware strategies
-		if httpAware, ok := e.BackoffStrategy.(interface {
-			ProcessCommandOutput(stdout, stderr string, exitCode int)
-		}); ok {
-			httpAware.ProcessCommandOutput(output.Stdout, output.Stderr, output.ExitCode)
+		e.beginAttempt(rs, attempt, maxAttempts)
+		attemptStartTime := e.clock().Now()
+		stopSlowWarning := e.watchSlowAttempt(attempt)
+		output, err, timeout := e.executeAttempt(attempt, rs.attemptCommand)
+		stopSlowWarning()
+		rs.lastOutput, rs.lastError = output, err
+		if timeout {
+			rs.timedOut = true
+		}
+		attemptDuration := e.clock().Now().Sub(attemptStartTime)
+		if err != nil {
+			return nil, commandError(err)
 		}
 
+		verdict := e.judgeAttempt(rs, attempt, maxAttempts, output, attemptDuration)
+		e.recordAttempt(rs, attempt, maxAttempts, output, attemptStartTime, attemptDuration, verdict)
+		maxAttempts = e.capAttemptsToRemaining(attempt, maxAttempts)
+
 		// If we should stop retrying (success or failure pattern matched)
-		if shouldStop {
-			stats.Finalize(conditionResult.Success, conditionResult.Reason)
-			return e.buildFinalResult(conditionResult.Success, attempt, output, timedOut, conditionResult.Reason, stats, attemptMetrics, runStartTime, command, lastError), nil
+		if verdict.stop {
+			return e.stopped(rs, attempt, verdict, attemptDuration), nil
 		}
 
-		// If this was the last attempt, break out of loop
-		if attempt == e.MaxAttempts {
-			// Report final failure (no retry)
-			if e.Reporter != nil {
-				failureReason := conditionResult.Reason
-				if timedOut {
-					failureReason = fmt.Sprintf("timeout: %s", e.Timeout)
-				}
-				e.Reporter.AttemptFailure(attempt, e.MaxAttempts, failureReason, 0)
-			}
+		// Report the final failure without a retry after the last attempt
+		if attempt == maxAttempts {
+			e.reportFailure(rs, attempt, maxAttempts, verdict.result.Reason, 0)
 			break
 		}
 
-		// Calculate delay and report failure
-		var delay time.Duration
-		if e.BackoffStrategy != nil {
-			delay = e.BackoffStrategy.Delay(attempt)
+		delay, reason, kind := e.nextRetry(rs, attempt, maxAttempts, verdict.result.Reason)
+		if reason != "" {
+			return e.finish(rs, false, attempt, reason, kind), nil
 		}
 
-		if e.Reporter != nil {
-			failureReason := conditionResult.Reason
-			if timedOut {
-				failureReason = fmt.Sprintf("timeout: %s", e.Timeout)
-			}
-			e.Reporter.AttemptFailure(attempt, e.MaxAttempts, failureReason, delay)
-		}
-
-		// Wait before next attempt if backoff strategy is configured
+		// Persist progress so an interrupted wait can be resumed
+		e.saveState(rs.command, attempt, delay)
 		if delay > 0 {
-			time.Sleep(delay)
+			e.clock().Sleep(delay)
+			rs.totalDelay += delay
 		}
 	}
 
 	// All attempts failed - determine final reason
-	finalReason := e.determineFinalReason(lastOutput, timedOut)
-	stats.Finalize(false, finalReason)
-
-	return e.buildFinalResult(false, e.MaxAttempts, lastOutput, timedOut, finalReason, stats, attemptMetrics, runStartTime, command, lastError), lastError
+	finalReason := e.determineFinalReason(maxAttempts, rs.lastOutput, rs.timedOut, rs.history, rs.stability, rs.lastResult)
+	finalKind := FailureMaxAttempts
+	if rs.timedOut && e.MaxAttempts == 1 {
+		finalKind = FailureTimeout
+	}
+	return e.finish(rs, false, maxAttempts, finalReason, finalKind), rs.lastError
 }
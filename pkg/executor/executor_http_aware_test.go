@@ -1,11 +1,15 @@
 package executor
 
 import (
+	"bytes"
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/shaneisley/patience/pkg/backoff"
+	"github.com/shaneisley/patience/pkg/conditions"
+	"github.com/shaneisley/patience/pkg/ui"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -197,3 +201,279 @@ func (m *MockHTTPCommandRunner) RunWithOutputAndContext(ctx context.Context, com
 		Stderr:   response.Stderr,
 	}, nil
 }
+
+// TestExecutorRespectRemaining tests that X-RateLimit-Remaining caps the attempt budget
+func TestExecutorRespectRemaining(t *testing.T) {
+	newRunner := func() *MockHTTPCommandRunner {
+		response := MockHTTPResponse{
+			ExitCode: 22,
+			Stderr:   "HTTP/1.1 503 Service Unavailable\r\nX-RateLimit-Remaining: 1\r\n\r\n",
+		}
+		return &MockHTTPCommandRunner{
+			responses: []MockHTTPResponse{response, response, response, response, response},
+		}
+	}
+
+	t.Run("RemainingOneLeavesOneFinalAttempt", func(t *testing.T) {
+		// Given an executor respecting remaining budget and a server reporting remaining=1
+		runner := newRunner()
+		executor := &Executor{
+			MaxAttempts:      5,
+			Runner:           runner,
+			BackoffStrategy:  backoff.NewHTTPAware(backoff.NewFixed(time.Millisecond), time.Minute),
+			RespectRemaining: true,
+		}
+
+		// When Run() is called
+		result, err := executor.Run([]string{"curl", "-f", "https://api.example.com"})
+
+		// Then only one final attempt should follow the first one
+		require.ErrorIs(t, err, ErrMaxAttempts)
+		assert.False(t, result.Success)
+		assert.Equal(t, 2, result.AttemptCount)
+		assert.Equal(t, 2, runner.currentCall)
+	})
+
+	t.Run("DisabledUsesAllAttempts", func(t *testing.T) {
+		// Given the same responses without --respect-remaining
+		runner := newRunner()
+		executor := &Executor{
+			MaxAttempts:     5,
+			Runner:          runner,
+			BackoffStrategy: backoff.NewHTTPAware(backoff.NewFixed(time.Millisecond), time.Minute),
+		}
+
+		// When Run() is called
+		result, err := executor.Run([]string{"curl", "-f", "https://api.example.com"})
+
+		// Then every configured attempt should run
+		require.ErrorIs(t, err, ErrMaxAttempts)
+		assert.Equal(t, 5, result.AttemptCount)
+	})
+}
+
+// TestExecutorFalseRateLimitNote tests that a 429 with requests remaining is
+// retried on the fallback delay with a verbose note
+func TestExecutorFalseRateLimitNote(t *testing.T) {
+	// Given a server answering 429 while still reporting remaining requests
+	reset := time.Now().Add(time.Hour).Unix()
+	runner := &MockHTTPCommandRunner{
+		responses: []MockHTTPResponse{
+			{ExitCode: 22, Stderr: fmt.Sprintf("HTTP/1.1 429 Too Many Requests\r\nX-RateLimit-Remaining: 10\r\nX-RateLimit-Reset: %d\r\n\r\n", reset)},
+			{ExitCode: 0, Stdout: "ok"},
+		},
+	}
+	var buf bytes.Buffer
+	reporter := ui.NewReporter(&buf)
+	reporter.SetVerbose(true)
+	executor := &Executor{
+		MaxAttempts:     2,
+		Runner:          runner,
+		Reporter:        reporter,
+		BackoffStrategy: backoff.NewHTTPAware(backoff.NewFixed(time.Millisecond), time.Hour),
+	}
+
+	// When Run() is called
+	start := time.Now()
+	result, err := executor.Run([]string{"curl", "-f", "https://api.example.com"})
+
+	// Then the retry follows the short fallback rather than the hour-long reset
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	// And the verbose output explains why
+	assert.Contains(t, buf.String(), "[note] HTTP 429 with 10 requests remaining; retrying as a transient error")
+}
+
+// TestExecutorDiscoveredRateLimitSummary tests that rate limit headers seen during
+// a run are reported in the final stats and metrics
+func TestExecutorDiscoveredRateLimitSummary(t *testing.T) {
+	// Given a server reporting its rate limit on failed attempts, then succeeding
+	reset := time.Now().Add(10 * time.Minute).Unix()
+	headers := func(remaining int) string {
+		return fmt.Sprintf("HTTP/1.1 429 Too Many Requests\r\nX-RateLimit-Limit: 100\r\nX-RateLimit-Remaining: %d\r\nX-RateLimit-Reset: %d\r\n\r\n", remaining, reset)
+	}
+	runner := &MockHTTPCommandRunner{
+		responses: []MockHTTPResponse{
+			{ExitCode: 22, Stderr: headers(5)},
+			{ExitCode: 22, Stderr: headers(0)},
+			{ExitCode: 0, Stdout: "ok"},
+		},
+	}
+
+	var buf bytes.Buffer
+	reporter := ui.NewReporter(&buf)
+	reporter.SetVerbose(true)
+	executor := &Executor{
+		MaxAttempts:        3,
+		Runner:             runner,
+		Reporter:           reporter,
+		DiscoverRateLimits: true,
+	}
+
+	// When the run completes
+	result, err := executor.Run([]string{"curl", "-i", "https://api.example.com/items"})
+	require.NoError(t, err)
+	require.True(t, result.Success)
+
+	// Then the latest discovered values are reported in stats and metrics
+	require.NotNil(t, result.Stats.RateLimit)
+	rl := result.Stats.RateLimit
+	assert.Equal(t, 100, rl.Limit)
+	assert.Equal(t, 0, rl.Remaining)
+	assert.Equal(t, reset, rl.ResetUnix)
+	assert.Equal(t, "http_header", rl.Source)
+	assert.Greater(t, rl.Confidence, 0.0)
+	assert.Equal(t, rl, result.Metrics.RateLimit)
+
+	// And the verbose summary prints them
+	reporter.FinalSummary(result.Stats)
+	output := buf.String()
+	assert.Contains(t, output, "Discovered Rate Limit:")
+	assert.Contains(t, output, "Limit: 100")
+	assert.Contains(t, output, "Remaining: 0")
+	assert.Contains(t, output, "Reset: "+time.Unix(reset, 0).UTC().Format(time.RFC3339))
+	assert.Contains(t, output, "Source: http_header")
+}
+
+// TestExecutorNoRateLimitDiscovered tests that runs without rate limit output report none
+func TestExecutorNoRateLimitDiscovered(t *testing.T) {
+	executor := &Executor{
+		MaxAttempts:        1,
+		Runner:             &FakeCommandRunnerWithOutput{ExitCode: 0, Stdout: "done"},
+		DiscoverRateLimits: true,
+	}
+
+	result, err := executor.Run([]string{"echo", "done"})
+	require.NoError(t, err)
+	assert.Nil(t, result.Stats.RateLimit)
+	assert.Nil(t, result.Metrics.RateLimit)
+}
+
+// TestExecutorHTTPAwareRetryAfterWithExitZero tests that curl without -f, which
+// exits 0 on a 429, still retries after the server's Retry-After delay
+func TestExecutorHTTPAwareRetryAfterWithExitZero(t *testing.T) {
+	// Given a 429 with Retry-After that curl exited 0 on, then a 200
+	mockRunner := &MockHTTPCommandRunner{
+		responses: []MockHTTPResponse{
+			{ExitCode: 0, Stdout: "HTTP/1.1 429 Too Many Requests\r\nRetry-After: 1\r\n\r\nslow down"},
+			{ExitCode: 0, Stdout: "HTTP/1.1 200 OK\r\n\r\nok"},
+		},
+	}
+	var buf bytes.Buffer
+	executor := &Executor{
+		MaxAttempts:     3,
+		Runner:          mockRunner,
+		BackoffStrategy: backoff.NewHTTPAware(backoff.NewFixed(50*time.Millisecond), 30*time.Minute),
+		Reporter:        ui.NewReporter(&buf),
+	}
+
+	// When Run() is called
+	start := time.Now()
+	result, err := executor.Run([]string{"curl", "-si", "https://api.example.com"})
+	elapsed := time.Since(start)
+
+	// Then the 429 should fail the first attempt and the server delay be used
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, 2, result.AttemptCount)
+	assert.Contains(t, buf.String(), "Attempt 1/3 failed (HTTP 429). Retrying in 1s.")
+	assert.GreaterOrEqual(t, elapsed, 900*time.Millisecond)
+}
+
+// TestExecutorExitZeroErrorStatusNeedsHTTPAware tests that other strategies
+// keep judging curl by its exit code
+func TestExecutorExitZeroErrorStatusNeedsHTTPAware(t *testing.T) {
+	// Given a 429 that curl exited 0 on, with a non-HTTP-aware strategy
+	executor := &Executor{
+		MaxAttempts:     3,
+		Runner:          &FakeCommandRunnerWithOutput{ExitCode: 0, Stdout: "HTTP/1.1 429 Too Many Requests\r\nRetry-After: 1\r\n\r\n"},
+		BackoffStrategy: backoff.NewFixed(10 * time.Millisecond),
+	}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"curl", "-si", "https://api.example.com"})
+
+	// Then the exit code should decide, as before
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, 1, result.AttemptCount)
+}
+
+// TestExecutorHTTPAwareEmptyBodyResponses tests that an empty 2xx response
+// succeeds while an empty error response is retried
+func TestExecutorHTTPAwareEmptyBodyResponses(t *testing.T) {
+	jsonEquals, err := conditions.NewChecker("", "", false)
+	require.NoError(t, err)
+	require.NoError(t, jsonEquals.AddJSONEquals("status=ok"))
+	successPattern, err := conditions.NewChecker("healthy", "", false)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		conditions *conditions.Checker
+		require    bool
+		response   string
+		success    bool
+		reason     string
+	}{
+		{name: "204 succeeds despite body condition", conditions: jsonEquals, response: "HTTP/1.1 204 No Content\r\nDate: Mon, 01 Jan 2024 00:00:00 GMT\r\n\r\n", success: true, reason: "HTTP 204 with empty body"},
+		{name: "200 with empty body succeeds", conditions: jsonEquals, response: "HTTP/1.1 200 OK\r\nContent-Length: 0\r\n\r\n", success: true, reason: "HTTP 200 with empty body"},
+		{name: "200 with mismatched body retries", conditions: jsonEquals, response: "HTTP/1.1 200 OK\r\n\r\n{\"status\":\"starting\"}", reason: "json field mismatch (status)"},
+		{name: "500 with empty body retries", conditions: successPattern, response: "HTTP/1.1 500 Internal Server Error\r\nContent-Length: 0\r\n\r\n", reason: "HTTP 500"},
+		{name: "500 without conditions retries", response: "HTTP/1.1 500 Internal Server Error\r\n\r\n", reason: "HTTP 500"},
+		{name: "required success pattern", conditions: successPattern, require: true, response: "HTTP/1.1 204 No Content\r\n\r\n", reason: "success pattern not matched"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given an HTTP-aware run whose command exits 0 with the response
+			runner := &FakeCommandRunnerWithOutput{ExitCode: 0, Stdout: tt.response}
+			executor := &Executor{
+				MaxAttempts:           2,
+				Runner:                runner,
+				BackoffStrategy:       backoff.NewHTTPAware(backoff.NewFixed(time.Millisecond), time.Second),
+				Conditions:            tt.conditions,
+				RequireSuccessPattern: tt.require,
+			}
+
+			// When Run() is called
+			result, err := executor.Run([]string{"curl", "-si", "https://api.example.com/health"})
+
+			// Then the response status and body should decide the outcome
+			require.NotNil(t, result)
+			assert.Equal(t, result.Err(), err)
+			assert.Equal(t, tt.success, result.Success)
+			if tt.success {
+				assert.Equal(t, 1, result.AttemptCount)
+				assert.Equal(t, tt.reason, result.Reason)
+			} else {
+				assert.Equal(t, 2, result.AttemptCount)
+				assert.Contains(t, result.Reason, tt.reason)
+			}
+		})
+	}
+}
+
+// TestExecutorRequireSuccessPattern tests that a required success pattern
+// must match, whatever the exit code
+func TestExecutorRequireSuccessPattern(t *testing.T) {
+	// Given a required success pattern and output that does not match it
+	checker, err := conditions.NewChecker("deployed", "", false)
+	require.NoError(t, err)
+	executor := &Executor{
+		MaxAttempts:           2,
+		Runner:                &FakeCommandRunnerWithSequence{ExitCodes: []int{0, 0}},
+		Conditions:            checker,
+		RequireSuccessPattern: true,
+	}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"deploy"})
+
+	// Then exit code 0 alone should not count as success
+	require.ErrorIs(t, err, ErrMaxAttempts)
+	assert.False(t, result.Success)
+	assert.Equal(t, 2, result.AttemptCount)
+}
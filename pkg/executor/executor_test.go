@@ -1,12 +1,19 @@
 package executor
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/shaneisley/patience/pkg/backoff"
 	"github.com/shaneisley/patience/pkg/conditions"
+	"github.com/shaneisley/patience/pkg/ui"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -130,7 +137,7 @@ func TestExecutor_FailureWithNoRetries(t *testing.T) {
 	result, err := executor.Run(command)
 
 	// Then there should be no error (the command ran, it just failed)
-	require.NoError(t, err)
+	require.ErrorIs(t, err, ErrMaxAttempts)
 
 	// And the result should indicate failure
 	assert.False(t, result.Success)
@@ -157,7 +164,7 @@ func TestExecutor_WithFakeRunner(t *testing.T) {
 	result, err := executor.Run([]string{"any", "command"})
 
 	// Then there should be no error
-	require.NoError(t, err)
+	require.ErrorIs(t, err, ErrMaxAttempts)
 
 	// And the result should have the fake exit code
 	assert.Equal(t, 42, result.ExitCode)
@@ -210,7 +217,7 @@ func TestExecutor_FailsAfterMaxAttempts(t *testing.T) {
 	result, err := executor.Run([]string{"any", "command"})
 
 	// Then there should be no error
-	require.NoError(t, err)
+	require.ErrorIs(t, err, ErrMaxAttempts)
 
 	// And the result should be failure
 	assert.False(t, result.Success)
@@ -226,18 +233,18 @@ func TestExecutor_FailsAfterMaxAttempts(t *testing.T) {
 }
 
 func TestExecutor_ZeroAttempts(t *testing.T) {
-	// Given an executor configured for 0 attempts
+	// Given an executor configured for 0 (unlimited) attempts with no bound
+	fakeRunner := &FakeCommandRunner{ExitCode: 0}
 	executor := NewExecutor(0)
+	executor.Runner = fakeRunner
 
 	// When Run() is called
 	result, err := executor.Run([]string{"true"})
 
-	// Then there should be no error
-	require.NoError(t, err)
-
-	// And the result should indicate failure (no attempts made)
-	assert.False(t, result.Success)
-	assert.Equal(t, 0, result.AttemptCount)
+	// Then it refuses to start rather than risk retrying forever
+	require.ErrorIs(t, err, ErrUnbounded)
+	assert.Nil(t, result)
+	assert.Equal(t, 0, fakeRunner.CallCount)
 }
 
 func TestExecutor_WaitsForFixedDelay(t *testing.T) {
@@ -257,7 +264,7 @@ func TestExecutor_WaitsForFixedDelay(t *testing.T) {
 	elapsed := time.Since(start)
 
 	// Then there should be no error
-	require.NoError(t, err)
+	require.ErrorIs(t, err, ErrMaxAttempts)
 
 	// And the result should be failure after 3 attempts
 	assert.False(t, result.Success)
@@ -316,7 +323,7 @@ func TestExecutor_FailsOnTimeout(t *testing.T) {
 	result, err := executor.Run(command)
 
 	// Then there should be no error (timeout is handled as failure, not error)
-	require.NoError(t, err)
+	require.ErrorIs(t, err, ErrTimeout)
 
 	// And the result should be a failure due to timeout
 	assert.False(t, result.Success)
@@ -337,7 +344,7 @@ func TestExecutor_TimeoutWithRetries(t *testing.T) {
 	elapsed := time.Since(start)
 
 	// Then there should be no error
-	require.NoError(t, err)
+	require.ErrorIs(t, err, ErrMaxAttempts)
 
 	// And all attempts should have timed out
 	assert.False(t, result.Success)
@@ -390,7 +397,7 @@ func TestExecutor_TimeoutWithBackoff(t *testing.T) {
 	elapsed := time.Since(start)
 
 	// Then there should be no error
-	require.NoError(t, err)
+	require.ErrorIs(t, err, ErrMaxAttempts)
 
 	// And it should fail with timeout after 2 attempts
 	assert.False(t, result.Success)
@@ -422,7 +429,7 @@ func TestExecutor_WithExponentialBackoff(t *testing.T) {
 	elapsed := time.Since(start)
 
 	// Then there should be no error
-	require.NoError(t, err)
+	require.ErrorIs(t, err, ErrMaxAttempts)
 
 	// And the result should be failure after 3 attempts
 	assert.False(t, result.Success)
@@ -499,6 +506,32 @@ func TestExecutor_WithSuccessPattern(t *testing.T) {
 	assert.Equal(t, 1, result.ExitCode) // Exit code preserved
 }
 
+func TestExecutor_ReportPatternMetrics(t *testing.T) {
+	// Given a success pattern that never matches over three attempts
+	checker, err := conditions.NewChecker("deployment successful", "", false)
+	require.NoError(t, err)
+
+	executor := &Executor{
+		MaxAttempts:          3,
+		Runner:               &FakeCommandRunnerWithOutput{ExitCode: 1, Stdout: "still rolling out"},
+		Conditions:           checker,
+		BackoffStrategy:      backoff.NewFixed(0),
+		ReportPatternMetrics: true,
+	}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"deploy"})
+	require.ErrorIs(t, err, ErrMaxAttempts)
+
+	// Then the stats count exactly one match call per attempt, since the
+	// failed run's reason reuses the last attempt's verdict
+	require.NotNil(t, result.Stats)
+	metrics := result.Stats.PatternMetrics[conditions.MetricsSuccessPattern]
+	assert.Equal(t, 3, metrics.TotalMatches)
+	assert.Equal(t, 3, metrics.FailedMatches)
+	assert.Zero(t, metrics.SuccessfulMatches)
+}
+
 func TestExecutor_WithFailurePattern(t *testing.T) {
 	// Given an executor with failure pattern matching
 	checker, err := conditions.NewChecker("", "(?i)error", false)
@@ -519,10 +552,550 @@ func TestExecutor_WithFailurePattern(t *testing.T) {
 	result, err := executor.Run([]string{"test"})
 
 	// Then there should be no error
-	require.NoError(t, err)
+	require.ErrorIs(t, err, ErrFailurePattern)
 
 	// And the result should be failure due to pattern match
 	assert.False(t, result.Success)
 	assert.Equal(t, "failure pattern matched", result.Reason)
 	assert.Equal(t, 0, result.ExitCode) // Exit code preserved
 }
+
+func TestExecutor_RetriesOnJSONFieldMismatch(t *testing.T) {
+	// Given an executor that requires status=ok in stdout JSON
+	checker, err := conditions.NewChecker("", "", false)
+	require.NoError(t, err)
+	require.NoError(t, checker.AddJSONEquals("status=ok"))
+
+	runner := &MockHTTPCommandRunner{
+		responses: []MockHTTPResponse{
+			{ExitCode: 0, Stdout: `{"status": "pending"}`},
+			{ExitCode: 0, Stdout: `{"status": "ok"}`},
+		},
+	}
+	executor := &Executor{
+		MaxAttempts: 3,
+		Runner:      runner,
+		Conditions:  checker,
+	}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"check"})
+
+	// Then the mismatch should be retried until the field matches
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, 2, result.AttemptCount)
+	assert.Equal(t, "json field matched", result.Reason)
+}
+
+func TestExecutor_ConfirmLongWaits(t *testing.T) {
+	tests := []struct {
+		name             string
+		answer           string
+		expectedAttempts int
+		expectedSuccess  bool
+	}{
+		{name: "declined wait aborts", answer: "n\n", expectedAttempts: 1, expectedSuccess: false},
+		{name: "accepted wait proceeds", answer: "y\n", expectedAttempts: 2, expectedSuccess: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given an executor that confirms waits over 10ms with a simulated TTY answer
+			var buf bytes.Buffer
+			reporter := ui.NewReporter(&buf)
+			reporter.SetInput(strings.NewReader(tt.answer), true)
+
+			executor := &Executor{
+				MaxAttempts:      3,
+				Runner:           &FakeCommandRunnerWithSequence{ExitCodes: []int{1, 0}},
+				BackoffStrategy:  backoff.NewFixed(20 * time.Millisecond),
+				Reporter:         reporter,
+				ConfirmWaitsOver: 10 * time.Millisecond,
+			}
+
+			// When Run() is called
+			result, err := executor.Run([]string{"any", "command"})
+
+			// Then the answer should decide whether the retry happens
+			require.NotNil(t, result)
+			assert.Equal(t, result.Err(), err)
+			assert.Equal(t, tt.expectedSuccess, result.Success)
+			assert.Equal(t, tt.expectedAttempts, result.AttemptCount)
+			assert.Contains(t, buf.String(), "before retry? [y/N]")
+			if !tt.expectedSuccess {
+				assert.Contains(t, result.Reason, "declined")
+			}
+		})
+	}
+}
+
+func TestExecutor_MinOutputLines(t *testing.T) {
+	// Given an executor requiring at least 3 lines of output
+	runner := &MockHTTPCommandRunner{
+		responses: []MockHTTPResponse{
+			{ExitCode: 0, Stdout: "progress 1\n"},
+			{ExitCode: 0, Stdout: "progress 1\nprogress 2\n", Stderr: "done"},
+		},
+	}
+	executor := &Executor{
+		MaxAttempts:    3,
+		Runner:         runner,
+		MinOutputLines: 3,
+	}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"stream"})
+
+	// Then the short output should be retried and the full output should succeed
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, 2, result.AttemptCount)
+}
+
+func TestExecutor_MinOutputBytesNeverReached(t *testing.T) {
+	// Given an executor requiring at least 10 bytes and a command that prints too little
+	fakeRunner := &FakeCommandRunnerWithOutput{
+		ExitCode: 0,
+		Stdout:   "ok\n",
+	}
+	executor := &Executor{
+		MaxAttempts:    2,
+		Runner:         fakeRunner,
+		MinOutputBytes: 10,
+	}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"stream"})
+
+	// Then every attempt should fail despite exit code 0
+	require.ErrorIs(t, err, ErrMaxAttempts)
+	assert.False(t, result.Success)
+	assert.Equal(t, 2, result.AttemptCount)
+	assert.Equal(t, "max retries reached (insufficient output (3 bytes, need 10))", result.Reason)
+}
+
+func TestExecutor_TimeoutOverhead(t *testing.T) {
+	t.Run("zero overhead cancels near the timeout", func(t *testing.T) {
+		// Given a 50ms timeout with no overhead buffer
+		zero := time.Duration(0)
+		executor := &Executor{
+			MaxAttempts:     1,
+			Runner:          &SystemCommandRunner{},
+			Timeout:         50 * time.Millisecond,
+			TimeoutOverhead: &zero,
+		}
+
+		// When running a command that sleeps much longer
+		start := time.Now()
+		result, err := executor.Run([]string{"sleep", "1"})
+		elapsed := time.Since(start)
+
+		// Then the attempt should be cancelled close to 50ms
+		require.ErrorIs(t, err, ErrTimeout)
+		assert.True(t, result.TimedOut)
+		assert.GreaterOrEqual(t, elapsed, 50*time.Millisecond)
+		assert.Less(t, elapsed, 90*time.Millisecond)
+	})
+
+	t.Run("default overhead is proportional and capped", func(t *testing.T) {
+		// Given executors with short and long timeouts and no explicit overhead
+		short := &Executor{Timeout: 50 * time.Millisecond}
+		long := &Executor{Timeout: time.Minute}
+
+		// Then short timeouts get a small buffer and long ones keep the 50ms cap
+		assert.Equal(t, time.Millisecond, short.timeoutOverhead())
+		assert.Equal(t, MaxTimeoutOverhead, long.timeoutOverhead())
+	})
+}
+
+func TestExecutor_CumulativePatterns(t *testing.T) {
+	newRunner := func() *MockHTTPCommandRunner {
+		return &MockHTTPCommandRunner{
+			responses: []MockHTTPResponse{
+				{ExitCode: 1, Stdout: "migrated batch 1"},
+				{ExitCode: 1, Stdout: "migrated batch 2"},
+				{ExitCode: 1, Stdout: "nothing to do"},
+			},
+		}
+	}
+	// Pattern only matches when both batches appear in the combined output
+	checker, err := conditions.NewChecker(`(?s)batch 1.*batch 2`, "", false)
+	require.NoError(t, err)
+
+	t.Run("matches across attempts 1 and 2", func(t *testing.T) {
+		// Given an executor evaluating patterns cumulatively
+		executor := &Executor{
+			MaxAttempts:        3,
+			Runner:             newRunner(),
+			Conditions:         checker,
+			CumulativePatterns: true,
+		}
+
+		// When Run() is called
+		result, err := executor.Run([]string{"migrate"})
+
+		// Then the success pattern should match on the second attempt
+		require.NoError(t, err)
+		assert.True(t, result.Success)
+		assert.Equal(t, 2, result.AttemptCount)
+		assert.Equal(t, "success pattern matched", result.Reason)
+	})
+
+	t.Run("per-attempt matching never succeeds", func(t *testing.T) {
+		// Given the same patterns evaluated per attempt
+		executor := &Executor{
+			MaxAttempts: 3,
+			Runner:      newRunner(),
+			Conditions:  checker,
+		}
+
+		// When Run() is called
+		result, err := executor.Run([]string{"migrate"})
+
+		// Then no single attempt should match
+		require.ErrorIs(t, err, ErrMaxAttempts)
+		assert.False(t, result.Success)
+		assert.Equal(t, 3, result.AttemptCount)
+	})
+
+	t.Run("max output size drops the oldest output", func(t *testing.T) {
+		// Given a cumulative buffer too small to hold both batches
+		executor := &Executor{
+			MaxAttempts:        2,
+			Runner:             newRunner(),
+			Conditions:         checker,
+			CumulativePatterns: true,
+			MaxOutputSize:      len("migrated batch 2"),
+		}
+
+		// When Run() is called
+		result, err := executor.Run([]string{"migrate"})
+
+		// Then the first batch should have been trimmed away
+		require.ErrorIs(t, err, ErrMaxAttempts)
+		assert.False(t, result.Success)
+	})
+}
+
+func TestExecutor_RetryUntilStable(t *testing.T) {
+	t.Run("succeeds once output stops changing", func(t *testing.T) {
+		// Given an executor waiting for output unchanged across 2 consecutive attempts
+		runner := &MockHTTPCommandRunner{
+			responses: []MockHTTPResponse{
+				{ExitCode: 0, Stdout: "replicas: 1\n"},
+				{ExitCode: 0, Stdout: "replicas: 2\n"},
+				{ExitCode: 0, Stdout: "replicas: 3\n"},
+				{ExitCode: 0, Stdout: "replicas: 3  \n"},
+				{ExitCode: 0, Stdout: "replicas: 3\n"},
+			},
+		}
+		executor := &Executor{
+			MaxAttempts:    6,
+			Runner:         runner,
+			StableAttempts: 2,
+		}
+
+		// When Run() is called
+		result, err := executor.Run([]string{"kubectl", "get"})
+
+		// Then it should succeed on the second repeat of the settled output
+		require.NoError(t, err)
+		assert.True(t, result.Success)
+		assert.Equal(t, 5, result.AttemptCount)
+		assert.Equal(t, "output stable (unchanged 2/2)", result.Reason)
+	})
+
+	t.Run("exhausts attempts while output keeps changing", func(t *testing.T) {
+		// Given output that differs on every attempt
+		runner := &MockHTTPCommandRunner{
+			responses: []MockHTTPResponse{
+				{ExitCode: 0, Stdout: "a"},
+				{ExitCode: 0, Stdout: "b"},
+				{ExitCode: 0, Stdout: "c"},
+			},
+		}
+		executor := &Executor{
+			MaxAttempts:    3,
+			Runner:         runner,
+			StableAttempts: 1,
+		}
+
+		// When Run() is called
+		result, err := executor.Run([]string{"poll"})
+
+		// Then every attempt should be retried until the limit is reached
+		require.ErrorIs(t, err, ErrMaxAttempts)
+		assert.False(t, result.Success)
+		assert.Equal(t, 3, result.AttemptCount)
+		assert.Equal(t, "max retries reached (output not yet stable (unchanged 0/1))", result.Reason)
+	})
+
+	t.Run("failed attempts reset the streak", func(t *testing.T) {
+		// Given identical output where the middle attempt fails
+		runner := &MockHTTPCommandRunner{
+			responses: []MockHTTPResponse{
+				{ExitCode: 0, Stdout: "ready"},
+				{ExitCode: 1, Stdout: "ready"},
+				{ExitCode: 0, Stdout: "ready"},
+				{ExitCode: 0, Stdout: "ready"},
+			},
+		}
+		executor := &Executor{
+			MaxAttempts:    4,
+			Runner:         runner,
+			StableAttempts: 1,
+		}
+
+		// When Run() is called
+		result, err := executor.Run([]string{"poll"})
+
+		// Then stability should only count after the failure
+		require.NoError(t, err)
+		assert.True(t, result.Success)
+		assert.Equal(t, 4, result.AttemptCount)
+	})
+}
+
+func TestSystemCommandRunner_PreservesProxyEnv(t *testing.T) {
+	// Given proxy variables set in the parent environment
+	t.Setenv("HTTP_PROXY", "http://proxy.internal:3128")
+	t.Setenv("NO_PROXY", "localhost,.internal")
+
+	// When a child process prints them
+	runner := &SystemCommandRunner{}
+	output, err := runner.RunWithOutput([]string{"sh", "-c", `printf '%s|%s' "$HTTP_PROXY" "$NO_PROXY"`})
+
+	// Then the child should see the parent's values unchanged
+	require.NoError(t, err)
+	assert.Equal(t, "http://proxy.internal:3128|localhost,.internal", output.Stdout)
+}
+
+func TestSystemCommandRunner_ForwardsToConfiguredWriters(t *testing.T) {
+	// Given a runner forwarding the command's stdout to another writer
+	var forwarded bytes.Buffer
+	runner := &SystemCommandRunner{Stdout: &forwarded, Stderr: &forwarded}
+
+	// When a command writes to both streams
+	output, err := runner.RunWithOutput([]string{"sh", "-c", "echo out; echo err >&2"})
+
+	// Then the output is forwarded there and still captured per stream
+	require.NoError(t, err)
+	assert.Contains(t, forwarded.String(), "out\n")
+	assert.Contains(t, forwarded.String(), "err\n")
+	assert.Equal(t, "out\n", output.Stdout)
+	assert.Equal(t, "err\n", output.Stderr)
+}
+
+func TestSystemCommandRunner_MergeStreams(t *testing.T) {
+	// Given a runner merging stdout and stderr
+	var forwarded bytes.Buffer
+	runner := &SystemCommandRunner{Stdout: &forwarded, Stderr: io.Discard, MergeStreams: true}
+
+	// When a command alternates between the streams many times
+	script := `i=1; while [ $i -le 50 ]; do echo "out $i"; echo "err $i" >&2; i=$((i+1)); done`
+	output, err := runner.RunWithOutput([]string{"sh", "-c", script})
+
+	// Then the capture and the forwarded output keep the exact write order
+	require.NoError(t, err)
+	var want strings.Builder
+	for i := 1; i <= 50; i++ {
+		fmt.Fprintf(&want, "out %d\nerr %d\n", i, i)
+	}
+	assert.Equal(t, want.String(), output.Stdout)
+	assert.Empty(t, output.Stderr)
+	assert.Equal(t, want.String(), forwarded.String())
+}
+
+func TestPreserveProxyEnv(t *testing.T) {
+	// Given an environment where a proxy entry was overridden and another dropped
+	parent := map[string]string{
+		"HTTP_PROXY":  "http://proxy.internal:3128",
+		"https_proxy": "http://secure.internal:3129",
+	}
+	lookup := func(name string) (string, bool) {
+		value, ok := parent[name]
+		return value, ok
+	}
+	env := []string{"PATH=/usr/bin", "HTTP_PROXY=", "CURL_CA_BUNDLE=/tmp/ca.pem"}
+
+	// When proxy variables are preserved
+	result := preserveProxyEnv(env, lookup)
+
+	// Then the parent's proxy values should win and other entries stay intact
+	assert.ElementsMatch(t, []string{
+		"PATH=/usr/bin",
+		"CURL_CA_BUNDLE=/tmp/ca.pem",
+		"HTTP_PROXY=http://proxy.internal:3128",
+		"https_proxy=http://secure.internal:3129",
+	}, result)
+}
+
+func TestExecutor_TimingMarkers(t *testing.T) {
+	// Given an executor emitting timing markers for a command that succeeds on attempt 3
+	runner := &MockHTTPCommandRunner{
+		responses: []MockHTTPResponse{
+			{ExitCode: 1},
+			{ExitCode: 2},
+			{ExitCode: 0},
+		},
+	}
+	var markers bytes.Buffer
+	executor := &Executor{
+		MaxAttempts:     3,
+		Runner:          runner,
+		BackoffStrategy: backoff.NewFixed(time.Millisecond),
+		TimingMarkers:   &markers,
+	}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"deploy"})
+	require.NoError(t, err)
+	require.True(t, result.Success)
+
+	// Then each attempt should produce one parseable marker line
+	markerPattern := regexp.MustCompile(`^PATIENCE attempt=(\d+) start_ns=(\d+) end_ns=(\d+) exit_code=(-?\d+) success=(true|false)$`)
+	lines := strings.Split(strings.TrimSpace(markers.String()), "\n")
+	require.Len(t, lines, 3)
+
+	var lastEnd int64
+	for i, line := range lines {
+		match := markerPattern.FindStringSubmatch(line)
+		require.NotNil(t, match, "unparseable marker: %q", line)
+
+		attempt, _ := strconv.Atoi(match[1])
+		start, _ := strconv.ParseInt(match[2], 10, 64)
+		end, _ := strconv.ParseInt(match[3], 10, 64)
+
+		// And attempts are numbered in order with monotonic timestamps
+		assert.Equal(t, i+1, attempt)
+		assert.GreaterOrEqual(t, end, start)
+		assert.GreaterOrEqual(t, start, lastEnd)
+		lastEnd = end
+	}
+	assert.Contains(t, lines[0], "exit_code=1 success=false")
+	assert.Contains(t, lines[2], "exit_code=0 success=true")
+}
+
+func TestExecutor_TagsRecordedOnMetrics(t *testing.T) {
+	// Given an executor with run tags
+	executor := &Executor{
+		MaxAttempts: 1,
+		Runner:      &FakeCommandRunnerWithOutput{ExitCode: 0},
+		Tags:        map[string]string{"env": "prod"},
+	}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"deploy"})
+
+	// Then the run metrics should carry the tags
+	require.NoError(t, err)
+	require.NotNil(t, result.Metrics)
+	assert.Equal(t, map[string]string{"env": "prod"}, result.Metrics.Tags)
+}
+
+func TestExecutor_CustomMetricsRecordedOnMetrics(t *testing.T) {
+	// Given an executor with custom metrics
+	executor := &Executor{
+		MaxAttempts:   1,
+		Runner:        &FakeCommandRunnerWithOutput{ExitCode: 0},
+		CustomMetrics: map[string]float64{"build_number": 1234},
+	}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"build"})
+
+	// Then the run metrics should carry them
+	require.NoError(t, err)
+	require.NotNil(t, result.Metrics)
+	assert.Equal(t, map[string]float64{"build_number": 1234}, result.Metrics.CustomMetrics)
+}
+
+func TestExecutor_ShellRunsPipeline(t *testing.T) {
+	// Given an executor running commands through the shell
+	executor := NewExecutor(1)
+	executor.Shell = true
+	executor.Conditions, _ = conditions.NewChecker(`^b\s*$`, "", false)
+
+	// When Run() is called with a pipeline
+	result, err := executor.Run([]string{"echo", "a", "|", "tr", "a", "b"})
+
+	// Then the pipe should take effect
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, "success pattern matched", result.Reason)
+}
+
+func TestExecutor_ShellKeepsArgumentQuoting(t *testing.T) {
+	tests := []struct {
+		name    string
+		command []string
+		want    string
+	}{
+		{name: "argument with spaces", command: []string{"printf", "%s|", "a  b", "c"}, want: "a  b|c|"},
+		{name: "argument with a quote", command: []string{"printf", "%s", "it's"}, want: "it's"},
+		{name: "single script", command: []string{"printf '%s|' 'a  b' | tr '|' ."}, want: "a  b."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given an executor running commands through the shell
+			var stdout bytes.Buffer
+			executor := &Executor{MaxAttempts: 1, Shell: true, Runner: &SystemCommandRunner{Stdout: &stdout, Stderr: io.Discard}}
+
+			// When Run() is called
+			_, err := executor.Run(tt.command)
+
+			// Then each argument should reach the command as one word
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, stdout.String())
+		})
+	}
+}
+
+func TestExecutor_CostBudgetLimitsAttempts(t *testing.T) {
+	tests := []struct {
+		name         string
+		costPer      float64
+		maxCost      float64
+		wantAttempts int
+		wantReason   string
+		wantCost     float64
+	}{
+		{name: "budget covers three attempts", costPer: 0.25, maxCost: 0.8, wantAttempts: 3, wantReason: ReasonCostBudgetExceeded, wantCost: 0.75},
+		{name: "budget exactly covers two attempts", costPer: 0.5, maxCost: 1, wantAttempts: 2, wantReason: ReasonCostBudgetExceeded, wantCost: 1},
+		{name: "decimal costs sum exactly to budget", costPer: 0.1, maxCost: 0.3, wantAttempts: 3, wantReason: ReasonCostBudgetExceeded, wantCost: 0.3},
+		{name: "budget larger than attempts", costPer: 0.1, maxCost: 10, wantAttempts: 5, wantReason: "max retries reached (exit code 1)", wantCost: 0.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given a failing command with a per-attempt cost and a budget
+			var buf bytes.Buffer
+			runner := &FakeCommandRunner{ExitCode: 1}
+			executor := &Executor{
+				MaxAttempts:    5,
+				Runner:         runner,
+				Reporter:       ui.NewReporter(&buf),
+				CostPerAttempt: tt.costPer,
+				MaxCost:        tt.maxCost,
+			}
+
+			// When Run() is called
+			result, err := executor.Run([]string{"paid-api"})
+
+			// Then the budget should stop the run before it is exceeded
+			require.NotNil(t, result)
+			assert.Equal(t, result.Err(), err)
+			assert.False(t, result.Success)
+			assert.Equal(t, tt.wantAttempts, runner.CallCount)
+			assert.Equal(t, tt.wantAttempts, result.AttemptCount)
+			assert.Equal(t, tt.wantReason, result.Reason)
+			assert.InDelta(t, tt.wantCost, result.Stats.TotalCost, 1e-9)
+
+			// And the final summary should include the total cost
+			executor.Reporter.FinalSummary(result.Stats)
+			assert.Contains(t, buf.String(), fmt.Sprintf("Total Cost: %g", tt.wantCost))
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package executor
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// failureCategoryNamePattern restricts category names to labels safe to
+// aggregate on, such as "auth" or "quota-exceeded"
+var failureCategoryNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// FailureCategory names a class of failed runs, such as "auth", recognized by
+// a pattern in the final reason or the last attempt's output
+type FailureCategory struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// ParseFailureCategory parses NAME=REGEX, e.g. auth=401|403|[Uu]nauthorized
+func ParseFailureCategory(expr string) (FailureCategory, error) {
+	name, pattern, found := strings.Cut(expr, "=")
+	if !found || pattern == "" {
+		return FailureCategory{}, fmt.Errorf("invalid failure category %q: expected NAME=REGEX", expr)
+	}
+	if !failureCategoryNamePattern.MatchString(name) {
+		return FailureCategory{}, fmt.Errorf("invalid failure category %q: name must start with a lowercase letter and contain only lowercase letters, digits, '_' or '-'", expr)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return FailureCategory{}, fmt.Errorf("invalid failure category %q: %w", expr, err)
+	}
+	return FailureCategory{Name: name, Pattern: re}, nil
+}
+
+// ParseFailureCategories parses repeated NAME=REGEX categories in order
+func ParseFailureCategories(exprs []string) ([]FailureCategory, error) {
+	var categories []FailureCategory
+	for _, expr := range exprs {
+		category, err := ParseFailureCategory(expr)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+	return categories, nil
+}
+
+// failureCategory returns the name of the first FailureCategories entry whose
+// pattern matches the final reason or the last attempt's output, or ""
+func (e *Executor) failureCategory(reason string, output CommandOutput) string {
+	for _, category := range e.FailureCategories {
+		for _, text := range []string{reason, output.Stdout, output.Stderr} {
+			if category.Pattern.MatchString(text) {
+				return category.Name
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,50 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutor_FailureCategory(t *testing.T) {
+	categories, err := ParseFailureCategories([]string{
+		`auth=HTTP/1\.1 40[13]|[Uu]nauthorized`,
+		`quota=quota exceeded`,
+	})
+	require.NoError(t, err)
+	run := func(runner CommandRunner) string {
+		executor := &Executor{MaxAttempts: 2, Runner: runner, FailureCategories: categories}
+		result, err := executor.Run([]string{"curl", "api"})
+		require.NotNil(t, result)
+		assert.Equal(t, result.Err(), err)
+		return result.Metrics.FailureCategory
+	}
+
+	// A failure whose output matches a category is classified by it
+	assert.Equal(t, "auth", run(&FakeCommandRunnerWithOutput{ExitCode: 22, Stderr: "HTTP/1.1 401 Unauthorized"}))
+	assert.Equal(t, "quota", run(&FakeCommandRunnerWithOutput{ExitCode: 1, Stdout: `{"error":"quota exceeded"}`}))
+
+	// A failure no category matches keeps only its failure kind
+	executor := &Executor{MaxAttempts: 1, Runner: &FakeCommandRunner{ExitCode: 7}, FailureCategories: categories}
+	result, err := executor.Run([]string{"flaky"})
+	require.ErrorIs(t, err, ErrMaxAttempts)
+	assert.Empty(t, result.Metrics.FailureCategory)
+	assert.Equal(t, "max_attempts", result.Metrics.Category())
+
+	// And a successful run has no category
+	assert.Empty(t, run(&FakeCommandRunnerWithOutput{ExitCode: 0, Stdout: "Unauthorized retries: 0"}))
+}
+
+func TestParseFailureCategory_Invalid(t *testing.T) {
+	for expr, expected := range map[string]string{
+		"auth":         "expected NAME=REGEX",
+		"auth=":        "expected NAME=REGEX",
+		"Auth=401":     "name must start with a lowercase letter",
+		"auth=(401":    "invalid failure category",
+		"bad name=401": "name must start with a lowercase letter",
+	} {
+		_, err := ParseFailureCategory(expr)
+		assert.ErrorContains(t, err, expected, expr)
+	}
+}
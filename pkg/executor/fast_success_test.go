@@ -0,0 +1,135 @@
+package executor
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/shaneisley/patience/pkg/conditions"
+	"github.com/shaneisley/patience/pkg/ui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutor_FirstSuccessExitFast(t *testing.T) {
+	// Given a fast-path executor whose command succeeds at once
+	executor := &Executor{
+		MaxAttempts:          3,
+		Runner:               &FakeCommandRunner{ExitCode: 0},
+		FirstSuccessExitFast: true,
+	}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"true"})
+
+	// Then a minimal successful result should be returned
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, 1, result.AttemptCount)
+	assert.Equal(t, 0, result.ExitCode)
+	assert.Equal(t, "exit code 0", result.Reason)
+	assert.NoError(t, result.Err())
+	assert.Nil(t, result.Stats)
+	assert.Nil(t, result.Metrics)
+}
+
+func TestExecutor_FirstSuccessExitFastKeepsReporter(t *testing.T) {
+	// Given a fast-path executor with a reporter
+	var buf bytes.Buffer
+	executor := &Executor{
+		MaxAttempts:          3,
+		Runner:               &FakeCommandRunner{ExitCode: 0},
+		Reporter:             ui.NewReporter(&buf),
+		FirstSuccessExitFast: true,
+	}
+
+	// When the first attempt succeeds
+	result, err := executor.Run([]string{"true"})
+
+	// Then the fast path should still be taken, skipping only the summary
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Nil(t, result.Stats)
+
+	// And the attempt's progress should have been reported
+	assert.Contains(t, buf.String(), "Attempt 1/3")
+}
+
+func TestExecutor_FirstSuccessExitFastFallsBack(t *testing.T) {
+	successPattern, err := conditions.NewChecker("ready", "", false)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		executor *Executor
+		attempts int
+	}{
+		{
+			name:     "success after a retry",
+			executor: &Executor{MaxAttempts: 3, Runner: &FakeCommandRunnerWithSequence{ExitCodes: []int{1, 0}}},
+			attempts: 2,
+		},
+		{
+			name:     "patterns configured",
+			executor: &Executor{MaxAttempts: 3, Runner: &FakeCommandRunnerWithOutput{Stdout: "ready"}, Conditions: successPattern},
+			attempts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given a fast-path executor that isn't eligible for the fast path
+			tt.executor.FirstSuccessExitFast = true
+
+			// When Run() is called
+			result, err := tt.executor.Run([]string{"any", "command"})
+
+			// Then the full result should be built as usual
+			require.NoError(t, err)
+			assert.True(t, result.Success)
+			assert.Equal(t, tt.attempts, result.AttemptCount)
+			require.NotNil(t, result.Stats)
+			require.NotNil(t, result.Metrics)
+			assert.Equal(t, tt.attempts, result.Metrics.TotalAttempts)
+		})
+	}
+}
+
+func TestExecutor_FirstSuccessExitFastAllocatesLess(t *testing.T) {
+	// Given identical executors with and without the fast path
+	run := func(fast bool) float64 {
+		executor := &Executor{MaxAttempts: 3, Runner: &FakeCommandRunner{ExitCode: 0}, FirstSuccessExitFast: fast}
+		command := []string{"curl", "-s", "https://api.example.com/health"}
+		return testing.AllocsPerRun(100, func() {
+			if _, err := executor.Run(command); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+
+	// When allocations per run are measured
+	full, fast := run(false), run(true)
+
+	// Then the fast path should allocate less
+	t.Logf("allocations per run: full %.0f, fast %.0f", full, fast)
+	assert.Less(t, fast, full)
+}
+
+func benchmarkFirstSuccess(b *testing.B, fast bool) {
+	executor := &Executor{MaxAttempts: 3, Runner: &FakeCommandRunner{ExitCode: 0}, FirstSuccessExitFast: fast}
+	command := []string{"curl", "-s", "https://api.example.com/health"}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := executor.Run(command); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRun_FirstSuccess(b *testing.B) {
+	benchmarkFirstSuccess(b, false)
+}
+
+func BenchmarkRun_FirstSuccessExitFast(b *testing.B) {
+	benchmarkFirstSuccess(b, true)
+}
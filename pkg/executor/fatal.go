@@ -0,0 +1,36 @@
+package executor
+
+import (
+	"fmt"
+
+	"github.com/shaneisley/patience/pkg/conditions"
+)
+
+// ReasonFatalPattern is the final reason when FatalPattern matches an attempt
+const ReasonFatalPattern = "fatal pattern matched"
+
+// DefaultFatalExitCode is the exit code reported when FatalPattern matches and
+// FatalExitCode is unset
+const DefaultFatalExitCode = 2
+
+// fatalMatched checks any attempt's output, successful or not, against
+// FatalPattern. A match overrides the attempt's outcome with a failure that
+// stops the run.
+func (e *Executor) fatalMatched(attempt int, output CommandOutput) (conditions.Result, bool) {
+	if e.FatalPattern == nil {
+		return conditions.Result{}, false
+	}
+	if !e.FatalPattern.MatchString(output.Stdout) && !e.FatalPattern.MatchString(output.Stderr) {
+		return conditions.Result{}, false
+	}
+	e.warn(fmt.Sprintf("attempt %d matched fatal pattern %q; stopping", attempt, e.FatalPattern.String()))
+	return conditions.Result{Success: false, Reason: ReasonFatalPattern}, true
+}
+
+// fatalExitCode returns the exit code to report for a fatal pattern match
+func (e *Executor) fatalExitCode() int {
+	if e.FatalExitCode == 0 {
+		return DefaultFatalExitCode
+	}
+	return e.FatalExitCode
+}
@@ -0,0 +1,75 @@
+package executor
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/shaneisley/patience/pkg/ui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutor_FatalPatternStopsRun(t *testing.T) {
+	// Given a fatal pattern that first appears in attempt 2's output
+	runner := &MockHTTPCommandRunner{responses: []MockHTTPResponse{
+		{ExitCode: 1, Stderr: "connection refused"},
+		{ExitCode: 1, Stderr: "panic: nil map"},
+		{ExitCode: 0, Stdout: "ok"},
+	}}
+	var buf strings.Builder
+	executor := &Executor{
+		MaxAttempts:   5,
+		Runner:        runner,
+		FatalPattern:  regexp.MustCompile("panic|fatal"),
+		FatalExitCode: 3,
+		Reporter:      ui.NewReporter(&buf),
+	}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"any", "command"})
+
+	// Then the run should stop after attempt 2 with the configured exit code
+	require.ErrorIs(t, err, ErrFatalPattern)
+	assert.False(t, result.Success)
+	assert.Equal(t, 2, result.AttemptCount)
+	assert.Equal(t, 3, result.ExitCode)
+	assert.Equal(t, ReasonFatalPattern, result.Reason)
+	assert.Equal(t, FailureFatal, result.Kind)
+	assert.ErrorIs(t, result.Err(), ErrFatalPattern)
+	assert.NotErrorIs(t, result.Err(), ErrFailurePattern)
+	assert.Equal(t, "fatal_pattern", result.Metrics.FailureKind)
+	assert.Equal(t, 1, result.Metrics.Attempts[1].ExitCode)
+	assert.Contains(t, buf.String(), `attempt 2 matched fatal pattern "panic|fatal"`)
+}
+
+func TestExecutor_FatalPatternOverridesSuccess(t *testing.T) {
+	// Given an attempt that exits 0 but prints a fatal message
+	executor := &Executor{
+		MaxAttempts:  3,
+		Runner:       &FakeCommandRunnerWithOutput{ExitCode: 0, Stdout: "deployed\nfatal: replica crashed"},
+		FatalPattern: regexp.MustCompile("fatal"),
+		Reporter:     ui.NewReporter(&strings.Builder{}),
+	}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"any", "command"})
+
+	// Then the run should fail with the default fatal exit code
+	require.ErrorIs(t, err, ErrFatalPattern)
+	assert.False(t, result.Success)
+	assert.Equal(t, 1, result.AttemptCount)
+	assert.Equal(t, DefaultFatalExitCode, result.ExitCode)
+	assert.Equal(t, ReasonFatalPattern, result.Reason)
+}
+
+func TestExecutor_FailureKindInMetrics(t *testing.T) {
+	// Given a run that uses every attempt
+	executor := &Executor{MaxAttempts: 2, Runner: &FakeCommandRunner{ExitCode: 1}}
+
+	// When Run() is called
+	result, _ := executor.Run([]string{"any", "command"})
+
+	// Then metrics should say why it stopped
+	assert.Equal(t, "max_attempts", result.Metrics.FailureKind)
+}
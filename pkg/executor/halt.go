@@ -0,0 +1,36 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ReasonHaltedByDaemon is the final reason when the daemon's kill switch is set
+// for the run's resource
+const ReasonHaltedByDaemon = "halted by daemon"
+
+// haltCheckTimeout bounds each halt check so a slow daemon can't stall retries
+const haltCheckTimeout = 2 * time.Second
+
+// checkHalt asks the daemon, when one is configured, whether retries for the
+// command's resource have been halted, returning ReasonHaltedByDaemon if so.
+// An unreachable daemon never stops the run.
+func (e *Executor) checkHalt(command []string) string {
+	if e.DaemonClient == nil {
+		return ""
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), haltCheckTimeout)
+	defer cancel()
+
+	resourceID := e.resourceID(command)
+	halted, reason, err := e.DaemonClient.CheckHalt(ctx, resourceID)
+	if err != nil || !halted {
+		return ""
+	}
+	if reason != "" {
+		e.warn(fmt.Sprintf("daemon halted retries for %s: %s", resourceID, reason))
+	}
+	return ReasonHaltedByDaemon
+}
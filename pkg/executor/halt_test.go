@@ -0,0 +1,130 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shaneisley/patience/pkg/daemon"
+	"github.com/shaneisley/patience/pkg/ui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// haltingRunner fails every attempt and runs onCall after each one
+type haltingRunner struct {
+	onCall func(call int)
+	calls  int
+}
+
+func (r *haltingRunner) RunWithOutputAndContext(ctx context.Context, command []string) (CommandOutput, error) {
+	r.calls++
+	if r.onCall != nil {
+		r.onCall(r.calls)
+	}
+	return CommandOutput{ExitCode: 1, Stderr: "upstream unavailable"}, nil
+}
+
+func (r *haltingRunner) RunWithOutput(command []string) (CommandOutput, error) {
+	return r.RunWithOutputAndContext(context.Background(), command)
+}
+
+func (r *haltingRunner) RunWithContext(ctx context.Context, command []string) (int, error) {
+	output, err := r.RunWithOutputAndContext(ctx, command)
+	return output.ExitCode, err
+}
+
+func (r *haltingRunner) Run(command []string) (int, error) {
+	return r.RunWithContext(context.Background(), command)
+}
+
+// startCoordinationServer starts a daemon coordination server and returns it
+// with a client connected to it
+func startCoordinationServer(t *testing.T) (*daemon.UnixServer, *daemon.DaemonClient) {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "halt")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	socketPath := filepath.Join(dir, "d.sock")
+
+	server := daemon.NewUnixServer(socketPath)
+	require.NoError(t, server.Start(context.Background()))
+	t.Cleanup(func() { server.Stop() })
+
+	client := daemon.NewDaemonClient(socketPath)
+	t.Cleanup(func() { client.Close() })
+	return server, client
+}
+
+func TestExecutor_HaltedByDaemonStopsBeforeNextAttempt(t *testing.T) {
+	// Given a coordinating executor whose resource is halted during attempt 2
+	server, client := startCoordinationServer(t)
+	runner := &haltingRunner{onCall: func(call int) {
+		if call == 2 {
+			server.Halts().Halt("payments-api", "incident 42")
+		}
+	}}
+	var buf bytes.Buffer
+	executor := &Executor{
+		MaxAttempts:  5,
+		Runner:       runner,
+		Reporter:     ui.NewReporter(&buf),
+		DaemonClient: client,
+		ResourceID:   "payments-api",
+	}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"curl", "https://payments.example.com"})
+
+	// Then the run should stop before attempt 3 with the halt reason
+	require.ErrorIs(t, err, ErrInterrupted)
+	assert.False(t, result.Success)
+	assert.Equal(t, 2, runner.calls)
+	assert.Equal(t, 2, result.AttemptCount)
+	assert.Equal(t, ReasonHaltedByDaemon, result.Reason)
+	assert.ErrorIs(t, result.Err(), ErrInterrupted)
+	assert.Contains(t, buf.String(), "daemon halted retries for payments-api: incident 42")
+	assert.Contains(t, buf.String(), "not starting attempt 3: halted by daemon")
+}
+
+func TestExecutor_HaltOnlyAffectsItsResource(t *testing.T) {
+	// Given a halt on a different resource
+	server, client := startCoordinationServer(t)
+	server.Halts().Halt("search-api", "")
+	runner := &haltingRunner{}
+	executor := &Executor{
+		MaxAttempts:  3,
+		Runner:       runner,
+		DaemonClient: client,
+		ResourceID:   "payments-api",
+	}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"curl", "https://payments.example.com"})
+
+	// Then every attempt should still run
+	require.ErrorIs(t, err, ErrMaxAttempts)
+	assert.Equal(t, 3, runner.calls)
+	assert.NotEqual(t, ReasonHaltedByDaemon, result.Reason)
+}
+
+func TestExecutor_HaltCheckIgnoredWithoutDaemon(t *testing.T) {
+	// Given a daemon client whose daemon is not running
+	dir := t.TempDir()
+	runner := &haltingRunner{}
+	executor := &Executor{
+		MaxAttempts:  2,
+		Runner:       runner,
+		DaemonClient: daemon.NewDaemonClient(filepath.Join(dir, "missing.sock")),
+	}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"true"})
+
+	// Then the unreachable daemon should not stop the retries
+	require.ErrorIs(t, err, ErrMaxAttempts)
+	assert.Equal(t, 2, runner.calls)
+	assert.Equal(t, 2, result.AttemptCount)
+}
@@ -0,0 +1,55 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"time"
+)
+
+// hookTailSize is the number of trailing bytes of each output stream passed to
+// external hook commands
+const hookTailSize = 4096
+
+// hookTimeout bounds how long a hook command may take to answer
+const hookTimeout = 10 * time.Second
+
+// attemptContext is the attempt context written to a hook command's stdin
+type attemptContext struct {
+	Attempt    int    `json:"attempt"`
+	ExitCode   int    `json:"exit_code"`
+	StdoutTail string `json:"stdout_tail"`
+	StderrTail string `json:"stderr_tail"`
+}
+
+// runHook runs an external hook command (such as --delay-command or
+// --check-command) through sh -c with the attempt context as JSON on stdin,
+// returning its stdout and stderr. A non-zero exit is returned as an
+// *exec.ExitError.
+func runHook(command string, attempt int, output CommandOutput) (string, string, error) {
+	input, err := json.Marshal(attemptContext{
+		Attempt:    attempt,
+		ExitCode:   output.ExitCode,
+		StdoutTail: keepTail(output.Stdout, hookTailSize),
+		StderrTail: keepTail(output.Stderr, hookTailSize),
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encode attempt context: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		err = fmt.Errorf("timed out after %v", hookTimeout)
+	}
+	return stdout.String(), stderr.String(), err
+}
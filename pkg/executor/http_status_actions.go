@@ -0,0 +1,149 @@
+package executor
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/shaneisley/patience/pkg/backoff"
+	"github.com/shaneisley/patience/pkg/conditions"
+)
+
+// Actions that can be mapped to an HTTP status
+const (
+	HTTPStatusSuccess = "success"
+	HTTPStatusRetry   = "retry"
+	HTTPStatusFail    = "fail"
+)
+
+// HTTPStatusActions maps HTTP status codes to the outcome of an attempt whose
+// response carries them, overriding the other success conditions
+type HTTPStatusActions map[int]string
+
+// ParseHTTPStatusActions parses a mapping such as "202:retry,200:success,409:fail"
+func ParseHTTPStatusActions(spec string) (HTTPStatusActions, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, nil
+	}
+
+	actions := make(HTTPStatusActions)
+	for _, entry := range strings.Split(spec, ",") {
+		statusStr, action, found := strings.Cut(strings.TrimSpace(entry), ":")
+		if !found {
+			return nil, fmt.Errorf("invalid HTTP status action %q: expected status:action", entry)
+		}
+
+		status, err := strconv.Atoi(strings.TrimSpace(statusStr))
+		if err != nil || status < 100 || status > 599 {
+			return nil, fmt.Errorf("invalid HTTP status action %q: status must be between 100 and 599", entry)
+		}
+		if _, dup := actions[status]; dup {
+			return nil, fmt.Errorf("invalid HTTP status action %q: status %d listed twice", entry, status)
+		}
+
+		action = strings.ToLower(strings.TrimSpace(action))
+		switch action {
+		case HTTPStatusSuccess, HTTPStatusRetry, HTTPStatusFail:
+		default:
+			return nil, fmt.Errorf("invalid HTTP status action %q: action must be success, retry or fail", entry)
+		}
+		actions[status] = action
+	}
+	return actions, nil
+}
+
+// httpStatusLinePattern matches a response status line, including the "< "
+// prefix curl -v writes to stderr
+var httpStatusLinePattern = regexp.MustCompile(`(?m)^(?:< )?HTTP/[0-9.]+ ([1-5][0-9]{2})\b`)
+
+// finalHTTPStatus returns the status of the last response in the output, so
+// interim responses (100 Continue, redirects followed with -L) are skipped.
+// Stdout is preferred; stderr covers curl -v.
+func finalHTTPStatus(output CommandOutput) (int, bool) {
+	for _, stream := range []string{output.Stdout, output.Stderr} {
+		matches := httpStatusLinePattern.FindAllStringSubmatch(stream, -1)
+		if len(matches) == 0 {
+			continue
+		}
+		status, err := strconv.Atoi(matches[len(matches)-1][1])
+		if err == nil {
+			return status, true
+		}
+	}
+	return 0, false
+}
+
+// httpResponseResult adjusts an attempt that exited 0 by its final HTTP status
+// when the strategy is HTTP-aware. An error status (400 or above) fails an
+// attempt that only succeeded by its exit code, treating curl without -f like
+// curl -f so the server's retry timing is used. A 2xx status with an empty
+// body, such as 204 No Content, succeeds even though body-based conditions
+// can't match it, unless RequireSuccessPattern is set. A matched failure
+// pattern always stands.
+func (e *Executor) httpResponseResult(output CommandOutput, result conditions.Result) conditions.Result {
+	if output.ExitCode != 0 || result.Reason == failurePatternReason {
+		return result
+	}
+	if _, ok := backoff.Unwrap(e.BackoffStrategy).(interface {
+		ProcessCommandOutput(stdout, stderr string, exitCode int)
+	}); !ok {
+		return result
+	}
+	status, ok := finalHTTPStatus(output)
+	if !ok {
+		return result
+	}
+
+	switch {
+	case status >= 400 && result.Success && result.Reason == exitCodeZeroReason:
+		return conditions.Result{Success: false, Reason: fmt.Sprintf("HTTP %d", status)}
+	case status >= 200 && status < 300 && !result.Success && !e.RequireSuccessPattern && httpBodyEmpty(output):
+		return conditions.Result{Success: true, Reason: fmt.Sprintf("HTTP %d with empty body", status)}
+	}
+	return result
+}
+
+// httpBodyEmpty reports whether the final response in the output has no body
+func httpBodyEmpty(output CommandOutput) bool {
+	return strings.TrimSpace(finalHTTPBody(output)) == ""
+}
+
+// finalHTTPBody returns the body of the final response in the output. With
+// headers in stdout (curl -i) the body follows the last header block; with
+// headers on stderr (curl -v) all of stdout is the body.
+func finalHTTPBody(output CommandOutput) string {
+	locations := httpStatusLinePattern.FindAllStringIndex(output.Stdout, -1)
+	if len(locations) == 0 {
+		return output.Stdout
+	}
+	response := strings.ReplaceAll(output.Stdout[locations[len(locations)-1][0]:], "\r\n", "\n")
+	_, body, _ := strings.Cut(response, "\n\n")
+	return body
+}
+
+// Apply overrides result when the attempt's HTTP status has a mapped action,
+// returning the new result and whether retrying should stop
+func (a HTTPStatusActions) Apply(output CommandOutput, result conditions.Result, shouldStop bool) (conditions.Result, bool) {
+	if len(a) == 0 {
+		return result, shouldStop
+	}
+	status, ok := finalHTTPStatus(output)
+	if !ok {
+		return result, shouldStop
+	}
+	action, ok := a[status]
+	if !ok {
+		return result, shouldStop
+	}
+
+	reason := fmt.Sprintf("HTTP %d (%s)", status, action)
+	switch action {
+	case HTTPStatusSuccess:
+		return conditions.Result{Success: true, Reason: reason}, true
+	case HTTPStatusFail:
+		return conditions.Result{Success: false, Reason: reason}, true
+	default:
+		return conditions.Result{Success: false, Reason: reason}, false
+	}
+}
@@ -0,0 +1,109 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutor_HTTPStatusActionRetriesUntilSuccess(t *testing.T) {
+	// Given a server answering 202 Accepted twice before 200 OK
+	actions, err := ParseHTTPStatusActions("202:retry,200:success")
+	require.NoError(t, err)
+	runner := &MockHTTPCommandRunner{responses: []MockHTTPResponse{
+		{ExitCode: 0, Stdout: "HTTP/1.1 202 Accepted\r\n\r\n{\"status\":\"pending\"}"},
+		{ExitCode: 0, Stdout: "HTTP/1.1 202 Accepted\r\n\r\n{\"status\":\"pending\"}"},
+		{ExitCode: 0, Stdout: "HTTP/1.1 200 OK\r\n\r\n{\"status\":\"done\"}"},
+	}}
+	executor := &Executor{
+		MaxAttempts:       5,
+		Runner:            runner,
+		HTTPStatusActions: actions,
+	}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"curl", "-i", "https://api.example.com/job"})
+
+	// Then 202 should be retried despite exit code 0 and 200 should succeed
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, 3, result.AttemptCount)
+	assert.Equal(t, "HTTP 200 (success)", result.Reason)
+}
+
+func TestExecutor_HTTPStatusActionRetryExhausted(t *testing.T) {
+	// Given a server that only ever answers 202
+	actions, err := ParseHTTPStatusActions("202:retry")
+	require.NoError(t, err)
+	runner := &MockHTTPCommandRunner{responses: []MockHTTPResponse{
+		{ExitCode: 0, Stdout: "HTTP/1.1 202 Accepted\r\n\r\n"},
+		{ExitCode: 0, Stdout: "HTTP/1.1 202 Accepted\r\n\r\n"},
+	}}
+	executor := &Executor{MaxAttempts: 2, Runner: runner, HTTPStatusActions: actions}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"curl", "-i", "https://api.example.com/job"})
+
+	// Then the run should fail naming the status
+	require.ErrorIs(t, err, ErrMaxAttempts)
+	assert.False(t, result.Success)
+	assert.Equal(t, "max retries reached (HTTP 202 (retry))", result.Reason)
+}
+
+func TestExecutor_HTTPStatusActionFailStops(t *testing.T) {
+	// Given 409 Conflict mapped to fail
+	actions, err := ParseHTTPStatusActions("409:fail")
+	require.NoError(t, err)
+	runner := &MockHTTPCommandRunner{responses: []MockHTTPResponse{
+		{ExitCode: 22, Stdout: "HTTP/1.1 409 Conflict\r\n\r\n"},
+		{ExitCode: 0, Stdout: "HTTP/1.1 200 OK\r\n\r\n"},
+	}}
+	executor := &Executor{MaxAttempts: 3, Runner: runner, HTTPStatusActions: actions}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"curl", "-i", "https://api.example.com/job"})
+
+	// Then it should stop after the first attempt without retrying
+	require.ErrorAs(t, err, new(*RunError))
+	assert.False(t, result.Success)
+	assert.Equal(t, 1, result.AttemptCount)
+	assert.Equal(t, "HTTP 409 (fail)", result.Reason)
+}
+
+func TestFinalHTTPStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		output CommandOutput
+		want   int
+		found  bool
+	}{
+		{name: "single response", output: CommandOutput{Stdout: "HTTP/2 200\r\n\r\nok"}, want: 200, found: true},
+		{name: "after redirect", output: CommandOutput{Stdout: "HTTP/1.1 301 Moved\r\nLocation: /x\r\n\r\nHTTP/1.1 202 Accepted\r\n\r\n"}, want: 202, found: true},
+		{name: "curl verbose stderr", output: CommandOutput{Stderr: "* Connected\n< HTTP/1.1 503 Service Unavailable\n< Retry-After: 5\n"}, want: 503, found: true},
+		{name: "no response", output: CommandOutput{Stdout: "connection refused"}, found: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, found := finalHTTPStatus(tt.output)
+			assert.Equal(t, tt.found, found)
+			assert.Equal(t, tt.want, status)
+		})
+	}
+}
+
+func TestParseHTTPStatusActions(t *testing.T) {
+	actions, err := ParseHTTPStatusActions("202:retry, 200:success,409:FAIL")
+	require.NoError(t, err)
+	assert.Equal(t, HTTPStatusActions{202: HTTPStatusRetry, 200: HTTPStatusSuccess, 409: HTTPStatusFail}, actions)
+
+	actions, err = ParseHTTPStatusActions("")
+	require.NoError(t, err)
+	assert.Nil(t, actions)
+
+	for _, invalid := range []string{"202", "abc:retry", "99:retry", "202:maybe", "202:retry,202:fail"} {
+		_, err := ParseHTTPStatusActions(invalid)
+		assert.Error(t, err, "spec %q", invalid)
+	}
+}
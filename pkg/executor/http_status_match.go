@@ -0,0 +1,65 @@
+package executor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shaneisley/patience/pkg/backoff"
+	"github.com/shaneisley/patience/pkg/conditions"
+)
+
+// HTTPStatusMatcher decides whether a failed attempt is retried from the HTTP
+// status of its response: matching statuses are retried and any other status
+// stops the run, whatever the response body says
+type HTTPStatusMatcher struct {
+	statuses []string // Codes and classes, see backoff.ValidStatusPattern
+}
+
+// ParseHTTPStatusMatcher parses a list of statuses and classes such as
+// "5..,408,429"; an empty spec returns nil, which matches nothing
+func ParseHTTPStatusMatcher(spec string) (*HTTPStatusMatcher, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, nil
+	}
+
+	var statuses []string
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if !backoff.ValidStatusPattern(entry) {
+			return nil, fmt.Errorf("invalid HTTP status %q: expected a status such as 503 or a class such as 5..", entry)
+		}
+		statuses = append(statuses, entry)
+	}
+	return &HTTPStatusMatcher{statuses: statuses}, nil
+}
+
+// Match reports whether status is one of the listed statuses or classes
+func (m *HTTPStatusMatcher) Match(status int) bool {
+	if m == nil {
+		return false
+	}
+	for _, pattern := range m.statuses {
+		if _, ok := backoff.MatchStatusPattern(pattern, status); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply decides whether a failed attempt with an HTTP response is retried,
+// returning the new result and whether retrying should stop. Successful
+// attempts, attempts without a response and a matched failure pattern are
+// left alone.
+func (m *HTTPStatusMatcher) Apply(output CommandOutput, result conditions.Result, shouldStop bool) (conditions.Result, bool) {
+	if m == nil || result.Success || result.Reason == failurePatternReason {
+		return result, shouldStop
+	}
+	status, ok := finalHTTPStatus(output)
+	if !ok {
+		return result, shouldStop
+	}
+	if m.Match(status) {
+		return result, false
+	}
+	return conditions.Result{Success: false, Reason: fmt.Sprintf("HTTP %d (not retryable)", status)}, true
+}
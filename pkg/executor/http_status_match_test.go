@@ -0,0 +1,92 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHTTPStatusMatcher(t *testing.T) {
+	// Given a spec with a class and a single status
+	matcher, err := ParseHTTPStatusMatcher("5.., 408")
+	require.NoError(t, err)
+
+	// When statuses are matched, Then the class and the status match
+	assert.True(t, matcher.Match(503))
+	assert.True(t, matcher.Match(500))
+	assert.True(t, matcher.Match(408))
+	assert.False(t, matcher.Match(404))
+	assert.False(t, matcher.Match(409))
+	assert.False(t, matcher.Match(200))
+}
+
+func TestParseHTTPStatusMatcher_Invalid(t *testing.T) {
+	for _, spec := range []string{"5xx", "600", "50", "5..,", "4.5.", "..3"} {
+		t.Run(spec, func(t *testing.T) {
+			// When an invalid spec is parsed, Then it is rejected
+			_, err := ParseHTTPStatusMatcher(spec)
+			assert.ErrorContains(t, err, "invalid HTTP status")
+		})
+	}
+
+	// And an empty spec disables matching
+	matcher, err := ParseHTTPStatusMatcher(" ")
+	require.NoError(t, err)
+	assert.Nil(t, matcher)
+	assert.False(t, matcher.Match(503))
+}
+
+func TestExecutor_RetryHTTPStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   string
+		attempts int
+		reason   string
+	}{
+		{"ServerErrorRetried", "503 Service Unavailable", 3, "max retries reached (exit code 22)"},
+		{"RequestTimeoutRetried", "408 Request Timeout", 3, "max retries reached (exit code 22)"},
+		{"NotFoundStops", "404 Not Found", 1, "HTTP 404 (not retryable)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given a server that keeps answering with one status, and only
+			// server errors and 408 retryable
+			matcher, err := ParseHTTPStatusMatcher("5..,408")
+			require.NoError(t, err)
+			response := MockHTTPResponse{ExitCode: 22, Stdout: "HTTP/1.1 " + tt.status + "\r\n\r\n"}
+			runner := &MockHTTPCommandRunner{responses: []MockHTTPResponse{response, response, response}}
+			executor := &Executor{MaxAttempts: 3, Runner: runner, RetryHTTPStatus: matcher}
+
+			// When Run() is called
+			result, err := executor.Run([]string{"curl", "-fi", "https://api.example.com/items/42"})
+
+			// Then only the listed statuses are retried
+			require.NotNil(t, result)
+			assert.Equal(t, result.Err(), err)
+			assert.False(t, result.Success)
+			assert.Equal(t, tt.attempts, result.AttemptCount)
+			assert.Equal(t, tt.reason, result.Reason)
+		})
+	}
+}
+
+func TestExecutor_RetryHTTPStatusLeavesSuccessAlone(t *testing.T) {
+	// Given a 503 retryable by status, followed by a success
+	matcher, err := ParseHTTPStatusMatcher("5..")
+	require.NoError(t, err)
+	runner := &MockHTTPCommandRunner{responses: []MockHTTPResponse{
+		{ExitCode: 22, Stdout: "HTTP/1.1 503 Service Unavailable\r\n\r\n{\"error\":\"unavailable\"}"},
+		{ExitCode: 0, Stdout: "HTTP/1.1 200 OK\r\n\r\n{}"},
+	}}
+	executor := &Executor{MaxAttempts: 3, Runner: runner, RetryHTTPStatus: matcher}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"curl", "-fi", "https://api.example.com/items/42"})
+
+	// Then the 503 is retried and the unmatched 200 still succeeds
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, 2, result.AttemptCount)
+}
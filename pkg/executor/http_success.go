@@ -0,0 +1,53 @@
+package executor
+
+import (
+	"fmt"
+
+	"github.com/shaneisley/patience/pkg/conditions"
+)
+
+// HTTPSuccessCriteria is a compound success condition on the final HTTP
+// response of an attempt: its status must match and the JSON fields of its
+// body must hold, such as "status 200 and body.status == ready". Either part
+// may be left out.
+type HTTPSuccessCriteria struct {
+	Status *HTTPStatusMatcher
+	Fields *conditions.JSONFieldMatcher
+}
+
+// ParseHTTPSuccessCriteria parses a status spec such as "200" or "2.." and
+// key=value field expectations; with neither it returns nil
+func ParseHTTPSuccessCriteria(statusSpec string, fields []string) (*HTTPSuccessCriteria, error) {
+	status, err := ParseHTTPStatusMatcher(statusSpec)
+	if err != nil {
+		return nil, err
+	}
+	matcher, err := conditions.NewJSONFieldMatcher(fields)
+	if err != nil {
+		return nil, err
+	}
+	if status == nil && matcher == nil {
+		return nil, nil
+	}
+	return &HTTPSuccessCriteria{Status: status, Fields: matcher}, nil
+}
+
+// Apply fails an otherwise successful attempt unless its final HTTP response
+// meets every criterion; failed attempts are left alone
+func (c *HTTPSuccessCriteria) Apply(output CommandOutput, result conditions.Result) conditions.Result {
+	if c == nil || !result.Success {
+		return result
+	}
+
+	status, ok := finalHTTPStatus(output)
+	if !ok {
+		return conditions.Result{Success: false, Reason: "no HTTP response"}
+	}
+	if c.Status != nil && !c.Status.Match(status) {
+		return conditions.Result{Success: false, Reason: fmt.Sprintf("HTTP %d not a success status", status)}
+	}
+	if ok, key := c.Fields.Match(finalHTTPBody(output)); !ok {
+		return conditions.Result{Success: false, Reason: fmt.Sprintf("HTTP %d json field mismatch (%s)", status, key)}
+	}
+	return conditions.Result{Success: true, Reason: fmt.Sprintf("HTTP %d met success criteria", status)}
+}
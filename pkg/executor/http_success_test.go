@@ -0,0 +1,79 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/shaneisley/patience/pkg/conditions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutor_HTTPSuccessCriteria(t *testing.T) {
+	// Given a deployment that answers 200 while pending, then 200 when ready
+	criteria, err := ParseHTTPSuccessCriteria("200", []string{"status=ready"})
+	require.NoError(t, err)
+	runner := &MockHTTPCommandRunner{responses: []MockHTTPResponse{
+		{ExitCode: 0, Stdout: "HTTP/1.1 200 OK\r\nContent-Type: application/json\r\n\r\n{\"status\":\"pending\"}"},
+		{ExitCode: 0, Stdout: "HTTP/1.1 200 OK\r\nContent-Type: application/json\r\n\r\n{\"status\":\"ready\"}"},
+	}}
+	executor := &Executor{MaxAttempts: 3, Runner: runner, HTTPSuccess: criteria}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"curl", "-si", "https://api.example.com/deploy/42"})
+
+	// Then 200-but-not-ready is retried and 200-and-ready succeeds
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, 2, result.AttemptCount)
+	assert.Equal(t, "HTTP 200 met success criteria", result.Reason)
+}
+
+func TestHTTPSuccessCriteria_Apply(t *testing.T) {
+	criteria, err := ParseHTTPSuccessCriteria("200", []string{"status=ready", "data.replicas=3"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		stdout  string
+		success bool
+		reason  string
+	}{
+		{"StatusAndBodyMatch", "HTTP/1.1 200 OK\r\n\r\n{\"status\":\"ready\",\"data\":{\"replicas\":3}}", true, "HTTP 200 met success criteria"},
+		{"BodyNotReady", "HTTP/1.1 200 OK\r\n\r\n{\"status\":\"pending\",\"data\":{\"replicas\":3}}", false, "HTTP 200 json field mismatch (status)"},
+		{"StatusMismatch", "HTTP/1.1 202 Accepted\r\n\r\n{\"status\":\"ready\",\"data\":{\"replicas\":3}}", false, "HTTP 202 not a success status"},
+		{"FinalResponseAfterRedirect", "HTTP/1.1 302 Found\r\nLocation: /ready\r\n\r\nHTTP/1.1 200 OK\r\n\r\n{\"status\":\"ready\",\"data\":{\"replicas\":3}}", true, "HTTP 200 met success criteria"},
+		{"NoResponse", "{\"status\":\"ready\"}", false, "no HTTP response"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given an attempt that succeeded by its exit code
+			output := CommandOutput{ExitCode: 0, Stdout: tt.stdout}
+
+			// When the criteria are applied
+			result := criteria.Apply(output, conditions.Result{Success: true, Reason: exitCodeZeroReason})
+
+			// Then both the status and the body decide success
+			assert.Equal(t, tt.success, result.Success)
+			assert.Equal(t, tt.reason, result.Reason)
+		})
+	}
+}
+
+func TestParseHTTPSuccessCriteria(t *testing.T) {
+	// An empty spec disables the criteria
+	criteria, err := ParseHTTPSuccessCriteria("", nil)
+	require.NoError(t, err)
+	assert.Nil(t, criteria)
+
+	// Either part may be given alone
+	criteria, err = ParseHTTPSuccessCriteria("2..", nil)
+	require.NoError(t, err)
+	assert.Nil(t, criteria.Fields)
+
+	// Invalid parts are rejected
+	_, err = ParseHTTPSuccessCriteria("2xx", nil)
+	assert.ErrorContains(t, err, "invalid HTTP status")
+	_, err = ParseHTTPSuccessCriteria("200", []string{"ready"})
+	assert.ErrorContains(t, err, "expected key=value")
+}
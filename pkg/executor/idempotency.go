@@ -0,0 +1,124 @@
+package executor
+
+import (
+	"crypto/rand"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// ValidateHeaderName checks that name can be used as an HTTP header name
+func ValidateHeaderName(name string) error {
+	if name == "" {
+		return fmt.Errorf("header name must not be empty")
+	}
+	for _, r := range name {
+		if r <= ' ' || r >= 0x7f || strings.ContainsRune(`:()<>@,;\"/[]?={}`, r) {
+			return fmt.Errorf("invalid header name %q", name)
+		}
+	}
+	return nil
+}
+
+// newRunID returns a random identifier for one run, formatted as a version 4 UUID
+func newRunID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to generate run ID: %w", err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+// isCurlCommand reports whether command runs curl
+func isCurlCommand(command []string) bool {
+	if len(command) == 0 {
+		return false
+	}
+	name := strings.TrimSuffix(filepath.Base(command[0]), ".exe")
+	return name == "curl"
+}
+
+// withIdempotencyKey returns command with an IdempotencyHeader carrying a key
+// generated once per run, so every attempt sends the same key and the server can
+// deduplicate retried requests. Only curl commands that don't already set the
+// header are changed. The header goes right after curl itself, so with Shell it
+// stays on curl rather than on a later command in a pipeline. With Steps, each
+// curl step gets the header with the same key.
+func (e *Executor) withIdempotencyKey(command []string) ([]string, error) {
+	if e.IdempotencyHeader == "" {
+		return command, nil
+	}
+
+	steps := [][]string{command}
+	if e.Steps {
+		var err error
+		if steps, err = SplitSteps(command); err != nil {
+			return nil, err
+		}
+	}
+
+	var header string
+	injected := make([]string, 0, len(command)+2*len(steps))
+	for i, step := range steps {
+		if i > 0 {
+			injected = append(injected, StepSeparator)
+		}
+		words, ok := e.needsIdempotencyKey(step)
+		if !ok {
+			injected = append(injected, step...)
+			continue
+		}
+		if header == "" {
+			key, err := newRunID()
+			if err != nil {
+				return nil, err
+			}
+			header = fmt.Sprintf("%s: %s", e.IdempotencyHeader, key)
+		}
+		words = append([]string{words[0], "-H", header}, words[1:]...)
+		if e.Shell && len(step) == 1 {
+			// Keep a script one argument, with the header one word in it
+			words = []string{strings.Join(append([]string{shellScript(words[:3])}, words[3:]...), " ")}
+		}
+		injected = append(injected, words...)
+	}
+	if header == "" {
+		return command, nil
+	}
+	return injected, nil
+}
+
+// needsIdempotencyKey reports whether command is a curl command without the
+// IdempotencyHeader, returning its words with curl first
+func (e *Executor) needsIdempotencyKey(command []string) ([]string, bool) {
+	words := command
+	if e.Shell && len(command) == 1 {
+		// A single argument is the script for sh -c, so look at its first word
+		words = shellWords(command[0])
+	}
+	if !isCurlCommand(words) {
+		return nil, false
+	}
+
+	prefix := strings.ToLower(e.IdempotencyHeader) + ":"
+	for _, arg := range words[1:] {
+		arg = strings.TrimLeft(strings.ToLower(arg), " \t'\"")
+		if strings.HasPrefix(arg, prefix) || (e.Shell && strings.Contains(arg, prefix)) {
+			return nil, false
+		}
+	}
+	return words, true
+}
+
+// shellWords splits the first word off a shell script so it can be inspected;
+// the rest of the script is kept as one argument
+func shellWords(script string) []string {
+	script = strings.TrimLeft(script, " \t")
+	end := strings.IndexAny(script, " \t\n")
+	if end < 0 {
+		return []string{script}
+	}
+	return []string{script[:end], strings.TrimLeft(script[end:], " \t")}
+}
@@ -0,0 +1,171 @@
+package executor
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// commandRecordingRunner records each command it is asked to run and fails it
+type commandRecordingRunner struct {
+	commands [][]string
+}
+
+func (r *commandRecordingRunner) Run(command []string) (int, error) {
+	output, err := r.RunWithOutput(command)
+	return output.ExitCode, err
+}
+
+func (r *commandRecordingRunner) RunWithContext(ctx context.Context, command []string) (int, error) {
+	return r.Run(command)
+}
+
+func (r *commandRecordingRunner) RunWithOutput(command []string) (CommandOutput, error) {
+	r.commands = append(r.commands, command)
+	return CommandOutput{ExitCode: 22}, nil
+}
+
+func (r *commandRecordingRunner) RunWithOutputAndContext(ctx context.Context, command []string) (CommandOutput, error) {
+	return r.RunWithOutput(command)
+}
+
+func TestExecutor_IdempotencyHeaderStableAcrossAttempts(t *testing.T) {
+	// Given a curl command retried twice with an idempotency header
+	runner := &commandRecordingRunner{}
+	executor := &Executor{
+		MaxAttempts:       2,
+		Runner:            runner,
+		IdempotencyHeader: "Idempotency-Key",
+	}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"curl", "-X", "POST", "https://api.example.com/payments"})
+	require.ErrorIs(t, err, ErrMaxAttempts)
+
+	// Then both attempts should carry the header with the same key
+	require.Len(t, runner.commands, 2)
+	first, second := runner.commands[0], runner.commands[1]
+	require.Len(t, first, 6)
+	assert.Equal(t, "curl", first[0])
+	assert.Equal(t, "-H", first[1])
+	assert.Regexp(t, regexp.MustCompile(`^Idempotency-Key: [0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), first[2])
+	assert.Equal(t, []string{"-X", "POST", "https://api.example.com/payments"}, first[3:])
+	assert.Equal(t, first, second)
+
+	// And metrics should report the command as given
+	assert.Equal(t, "curl -X POST https://api.example.com/payments", result.Metrics.Command)
+}
+
+func TestExecutor_IdempotencyHeaderNewKeyPerRun(t *testing.T) {
+	// Given two separate runs
+	runner := &commandRecordingRunner{}
+	executor := &Executor{MaxAttempts: 1, Runner: runner, IdempotencyHeader: "Idempotency-Key"}
+
+	// When each runs once
+	_, err := executor.Run([]string{"/usr/bin/curl", "https://api.example.com"})
+	require.ErrorIs(t, err, ErrMaxAttempts)
+	_, err = executor.Run([]string{"/usr/bin/curl", "https://api.example.com"})
+	require.ErrorIs(t, err, ErrMaxAttempts)
+
+	// Then each run should use its own key
+	assert.NotEqual(t, runner.commands[0][2], runner.commands[1][2])
+}
+
+func TestExecutor_IdempotencyHeaderInShellPipeline(t *testing.T) {
+	tests := []struct {
+		name    string
+		command []string
+	}{
+		{name: "separate arguments", command: []string{"curl", "-s", "https://api.example.com", "|", "jq", "."}},
+		{name: "one script", command: []string{"curl -s https://api.example.com | jq ."}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given a shell pipeline starting with curl
+			runner := &commandRecordingRunner{}
+			executor := &Executor{MaxAttempts: 1, Runner: runner, Shell: true, IdempotencyHeader: "Idempotency-Key"}
+
+			// When Run() is called
+			_, err := executor.Run(tt.command)
+			require.ErrorIs(t, err, ErrMaxAttempts)
+
+			// Then the header should be passed to curl, not to jq
+			require.Len(t, runner.commands[0], 3)
+			assert.Equal(t, "sh", runner.commands[0][0])
+			assert.Regexp(t, regexp.MustCompile(`^curl -H 'Idempotency-Key: [0-9a-f-]{36}' -s https://api\.example\.com \| jq \.$`), runner.commands[0][2])
+		})
+	}
+}
+
+func TestExecutor_IdempotencyHeaderShellScriptUnchanged(t *testing.T) {
+	// Given a shell script that doesn't start with curl, or already sets the header
+	for _, command := range []string{"echo  'a  b' | curl -d @- https://api.example.com", "curl -H 'Idempotency-Key: mine' https://api.example.com"} {
+		runner := &commandRecordingRunner{}
+		executor := &Executor{MaxAttempts: 1, Runner: runner, Shell: true, IdempotencyHeader: "Idempotency-Key"}
+
+		// When Run() is called
+		_, err := executor.Run([]string{command})
+		require.ErrorIs(t, err, ErrMaxAttempts)
+
+		// Then the script should run exactly as given
+		assert.Equal(t, []string{"sh", "-c", command}, runner.commands[0])
+	}
+}
+
+func TestExecutor_IdempotencyHeaderOnlyForCurl(t *testing.T) {
+	tests := []struct {
+		name    string
+		command []string
+	}{
+		{name: "other command", command: []string{"wget", "https://api.example.com"}},
+		{name: "header already set", command: []string{"curl", "-H", "idempotency-key: mine", "https://api.example.com"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := &commandRecordingRunner{}
+			executor := &Executor{MaxAttempts: 1, Runner: runner, IdempotencyHeader: "Idempotency-Key"}
+
+			_, err := executor.Run(tt.command)
+			require.ErrorIs(t, err, ErrMaxAttempts)
+
+			// The command should run unchanged
+			assert.Equal(t, tt.command, runner.commands[0])
+		})
+	}
+}
+
+func TestExecutor_IdempotencyHeaderPerStep(t *testing.T) {
+	// Given steps that post twice around another command
+	runner := &commandRecordingRunner{}
+	executor := &Executor{MaxAttempts: 1, Runner: runner, Steps: true, IdempotencyHeader: "Idempotency-Key"}
+
+	// When Run() is called
+	_, err := executor.Run([]string{"curl", "-d", "a", "https://api.example.com", "--and", "echo", "done", "--and", "curl", "-d", "b", "https://api.example.com"})
+	require.ErrorIs(t, err, ErrMaxAttempts)
+
+	// Then the first step should carry the header; the run stops at its failure
+	require.Len(t, runner.commands, 1)
+	assert.Equal(t, "-H", runner.commands[0][1])
+
+	// And each curl step should get the same key, leaving the others unchanged
+	steps, err := executor.withIdempotencyKey([]string{"curl", "a", "--and", "echo", "--and", "curl", "b"})
+	require.NoError(t, err)
+	require.Len(t, steps, 11)
+	assert.Equal(t, []string{"curl", "-H"}, steps[0:2])
+	assert.Equal(t, []string{"a", "--and", "echo", "--and", "curl", "-H"}, steps[3:9])
+	assert.Equal(t, steps[2], steps[9])
+	assert.Equal(t, "b", steps[10])
+}
+
+func TestValidateHeaderName(t *testing.T) {
+	assert.NoError(t, ValidateHeaderName("Idempotency-Key"))
+	assert.NoError(t, ValidateHeaderName("X-Request-ID"))
+	for _, invalid := range []string{"", "Bad Header", "Key:", "Key\n"} {
+		assert.Error(t, ValidateHeaderName(invalid), "name %q", invalid)
+	}
+}
@@ -0,0 +1,21 @@
+package executor
+
+import "time"
+
+// waitInitialDelay waits InitialDelay before the first attempt of a fresh run,
+// returning ReasonMaxTotalTime instead when the wait would pass the deadline
+func (e *Executor) waitInitialDelay(attempt int, deadline time.Time) (time.Duration, string) {
+	if e.InitialDelay <= 0 || attempt != 1 {
+		return 0, ""
+	}
+	clock := e.clock()
+	if !deadline.IsZero() && clock.Now().Add(e.InitialDelay).After(deadline) {
+		return 0, ReasonMaxTotalTime
+	}
+
+	if e.Reporter != nil {
+		e.Reporter.ShowWaiting(e.InitialDelay, "initial delay before the first attempt")
+	}
+	clock.Sleep(e.InitialDelay)
+	return e.InitialDelay, ""
+}
@@ -0,0 +1,73 @@
+package executor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shaneisley/patience/pkg/backoff"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// callCountingClock records how many commands had run at each sleep
+type callCountingClock struct {
+	fakeClock
+	runner       *MockHTTPCommandRunner
+	callsAtSleep []int
+}
+
+func (c *callCountingClock) Sleep(d time.Duration) {
+	c.callsAtSleep = append(c.callsAtSleep, c.runner.currentCall)
+	c.fakeClock.Sleep(d)
+}
+
+func TestExecutor_InitialDelay(t *testing.T) {
+	// Given an HTTP-aware run with a 10s initial delay and a 2s fallback, where
+	// the server first asks for 7s and then gives no hint
+	runner := &MockHTTPCommandRunner{responses: []MockHTTPResponse{
+		{ExitCode: 22, Stderr: "HTTP/1.1 429 Too Many Requests\r\nRetry-After: 7\r\n\r\n"},
+		{ExitCode: 22, Stderr: "HTTP/1.1 503 Service Unavailable\r\n\r\n"},
+		{ExitCode: 0, Stdout: "ok"},
+	}}
+	clock := &callCountingClock{fakeClock: fakeClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}, runner: runner}
+	executor := &Executor{
+		MaxAttempts:     3,
+		Runner:          runner,
+		BackoffStrategy: backoff.NewHTTPAware(backoff.NewFixed(2*time.Second), time.Minute),
+		InitialDelay:    10 * time.Second,
+		Clock:           clock,
+	}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"curl", "-f", "https://slow.example.com"})
+	require.NoError(t, err)
+	require.True(t, result.Success)
+
+	// Then the initial delay is waited before attempt 1, once
+	assert.Equal(t, []int{0, 1, 2}, clock.callsAtSleep)
+
+	// And later delays come from the server hint, then the fallback
+	assert.Equal(t, []time.Duration{10 * time.Second, 7 * time.Second, 2 * time.Second}, clock.sleeps)
+}
+
+func TestExecutor_InitialDelayPastDeadline(t *testing.T) {
+	// Given an initial delay longer than the total time allowed
+	runner := &MockHTTPCommandRunner{responses: []MockHTTPResponse{{ExitCode: 0}}}
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	executor := &Executor{
+		MaxAttempts:  3,
+		Runner:       runner,
+		InitialDelay: time.Minute,
+		MaxTotalTime: 30 * time.Second,
+		Clock:        clock,
+	}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"curl", "https://slow.example.com"})
+
+	// Then it stops without waiting or running the command
+	require.ErrorIs(t, err, ErrTimeout)
+	assert.Equal(t, ReasonMaxTotalTime, result.Reason)
+	assert.Equal(t, 0, runner.currentCall)
+	assert.Empty(t, clock.sleeps)
+}
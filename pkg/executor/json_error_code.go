@@ -0,0 +1,43 @@
+package executor
+
+import (
+	"fmt"
+
+	"github.com/shaneisley/patience/pkg/conditions"
+	"github.com/shaneisley/patience/pkg/discovery"
+)
+
+// jsonErrorCode returns the API error code in the attempt's JSON response
+// body, preferring stdout over stderr
+func jsonErrorCode(output CommandOutput) (string, bool) {
+	for _, stream := range []string{output.Stdout, output.Stderr} {
+		data, ok := discovery.ExtractJSONObject(stream)
+		if !ok {
+			continue
+		}
+		if code, ok := discovery.ExtractErrorCode(data); ok {
+			return code, true
+		}
+	}
+	return "", false
+}
+
+// applyJSONErrorCode keeps retrying a failed attempt whose JSON error code is
+// in RetryJSONErrorCodes and stops on any other code, returning the new result
+// and whether retrying should stop
+func (e *Executor) applyJSONErrorCode(output CommandOutput, result conditions.Result, shouldStop bool) (conditions.Result, bool) {
+	if len(e.RetryJSONErrorCodes) == 0 || result.Success || shouldStop {
+		return result, shouldStop
+	}
+	code, ok := jsonErrorCode(output)
+	if !ok {
+		return result, shouldStop
+	}
+
+	for _, retryable := range e.RetryJSONErrorCodes {
+		if code == retryable {
+			return conditions.Result{Success: false, Reason: fmt.Sprintf("error code %s (retry)", code)}, false
+		}
+	}
+	return conditions.Result{Success: false, Reason: fmt.Sprintf("error code %s (not retryable)", code)}, true
+}
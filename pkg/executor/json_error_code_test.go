@@ -0,0 +1,75 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// jsonErrorResponse is a failed curl call whose body carries code
+func jsonErrorResponse(code string) MockHTTPResponse {
+	return MockHTTPResponse{
+		ExitCode: 22,
+		Stdout:   "HTTP/1.1 503 Service Unavailable\r\n\r\n{\"error\":{\"code\":\"" + code + "\",\"message\":\"busy\"}}",
+	}
+}
+
+func TestExecutor_RetryJSONErrorCode(t *testing.T) {
+	tests := []struct {
+		name             string
+		code             string
+		expectedAttempts int
+		expectedReason   string
+	}{
+		{name: "retryable code keeps retrying", code: "TRY_AGAIN", expectedAttempts: 3, expectedReason: "max retries reached (error code TRY_AGAIN (retry))"},
+		{name: "other code stops", code: "INVALID_ARGUMENT", expectedAttempts: 1, expectedReason: "error code INVALID_ARGUMENT (not retryable)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given identical failing responses that differ only in their error code
+			runner := &MockHTTPCommandRunner{responses: []MockHTTPResponse{
+				jsonErrorResponse(tt.code), jsonErrorResponse(tt.code), jsonErrorResponse(tt.code),
+			}}
+			executor := &Executor{
+				MaxAttempts:         3,
+				Runner:              runner,
+				RetryJSONErrorCodes: []string{"TRY_AGAIN", "RATE_LIMITED"},
+			}
+
+			// When Run() is called
+			result, err := executor.Run([]string{"curl", "-si", "https://api.example.com"})
+
+			// Then only the retryable code should be retried
+			require.NotNil(t, result)
+			assert.Equal(t, result.Err(), err)
+			assert.False(t, result.Success)
+			assert.Equal(t, tt.expectedAttempts, result.AttemptCount)
+			assert.Equal(t, tt.expectedAttempts, runner.currentCall)
+			assert.Equal(t, tt.expectedReason, result.Reason)
+			assert.Equal(t, 22, result.ExitCode)
+		})
+	}
+}
+
+func TestExecutor_RetryJSONErrorCodeWithoutCode(t *testing.T) {
+	// Given failures without a JSON error code, then a success
+	runner := &MockHTTPCommandRunner{responses: []MockHTTPResponse{
+		{ExitCode: 7, Stderr: "curl: (7) Failed to connect"},
+		{ExitCode: 0, Stdout: `{"error":{"code":"INVALID_ARGUMENT"}}`},
+	}}
+	executor := &Executor{
+		MaxAttempts:         3,
+		Runner:              runner,
+		RetryJSONErrorCodes: []string{"TRY_AGAIN"},
+	}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"curl", "https://api.example.com"})
+
+	// Then the codeless failure should be retried as usual and success left alone
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, 2, result.AttemptCount)
+}
@@ -0,0 +1,102 @@
+package executor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// Policies for an invocation that finds another one holding the run lock
+const (
+	IfRunningWait = "wait" // Block until the other invocation finishes
+	IfRunningSkip = "skip" // Exit successfully without running
+	IfRunningFail = "fail" // Exit with an error without running
+)
+
+// errAlreadyRunning is returned when the run lock is held and the policy does not wait
+var errAlreadyRunning = errors.New("another invocation is already running")
+
+// runLock is an exclusive flock held for the duration of a run
+type runLock struct {
+	file *os.File
+}
+
+// LockPath returns the lock file used for a resource or command. Invocations with
+// the same resource ID (or, without one, the same command) share a lock.
+func LockPath(resourceID string, command []string) string {
+	key := resourceID
+	if key == "" {
+		key = strings.Join(command, "\x00")
+	}
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(os.TempDir(), "patience-"+hex.EncodeToString(sum[:8])+".lock")
+}
+
+// acquireRunLock takes the lock at path. With IfRunningWait it blocks until the
+// lock is free; otherwise it returns errAlreadyRunning if the lock is held.
+func acquireRunLock(path, policy string) (*runLock, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	how := syscall.LOCK_EX
+	if policy != IfRunningWait {
+		how |= syscall.LOCK_NB
+	}
+	if err := syscall.Flock(int(file.Fd()), how); err != nil {
+		file.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, errAlreadyRunning
+		}
+		return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+	return &runLock{file: file}, nil
+}
+
+// release unlocks and closes the lock file. The file is left in place so that
+// concurrent invocations always lock the same inode.
+func (l *runLock) release() {
+	if l == nil {
+		return
+	}
+	syscall.Flock(int(l.file.Fd()), syscall.LOCK_UN)
+	l.file.Close()
+}
+
+// lockRun applies the IfRunning policy before a run. It returns the held lock,
+// or a result to return immediately when the run should not proceed.
+func (e *Executor) lockRun(command []string) (*runLock, *Result, error) {
+	if e.IfRunning == "" {
+		return nil, nil, nil
+	}
+
+	path := e.LockFile
+	if path == "" {
+		path = LockPath(e.ResourceID, command)
+	}
+
+	lock, err := acquireRunLock(path, IfRunningSkip)
+	if errors.Is(err, errAlreadyRunning) && e.IfRunning == IfRunningWait {
+		e.warn("another invocation is running, waiting for it to finish")
+		lock, err = acquireRunLock(path, IfRunningWait)
+	}
+
+	switch {
+	case err == nil:
+		return lock, nil, nil
+	case !errors.Is(err, errAlreadyRunning):
+		return nil, nil, err
+	case e.IfRunning == IfRunningSkip:
+		e.warn("another invocation is already running, skipping")
+		return nil, &Result{Success: true, Reason: "skipped: " + errAlreadyRunning.Error()}, nil
+	default:
+		e.warn(errAlreadyRunning.Error())
+		return nil, &Result{Success: false, Reason: errAlreadyRunning.Error(), Kind: FailureOther}, nil
+	}
+}
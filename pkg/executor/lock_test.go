@@ -0,0 +1,131 @@
+package executor
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLockHelperProcess is not a real test: it is run as a separate process by
+// startLockHolder to hold a run lock, as a concurrent patience invocation would
+func TestLockHelperProcess(t *testing.T) {
+	path := os.Getenv("PATIENCE_LOCK_HELPER_PATH")
+	if path == "" {
+		return
+	}
+	hold, _ := time.ParseDuration(os.Getenv("PATIENCE_LOCK_HELPER_HOLD"))
+
+	lock, err := acquireRunLock(path, IfRunningFail)
+	if err != nil {
+		os.Exit(2)
+	}
+	os.Stdout.WriteString("locked\n")
+	time.Sleep(hold)
+	lock.release()
+	os.Exit(0)
+}
+
+// startLockHolder launches a process that holds the lock at path for hold,
+// returning once the lock is taken
+func startLockHolder(t *testing.T, path string, hold time.Duration) *exec.Cmd {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], "-test.run=^TestLockHelperProcess$")
+	cmd.Env = append(os.Environ(),
+		"PATIENCE_LOCK_HELPER_PATH="+path,
+		"PATIENCE_LOCK_HELPER_HOLD="+hold.String())
+	stdout, err := cmd.StdoutPipe()
+	require.NoError(t, err)
+	require.NoError(t, cmd.Start())
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+
+	line, err := bufio.NewReader(stdout).ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, "locked", strings.TrimSpace(line))
+	return cmd
+}
+
+func TestExecutor_IfRunning(t *testing.T) {
+	tests := []struct {
+		policy      string
+		hold        time.Duration
+		wantRun     bool
+		wantSuccess bool
+		wantReason  string
+		minElapsed  time.Duration
+	}{
+		{policy: IfRunningSkip, hold: 5 * time.Second, wantRun: false, wantSuccess: true, wantReason: "skipped: another invocation is already running"},
+		{policy: IfRunningFail, hold: 5 * time.Second, wantRun: false, wantSuccess: false, wantReason: "another invocation is already running"},
+		{policy: IfRunningWait, hold: 300 * time.Millisecond, wantRun: true, wantSuccess: true, minElapsed: 200 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			// Given another process holding the run lock
+			lockPath := filepath.Join(t.TempDir(), "run.lock")
+			startLockHolder(t, lockPath, tt.hold)
+
+			runner := &FakeCommandRunner{ExitCode: 0}
+			executor := &Executor{
+				MaxAttempts: 1,
+				Runner:      runner,
+				IfRunning:   tt.policy,
+				LockFile:    lockPath,
+			}
+
+			// When a second invocation runs
+			start := time.Now()
+			result, err := executor.Run([]string{"backup"})
+			elapsed := time.Since(start)
+
+			// Then it should skip, fail or wait according to the policy
+			require.NotNil(t, result)
+			assert.Equal(t, result.Err(), err)
+			assert.Equal(t, tt.wantSuccess, result.Success)
+			assert.Equal(t, tt.wantRun, runner.CallCount > 0)
+			if tt.wantReason != "" {
+				assert.Equal(t, tt.wantReason, result.Reason)
+			}
+			assert.GreaterOrEqual(t, elapsed, tt.minElapsed)
+			assert.Less(t, elapsed, 3*time.Second)
+		})
+	}
+}
+
+func TestExecutor_IfRunningReleasesLock(t *testing.T) {
+	// Given an executor that locks its runs
+	lockPath := filepath.Join(t.TempDir(), "run.lock")
+	executor := &Executor{
+		MaxAttempts: 1,
+		Runner:      &FakeCommandRunner{ExitCode: 0},
+		IfRunning:   IfRunningFail,
+		LockFile:    lockPath,
+	}
+
+	// When it runs twice in sequence
+	first, err := executor.Run([]string{"backup"})
+	require.NoError(t, err)
+	second, err := executor.Run([]string{"backup"})
+	require.NoError(t, err)
+
+	// Then the lock is released after each run and both succeed
+	assert.True(t, first.Success)
+	assert.True(t, second.Success)
+}
+
+func TestLockPath(t *testing.T) {
+	// Same command shares a lock; different commands and resource IDs do not
+	assert.Equal(t, LockPath("", []string{"sync", "a"}), LockPath("", []string{"sync", "a"}))
+	assert.NotEqual(t, LockPath("", []string{"sync", "a"}), LockPath("", []string{"sync a"}))
+	assert.NotEqual(t, LockPath("", []string{"sync"}), LockPath("db", []string{"sync"}))
+	assert.Equal(t, LockPath("db", []string{"sync"}), LockPath("db", []string{"migrate"}))
+}
@@ -0,0 +1,33 @@
+package executor
+
+import (
+	"fmt"
+
+	"github.com/shaneisley/patience/pkg/metrics"
+)
+
+// DispatchMetrics sends the run's metrics to the daemon through client. With
+// MetricsSync it waits until they are written to the daemon socket, bounded by
+// the client's timeout, records the outcome in result.MetricsDelivered and
+// warns on failure; otherwise the
+// send is fire-and-forget and MetricsDelivered stays false. With MetricsFile,
+// the metrics are appended to that file as well.
+func (e *Executor) DispatchMetrics(result *Result, client *metrics.Client) {
+	if result.Metrics == nil {
+		return
+	}
+	if e.MetricsFile != "" {
+		if err := metrics.AppendFile(e.MetricsFile, result.Metrics); err != nil {
+			e.warn(fmt.Sprintf("metrics not written: %v", err))
+		}
+	}
+	if !e.MetricsSync {
+		client.SendMetricsAsync(result.Metrics)
+		return
+	}
+	if err := client.SendMetrics(result.Metrics); err != nil {
+		e.warn(fmt.Sprintf("metrics not delivered: %v", err))
+		return
+	}
+	result.MetricsDelivered = true
+}
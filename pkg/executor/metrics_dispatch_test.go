@@ -0,0 +1,114 @@
+package executor
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shaneisley/patience/pkg/metrics"
+	"github.com/shaneisley/patience/pkg/ui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutor_DispatchMetricsSyncDelivered(t *testing.T) {
+	// Given a mock daemon listening on a Unix socket
+	socketPath := fmt.Sprintf("/tmp/patience-dispatch-%d.sock", time.Now().UnixNano())
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+	defer os.Remove(socketPath)
+	defer listener.Close()
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		data, _ := io.ReadAll(conn)
+		received <- data
+	}()
+
+	// And a finished run with metrics sending synchronously
+	executor := &Executor{MaxAttempts: 1, Runner: &FakeCommandRunner{ExitCode: 0}, MetricsSync: true}
+	result, err := executor.Run([]string{"deploy"})
+	require.NoError(t, err)
+
+	// When the metrics are dispatched
+	executor.DispatchMetrics(result, metrics.NewClient(socketPath))
+
+	// Then delivery is reported and the daemon got the run
+	assert.True(t, result.MetricsDelivered)
+	select {
+	case data := <-received:
+		assert.Contains(t, string(data), `"command":"deploy"`)
+	case <-time.After(time.Second):
+		t.Fatal("daemon received no metrics")
+	}
+}
+
+func TestExecutor_DispatchMetricsSyncSocketMissing(t *testing.T) {
+	// Given a synchronous executor and no daemon
+	var buf bytes.Buffer
+	executor := &Executor{
+		MaxAttempts: 1,
+		Runner:      &FakeCommandRunner{ExitCode: 0},
+		Reporter:    ui.NewReporter(&buf),
+		MetricsSync: true,
+	}
+	result, err := executor.Run([]string{"deploy"})
+	require.NoError(t, err)
+
+	// When the metrics are dispatched to a socket that does not exist
+	executor.DispatchMetrics(result, metrics.NewClient("/tmp/patience-no-such-daemon.sock"))
+
+	// Then delivery is reported false with a warning
+	assert.False(t, result.MetricsDelivered)
+	assert.Contains(t, buf.String(), "metrics not delivered")
+}
+
+func TestExecutor_DispatchMetricsAsyncByDefault(t *testing.T) {
+	// Given an executor sending metrics in the background
+	executor := &Executor{MaxAttempts: 1, Runner: &FakeCommandRunner{ExitCode: 0}}
+	result, err := executor.Run([]string{"deploy"})
+	require.NoError(t, err)
+
+	// When the metrics are dispatched
+	start := time.Now()
+	executor.DispatchMetrics(result, metrics.NewClient("/tmp/patience-no-such-daemon.sock"))
+
+	// Then it returns at once without claiming delivery
+	assert.Less(t, time.Since(start), 10*time.Millisecond)
+	assert.False(t, result.MetricsDelivered)
+}
+
+func TestExecutor_DispatchMetricsAppendsFile(t *testing.T) {
+	// Given an executor writing metrics to a file, and no daemon
+	path := filepath.Join(t.TempDir(), "runs.ndjson")
+	executor := &Executor{MaxAttempts: 1, Runner: &FakeCommandRunner{ExitCode: 0}, MetricsFile: path}
+
+	// When two runs dispatch their metrics
+	for i := 0; i < 2; i++ {
+		result, err := executor.Run([]string{"deploy"})
+		require.NoError(t, err)
+		executor.DispatchMetrics(result, metrics.NewClient("/tmp/patience-no-such-daemon.sock"))
+	}
+
+	// Then the file holds one replayable record per run
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	var commands []string
+	replayed, err := metrics.Replay(f, func(m *metrics.RunMetrics) error {
+		commands = append(commands, m.Command)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, metrics.ReplayResult{Sent: 2}, replayed)
+	assert.Equal(t, []string{"deploy", "deploy"}, commands)
+}
@@ -0,0 +1,94 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shaneisley/patience/pkg/daemon"
+)
+
+// DefaultMaxPlannedRegistrations is how many planned requests are registered
+// with the daemon ahead of the current attempt when MaxPlannedRegistrations
+// is unset
+const DefaultMaxPlannedRegistrations = 20
+
+// plannedRegistrationExpiry is how long after its scheduled time a planned
+// request stays registered with the daemon
+const plannedRegistrationExpiry = time.Hour
+
+// plannedRegistrations tracks a run's planned requests, one per retry offset,
+// so they are registered with the daemon a bounded batch at a time as the
+// attempts proceed instead of all upfront
+type plannedRegistrations struct {
+	resourceID string
+	baseTime   time.Time
+	offsets    []time.Duration
+	limit      int // Registrations kept ahead of the current attempt
+	next       int // Index of the first offset not yet registered
+}
+
+// newPlannedRegistrations plans one request per offset from baseTime; limit
+// <= 0 means DefaultMaxPlannedRegistrations
+func newPlannedRegistrations(resourceID string, baseTime time.Time, offsets []time.Duration, limit int) *plannedRegistrations {
+	if limit <= 0 {
+		limit = DefaultMaxPlannedRegistrations
+	}
+	return &plannedRegistrations{resourceID: resourceID, baseTime: baseTime, offsets: offsets, limit: limit}
+}
+
+// ahead returns the planned requests still to register so that the one for
+// attempt (1-based) and up to limit-1 after it are covered
+func (p *plannedRegistrations) ahead(attempt int) []*daemon.ScheduledRequest {
+	end := min(attempt-1+p.limit, len(p.offsets))
+	if end <= p.next {
+		return nil
+	}
+
+	requests := make([]*daemon.ScheduledRequest, 0, end-p.next)
+	for i := p.next; i < end; i++ {
+		scheduledAt := p.baseTime.Add(p.offsets[i])
+		requests = append(requests, &daemon.ScheduledRequest{
+			ID:          fmt.Sprintf("%s-%d-%d", p.resourceID, p.baseTime.Unix(), i),
+			ResourceID:  p.resourceID,
+			ScheduledAt: scheduledAt,
+			ExpiresAt:   scheduledAt.Add(plannedRegistrationExpiry),
+		})
+	}
+	return requests
+}
+
+// register sends the requests ahead of attempt to the daemon, advancing past
+// them only once the daemon has accepted them so a failed batch is retried
+// before the next attempt
+func (p *plannedRegistrations) register(ctx context.Context, client *daemon.DaemonClient, attempt int) error {
+	requests := p.ahead(attempt)
+	if len(requests) == 0 {
+		return nil
+	}
+	if err := client.RegisterScheduledRequests(ctx, requests); err != nil {
+		return err
+	}
+	p.next += len(requests)
+	return nil
+}
+
+// registerPlannedAhead tops up the daemon's registrations before an attempt.
+// With CoordinationRequired a failure is returned to abort the run, as it is
+// for the first batch; otherwise it only warns and the attempt runs on local
+// scheduling.
+func (e *Executor) registerPlannedAhead(planned *plannedRegistrations, attempt int) error {
+	if planned == nil || e.DaemonClient == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := planned.register(ctx, e.DaemonClient, attempt); err != nil {
+		if e.DaemonCoordination == CoordinationRequired {
+			return fmt.Errorf("failed to register requests with daemon: %w", err)
+		}
+		e.warn(fmt.Sprintf("Failed to register requests with daemon: %v", err))
+	}
+	return nil
+}
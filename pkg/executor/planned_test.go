@@ -0,0 +1,134 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/shaneisley/patience/pkg/backoff"
+	"github.com/shaneisley/patience/pkg/ui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// manyOffsets returns n retry offsets a minute apart
+func manyOffsets(n int) []time.Duration {
+	offsets := make([]time.Duration, n)
+	for i := range offsets {
+		offsets[i] = time.Duration(i) * time.Minute
+	}
+	return offsets
+}
+
+func TestPlannedRegistrations_BoundedBatches(t *testing.T) {
+	// Given a run planning 500 requests with the default limit
+	base := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	planned := newPlannedRegistrations("api", base, manyOffsets(500), 0)
+
+	// When the first batch is taken
+	first := planned.ahead(1)
+
+	// Then only the default number of near-term requests is planned
+	require.Len(t, first, DefaultMaxPlannedRegistrations)
+	assert.Equal(t, fmt.Sprintf("api-%d-0", base.Unix()), first[0].ID)
+	assert.Equal(t, base, first[0].ScheduledAt)
+	assert.Equal(t, base.Add(19*time.Minute), first[19].ScheduledAt)
+	assert.Equal(t, first[19].ScheduledAt.Add(time.Hour), first[19].ExpiresAt)
+
+	// And once registered, a later attempt only adds the requests it needs
+	planned.next += len(first)
+	assert.Empty(t, planned.ahead(1))
+	later := planned.ahead(5)
+	require.Len(t, later, 4)
+	assert.Equal(t, fmt.Sprintf("api-%d-20", base.Unix()), later[0].ID)
+	assert.Equal(t, fmt.Sprintf("api-%d-23", base.Unix()), later[3].ID)
+}
+
+func TestPlannedRegistrations_RegisterIncrementally(t *testing.T) {
+	// Given a daemon and a run with far more retry offsets than the limit
+	_, client := startCoordinationServer(t)
+	planned := newPlannedRegistrations("api", time.Now(), manyOffsets(300), 10)
+
+	for attempt := 1; attempt <= 300; attempt++ {
+		// When each attempt tops up the registrations
+		require.NoError(t, planned.register(context.Background(), client, attempt))
+
+		// Then the registrations stay bounded yet cover the next attempts
+		assert.Equal(t, min(attempt-1+10, 300), planned.next, "attempt %d", attempt)
+	}
+}
+
+func TestExecutor_DaemonRegistrationsWithHighAttemptCount(t *testing.T) {
+	// Given a coordinated Diophantine run allowed many attempts, failing until the last
+	_, client := startCoordinationServer(t)
+	runner := &FakeCommandRunner{ExitCode: 1}
+	executor := &Executor{
+		MaxAttempts:             50,
+		Runner:                  runner,
+		BackoffStrategy:         backoff.NewDiophantine(1000, time.Hour, manyOffsets(500)),
+		DaemonClient:            client,
+		MaxPlannedRegistrations: 5,
+		Clock:                   &fakeClock{now: time.Now()},
+	}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"deploy"})
+
+	// Then every attempt runs while registrations are topped up along the way
+	require.ErrorIs(t, err, ErrMaxAttempts)
+	assert.False(t, result.Success)
+	assert.Equal(t, 50, runner.CallCount)
+}
+
+func TestExecutor_RequiredCoordinationAbortsOnLaterRegistration(t *testing.T) {
+	tests := []struct {
+		name         string
+		coordination string
+		wantCalls    int
+	}{
+		{name: "required aborts", coordination: CoordinationRequired, wantCalls: 1},
+		{name: "best effort warns and continues", coordination: CoordinationBestEffort, wantCalls: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given a coordinated run whose daemon goes away during attempt 1
+			server, client := startCoordinationServer(t)
+			runner := &haltingRunner{onCall: func(call int) {
+				if call == 1 {
+					client.Close()
+					server.Stop()
+				}
+			}}
+			var buf bytes.Buffer
+			executor := &Executor{
+				MaxAttempts:             3,
+				Runner:                  runner,
+				Reporter:                ui.NewReporter(&buf),
+				BackoffStrategy:         backoff.NewDiophantine(1000, time.Hour, manyOffsets(10)),
+				DaemonClient:            client,
+				DaemonCoordination:      tt.coordination,
+				MaxPlannedRegistrations: 1,
+				Clock:                   &fakeClock{now: time.Now()},
+			}
+
+			// When Run() is called
+			result, err := executor.Run([]string{"deploy"})
+
+			// Then topping up the registrations fails before attempt 2
+			assert.Equal(t, tt.wantCalls, runner.calls)
+			require.NotNil(t, result)
+			assert.False(t, result.Success)
+			if tt.coordination == CoordinationRequired {
+				require.ErrorContains(t, err, "failed to register requests with daemon")
+				assert.Contains(t, result.Reason, "daemon coordination failed")
+				assert.Equal(t, 1, result.AttemptCount)
+			} else {
+				require.ErrorIs(t, err, ErrMaxAttempts)
+				assert.Contains(t, buf.String(), "Failed to register requests with daemon: ")
+			}
+		})
+	}
+}
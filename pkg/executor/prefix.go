@@ -0,0 +1,71 @@
+package executor
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// prefixWriter prepends a prefix to every line written through it. The prefix
+// is written when a line's first byte arrives, so partial lines such as
+// progress output still stream immediately. Once a write contains a NUL byte
+// the output is treated as binary and passed through untouched from then on.
+type prefixWriter struct {
+	w           io.Writer
+	prefix      []byte
+	atLineStart bool
+	binary      bool
+}
+
+// newPrefixWriter returns a writer that prefixes each line written to w
+func newPrefixWriter(w io.Writer, prefix string) *prefixWriter {
+	return &prefixWriter{w: w, prefix: []byte(prefix), atLineStart: true}
+}
+
+// Write writes p to the underlying writer with each line prefixed, reporting
+// len(p) on success so callers see only their own bytes
+func (pw *prefixWriter) Write(p []byte) (int, error) {
+	if pw.binary || bytes.IndexByte(p, 0) >= 0 {
+		pw.binary = true
+		return pw.w.Write(p)
+	}
+
+	n := len(p)
+	var out bytes.Buffer
+	for len(p) > 0 {
+		if pw.atLineStart {
+			out.Write(pw.prefix)
+			pw.atLineStart = false
+		}
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			out.Write(p)
+			break
+		}
+		out.Write(p[:i+1])
+		p = p[i+1:]
+		pw.atLineStart = true
+	}
+
+	if _, err := pw.w.Write(out.Bytes()); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// attemptRunner returns the runner for an attempt, wrapping the live output of
+// a SystemCommandRunner with an "[attempt N] " line prefix when PrefixOutput is
+// set. Captured output, which conditions are checked against, is never prefixed.
+func (e *Executor) attemptRunner(attempt int) CommandRunner {
+	system, ok := e.Runner.(*SystemCommandRunner)
+	if !e.PrefixOutput || !ok {
+		return e.Runner
+	}
+
+	stdout, stderr := system.outputs()
+	prefix := fmt.Sprintf("[attempt %d] ", attempt)
+	prefixed := *system
+	prefixed.Stdout = newPrefixWriter(stdout, prefix)
+	prefixed.Stderr = newPrefixWriter(stderr, prefix)
+	return &prefixed
+}
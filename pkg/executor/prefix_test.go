@@ -0,0 +1,62 @@
+package executor
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutor_PrefixOutputMarksEachLineWithAttempt(t *testing.T) {
+	// Given a command that prints two lines on each stream and fails
+	var stdout, stderr bytes.Buffer
+	executor := NewExecutor(2)
+	executor.Runner = &SystemCommandRunner{Stdout: &stdout, Stderr: &stderr}
+	executor.PrefixOutput = true
+
+	// When Run() is called
+	result, err := executor.Run([]string{"sh", "-c", "echo one; echo two; echo oops >&2; printf partial; exit 1"})
+
+	// Then every line of live output should carry its attempt number
+	require.ErrorIs(t, err, ErrMaxAttempts)
+	assert.Equal(t, 2, result.AttemptCount)
+	assert.Equal(t,
+		"[attempt 1] one\n[attempt 1] two\n[attempt 1] partial"+
+			"[attempt 2] one\n[attempt 2] two\n[attempt 2] partial", stdout.String())
+	assert.Equal(t, "[attempt 1] oops\n[attempt 2] oops\n", stderr.String())
+}
+
+func TestExecutor_PrefixOutputLeavesCapturedOutputAlone(t *testing.T) {
+	// Given an executor prefixing live output
+	var stdout bytes.Buffer
+	executor := NewExecutor(1)
+	executor.Runner = &SystemCommandRunner{Stdout: &stdout, Stderr: &bytes.Buffer{}}
+	executor.PrefixOutput = true
+
+	// When an attempt runs
+	output, err, _ := executor.executeAttempt(1, []string{"echo", "ready"})
+
+	// Then only the live output should be prefixed
+	require.NoError(t, err)
+	assert.Equal(t, "ready\n", output.Stdout)
+	assert.Equal(t, "[attempt 1] ready\n", stdout.String())
+}
+
+func TestPrefixWriter_PassesBinaryThrough(t *testing.T) {
+	// Given a prefix writer
+	var out bytes.Buffer
+	pw := newPrefixWriter(&out, "[attempt 1] ")
+
+	// When text is followed by binary data
+	_, err := pw.Write([]byte("header\n"))
+	require.NoError(t, err)
+	n, err := pw.Write([]byte("\x00\x01\nraw\n"))
+	require.NoError(t, err)
+	_, err = pw.Write([]byte("more\n"))
+	require.NoError(t, err)
+
+	// Then the binary data and everything after it should be written unchanged
+	assert.Equal(t, 7, n)
+	assert.Equal(t, "[attempt 1] header\n\x00\x01\nraw\nmore\n", out.String())
+}
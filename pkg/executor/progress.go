@@ -0,0 +1,16 @@
+package executor
+
+import "github.com/shaneisley/patience/pkg/backoff"
+
+// backoffAttempt returns the attempt number passed to the backoff strategy after
+// a failed attempt, counted from backoff.AttemptBaseOne. When ProgressPattern matches the attempt's output the
+// strategy is reset and counting restarts, so the next delay is the base delay
+// again; start is the attempt after which counting last restarted.
+func (e *Executor) backoffAttempt(attempt int, output CommandOutput, start *int) int {
+	if e.ProgressPattern != nil &&
+		(e.ProgressPattern.MatchString(output.Stdout) || e.ProgressPattern.MatchString(output.Stderr)) {
+		backoff.Reset(e.BackoffStrategy)
+		*start = attempt - 1
+	}
+	return attempt - *start
+}
@@ -0,0 +1,59 @@
+package executor
+
+import (
+	"os"
+	"strconv"
+)
+
+// Progress states written to a ProgressFIFO
+const (
+	ProgressRunning   = "running"
+	ProgressFailed    = "failed"
+	ProgressSucceeded = "succeeded"
+)
+
+// ProgressFIFO writes one compact line per attempt event to a named pipe, such
+// as "attempt=2/5 state=running", for dashboards that would rather not parse
+// the reporter's output. Unlimited runs omit the "/max". The pipe is opened
+// without blocking: records are dropped while no reader is attached or the
+// reader falls behind, so a dashboard never holds up the run.
+type ProgressFIFO struct {
+	path string
+	file *os.File
+}
+
+// Record writes a progress line, dropping it when no reader can take it
+func (p *ProgressFIFO) Record(attempt, maxAttempts int, state string) {
+	if p.file == nil && !p.open() {
+		return
+	}
+
+	line := "attempt=" + strconv.Itoa(attempt)
+	if maxAttempts > 0 {
+		line += "/" + strconv.Itoa(maxAttempts)
+	}
+	line += " state=" + state + "\n"
+
+	// A full pipe drops this record; a departed reader closes the pipe so the
+	// next record looks for a new one
+	if _, err := p.file.WriteString(line); err != nil && !pipeFull(err) {
+		p.Close()
+	}
+}
+
+// Close closes the pipe; the next record reopens it
+func (p *ProgressFIFO) Close() error {
+	if p.file == nil {
+		return nil
+	}
+	err := p.file.Close()
+	p.file = nil
+	return err
+}
+
+// recordProgress writes an attempt event to ProgressFIFO when one is set
+func (e *Executor) recordProgress(attempt, maxAttempts int, state string) {
+	if e.ProgressFIFO != nil {
+		e.ProgressFIFO.Record(attempt, reportedLimit(maxAttempts), state)
+	}
+}
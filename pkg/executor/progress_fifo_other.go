@@ -0,0 +1,25 @@
+//go:build !unix
+
+package executor
+
+import "fmt"
+
+// ValidateProgressFIFO fails: named pipes are not supported on this platform
+func ValidateProgressFIFO(path string) error {
+	return fmt.Errorf("progress fifo %s: named pipes are not supported on this platform", path)
+}
+
+// NewProgressFIFO fails: named pipes are not supported on this platform
+func NewProgressFIFO(path string) (*ProgressFIFO, error) {
+	return nil, ValidateProgressFIFO(path)
+}
+
+// open never succeeds on this platform
+func (p *ProgressFIFO) open() bool {
+	return false
+}
+
+// pipeFull never applies on this platform
+func pipeFull(err error) bool {
+	return false
+}
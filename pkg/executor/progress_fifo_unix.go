@@ -0,0 +1,51 @@
+//go:build unix
+
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// ValidateProgressFIFO checks that path is a named pipe or doesn't exist yet
+func ValidateProgressFIFO(path string) error {
+	info, err := os.Stat(path)
+	switch {
+	case err == nil && info.Mode()&fs.ModeNamedPipe == 0:
+		return fmt.Errorf("progress fifo %s exists and is not a named pipe", path)
+	case err != nil && !errors.Is(err, fs.ErrNotExist):
+		return fmt.Errorf("progress fifo: %w", err)
+	}
+	return nil
+}
+
+// NewProgressFIFO returns a writer for the named pipe at path, creating the
+// pipe if it doesn't exist. It fails when path exists and is not a named pipe.
+func NewProgressFIFO(path string) (*ProgressFIFO, error) {
+	if err := ValidateProgressFIFO(path); err != nil {
+		return nil, err
+	}
+	if err := syscall.Mkfifo(path, 0o600); err != nil && !errors.Is(err, fs.ErrExist) {
+		return nil, fmt.Errorf("progress fifo: %w", err)
+	}
+	return &ProgressFIFO{path: path}, nil
+}
+
+// open opens the pipe without blocking, reporting whether a reader is attached
+func (p *ProgressFIFO) open() bool {
+	// Opening a pipe for writing fails with ENXIO while nobody reads it
+	file, err := os.OpenFile(p.path, os.O_WRONLY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		return false
+	}
+	p.file = file
+	return true
+}
+
+// pipeFull reports whether a write failed because the reader fell behind
+func pipeFull(err error) bool {
+	return errors.Is(err, syscall.EAGAIN)
+}
@@ -0,0 +1,82 @@
+//go:build unix
+
+package executor
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgressFIFO_RecordsAttempts(t *testing.T) {
+	// Given a named pipe with a reader attached
+	path := filepath.Join(t.TempDir(), "progress")
+	require.NoError(t, syscall.Mkfifo(path, 0o600))
+	reader, err := os.OpenFile(path, os.O_RDONLY|syscall.O_NONBLOCK, 0)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	fifo, err := NewProgressFIFO(path)
+	require.NoError(t, err)
+	executor := &Executor{
+		MaxAttempts: 3,
+		Runner: &MockHTTPCommandRunner{responses: []MockHTTPResponse{
+			{ExitCode: 1},
+			{ExitCode: 0},
+		}},
+		ProgressFIFO: fifo,
+	}
+
+	// When a run fails once and then succeeds
+	result, err := executor.Run([]string{"flaky"})
+	require.NoError(t, err)
+	require.True(t, result.Success)
+	require.NoError(t, fifo.Close())
+
+	// Then the reader sees a compact record for each attempt event
+	records, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"attempt=1/3 state=running",
+		"attempt=1/3 state=failed",
+		"attempt=2/3 state=running",
+		"attempt=2/3 state=succeeded",
+	}, strings.Split(strings.TrimSpace(string(records)), "\n"))
+}
+
+func TestProgressFIFO_NoReader(t *testing.T) {
+	// Given a pipe path that does not exist yet and no reader
+	path := filepath.Join(t.TempDir(), "progress")
+
+	// When the writer is created
+	fifo, err := NewProgressFIFO(path)
+	require.NoError(t, err)
+	executor := &Executor{MaxAttempts: 2, Runner: &FakeCommandRunner{ExitCode: 1}, ProgressFIFO: fifo}
+
+	// Then the pipe is created up front
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.NotZero(t, info.Mode()&fs.ModeNamedPipe)
+
+	// And the run completes without blocking
+	result, err := executor.Run([]string{"flaky"})
+	require.ErrorIs(t, err, ErrMaxAttempts)
+	assert.Equal(t, 2, result.AttemptCount)
+}
+
+func TestNewProgressFIFO_RejectsRegularFile(t *testing.T) {
+	// Given a regular file at the path
+	path := filepath.Join(t.TempDir(), "progress.log")
+	require.NoError(t, os.WriteFile(path, nil, 0o600))
+
+	// Then it is not used as a pipe
+	_, err := NewProgressFIFO(path)
+	assert.ErrorContains(t, err, "is not a named pipe")
+}
@@ -0,0 +1,62 @@
+package executor
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/shaneisley/patience/pkg/backoff"
+	"github.com/shaneisley/patience/pkg/ui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutor_ProgressResetsExponentialBackoff(t *testing.T) {
+	// Given an upload that fails four times, reporting progress on the third
+	runner := &MockHTTPCommandRunner{responses: []MockHTTPResponse{
+		{ExitCode: 1, Stderr: "connection reset"},
+		{ExitCode: 1, Stderr: "connection reset"},
+		{ExitCode: 1, Stdout: "uploaded chunk 3/10", Stderr: "connection reset"},
+		{ExitCode: 1, Stderr: "connection reset"},
+		{ExitCode: 0, Stdout: "uploaded chunk 10/10"},
+	}}
+	var events bytes.Buffer
+	reporter := ui.NewReporter(io.Discard)
+	reporter.SetJSONOutput(ui.OutputJSONEvents, &events)
+	executor := &Executor{
+		MaxAttempts:     5,
+		Runner:          runner,
+		BackoffStrategy: backoff.NewExponential(time.Millisecond, 2, 0),
+		Reporter:        reporter,
+		ProgressPattern: regexp.MustCompile(`uploaded chunk \d+`),
+	}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"upload"})
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+
+	// Then the delay should grow, drop back to the base after progress, and grow again
+	var delays []float64
+	scanner := bufio.NewScanner(&events)
+	for scanner.Scan() {
+		var event ui.Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		if event.Event == "attempt_failure" && event.NextDelaySeconds != nil {
+			delays = append(delays, *event.NextDelaySeconds)
+		}
+	}
+	assert.Equal(t, []float64{0.001, 0.002, 0.001, 0.002}, delays)
+}
+
+func TestExecutor_BackoffAttemptWithoutProgressPattern(t *testing.T) {
+	// Without a progress pattern the strategy sees the real attempt number
+	executor := &Executor{}
+	start := 0
+	assert.Equal(t, 3, executor.backoffAttempt(3, CommandOutput{Stdout: "uploaded chunk 1"}, &start))
+	assert.Equal(t, 0, start)
+}
@@ -0,0 +1,107 @@
+package executor
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shaneisley/patience/pkg/discovery"
+	"github.com/shaneisley/patience/pkg/metrics"
+)
+
+// rateLimitTracker keeps the most informative rate limit discovered in the
+// output of a run's attempts
+type rateLimitTracker struct {
+	parse func(stdout, stderr string, exitCode int, command []string) *discovery.DiscoveryResult
+	best  *discovery.DiscoveryResult
+
+	// enhanced is set when a discovery cache is in use; its results are the
+	// ones the cache stores
+	enhanced *discovery.EnhancedParser
+}
+
+func newRateLimitTracker(cache *discovery.Cache) *rateLimitTracker {
+	if cache == nil {
+		return &rateLimitTracker{parse: discovery.NewParser().ParseFromCommandOutput}
+	}
+	enhanced := discovery.NewEnhancedParser()
+	enhanced.UseCache(cache)
+	return &rateLimitTracker{parse: enhanced.ParseFromCommandOutputEnhanced, enhanced: enhanced}
+}
+
+// observe parses an attempt's output, keeping the result when it is at least as
+// confident as the best so far (so later attempts win ties with fresher values)
+func (t *rateLimitTracker) observe(output CommandOutput, command []string) {
+	result := t.parse(output.Stdout, output.Stderr, output.ExitCode, command)
+	if result == nil || !result.Found || result.Info == nil {
+		return
+	}
+	if t.best == nil || result.Confidence >= t.best.Confidence {
+		t.best = result
+	}
+}
+
+// knownReset returns how long until a cached rate limit for command's resource
+// resets, when an earlier run found it used up; 0 otherwise
+func (t *rateLimitTracker) knownReset(command []string, now time.Time) time.Duration {
+	if t.enhanced == nil {
+		return 0
+	}
+	info := t.enhanced.KnownRateLimit(command)
+	if info == nil || info.Limit <= 0 || info.Remaining > 0 || !info.ResetTime.After(now) {
+		return 0
+	}
+	return info.ResetTime.Sub(now)
+}
+
+// summary returns the best discovered rate limit, or nil if none was found
+func (t *rateLimitTracker) summary() *metrics.RateLimitSummary {
+	if t.best == nil {
+		return nil
+	}
+
+	info := t.best.Info
+	summary := &metrics.RateLimitSummary{
+		Limit:      info.Limit,
+		Remaining:  info.Remaining,
+		Source:     string(t.best.Source),
+		Confidence: t.best.Confidence,
+	}
+	if !info.ResetTime.IsZero() {
+		summary.ResetUnix = info.ResetTime.Unix()
+	}
+	return summary
+}
+
+// waitForKnownReset holds the first attempt until a rate limit that the
+// discovery cache says is used up resets. It returns the time waited, or
+// ReasonMaxTotalTime without waiting when the reset is past the deadline.
+func (e *Executor) waitForKnownReset(t *rateLimitTracker, command []string, deadline time.Time) (time.Duration, string) {
+	if t == nil {
+		return 0, ""
+	}
+	clock := e.clock()
+	now := clock.Now()
+	wait := t.knownReset(command, now)
+	if wait <= 0 {
+		return 0, ""
+	}
+	if !deadline.IsZero() && now.Add(wait).After(deadline) {
+		return 0, ReasonMaxTotalTime
+	}
+
+	if e.Reporter != nil {
+		e.Reporter.ShowWaiting(wait, "a cached rate limit is used up until it resets")
+	}
+	clock.Sleep(wait)
+	return wait, ""
+}
+
+// saveDiscoveryCache writes what the run discovered back to the cache file
+func (e *Executor) saveDiscoveryCache() {
+	if e.DiscoveryCache == nil || !e.DiscoverRateLimits {
+		return
+	}
+	if err := e.DiscoveryCache.Save(); err != nil {
+		e.warn(fmt.Sprintf("discovery cache not saved: %v", err))
+	}
+}
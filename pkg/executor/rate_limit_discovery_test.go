@@ -0,0 +1,122 @@
+package executor
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shaneisley/patience/pkg/discovery"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// exhaustRateLimit runs a command whose first attempt uses up a rate limit
+// resetting at reset, saving what was discovered to the cache file at path
+func exhaustRateLimit(t *testing.T, path string, command []string, reset time.Time) {
+	t.Helper()
+	cache, err := discovery.LoadCache(path, time.Hour)
+	require.NoError(t, err)
+	executor := &Executor{
+		MaxAttempts: 2,
+		Runner: &MockHTTPCommandRunner{
+			responses: []MockHTTPResponse{
+				{ExitCode: 22, Stderr: fmt.Sprintf("HTTP/1.1 429 Too Many Requests\r\nX-RateLimit-Limit: 100\r\nX-RateLimit-Remaining: 0\r\nX-RateLimit-Reset: %d\r\n\r\n", reset.Unix())},
+				{ExitCode: 0, Stdout: "ok"},
+			},
+		},
+		DiscoverRateLimits: true,
+		DiscoveryCache:     cache,
+	}
+	result, err := executor.Run(command)
+	require.NoError(t, err)
+	require.True(t, result.Success)
+}
+
+func TestExecutor_DiscoveryCacheDelaysFirstAttempt(t *testing.T) {
+	// Given an earlier run that used up the rate limit and cached it
+	path := filepath.Join(t.TempDir(), "discovery.json")
+	command := []string{"curl", "-i", "https://api.example.com/items"}
+	start := time.Now().Truncate(time.Second)
+	reset := start.Add(10 * time.Minute)
+	exhaustRateLimit(t, path, command, reset)
+
+	// When a new run loads the cache
+	cache, err := discovery.LoadCache(path, time.Hour)
+	require.NoError(t, err)
+	clock := &fakeClock{now: start}
+	runner := &MockHTTPCommandRunner{responses: []MockHTTPResponse{{ExitCode: 0, Stdout: "ok"}}}
+	executor := &Executor{
+		MaxAttempts:        3,
+		Runner:             runner,
+		DiscoverRateLimits: true,
+		DiscoveryCache:     cache,
+		Clock:              clock,
+	}
+	result, err := executor.Run(command)
+
+	// Then its first attempt should wait for the known reset
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, []time.Duration{10 * time.Minute}, clock.sleeps)
+	assert.Equal(t, 10*time.Minute, result.TotalDelay)
+}
+
+func TestExecutor_DiscoveryCacheResetPastDeadline(t *testing.T) {
+	// Given a cached rate limit that resets after the total time limit
+	path := filepath.Join(t.TempDir(), "discovery.json")
+	command := []string{"curl", "-i", "https://api.example.com/items"}
+	start := time.Now().Truncate(time.Second)
+	exhaustRateLimit(t, path, command, start.Add(10*time.Minute))
+
+	cache, err := discovery.LoadCache(path, time.Hour)
+	require.NoError(t, err)
+	clock := &fakeClock{now: start}
+	runner := &MockHTTPCommandRunner{responses: []MockHTTPResponse{{ExitCode: 0, Stdout: "ok"}}}
+	executor := &Executor{
+		MaxAttempts:        3,
+		Runner:             runner,
+		DiscoverRateLimits: true,
+		DiscoveryCache:     cache,
+		MaxTotalTime:       time.Minute,
+		Clock:              clock,
+	}
+
+	// When Run() is called
+	result, err := executor.Run(command)
+
+	// Then it should stop without waiting or running an attempt
+	require.ErrorIs(t, err, ErrTimeout)
+	assert.False(t, result.Success)
+	assert.Equal(t, ReasonMaxTotalTime, result.Reason)
+	assert.Equal(t, 0, result.AttemptCount)
+	assert.Empty(t, clock.sleeps)
+}
+
+func TestExecutor_DiscoveryCacheIgnoresRemainingRequests(t *testing.T) {
+	// Given a cached rate limit with requests remaining
+	path := filepath.Join(t.TempDir(), "discovery.json")
+	command := []string{"curl", "-i", "https://api.example.com/items"}
+	start := time.Now().Truncate(time.Second)
+	cache := discovery.NewCache(path, time.Hour)
+	parser := discovery.NewEnhancedParser()
+	parser.UseCache(cache)
+	parser.ParseFromCommandOutputEnhanced(fmt.Sprintf("HTTP/1.1 200 OK\r\nX-RateLimit-Limit: 100\r\nX-RateLimit-Remaining: 40\r\nX-RateLimit-Reset: %d\r\n\r\n", start.Add(10*time.Minute).Unix()), "", 0, command)
+
+	clock := &fakeClock{now: start}
+	executor := &Executor{
+		MaxAttempts:        1,
+		Runner:             &MockHTTPCommandRunner{responses: []MockHTTPResponse{{ExitCode: 0, Stdout: "ok"}}},
+		DiscoverRateLimits: true,
+		DiscoveryCache:     cache,
+		Clock:              clock,
+	}
+
+	// When Run() is called
+	result, err := executor.Run(command)
+
+	// Then the first attempt should start right away
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Empty(t, clock.sleeps)
+}
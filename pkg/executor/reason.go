@@ -0,0 +1,81 @@
+package executor
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// ReasonData is what a ReasonTemplate can refer to when rendering the reason
+// for a failed attempt, such as "{{if .HTTPStatus}}HTTP {{.HTTPStatus}}{{else}}{{.Reason}}{{end}}"
+type ReasonData struct {
+	Attempt    int    // 1-based attempt number
+	ExitCode   int    // the command's exit code
+	Reason     string // the default reason, such as "exit code 22"
+	Pattern    string // the success, failure or fatal pattern that decided the attempt, if any
+	HTTPStatus int    // the final HTTP status in the output, or 0 when there is none
+	TimedOut   bool   // whether the attempt hit the per-attempt timeout
+}
+
+// ParseReasonTemplate parses a reason template, rendering it once against
+// sample data so a reference to an unknown field fails here rather than
+// during a run
+func ParseReasonTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("reason").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid reason template: %w", err)
+	}
+	if err := tmpl.Execute(&strings.Builder{}, ReasonData{}); err != nil {
+		return nil, fmt.Errorf("invalid reason template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// attemptReason returns the reason reported for a failed attempt: the default
+// reason, or ReasonTemplate rendered with the attempt's details when one is
+// set. A template that fails to render falls back to the default.
+func (e *Executor) attemptReason(attempt int, output CommandOutput, reason string, timedOut bool) string {
+	if timedOut {
+		reason = fmt.Sprintf("timeout: %s", e.Timeout)
+	}
+	return e.renderReason(attempt, output, reason, timedOut)
+}
+
+// renderReason renders ReasonTemplate for an attempt whose default reason is
+// reason, returning reason itself when no template is set
+func (e *Executor) renderReason(attempt int, output CommandOutput, reason string, timedOut bool) string {
+	if e.ReasonTemplate == nil {
+		return reason
+	}
+
+	data := ReasonData{
+		Attempt:  attempt,
+		ExitCode: output.ExitCode,
+		Reason:   reason,
+		Pattern:  e.decidingPattern(reason),
+		TimedOut: timedOut,
+	}
+	if status, ok := finalHTTPStatus(output); ok {
+		data.HTTPStatus = status
+	}
+
+	var rendered strings.Builder
+	if err := e.ReasonTemplate.Execute(&rendered, data); err != nil {
+		e.warn(fmt.Sprintf("failed to render reason template: %v", err))
+		return reason
+	}
+	return rendered.String()
+}
+
+// decidingPattern returns the pattern behind a pattern-matched reason
+func (e *Executor) decidingPattern(reason string) string {
+	switch {
+	case reason == ReasonFatalPattern && e.FatalPattern != nil:
+		return e.FatalPattern.String()
+	case reason == failurePatternReason && e.Conditions != nil:
+		return e.Conditions.FailurePattern()
+	case reason == successPatternReason && e.Conditions != nil:
+		return e.Conditions.SuccessPattern()
+	}
+	return ""
+}
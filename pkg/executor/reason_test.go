@@ -0,0 +1,97 @@
+package executor
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/shaneisley/patience/pkg/conditions"
+	"github.com/shaneisley/patience/pkg/ui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReasonTemplate_ExitCodeFailure(t *testing.T) {
+	// Given a reason template and a command that always exits 3
+	tmpl, err := ParseReasonTemplate("attempt {{.Attempt}} exited {{.ExitCode}} ({{.Reason}})")
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	executor := &Executor{
+		MaxAttempts:    2,
+		Runner:         &FakeCommandRunnerWithOutput{ExitCode: 3},
+		Reporter:       ui.NewReporter(&buf),
+		ReasonTemplate: tmpl,
+	}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"false"})
+
+	// Then each failed attempt and the final reason use the template
+	require.ErrorIs(t, err, ErrMaxAttempts)
+	assert.False(t, result.Success)
+	assert.Contains(t, buf.String(), "Attempt 1/2 failed (attempt 1 exited 3 (exit code 3))")
+	assert.Contains(t, buf.String(), "Attempt 2/2 failed (attempt 2 exited 3 (exit code 3))")
+	assert.Equal(t, "max retries reached (attempt 2 exited 3 (exit code 3))", result.Reason)
+}
+
+func TestReasonTemplate_HTTPStatus(t *testing.T) {
+	// Given a template preferring the HTTP status and a server answering 503
+	tmpl, err := ParseReasonTemplate("{{if .HTTPStatus}}HTTP {{.HTTPStatus}}{{else}}{{.Reason}}{{end}}")
+	require.NoError(t, err)
+	executor := &Executor{
+		MaxAttempts: 1,
+		Runner: &FakeCommandRunnerWithOutput{
+			ExitCode: 22,
+			Stdout:   "HTTP/1.1 503 Service Unavailable\r\nContent-Length: 0\r\n\r\n",
+		},
+		ReasonTemplate: tmpl,
+	}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"curl", "-fi", "https://api.example.com"})
+
+	// Then the reason names the HTTP status instead of curl's exit code
+	require.ErrorIs(t, err, ErrMaxAttempts)
+	assert.Equal(t, "HTTP 503", result.Reason)
+}
+
+func TestReasonTemplate_Pattern(t *testing.T) {
+	// Given a template naming the pattern that stopped the run
+	tmpl, err := ParseReasonTemplate("matched {{.Pattern}}")
+	require.NoError(t, err)
+	checker, err := conditions.NewChecker("", "denied", false)
+	require.NoError(t, err)
+	executor := &Executor{
+		MaxAttempts:    3,
+		Runner:         &FakeCommandRunnerWithOutput{ExitCode: 1, Stderr: "access denied"},
+		Conditions:     checker,
+		ReasonTemplate: tmpl,
+	}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"deploy"})
+
+	// Then the final reason renders the failure pattern
+	require.ErrorIs(t, err, ErrFailurePattern)
+	assert.Equal(t, "matched denied", result.Reason)
+}
+
+func TestReasonTemplate_Defaults(t *testing.T) {
+	// Given no reason template
+	executor := &Executor{MaxAttempts: 1, Runner: &FakeCommandRunnerWithOutput{ExitCode: 3}}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"false"})
+
+	// Then the default reason is kept
+	require.ErrorIs(t, err, ErrMaxAttempts)
+	assert.Equal(t, "exit code 3", result.Reason)
+}
+
+func TestParseReasonTemplate_Invalid(t *testing.T) {
+	// Unknown fields fail at parse time rather than mid-run
+	_, err := ParseReasonTemplate("{{.Status}}")
+	assert.ErrorContains(t, err, "invalid reason template")
+
+	_, err = ParseReasonTemplate("{{.Reason")
+	assert.ErrorContains(t, err, "invalid reason template")
+}
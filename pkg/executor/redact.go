@@ -0,0 +1,95 @@
+package executor
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// DefaultRedactedHeaders are the headers whose values are hidden whenever a
+// command is printed or recorded
+var DefaultRedactedHeaders = []string{"Authorization", "Cookie", "X-Api-Key"}
+
+// RedactedValue replaces the value of a redacted header
+const RedactedValue = "[REDACTED]"
+
+// HeaderRedaction decides which header values are hidden when a command is
+// printed (--dry-run) or recorded (run metrics, JSON summaries). The zero value
+// redacts DefaultRedactedHeaders.
+type HeaderRedaction struct {
+	// Redact names headers to hide in addition to DefaultRedactedHeaders
+	Redact []string
+	// Show names headers to leave visible, including default ones
+	Show []string
+}
+
+// headers returns the lowercased names of the headers to redact
+func (h HeaderRedaction) headers() []string {
+	shown := make(map[string]bool, len(h.Show))
+	for _, name := range h.Show {
+		shown[strings.ToLower(name)] = true
+	}
+
+	var names []string
+	seen := make(map[string]bool)
+	for _, list := range [][]string{DefaultRedactedHeaders, h.Redact} {
+		for _, name := range list {
+			name = strings.ToLower(name)
+			if name == "" || shown[name] || seen[name] {
+				continue
+			}
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Command returns a copy of command with the values of redacted headers
+// replaced by RedactedValue. Header names stay visible. It recognizes headers
+// passed as curl or wget arguments ("-H", "--header", "--header=",
+// "-HName: value"), bare "Name: value" arguments (httpie), headers inside a
+// single shell-string argument, and curl's -b/--cookie when Cookie is redacted.
+func (h HeaderRedaction) Command(command []string) []string {
+	names := h.headers()
+	if len(names) == 0 || len(command) == 0 {
+		return command
+	}
+
+	header := headerPattern(names)
+	redactCookie := slices.Contains(names, "cookie")
+
+	redacted := make([]string, len(command))
+	copy(redacted, command)
+	for i := range redacted {
+		if redactCookie && i > 0 && (command[i-1] == "-b" || command[i-1] == "--cookie") {
+			redacted[i] = RedactedValue
+			continue
+		}
+		redacted[i] = header.ReplaceAllString(redacted[i], "${1}${2}: "+RedactedValue)
+	}
+	return redacted
+}
+
+// headerPatterns caches the compiled pattern for each set of redacted headers,
+// since commands are redacted on every run
+var headerPatterns sync.Map // names joined by "|" -> *regexp.Regexp
+
+// headerPattern returns the pattern matching the given headers and their values
+func headerPattern(names []string) *regexp.Regexp {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = regexp.QuoteMeta(name)
+	}
+	key := strings.Join(quoted, "|")
+	if cached, ok := headerPatterns.Load(key); ok {
+		return cached.(*regexp.Regexp)
+	}
+
+	// A header starts an argument or follows whitespace, a quote, "=" or -H,
+	// and its value runs to the closing quote or the end of the argument
+	pattern := regexp.MustCompile(`(?i)(^|[\s'"=]|-H)(` + key + `)\s*:[^'"]*`)
+	cached, _ := headerPatterns.LoadOrStore(key, pattern)
+	return cached.(*regexp.Regexp)
+}
@@ -0,0 +1,95 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeaderRedaction_Command(t *testing.T) {
+	tests := []struct {
+		name      string
+		redaction HeaderRedaction
+		command   []string
+		expected  []string
+	}{
+		{
+			name:     "curl -H argument",
+			command:  []string{"curl", "-H", "Authorization: Bearer s3cret", "https://api.example.com"},
+			expected: []string{"curl", "-H", "Authorization: [REDACTED]", "https://api.example.com"},
+		},
+		{
+			name:     "joined -H and --header= forms",
+			command:  []string{"curl", "-HX-Api-Key:abc", "--header=cookie: session=1"},
+			expected: []string{"curl", "-HX-Api-Key: [REDACTED]", "--header=cookie: [REDACTED]"},
+		},
+		{
+			name:     "curl cookie flag",
+			command:  []string{"curl", "-b", "session=1", "https://example.com"},
+			expected: []string{"curl", "-b", "[REDACTED]", "https://example.com"},
+		},
+		{
+			name:     "httpie header item",
+			command:  []string{"http", "GET", "https://api.example.com", "Authorization:token"},
+			expected: []string{"http", "GET", "https://api.example.com", "Authorization: [REDACTED]"},
+		},
+		{
+			name:     "shell string",
+			command:  []string{"curl -H 'Authorization: Bearer s3cret' -H 'Accept: */*' https://api.example.com"},
+			expected: []string{"curl -H 'Authorization: [REDACTED]' -H 'Accept: */*' https://api.example.com"},
+		},
+		{
+			name:     "other headers and URLs unchanged",
+			command:  []string{"curl", "-H", "Accept: application/json", "https://api.example.com/authorization"},
+			expected: []string{"curl", "-H", "Accept: application/json", "https://api.example.com/authorization"},
+		},
+		{
+			name:      "extra redacted header",
+			redaction: HeaderRedaction{Redact: []string{"X-Session-Token"}},
+			command:   []string{"curl", "-H", "X-Session-Token: t0k3n"},
+			expected:  []string{"curl", "-H", "X-Session-Token: [REDACTED]"},
+		},
+		{
+			name:      "shown default header",
+			redaction: HeaderRedaction{Show: []string{"authorization"}},
+			command:   []string{"curl", "-H", "Authorization: Bearer s3cret", "-H", "Cookie: a=b"},
+			expected:  []string{"curl", "-H", "Authorization: Bearer s3cret", "-H", "Cookie: [REDACTED]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// When redacting the command
+			redacted := tt.redaction.Command(tt.command)
+
+			// Then only the redacted header values should be replaced
+			assert.Equal(t, tt.expected, redacted)
+		})
+	}
+}
+
+func TestHeaderRedaction_CommandLeavesInputUnchanged(t *testing.T) {
+	// Given a command with an Authorization header
+	command := []string{"curl", "-H", "Authorization: Bearer s3cret"}
+
+	// When redacting it
+	HeaderRedaction{}.Command(command)
+
+	// Then the command that runs should keep the real value
+	assert.Equal(t, "Authorization: Bearer s3cret", command[2])
+}
+
+func TestExecutor_RunRecordsRedactedCommand(t *testing.T) {
+	// Given a command sending an Authorization header
+	executor := &Executor{MaxAttempts: 1, Runner: &FakeCommandRunner{ExitCode: 0}}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"curl", "-H", "Authorization: Bearer s3cret", "https://api.example.com"})
+
+	// Then the recorded command should keep the header name but not its value
+	require.NoError(t, err)
+	require.NotNil(t, result.Metrics)
+	assert.Equal(t, "curl -H Authorization: [REDACTED] https://api.example.com", result.Metrics.Command)
+	assert.NotContains(t, result.Metrics.Command, "s3cret")
+}
@@ -0,0 +1,50 @@
+package executor
+
+import (
+	"fmt"
+
+	"github.com/shaneisley/patience/pkg/conditions"
+)
+
+// passTally counts passing and failing attempts for RequirePasses, where the
+// run succeeds once enough attempts have passed in total, not necessarily in a row
+type passTally struct {
+	required int
+	passes   int
+	failures int
+}
+
+// check records an attempt's outcome and decides whether the run is over:
+// it succeeds once the required passes are reached, and fails as soon as the
+// attempts left cannot make up the difference. A failure that already stops
+// the run, such as a matched failure pattern, is returned unchanged.
+func (t *passTally) check(result conditions.Result, shouldStop bool, attempt, maxAttempts int) (conditions.Result, bool) {
+	if result.Success {
+		t.passes++
+	} else {
+		t.failures++
+		if shouldStop {
+			return result, true
+		}
+	}
+
+	if t.passes >= t.required {
+		return conditions.Result{Success: true, Reason: t.summary()}, true
+	}
+	if maxAttempts-attempt+t.passes < t.required {
+		return conditions.Result{Success: false, Reason: t.summary()}, true
+	}
+
+	if result.Success {
+		return conditions.Result{
+			Success: false,
+			Reason:  fmt.Sprintf("passed (%d/%d passes)", t.passes, t.required),
+		}, false
+	}
+	return result, false
+}
+
+// summary describes the tally, e.g. "2 passed, 1 failed (2 passes required)"
+func (t *passTally) summary() string {
+	return fmt.Sprintf("%d passed, %d failed (%d passes required)", t.passes, t.failures, t.required)
+}
@@ -0,0 +1,86 @@
+package executor
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/shaneisley/patience/pkg/ui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutor_RequirePassesTwoOfThreeSucceeds(t *testing.T) {
+	// Given a flaky command that passes, fails, then passes
+	runner := &MockHTTPCommandRunner{responses: []MockHTTPResponse{
+		{ExitCode: 0, Stdout: "ok"},
+		{ExitCode: 1, Stderr: "flaky"},
+		{ExitCode: 0, Stdout: "ok"},
+	}}
+	var buf bytes.Buffer
+	executor := &Executor{
+		MaxAttempts:   3,
+		Runner:        runner,
+		Reporter:      ui.NewReporter(&buf),
+		RequirePasses: 2,
+	}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"go", "test"})
+
+	// Then the run should continue past the first pass and succeed on the second
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, 3, result.AttemptCount)
+	assert.Equal(t, 0, result.ExitCode)
+	assert.Equal(t, "2 passed, 1 failed (2 passes required)", result.Reason)
+	assert.Equal(t, 2, result.Stats.SuccessfulRuns)
+	assert.Equal(t, 1, result.Stats.FailedRuns)
+	assert.Contains(t, buf.String(), "passed (1/2 passes)")
+}
+
+func TestExecutor_RequirePassesOneOfThreeFails(t *testing.T) {
+	// Given a command that passes only once in three attempts
+	runner := &MockHTTPCommandRunner{responses: []MockHTTPResponse{
+		{ExitCode: 1, Stderr: "flaky"},
+		{ExitCode: 0, Stdout: "ok"},
+		{ExitCode: 1, Stderr: "flaky"},
+	}}
+	executor := &Executor{
+		MaxAttempts:   3,
+		Runner:        runner,
+		RequirePasses: 2,
+	}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"go", "test"})
+
+	// Then the run should fail with the tally and the last attempt's exit code
+	require.ErrorAs(t, err, new(*RunError))
+	assert.False(t, result.Success)
+	assert.Equal(t, 3, result.AttemptCount)
+	assert.Equal(t, 1, result.ExitCode)
+	assert.Equal(t, "1 passed, 2 failed (2 passes required)", result.Reason)
+}
+
+func TestExecutor_RequirePassesStopsWhenUnreachable(t *testing.T) {
+	// Given two failures when two of three passes are required
+	runner := &MockHTTPCommandRunner{responses: []MockHTTPResponse{
+		{ExitCode: 1, Stderr: "flaky"},
+		{ExitCode: 1, Stderr: "flaky"},
+		{ExitCode: 0, Stdout: "ok"},
+	}}
+	executor := &Executor{
+		MaxAttempts:   3,
+		Runner:        runner,
+		RequirePasses: 2,
+	}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"go", "test"})
+
+	// Then the third attempt should be skipped since it cannot reach two passes
+	require.ErrorAs(t, err, new(*RunError))
+	assert.False(t, result.Success)
+	assert.Equal(t, 2, result.AttemptCount)
+	assert.Equal(t, "0 passed, 2 failed (2 passes required)", result.Reason)
+}
@@ -0,0 +1,94 @@
+package executor
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/shaneisley/patience/pkg/ui"
+)
+
+// ReasonLowResources prefixes the result reason when a resource check stops a run
+const ReasonLowResources = "insufficient resources"
+
+// ResourceChecker reports free resources, checked before each attempt when
+// MinFreeDisk or MinFreeMemory is set
+type ResourceChecker interface {
+	// FreeDisk returns the bytes available to unprivileged users on the
+	// filesystem containing path
+	FreeDisk(path string) (uint64, error)
+	// FreeMemory returns the bytes of memory available without swapping
+	FreeMemory() (uint64, error)
+}
+
+// SystemResources checks resources of the local machine using platform calls
+type SystemResources struct{}
+
+// byteUnits maps size suffixes to multipliers; units are binary, so 1K is 1024 bytes
+var byteUnits = map[string]uint64{
+	"":  1,
+	"b": 1,
+	"k": 1 << 10, "kb": 1 << 10, "kib": 1 << 10,
+	"m": 1 << 20, "mb": 1 << 20, "mib": 1 << 20,
+	"g": 1 << 30, "gb": 1 << 30, "gib": 1 << 30,
+	"t": 1 << 40, "tb": 1 << 40, "tib": 1 << 40,
+}
+
+// ParseByteSize parses a size such as "512M", "1.5GB" or "4096" into bytes.
+// Units are binary and case-insensitive: K, M, G and T are powers of 1024.
+func ParseByteSize(value string) (uint64, error) {
+	trimmed := strings.TrimSpace(value)
+	split := len(trimmed)
+	for split > 0 && !(trimmed[split-1] >= '0' && trimmed[split-1] <= '9') && trimmed[split-1] != '.' {
+		split--
+	}
+
+	multiplier, ok := byteUnits[strings.ToLower(strings.TrimSpace(trimmed[split:]))]
+	if !ok {
+		return 0, fmt.Errorf("invalid size %q: unknown unit %q", value, trimmed[split:])
+	}
+	number, err := strconv.ParseFloat(strings.TrimSpace(trimmed[:split]), 64)
+	if err != nil || number < 0 {
+		return 0, fmt.Errorf("invalid size %q: expected a non-negative number with an optional unit (K, M, G, T)", value)
+	}
+	bytes := number * float64(multiplier)
+	if bytes >= math.MaxUint64 {
+		return 0, fmt.Errorf("invalid size %q: too large", value)
+	}
+	return uint64(bytes), nil
+}
+
+// checkResources returns a reason when free disk space in the working directory
+// or free memory is below its threshold, or "" when the attempt may start.
+// A resource that cannot be measured is warned about and not enforced.
+func (e *Executor) checkResources(attempt int) string {
+	if e.MinFreeDisk == 0 && e.MinFreeMemory == 0 {
+		return ""
+	}
+
+	checker := e.Resources
+	if checker == nil {
+		checker = SystemResources{}
+	}
+
+	if e.MinFreeDisk > 0 {
+		free, err := checker.FreeDisk(".")
+		if err != nil {
+			e.warn(fmt.Sprintf("cannot check free disk space before attempt %d: %v", attempt, err))
+		} else if free < e.MinFreeDisk {
+			return fmt.Sprintf("%s: %s free disk space, need %s", ReasonLowResources, ui.FormatBytes(free), ui.FormatBytes(e.MinFreeDisk))
+		}
+	}
+
+	if e.MinFreeMemory > 0 {
+		free, err := checker.FreeMemory()
+		if err != nil {
+			e.warn(fmt.Sprintf("cannot check free memory before attempt %d: %v", attempt, err))
+		} else if free < e.MinFreeMemory {
+			return fmt.Sprintf("%s: %s free memory, need %s", ReasonLowResources, ui.FormatBytes(free), ui.FormatBytes(e.MinFreeMemory))
+		}
+	}
+
+	return ""
+}
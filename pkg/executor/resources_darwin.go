@@ -0,0 +1,27 @@
+//go:build darwin
+
+package executor
+
+import "syscall"
+
+// FreeDisk returns the bytes available to unprivileged users on path's filesystem
+func (SystemResources) FreeDisk(path string) (uint64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, err
+	}
+	return stat.Bavail * uint64(stat.Bsize), nil
+}
+
+// FreeMemory returns the free page count times the page size
+func (SystemResources) FreeMemory() (uint64, error) {
+	pages, err := syscall.SysctlUint32("vm.page_free_count")
+	if err != nil {
+		return 0, err
+	}
+	pageSize, err := syscall.SysctlUint32("hw.pagesize")
+	if err != nil {
+		return 0, err
+	}
+	return uint64(pages) * uint64(pageSize), nil
+}
@@ -0,0 +1,18 @@
+//go:build !linux && !darwin
+
+package executor
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// FreeDisk is not supported on this platform
+func (SystemResources) FreeDisk(path string) (uint64, error) {
+	return 0, fmt.Errorf("free disk space checks are not supported on %s", runtime.GOOS)
+}
+
+// FreeMemory is not supported on this platform
+func (SystemResources) FreeMemory() (uint64, error) {
+	return 0, fmt.Errorf("free memory checks are not supported on %s", runtime.GOOS)
+}
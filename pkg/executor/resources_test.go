@@ -0,0 +1,160 @@
+package executor
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/shaneisley/patience/pkg/ui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeResources reports fixed free resources, or errors, for resource checks
+type fakeResources struct {
+	disk    []uint64 // free disk per call; the last value repeats
+	memory  uint64
+	diskErr error
+	calls   int
+}
+
+func (f *fakeResources) FreeDisk(path string) (uint64, error) {
+	if f.diskErr != nil {
+		return 0, f.diskErr
+	}
+	free := f.disk[min(f.calls, len(f.disk)-1)]
+	f.calls++
+	return free, nil
+}
+
+func (f *fakeResources) FreeMemory() (uint64, error) {
+	return f.memory, nil
+}
+
+func TestExecutor_LowDiskAbortsBeforeFirstAttempt(t *testing.T) {
+	// Given only 100MiB of free disk when 1GiB is required
+	runner := &MockHTTPCommandRunner{responses: []MockHTTPResponse{{ExitCode: 0}}}
+	var buf bytes.Buffer
+	executor := &Executor{
+		MaxAttempts: 3,
+		Runner:      runner,
+		Reporter:    ui.NewReporter(&buf),
+		MinFreeDisk: 1 << 30,
+		Resources:   &fakeResources{disk: []uint64{100 << 20}, memory: 8 << 30},
+	}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"make"})
+
+	// Then no attempt should start and the reason should say why
+	require.ErrorAs(t, err, new(*RunError))
+	assert.False(t, result.Success)
+	assert.Equal(t, 0, result.AttemptCount)
+	assert.Equal(t, 0, runner.currentCall)
+	assert.Equal(t, "insufficient resources: 100.0MiB free disk space, need 1.0GiB", result.Reason)
+	assert.Contains(t, buf.String(), "not starting attempt 1")
+}
+
+func TestExecutor_LowMemoryAborts(t *testing.T) {
+	// Given enough disk but too little free memory
+	runner := &MockHTTPCommandRunner{responses: []MockHTTPResponse{{ExitCode: 0}}}
+	executor := &Executor{
+		MaxAttempts:   3,
+		Runner:        runner,
+		MinFreeDisk:   1 << 30,
+		MinFreeMemory: 32 << 20,
+		Resources:     &fakeResources{disk: []uint64{10 << 30}, memory: 16 << 20},
+	}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"make"})
+
+	// Then the run should stop before starting with the memory shortfall
+	require.ErrorAs(t, err, new(*RunError))
+	assert.False(t, result.Success)
+	assert.Equal(t, 0, runner.currentCall)
+	assert.Equal(t, "insufficient resources: 16.0MiB free memory, need 32.0MiB", result.Reason)
+}
+
+func TestExecutor_LowDiskAbortsMidRun(t *testing.T) {
+	// Given disk space that runs low after the first attempt fails
+	runner := &MockHTTPCommandRunner{responses: []MockHTTPResponse{{ExitCode: 2}, {ExitCode: 0}}}
+	executor := &Executor{
+		MaxAttempts: 3,
+		Runner:      runner,
+		MinFreeDisk: 1 << 30,
+		Resources:   &fakeResources{disk: []uint64{2 << 30, 10 << 20}},
+	}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"make"})
+
+	// Then the second attempt should not start and the first attempt's exit code is kept
+	require.ErrorAs(t, err, new(*RunError))
+	assert.False(t, result.Success)
+	assert.Equal(t, 1, result.AttemptCount)
+	assert.Equal(t, 2, result.ExitCode)
+	assert.Contains(t, result.Reason, "free disk space")
+}
+
+func TestExecutor_UncheckableResourcesWarnAndContinue(t *testing.T) {
+	// Given a checker that cannot measure disk space
+	runner := &MockHTTPCommandRunner{responses: []MockHTTPResponse{{ExitCode: 0}}}
+	var buf bytes.Buffer
+	executor := &Executor{
+		MaxAttempts: 1,
+		Runner:      runner,
+		Reporter:    ui.NewReporter(&buf),
+		MinFreeDisk: 1 << 30,
+		Resources:   &fakeResources{diskErr: errors.New("statfs failed")},
+	}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"make"})
+
+	// Then the attempt should run with a warning
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Contains(t, buf.String(), "cannot check free disk space before attempt 1: statfs failed")
+}
+
+func TestSystemResources_ReportsFreeResources(t *testing.T) {
+	free, err := SystemResources{}.FreeDisk(".")
+	require.NoError(t, err)
+	assert.Greater(t, free, uint64(0))
+
+	memory, err := SystemResources{}.FreeMemory()
+	require.NoError(t, err)
+	assert.Greater(t, memory, uint64(0))
+}
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected uint64
+		wantErr  bool
+	}{
+		{"4096", 4096, false},
+		{"512M", 512 << 20, false},
+		{"1.5GB", 3 << 29, false},
+		{"2gib", 2 << 30, false},
+		{"10 K", 10 << 10, false},
+		{"1T", 1 << 40, false},
+		{"", 0, true},
+		{"-1G", 0, true},
+		{"5X", 0, true},
+		{"GB", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseByteSize(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
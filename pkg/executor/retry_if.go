@@ -0,0 +1,36 @@
+package executor
+
+import (
+	"time"
+
+	"github.com/shaneisley/patience/pkg/conditions"
+)
+
+// retryIfReason is the failure reason of an attempt the RetryIf expression
+// sent back for another try
+const retryIfReason = "retry-if matched"
+
+// applyRetryIf lets the RetryIf expression decide whether a finished attempt is
+// retried. When it holds the attempt counts as failed and is retried; when it
+// does not, retrying stops and the attempt keeps its own verdict.
+func (e *Executor) applyRetryIf(attempt int, output CommandOutput, duration time.Duration, result conditions.Result, shouldStop bool) (conditions.Result, bool) {
+	if e.RetryIf == nil {
+		return result, shouldStop
+	}
+	status, _ := finalHTTPStatus(output)
+	retry := e.RetryIf.Eval(conditions.AttemptContext{
+		Attempt:    attempt,
+		ExitCode:   output.ExitCode,
+		Stdout:     output.Stdout,
+		Stderr:     output.Stderr,
+		HTTPStatus: status,
+		Duration:   duration,
+	})
+	if retry {
+		return conditions.Result{Success: false, Reason: retryIfReason}, false
+	}
+	if !result.Success {
+		result.Reason += " (retry-if: not retried)"
+	}
+	return result, true
+}
@@ -0,0 +1,64 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/shaneisley/patience/pkg/conditions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutor_RetryIf(t *testing.T) {
+	tests := []struct {
+		name         string
+		expr         string
+		runner       CommandRunner
+		wantSuccess  bool
+		wantAttempts int
+		wantReason   string
+	}{
+		{
+			name:         "retries while the expression holds",
+			expr:         "exit == 0 and not match(out, 'ready')",
+			runner:       &FakeCommandRunnerWithOutput{ExitCode: 0, Stdout: "starting"},
+			wantSuccess:  false,
+			wantAttempts: 3,
+			wantReason:   "max retries reached (retry-if matched)",
+		},
+		{
+			name:         "stops a failure the expression does not retry",
+			expr:         "exit == 75",
+			runner:       &FakeCommandRunnerWithOutput{ExitCode: 1},
+			wantSuccess:  false,
+			wantAttempts: 1,
+			wantReason:   "exit code 1 (retry-if: not retried)",
+		},
+		{
+			name:         "success stands when the expression is false",
+			expr:         "exit != 0",
+			runner:       &FakeCommandRunnerWithSequence{ExitCodes: []int{1, 0}},
+			wantSuccess:  true,
+			wantAttempts: 2,
+			wantReason:   "exit code 0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given an executor with a retry-if expression
+			expr, err := conditions.CompileRetryIf(tt.expr)
+			require.NoError(t, err)
+			executor := &Executor{MaxAttempts: 3, Runner: tt.runner, RetryIf: expr}
+
+			// When Run() is called
+			result, err := executor.Run([]string{"deploy"})
+
+			// Then the expression decides whether attempts are retried
+			require.NotNil(t, result)
+			assert.Equal(t, result.Err(), err)
+			assert.Equal(t, tt.wantSuccess, result.Success)
+			assert.Equal(t, tt.wantAttempts, result.AttemptCount)
+			assert.Equal(t, tt.wantReason, result.Reason)
+		})
+	}
+}
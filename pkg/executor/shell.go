@@ -0,0 +1,41 @@
+package executor
+
+import "strings"
+
+// shellOperators are arguments that only mean something to a shell
+var shellOperators = map[string]bool{
+	"|": true, "||": true, "&&": true, "&": true, ";": true,
+	">": true, ">>": true, "<": true, "2>": true, "2>&1": true,
+}
+
+// safeShellChars are the characters a word may hold and still need no quoting
+const safeShellChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./:=@%+,"
+
+// IsShellOperator reports whether arg is a bare shell operator such as "|" or "&&"
+func IsShellOperator(arg string) bool {
+	return shellOperators[arg]
+}
+
+// shellScript returns the script sh -c runs for command with Shell. A single
+// argument is the script itself. Several are joined with each argument quoted
+// where needed, so one containing spaces stays one word, while bare operators
+// such as "|" keep their meaning.
+func shellScript(command []string) string {
+	if len(command) == 1 {
+		return command[0]
+	}
+	words := make([]string, len(command))
+	for i, arg := range command {
+		words[i] = shellQuote(arg)
+	}
+	return strings.Join(words, " ")
+}
+
+// shellQuote quotes arg as one shell word, leaving operators and words that
+// need no quoting as they are
+func shellQuote(arg string) string {
+	if IsShellOperator(arg) || (arg != "" && strings.Trim(arg, safeShellChars) == "") {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
@@ -0,0 +1,44 @@
+package executor
+
+import (
+	"fmt"
+	"os/exec"
+	"syscall"
+
+	"github.com/shaneisley/patience/pkg/conditions"
+)
+
+// signalExitCodeBase is added to the number of the signal that terminated a
+// command to give its exit code, following the shell convention (SIGKILL
+// exits 137)
+const signalExitCodeBase = 128
+
+// exitStatus returns the exit code of a command that ran to completion and the
+// signal that terminated it, if any. A command killed by a signal reports
+// 128+signal rather than os/exec's -1.
+func exitStatus(exitErr *exec.ExitError) (int, syscall.Signal) {
+	status, ok := exitErr.Sys().(syscall.WaitStatus)
+	if !ok || !status.Signaled() {
+		return exitErr.ExitCode(), 0
+	}
+	return signalExitCodeBase + int(status.Signal()), status.Signal()
+}
+
+// signalResult rewords the plain exit-code failure of a command terminated by
+// a signal as "terminated by signal SIGKILL"; other results pass through
+func signalResult(output CommandOutput, result conditions.Result) conditions.Result {
+	if output.Signal == 0 || result.Success || result.Reason != fmt.Sprintf("exit code %d", output.ExitCode) {
+		return result
+	}
+	result.Reason = "terminated by signal " + signalName(output.Signal)
+	return result
+}
+
+// signalName returns the name of the signal that terminated the command, or ""
+// when it exited
+func (o CommandOutput) signalName() string {
+	if o.Signal == 0 {
+		return ""
+	}
+	return signalName(o.Signal)
+}
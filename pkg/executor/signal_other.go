@@ -0,0 +1,13 @@
+//go:build !unix
+
+package executor
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// signalName returns sig by number; signal names are not known on this platform
+func signalName(sig syscall.Signal) string {
+	return fmt.Sprintf("signal %d", int(sig))
+}
@@ -0,0 +1,81 @@
+//go:build unix
+
+package executor
+
+import (
+	"bytes"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSystemCommandRunner_SignalTermination(t *testing.T) {
+	// Given a command that kills itself
+	var discard bytes.Buffer
+	runner := &SystemCommandRunner{Stdout: &discard, Stderr: &discard}
+
+	// When it runs
+	output, err := runner.RunWithOutput([]string{"sh", "-c", "kill -KILL $$"})
+
+	// Then the signal is reported with the shell's 128+signal exit code
+	require.NoError(t, err)
+	assert.Equal(t, syscall.SIGKILL, output.Signal)
+	assert.Equal(t, 137, output.ExitCode)
+}
+
+func TestSystemCommandRunner_NormalExitHasNoSignal(t *testing.T) {
+	// Given a command that exits with a code
+	var discard bytes.Buffer
+	runner := &SystemCommandRunner{Stdout: &discard, Stderr: &discard}
+
+	// When it runs
+	output, err := runner.RunWithOutput([]string{"sh", "-c", "exit 3"})
+
+	// Then no signal is reported
+	require.NoError(t, err)
+	assert.Equal(t, syscall.Signal(0), output.Signal)
+	assert.Equal(t, 3, output.ExitCode)
+}
+
+func TestExecutor_SignalTermination(t *testing.T) {
+	// Given an executor running a command that terminates itself
+	var discard bytes.Buffer
+	executor := &Executor{MaxAttempts: 2, Runner: &SystemCommandRunner{Stdout: &discard, Stderr: &discard}}
+
+	// When it runs
+	result, err := executor.Run([]string{"sh", "-c", "kill -TERM $$"})
+
+	// Then the reason names the signal and the exit code follows the convention
+	require.ErrorIs(t, err, ErrMaxAttempts)
+	assert.False(t, result.Success)
+	assert.Equal(t, 143, result.ExitCode)
+	assert.Contains(t, result.Reason, "terminated by signal SIGTERM")
+
+	// And each attempt records the signal in its metrics
+	require.NotNil(t, result.Metrics)
+	require.Len(t, result.Metrics.Attempts, 2)
+	for _, attempt := range result.Metrics.Attempts {
+		assert.Equal(t, "SIGTERM", attempt.Signal)
+		assert.Equal(t, 143, attempt.ExitCode)
+	}
+}
+
+func TestExecutor_StepSignalTermination(t *testing.T) {
+	// Given a multi-step command whose second step terminates itself
+	var discard bytes.Buffer
+	executor := &Executor{MaxAttempts: 1, Steps: true, Runner: &SystemCommandRunner{Stdout: &discard, Stderr: &discard}}
+
+	// When it runs
+	result, err := executor.Run([]string{"true", StepSeparator, "sh", "-c", "kill -TERM $$"})
+
+	// Then the attempt reports the failing step's signal
+	require.ErrorIs(t, err, ErrMaxAttempts)
+	assert.Equal(t, 143, result.ExitCode)
+	assert.Contains(t, result.Reason, "terminated by signal SIGTERM")
+	require.NotNil(t, result.Metrics)
+	require.Len(t, result.Metrics.Attempts, 1)
+	assert.Equal(t, "SIGTERM", result.Metrics.Attempts[0].Signal)
+	assert.Equal(t, []int{0, 143}, result.Metrics.Attempts[0].StepExitCodes)
+}
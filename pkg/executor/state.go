@@ -0,0 +1,141 @@
+package executor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/shaneisley/patience/pkg/storage"
+)
+
+// DefaultStateMaxAge is how old a state file may be before it is ignored
+const DefaultStateMaxAge = 24 * time.Hour
+
+// RetryState is the progress persisted to a state file so that a killed run
+// can resume its schedule instead of starting over. The command is kept only
+// as a hash, since its arguments may hold secrets.
+type RetryState struct {
+	CommandHash   string    `json:"command_hash"`    // CommandHash of the command that wrote the state
+	Attempt       int       `json:"attempt"`         // Last completed attempt
+	NextAttemptAt time.Time `json:"next_attempt_at"` // When the next attempt is due
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// CommandHash identifies a command in a state file without storing its arguments
+func CommandHash(command []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(command, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// LoadRetryState reads a state file. A missing file returns nil state and no error.
+func LoadRetryState(path string) (*RetryState, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+
+	var state RetryState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse state file: %w", err)
+	}
+	return &state, nil
+}
+
+// SaveRetryState atomically writes state to path
+func SaveRetryState(path string, state RetryState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+
+	if err := storage.WriteFileAtomic(path, data); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	return nil
+}
+
+// resumeState loads the state file and decides where the run should continue.
+// It returns the first attempt to run and how long to wait before it. Stale,
+// unreadable or mismatched state is discarded with a warning.
+func (e *Executor) resumeState(command []string) (int, time.Duration) {
+	if e.StateFile == "" {
+		return 1, 0
+	}
+
+	now := e.clock().Now()
+	state, err := LoadRetryState(e.StateFile)
+	if err != nil {
+		e.warn(fmt.Sprintf("ignoring state file: %v", err))
+		return 1, 0
+	}
+	if state == nil {
+		return 1, 0
+	}
+
+	switch {
+	case state.CommandHash != CommandHash(command):
+		e.warn("ignoring state file written for a different command")
+		return 1, 0
+	case state.Attempt < 1:
+		e.warn(fmt.Sprintf("ignoring state file: invalid attempt %d", state.Attempt))
+		return 1, 0
+	case state.Attempt >= e.attemptLimit():
+		e.warn(fmt.Sprintf("ignoring state file: attempt %d is outside the attempt limit %d", state.Attempt, e.MaxAttempts))
+		return 1, 0
+	case !state.UpdatedAt.IsZero() && now.Sub(state.UpdatedAt) > e.stateMaxAge():
+		e.warn(fmt.Sprintf("ignoring state file: last updated %s ago, more than %s", now.Sub(state.UpdatedAt).Round(time.Second), e.stateMaxAge()))
+		return 1, 0
+	}
+
+	// A due time in the past means the wait already elapsed while we were down
+	wait := state.NextAttemptAt.Sub(now)
+	if wait < 0 {
+		wait = 0
+	}
+	e.warn(fmt.Sprintf("resuming from state file at attempt %d", state.Attempt+1))
+	return state.Attempt + 1, wait
+}
+
+// stateMaxAge returns StateMaxAge, or DefaultStateMaxAge when it is unset
+func (e *Executor) stateMaxAge() time.Duration {
+	if e.StateMaxAge <= 0 {
+		return DefaultStateMaxAge
+	}
+	return e.StateMaxAge
+}
+
+// saveState records a failed attempt and when the next one is due
+func (e *Executor) saveState(command []string, attempt int, delay time.Duration) {
+	if e.StateFile == "" {
+		return
+	}
+
+	now := e.clock().Now()
+	err := SaveRetryState(e.StateFile, RetryState{
+		CommandHash:   CommandHash(command),
+		Attempt:       attempt,
+		NextAttemptAt: now.Add(delay),
+		UpdatedAt:     now,
+	})
+	if err != nil {
+		e.warn(err.Error())
+	}
+}
+
+// clearState removes the state file once the run has finished
+func (e *Executor) clearState() {
+	if e.StateFile == "" {
+		return
+	}
+	if err := os.Remove(e.StateFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+		e.warn(fmt.Sprintf("failed to remove state file: %v", err))
+	}
+}
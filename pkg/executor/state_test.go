@@ -0,0 +1,264 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shaneisley/patience/pkg/backoff"
+	"github.com/shaneisley/patience/pkg/ui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stateInspectingRunner fails every attempt and records the state file contents
+// seen at the start of each attempt
+type stateInspectingRunner struct {
+	statePath string
+	seen      []*RetryState
+}
+
+func (r *stateInspectingRunner) Run(command []string) (int, error) {
+	output, err := r.RunWithOutput(command)
+	return output.ExitCode, err
+}
+
+func (r *stateInspectingRunner) RunWithContext(ctx context.Context, command []string) (int, error) {
+	return r.Run(command)
+}
+
+func (r *stateInspectingRunner) RunWithOutput(command []string) (CommandOutput, error) {
+	state, _ := LoadRetryState(r.statePath)
+	r.seen = append(r.seen, state)
+	return CommandOutput{ExitCode: 1}, nil
+}
+
+func (r *stateInspectingRunner) RunWithOutputAndContext(ctx context.Context, command []string) (CommandOutput, error) {
+	return r.RunWithOutput(command)
+}
+
+func TestExecutor_StateFileWrittenMidRun(t *testing.T) {
+	// Given an executor persisting state for a command that always fails
+	statePath := filepath.Join(t.TempDir(), "retry.state")
+	runner := &stateInspectingRunner{statePath: statePath}
+	executor := &Executor{
+		MaxAttempts:     3,
+		Runner:          runner,
+		BackoffStrategy: backoff.NewFixed(10 * time.Millisecond),
+		StateFile:       statePath,
+	}
+
+	// When Run() is called
+	before := time.Now()
+	result, err := executor.Run([]string{"sync", "--all"})
+	require.ErrorIs(t, err, ErrMaxAttempts)
+	assert.False(t, result.Success)
+
+	// Then no state exists before the first attempt
+	require.Len(t, runner.seen, 3)
+	assert.Nil(t, runner.seen[0])
+
+	// And each later attempt sees the previous attempt and its due time recorded
+	for i, state := range runner.seen[1:] {
+		require.NotNil(t, state)
+		assert.Equal(t, CommandHash([]string{"sync", "--all"}), state.CommandHash)
+		assert.Equal(t, i+1, state.Attempt)
+		assert.True(t, state.NextAttemptAt.After(before))
+	}
+
+	// And the state file is removed once the run finishes
+	_, statErr := os.Stat(statePath)
+	assert.True(t, os.IsNotExist(statErr))
+}
+
+func TestExecutor_ResumesFromStateFile(t *testing.T) {
+	// Given a state file from a run killed after attempt 2, with 100ms left to wait
+	statePath := filepath.Join(t.TempDir(), "retry.state")
+	command := []string{"sync", "--all"}
+	require.NoError(t, SaveRetryState(statePath, RetryState{
+		CommandHash:   CommandHash(command),
+		Attempt:       2,
+		NextAttemptAt: time.Now().Add(100 * time.Millisecond),
+		UpdatedAt:     time.Now(),
+	}))
+
+	runner := &FakeCommandRunnerWithOutput{ExitCode: 0}
+	executor := &Executor{
+		MaxAttempts: 5,
+		Runner:      runner,
+		StateFile:   statePath,
+	}
+
+	// When Run() is called with the same command
+	start := time.Now()
+	result, err := executor.Run(command)
+	elapsed := time.Since(start)
+
+	// Then it should honor the remaining wait and continue at attempt 3
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, 3, result.AttemptCount)
+	assert.GreaterOrEqual(t, elapsed, 90*time.Millisecond)
+}
+
+func TestExecutor_IgnoresStaleStateFile(t *testing.T) {
+	tests := []struct {
+		name  string
+		state RetryState
+		raw   string
+	}{
+		{
+			name:  "different command",
+			state: RetryState{CommandHash: CommandHash([]string{"other"}), Attempt: 2, NextAttemptAt: time.Now().Add(time.Hour)},
+		},
+		{
+			name:  "attempt beyond limit",
+			state: RetryState{CommandHash: CommandHash([]string{"sync"}), Attempt: 3, NextAttemptAt: time.Now().Add(time.Hour)},
+		},
+		{
+			name: "corrupt file",
+			raw:  "{not json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given a state file that does not apply to this run
+			statePath := filepath.Join(t.TempDir(), "retry.state")
+			if tt.raw != "" {
+				require.NoError(t, os.WriteFile(statePath, []byte(tt.raw), 0600))
+			} else {
+				require.NoError(t, SaveRetryState(statePath, tt.state))
+			}
+
+			executor := &Executor{
+				MaxAttempts: 3,
+				Runner:      &FakeCommandRunnerWithOutput{ExitCode: 0},
+				StateFile:   statePath,
+			}
+
+			// When Run() is called
+			start := time.Now()
+			result, err := executor.Run([]string{"sync"})
+
+			// Then it should start over immediately at attempt 1
+			require.NoError(t, err)
+			assert.Equal(t, 1, result.AttemptCount)
+			assert.Less(t, time.Since(start), time.Second)
+		})
+	}
+}
+
+func TestExecutor_IgnoresExpiredStateFile(t *testing.T) {
+	// Given a state file last updated two days ago, by the executor's clock
+	clock := &fakeClock{now: time.Date(2025, 1, 3, 12, 0, 0, 0, time.UTC)}
+	statePath := filepath.Join(t.TempDir(), "retry.state")
+	require.NoError(t, SaveRetryState(statePath, RetryState{
+		CommandHash:   CommandHash([]string{"sync"}),
+		Attempt:       1,
+		NextAttemptAt: clock.now.Add(-47 * time.Hour),
+		UpdatedAt:     clock.now.Add(-48 * time.Hour),
+	}))
+
+	var buf bytes.Buffer
+	newExecutor := func(maxAge time.Duration) *Executor {
+		return &Executor{
+			MaxAttempts: 3,
+			Runner:      &FakeCommandRunnerWithOutput{ExitCode: 0},
+			StateFile:   statePath,
+			StateMaxAge: maxAge,
+			Clock:       clock,
+			Reporter:    ui.NewReporter(&buf),
+		}
+	}
+
+	// When Run() is called with the default maximum age
+	result, err := newExecutor(0).Run([]string{"sync"})
+
+	// Then the state should be ignored with a warning, starting over at attempt 1
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.AttemptCount)
+	assert.Contains(t, buf.String(), "ignoring state file: last updated 48h0m0s ago, more than 24h0m0s")
+
+	// And a longer maximum age should resume it
+	require.NoError(t, SaveRetryState(statePath, RetryState{
+		CommandHash:   CommandHash([]string{"sync"}),
+		Attempt:       1,
+		NextAttemptAt: clock.now.Add(-47 * time.Hour),
+		UpdatedAt:     clock.now.Add(-48 * time.Hour),
+	}))
+	result, err = newExecutor(72 * time.Hour).Run([]string{"sync"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.AttemptCount)
+}
+
+func TestExecutor_StateFileWarningWithUnlimitedAttempts(t *testing.T) {
+	// Given an unlimited run and a state file with an invalid attempt
+	statePath := filepath.Join(t.TempDir(), "retry.state")
+	require.NoError(t, SaveRetryState(statePath, RetryState{CommandHash: CommandHash([]string{"sync"}), Attempt: 0}))
+
+	var buf bytes.Buffer
+	executor := &Executor{
+		MaxAttempts:  0,
+		MaxTotalTime: time.Minute,
+		Runner:       &FakeCommandRunnerWithOutput{ExitCode: 0},
+		StateFile:    statePath,
+		Reporter:     ui.NewReporter(&buf),
+	}
+
+	// When Run() is called
+	_, err := executor.Run([]string{"sync"})
+
+	// Then the warning should name the attempt without claiming a limit of 0
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "ignoring state file: invalid attempt 0")
+	assert.NotContains(t, buf.String(), "attempt limit")
+}
+
+func TestExecutor_ResumeWithElapsedWaitRunsImmediately(t *testing.T) {
+	// Given a state file whose next attempt was due an hour ago
+	statePath := filepath.Join(t.TempDir(), "retry.state")
+	require.NoError(t, SaveRetryState(statePath, RetryState{
+		CommandHash:   CommandHash([]string{"sync"}),
+		Attempt:       1,
+		NextAttemptAt: time.Now().Add(-time.Hour),
+	}))
+
+	executor := &Executor{
+		MaxAttempts: 3,
+		Runner:      &FakeCommandRunnerWithOutput{ExitCode: 0},
+		StateFile:   statePath,
+	}
+
+	// When Run() is called
+	start := time.Now()
+	result, err := executor.Run([]string{"sync"})
+
+	// Then it should resume at attempt 2 without waiting
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.AttemptCount)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestExecutor_StateFileKeepsNoArguments(t *testing.T) {
+	// Given a command whose arguments hold a secret
+	statePath := filepath.Join(t.TempDir(), "retry.state")
+	command := []string{"curl", "-H", "Authorization: Bearer s3cr3t-token", "https://api.example.com"}
+	executor := &Executor{StateFile: statePath}
+
+	// When its progress is saved
+	executor.saveState(command, 1, time.Minute)
+
+	// Then the state file identifies the command by hash alone
+	data, err := os.ReadFile(statePath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "s3cr3t-token")
+	assert.NotContains(t, string(data), "api.example.com")
+
+	state, err := LoadRetryState(statePath)
+	require.NoError(t, err)
+	assert.Equal(t, CommandHash(command), state.CommandHash)
+}
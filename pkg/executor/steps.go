@@ -0,0 +1,92 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// StepSeparator separates the steps of a command whose attempts run several
+// commands in sequence, as in "make build --and make test"
+const StepSeparator = "--and"
+
+// StepOutput is the output of one step of a multi-step attempt
+type StepOutput struct {
+	Command  []string
+	ExitCode int
+	Stdout   string
+	Stderr   string
+}
+
+// SplitSteps splits command at each StepSeparator. A command without one is a
+// single step; an empty step is an error.
+func SplitSteps(command []string) ([][]string, error) {
+	var steps [][]string
+	start := 0
+	for i := 0; i <= len(command); i++ {
+		if i < len(command) && command[i] != StepSeparator {
+			continue
+		}
+		if i == start {
+			return nil, fmt.Errorf("empty step in command: %q must separate two commands", StepSeparator)
+		}
+		steps = append(steps, command[start:i])
+		start = i + 1
+	}
+	return steps, nil
+}
+
+// executeSteps runs command's steps in sequence within one attempt, stopping at
+// the first that fails or times out. The attempt's output joins the outputs of
+// the steps that ran, its exit code and signal are the failing step's (0 if
+// all passed), and Steps keeps each step's own output.
+func (e *Executor) executeSteps(ctx context.Context, runner CommandRunner, command []string) (CommandOutput, error, bool) {
+	steps, err := SplitSteps(command)
+	if err != nil {
+		return CommandOutput{}, err, false
+	}
+
+	var combined CommandOutput
+	var stdout, stderr strings.Builder
+	for _, step := range steps {
+		output, err, timedOut := e.runCommand(ctx, runner, step)
+		if err != nil {
+			return CommandOutput{}, err, false
+		}
+		if timedOut {
+			return CommandOutput{ExitCode: -1, Stdout: stdout.String(), Stderr: stderr.String(), Steps: combined.Steps}, nil, true
+		}
+
+		stdout.WriteString(output.Stdout)
+		stderr.WriteString(output.Stderr)
+		combined.Usage = combined.Usage.add(output.Usage)
+		combined.Steps = append(combined.Steps, StepOutput{
+			Command:  step,
+			ExitCode: output.ExitCode,
+			Stdout:   output.Stdout,
+			Stderr:   output.Stderr,
+		})
+		if output.ExitCode != 0 {
+			combined.ExitCode = output.ExitCode
+			combined.Signal = output.Signal
+			break
+		}
+	}
+
+	combined.Stdout = stdout.String()
+	combined.Stderr = stderr.String()
+	return combined, nil, false
+}
+
+// stepExitCodes returns the exit codes of a multi-step attempt's steps, or nil
+// for a single command
+func (o CommandOutput) stepExitCodes() []int {
+	if len(o.Steps) == 0 {
+		return nil
+	}
+	codes := make([]int, len(o.Steps))
+	for i, step := range o.Steps {
+		codes[i] = step.ExitCode
+	}
+	return codes
+}
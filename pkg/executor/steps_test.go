@@ -0,0 +1,131 @@
+package executor
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scriptedStepRunner records each command it runs and answers with the next
+// output scripted for that command's name
+type scriptedStepRunner struct {
+	outputs  map[string][]CommandOutput
+	commands []string
+}
+
+func (r *scriptedStepRunner) Run(command []string) (int, error) {
+	output, err := r.RunWithOutput(command)
+	return output.ExitCode, err
+}
+
+func (r *scriptedStepRunner) RunWithContext(ctx context.Context, command []string) (int, error) {
+	return r.Run(command)
+}
+
+func (r *scriptedStepRunner) RunWithOutput(command []string) (CommandOutput, error) {
+	name := strings.Join(command, " ")
+	r.commands = append(r.commands, name)
+	outputs := r.outputs[name]
+	output := outputs[0]
+	if len(outputs) > 1 {
+		r.outputs[name] = outputs[1:]
+	}
+	return output, nil
+}
+
+func (r *scriptedStepRunner) RunWithOutputAndContext(ctx context.Context, command []string) (CommandOutput, error) {
+	return r.RunWithOutput(command)
+}
+
+func TestSplitSteps(t *testing.T) {
+	tests := []struct {
+		name        string
+		command     []string
+		expected    [][]string
+		errContains string
+	}{
+		{name: "single command", command: []string{"make", "build"}, expected: [][]string{{"make", "build"}}},
+		{name: "two steps", command: []string{"make", "build", "--and", "make", "test"}, expected: [][]string{{"make", "build"}, {"make", "test"}}},
+		{name: "leading separator", command: []string{"--and", "make"}, errContains: "empty step"},
+		{name: "trailing separator", command: []string{"make", "--and"}, errContains: "empty step"},
+		{name: "doubled separator", command: []string{"make", "--and", "--and", "test"}, errContains: "empty step"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// When splitting the command
+			steps, err := SplitSteps(tt.command)
+
+			// Then it should produce the steps or reject empty ones
+			if tt.errContains != "" {
+				assert.ErrorContains(t, err, tt.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, steps)
+		})
+	}
+}
+
+func TestExecutor_StepsRetryWholeSequence(t *testing.T) {
+	// Given two steps where the second fails once, then passes
+	runner := &scriptedStepRunner{outputs: map[string][]CommandOutput{
+		"migrate": {{ExitCode: 0, Stdout: "migrated\n"}},
+		"smoke":   {{ExitCode: 3, Stderr: "smoke failed\n"}, {ExitCode: 0, Stdout: "smoke ok\n"}},
+	}}
+	executor := &Executor{MaxAttempts: 3, Runner: runner, Steps: true}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"migrate", StepSeparator, "smoke"})
+
+	// Then both steps should run again on the retry and the run succeed
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, 2, result.AttemptCount)
+	assert.Equal(t, []string{"migrate", "smoke", "migrate", "smoke"}, runner.commands)
+
+	// And each attempt's metrics should record its steps' exit codes
+	require.Len(t, result.Metrics.Attempts, 2)
+	assert.Equal(t, 3, result.Metrics.Attempts[0].ExitCode)
+	assert.Equal(t, []int{0, 3}, result.Metrics.Attempts[0].StepExitCodes)
+	assert.Equal(t, []int{0, 0}, result.Metrics.Attempts[1].StepExitCodes)
+}
+
+func TestExecutor_StepsCapturePerStepOutput(t *testing.T) {
+	// Given three steps where the second fails
+	runner := &scriptedStepRunner{outputs: map[string][]CommandOutput{
+		"build":  {{ExitCode: 0, Stdout: "built\n"}},
+		"test":   {{ExitCode: 1, Stdout: "1 failed\n", Stderr: "FAIL\n"}},
+		"deploy": {{ExitCode: 0}},
+	}}
+	executor := &Executor{Runner: runner, Steps: true}
+
+	// When one attempt runs
+	output, err, timedOut := executor.executeAttempt(1, []string{"build", StepSeparator, "test", StepSeparator, "deploy"})
+
+	// Then it should stop at the failing step, joining the outputs that ran
+	require.NoError(t, err)
+	assert.False(t, timedOut)
+	assert.Equal(t, []string{"build", "test"}, runner.commands)
+	assert.Equal(t, 1, output.ExitCode)
+	assert.Equal(t, "built\n1 failed\n", output.Stdout)
+	assert.Equal(t, "FAIL\n", output.Stderr)
+	assert.Equal(t, []StepOutput{
+		{Command: []string{"build"}, ExitCode: 0, Stdout: "built\n"},
+		{Command: []string{"test"}, ExitCode: 1, Stdout: "1 failed\n", Stderr: "FAIL\n"},
+	}, output.Steps)
+}
+
+func TestExecutor_StepsRejectEmptyStep(t *testing.T) {
+	// Given a command ending in a separator
+	executor := &Executor{MaxAttempts: 3, Runner: &FakeCommandRunner{}, Steps: true}
+
+	// When Run() is called
+	_, err := executor.Run([]string{"make", StepSeparator})
+
+	// Then it should fail before running anything
+	assert.ErrorContains(t, err, "empty step")
+}
@@ -0,0 +1,94 @@
+package executor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shaneisley/patience/pkg/backoff"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// clockAdvancingRunner fails until its last call, each attempt taking duration
+// on the fake clock; starts records when each attempt began
+type clockAdvancingRunner struct {
+	clock    *fakeClock
+	duration time.Duration
+	failures int
+	calls    int
+	starts   []time.Time
+}
+
+func (r *clockAdvancingRunner) run() (CommandOutput, error) {
+	r.calls++
+	r.starts = append(r.starts, r.clock.now)
+	r.clock.now = r.clock.now.Add(r.duration)
+	if r.calls <= r.failures {
+		return CommandOutput{ExitCode: 1}, nil
+	}
+	return CommandOutput{ExitCode: 0}, nil
+}
+
+func (r *clockAdvancingRunner) Run(command []string) (int, error) {
+	output, err := r.run()
+	return output.ExitCode, err
+}
+
+func (r *clockAdvancingRunner) RunWithContext(ctx context.Context, command []string) (int, error) {
+	return r.Run(command)
+}
+
+func (r *clockAdvancingRunner) RunWithOutput(command []string) (CommandOutput, error) {
+	return r.run()
+}
+
+func (r *clockAdvancingRunner) RunWithOutputAndContext(ctx context.Context, command []string) (CommandOutput, error) {
+	return r.run()
+}
+
+func TestExecutor_TotalDelayAndExecutionSplit(t *testing.T) {
+	// Given attempts taking 3s each, with 10s between them and a success on the third
+	clock := &fakeClock{now: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)}
+	runner := &clockAdvancingRunner{clock: clock, duration: 3 * time.Second, failures: 2}
+	executor := &Executor{
+		MaxAttempts:     5,
+		Runner:          runner,
+		BackoffStrategy: backoff.NewFixed(10 * time.Second),
+		Clock:           clock,
+	}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"any", "command"})
+
+	// Then the delays and attempt durations should be summed separately
+	require.NoError(t, err)
+	require.True(t, result.Success)
+	assert.Equal(t, 20*time.Second, result.TotalDelay)
+	assert.Equal(t, 9*time.Second, result.TotalExecution)
+	require.NotNil(t, result.Metrics)
+	assert.Equal(t, 20.0, result.Metrics.TotalDelaySeconds)
+	assert.Equal(t, 9.0, result.Metrics.TotalExecutionSeconds)
+	assert.Equal(t, 29.0, result.Metrics.TotalDurationSeconds)
+}
+
+func TestExecutor_TotalDelayAndExecutionAddUp(t *testing.T) {
+	// Given real attempts and delays
+	executor := &Executor{
+		MaxAttempts:     3,
+		Runner:          &SystemCommandRunner{},
+		BackoffStrategy: backoff.NewFixed(30 * time.Millisecond),
+	}
+
+	// When Run() is called on a command that sleeps and fails
+	result, err := executor.Run([]string{"sh", "-c", "sleep 0.02; exit 1"})
+
+	// Then delay and execution time should account for the total, apart from overhead
+	require.ErrorIs(t, err, ErrMaxAttempts)
+	assert.Equal(t, 60*time.Millisecond, result.TotalDelay)
+	assert.GreaterOrEqual(t, result.TotalExecution, 60*time.Millisecond)
+	total := time.Duration(result.Metrics.TotalDurationSeconds * float64(time.Second))
+	split := result.TotalDelay + result.TotalExecution
+	assert.LessOrEqual(t, split, total)
+	assert.InDelta(t, total.Seconds(), split.Seconds(), 0.05)
+}
@@ -0,0 +1,37 @@
+package executor
+
+import (
+	"fmt"
+	"math"
+)
+
+// checkBounded refuses a run that could retry forever: unlimited attempts
+// (MaxAttempts 0) need MaxTotalTime, or MaxCost with a CostPerAttempt that
+// spends it, to end the run
+func (e *Executor) checkBounded() error {
+	costBounded := e.MaxCost > 0 && e.CostPerAttempt > 0
+	switch {
+	case e.MaxAttempts < 0:
+		return fmt.Errorf("max attempts must be non-negative, got %d", e.MaxAttempts)
+	case e.MaxAttempts == 0 && e.MaxTotalTime <= 0 && !costBounded:
+		return fmt.Errorf("%w: set a total time limit or a cost budget", ErrUnbounded)
+	}
+	return nil
+}
+
+// attemptLimit returns the number of attempts the run may make; unlimited
+// runs are bounded by time or cost instead
+func (e *Executor) attemptLimit() int {
+	if e.MaxAttempts == 0 {
+		return math.MaxInt
+	}
+	return e.MaxAttempts
+}
+
+// reportedLimit returns the attempt limit shown by the reporter, 0 when unlimited
+func reportedLimit(maxAttempts int) int {
+	if maxAttempts == math.MaxInt {
+		return 0
+	}
+	return maxAttempts
+}
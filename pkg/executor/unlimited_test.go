@@ -0,0 +1,101 @@
+package executor
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/shaneisley/patience/pkg/backoff"
+	"github.com/shaneisley/patience/pkg/ui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutor_UnlimitedWithoutBound(t *testing.T) {
+	// Given unlimited attempts and nothing else to stop the run
+	runner := &FakeCommandRunner{ExitCode: 1}
+	executor := &Executor{MaxAttempts: 0, Runner: runner, BackoffStrategy: backoff.NewFixed(time.Second)}
+
+	// When Run() is called
+	_, err := executor.Run([]string{"flaky"})
+
+	// Then it fails at startup with a clear error, before any attempt
+	require.ErrorIs(t, err, ErrUnbounded)
+	assert.Contains(t, err.Error(), "set a total time limit or a cost budget")
+	assert.Equal(t, 0, runner.CallCount)
+}
+
+func TestExecutor_UnlimitedWithFreeAttempts(t *testing.T) {
+	// Given unlimited attempts with a cost budget that free attempts never spend
+	runner := &FakeCommandRunner{ExitCode: 1}
+	executor := &Executor{MaxAttempts: 0, Runner: runner, MaxCost: 10}
+
+	// When Run() is called
+	_, err := executor.Run([]string{"flaky"})
+
+	// Then the budget does not count as a bound
+	require.ErrorIs(t, err, ErrUnbounded)
+	assert.Equal(t, 0, runner.CallCount)
+}
+
+func TestExecutor_UnlimitedWithTotalTime(t *testing.T) {
+	// Given unlimited attempts bounded by a 30s total time, 1s attempts and 5s delays
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	runner := &clockAdvancingRunner{clock: clock, duration: time.Second, failures: 1000}
+	var buf bytes.Buffer
+	executor := &Executor{
+		MaxAttempts:     0,
+		Runner:          runner,
+		BackoffStrategy: backoff.NewFixed(5 * time.Second),
+		MaxTotalTime:    30 * time.Second,
+		Clock:           clock,
+		Reporter:        ui.NewReporter(&buf),
+	}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"flaky"})
+
+	// Then it retries until the time limit stops it
+	require.ErrorIs(t, err, ErrTimeout)
+	assert.False(t, result.Success)
+	assert.Equal(t, ReasonMaxTotalTime, result.Reason)
+	assert.Equal(t, 6, runner.calls)
+	assert.Equal(t, 6, result.AttemptCount)
+
+	// And attempts are reported without a limit
+	assert.Contains(t, buf.String(), "[retry] Attempt 6 starting...")
+}
+
+func TestExecutor_UnlimitedWithCostBudget(t *testing.T) {
+	// Given unlimited attempts bounded by a cost budget for four attempts
+	runner := &FakeCommandRunner{ExitCode: 1}
+	executor := &Executor{
+		MaxAttempts:    0,
+		Runner:         runner,
+		CostPerAttempt: 0.25,
+		MaxCost:        1,
+	}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"flaky"})
+
+	// Then the budget ends the run
+	require.ErrorIs(t, err, ErrBudgetExceeded)
+	assert.Equal(t, ReasonCostBudgetExceeded, result.Reason)
+	assert.Equal(t, 4, runner.CallCount)
+}
+
+func TestExecutor_UnlimitedSucceeds(t *testing.T) {
+	// Given unlimited attempts with a time bound and a command that succeeds on attempt 3
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	runner := &clockAdvancingRunner{clock: clock, duration: time.Second, failures: 2}
+	executor := &Executor{MaxAttempts: 0, Runner: runner, MaxTotalTime: time.Hour, Clock: clock}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"flaky"})
+
+	// Then it stops at the first success
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, 3, result.AttemptCount)
+}
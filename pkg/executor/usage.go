@@ -0,0 +1,21 @@
+package executor
+
+import "time"
+
+// ResourceUsage is what a command used while it ran, as reported by the
+// operating system when it exits; fields are zero where it cannot be measured
+type ResourceUsage struct {
+	// PeakRSS is the command's peak resident set size in bytes
+	PeakRSS uint64
+	// CPUTime is the user plus system CPU time the command consumed
+	CPUTime time.Duration
+}
+
+// add combines the usage of steps run one after another: CPU time adds up and
+// the peak is the higher of the two
+func (u ResourceUsage) add(other ResourceUsage) ResourceUsage {
+	return ResourceUsage{
+		PeakRSS: max(u.PeakRSS, other.PeakRSS),
+		CPUTime: u.CPUTime + other.CPUTime,
+	}
+}
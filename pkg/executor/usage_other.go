@@ -0,0 +1,10 @@
+//go:build !unix
+
+package executor
+
+import "os"
+
+// processUsage is not supported on this platform and returns zero usage
+func processUsage(state *os.ProcessState) ResourceUsage {
+	return ResourceUsage{}
+}
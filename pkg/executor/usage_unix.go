@@ -0,0 +1,37 @@
+//go:build unix
+
+package executor
+
+import (
+	"os"
+	"runtime"
+	"syscall"
+)
+
+// processUsage returns the rusage that wait4 reported for an exited command,
+// or zero usage when it did not start
+func processUsage(state *os.ProcessState) ResourceUsage {
+	if state == nil {
+		return ResourceUsage{}
+	}
+	rusage, ok := state.SysUsage().(*syscall.Rusage)
+	if !ok || rusage == nil {
+		return ResourceUsage{}
+	}
+	return ResourceUsage{
+		PeakRSS: maxRSSBytes(int64(rusage.Maxrss)),
+		CPUTime: state.UserTime() + state.SystemTime(),
+	}
+}
+
+// maxRSSBytes converts ru_maxrss to bytes: Darwin reports bytes, the other
+// Unix systems kilobytes
+func maxRSSBytes(maxrss int64) uint64 {
+	if maxrss <= 0 {
+		return 0
+	}
+	if runtime.GOOS == "darwin" || runtime.GOOS == "ios" {
+		return uint64(maxrss)
+	}
+	return uint64(maxrss) * 1024
+}
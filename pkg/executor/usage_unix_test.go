@@ -0,0 +1,38 @@
+//go:build unix
+
+package executor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutor_RecordsResourceUsage(t *testing.T) {
+	// Given a command that keeps the CPU busy
+	executor := &Executor{MaxAttempts: 1, Runner: &SystemCommandRunner{}}
+	command := []string{"sh", "-c", "i=0; while [ $i -lt 200000 ]; do i=$((i+1)); done"}
+
+	// When it runs
+	result, err := executor.Run(command)
+
+	// Then the attempt records the CPU time and memory it used
+	require.NoError(t, err)
+	require.True(t, result.Success)
+	require.Len(t, result.Metrics.Attempts, 1)
+	attempt := result.Metrics.Attempts[0]
+	assert.Positive(t, attempt.CPUTime)
+	assert.Positive(t, attempt.PeakRSSBytes)
+	assert.Equal(t, attempt.CPUTime, result.Stats.CPUTime)
+	assert.Equal(t, attempt.PeakRSSBytes, result.Stats.PeakRSS)
+}
+
+func TestResourceUsage_AddSteps(t *testing.T) {
+	// Given the usage of two steps
+	first := ResourceUsage{PeakRSS: 4 << 20, CPUTime: 30}
+	second := ResourceUsage{PeakRSS: 2 << 20, CPUTime: 20}
+
+	// Then the attempt keeps the higher peak and the total CPU time
+	assert.Equal(t, ResourceUsage{PeakRSS: 4 << 20, CPUTime: 50}, first.add(second))
+}
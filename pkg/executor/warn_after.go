@@ -0,0 +1,27 @@
+package executor
+
+import (
+	"fmt"
+	"time"
+)
+
+// watchSlowAttempt warns once if an attempt is still running after WarnAfter,
+// without cancelling it. The returned function stops the timer and waits for a
+// warning already in progress, so the Reporter is never used concurrently
+// with the rest of the run.
+func (e *Executor) watchSlowAttempt(attempt int) func() {
+	if e.WarnAfter <= 0 {
+		return func() {}
+	}
+
+	fired := make(chan struct{})
+	timer := time.AfterFunc(e.WarnAfter, func() {
+		defer close(fired)
+		e.warn(fmt.Sprintf("attempt %d still running after %s", attempt, e.WarnAfter))
+	})
+	return func() {
+		if !timer.Stop() {
+			<-fired
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package executor
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/shaneisley/patience/pkg/ui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutor_WarnAfterWarnsWithoutCancelling(t *testing.T) {
+	// Given a command slower than warn-after but faster than the timeout
+	var buf bytes.Buffer
+	executor := NewExecutorWithTimeout(1, 2*time.Second)
+	executor.Reporter = ui.NewReporter(&buf)
+	executor.WarnAfter = 50 * time.Millisecond
+
+	// When Run() is called
+	result, err := executor.Run([]string{"sleep", "0.2"})
+
+	// Then the warning should fire and the attempt should still complete
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.False(t, result.TimedOut)
+	assert.Contains(t, buf.String(), "[warning] attempt 1 still running after 50ms")
+}
+
+func TestExecutor_WarnAfterQuietForFastAttempts(t *testing.T) {
+	// Given a command that finishes well within warn-after
+	var buf bytes.Buffer
+	executor := NewExecutor(1)
+	executor.Reporter = ui.NewReporter(&buf)
+	executor.WarnAfter = 5 * time.Second
+
+	// When Run() is called
+	result, err := executor.Run([]string{"true"})
+
+	// Then no warning should be shown
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.NotContains(t, buf.String(), "still running")
+}
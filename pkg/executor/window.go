@@ -0,0 +1,127 @@
+package executor
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ReasonMaxTotalTime is the final reason when the run would outlast MaxTotalTime
+const ReasonMaxTotalTime = "max total time exceeded"
+
+// AllowedWindow is a daily time window, such as 22:00-06:00, outside of which
+// no attempt starts. A window whose end is before its start spans midnight.
+type AllowedWindow struct {
+	Start, End time.Duration // offsets from midnight
+	Location   *time.Location
+	spec       string
+}
+
+// ParseAllowedWindow parses "HH:MM-HH:MM", optionally followed by a space and
+// an IANA time zone name ("22:00-06:00 Europe/London"); without one, times
+// are local
+func ParseAllowedWindow(spec string) (*AllowedWindow, error) {
+	trimmed := strings.TrimSpace(spec)
+	times, zone, _ := strings.Cut(trimmed, " ")
+	startText, endText, ok := strings.Cut(times, "-")
+	if !ok {
+		return nil, fmt.Errorf("invalid allowed window %q: expected HH:MM-HH:MM", spec)
+	}
+
+	start, err := parseClockTime(startText)
+	if err != nil {
+		return nil, fmt.Errorf("invalid allowed window %q: %w", spec, err)
+	}
+	end, err := parseClockTime(endText)
+	if err != nil {
+		return nil, fmt.Errorf("invalid allowed window %q: %w", spec, err)
+	}
+	if start == end {
+		return nil, fmt.Errorf("invalid allowed window %q: start and end must differ", spec)
+	}
+
+	location := time.Local
+	if zone = strings.TrimSpace(zone); zone != "" {
+		location, err = time.LoadLocation(zone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed window %q: unknown time zone %q", spec, zone)
+		}
+	}
+
+	return &AllowedWindow{Start: start, End: end, Location: location, spec: trimmed}, nil
+}
+
+// parseClockTime parses a 24-hour "HH:MM" into an offset from midnight
+func parseClockTime(text string) (time.Duration, error) {
+	parsed, err := time.Parse("15:04", strings.TrimSpace(text))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", text)
+	}
+	return time.Duration(parsed.Hour())*time.Hour + time.Duration(parsed.Minute())*time.Minute, nil
+}
+
+// String returns the window as it was given
+func (w *AllowedWindow) String() string {
+	if w.spec != "" {
+		return w.spec
+	}
+	return fmt.Sprintf("%02d:%02d-%02d:%02d", int(w.Start.Hours()), int(w.Start.Minutes())%60, int(w.End.Hours()), int(w.End.Minutes())%60)
+}
+
+// location returns the window's time zone, local time by default
+func (w *AllowedWindow) location() *time.Location {
+	if w.Location == nil {
+		return time.Local
+	}
+	return w.Location
+}
+
+// Contains reports whether t falls inside the window
+func (w *AllowedWindow) Contains(t time.Time) bool {
+	t = t.In(w.location())
+	offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second + time.Duration(t.Nanosecond())
+	if w.Start < w.End {
+		return offset >= w.Start && offset < w.End
+	}
+	return offset >= w.Start || offset < w.End
+}
+
+// Until returns how long after t the window next opens, or 0 when t is inside it
+func (w *AllowedWindow) Until(t time.Time) time.Duration {
+	if w.Contains(t) {
+		return 0
+	}
+	local := t.In(w.location())
+	opens := time.Date(local.Year(), local.Month(), local.Day(),
+		int(w.Start/time.Hour), int(w.Start%time.Hour/time.Minute), 0, 0, w.location())
+	if !opens.After(local) {
+		opens = time.Date(local.Year(), local.Month(), local.Day()+1,
+			int(w.Start/time.Hour), int(w.Start%time.Hour/time.Minute), 0, 0, w.location())
+	}
+	return opens.Sub(t)
+}
+
+// waitForWindow sleeps until AllowedWindow opens and returns how long it
+// waited, or returns ReasonMaxTotalTime without waiting when the window would
+// open after the run's deadline (zero for none)
+func (e *Executor) waitForWindow(attempt int, deadline time.Time) (time.Duration, string) {
+	if e.AllowedWindow == nil {
+		return 0, ""
+	}
+	clock := e.clock()
+	now := clock.Now()
+	wait := e.AllowedWindow.Until(now)
+	if wait <= 0 {
+		return 0, ""
+	}
+	if !deadline.IsZero() && now.Add(wait).After(deadline) {
+		return 0, ReasonMaxTotalTime
+	}
+
+	if e.Reporter != nil {
+		e.Reporter.ShowWaiting(wait, fmt.Sprintf("attempt %d is outside the allowed window %s", attempt, e.AllowedWindow))
+	}
+	clock.Sleep(wait)
+	return wait, ""
+}
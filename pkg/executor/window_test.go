@@ -0,0 +1,166 @@
+package executor
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/shaneisley/patience/pkg/backoff"
+	"github.com/shaneisley/patience/pkg/ui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a Clock whose time only moves when it sleeps
+type fakeClock struct {
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Sleep(d time.Duration) {
+	c.sleeps = append(c.sleeps, d)
+	c.now = c.now.Add(d)
+}
+
+func TestParseAllowedWindow(t *testing.T) {
+	tests := []struct {
+		name        string
+		spec        string
+		start, end  time.Duration
+		zone        string
+		errContains string
+	}{
+		{name: "same day", spec: "09:00-17:30", start: 9 * time.Hour, end: 17*time.Hour + 30*time.Minute, zone: "Local"},
+		{name: "spans midnight", spec: "22:00-06:00", start: 22 * time.Hour, end: 6 * time.Hour, zone: "Local"},
+		{name: "time zone", spec: "22:00-06:00 UTC", start: 22 * time.Hour, end: 6 * time.Hour, zone: "UTC"},
+		{name: "missing end", spec: "22:00", errContains: "expected HH:MM-HH:MM"},
+		{name: "bad time", spec: "25:00-06:00", errContains: "invalid time"},
+		{name: "empty window", spec: "06:00-06:00", errContains: "start and end must differ"},
+		{name: "unknown zone", spec: "22:00-06:00 Mars/Olympus", errContains: "unknown time zone"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// When parsing the window
+			window, err := ParseAllowedWindow(tt.spec)
+
+			// Then it should match the expected bounds or be rejected
+			if tt.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.start, window.Start)
+			assert.Equal(t, tt.end, window.End)
+			assert.Equal(t, tt.zone, window.Location.String())
+			assert.Equal(t, tt.spec, window.String())
+		})
+	}
+}
+
+func TestAllowedWindow_Until(t *testing.T) {
+	window, err := ParseAllowedWindow("22:00-06:00 UTC")
+	require.NoError(t, err)
+	at := func(hour, minute int) time.Time { return time.Date(2024, 3, 1, hour, minute, 0, 0, time.UTC) }
+
+	// Inside the window, on either side of midnight, there is no wait
+	assert.Equal(t, time.Duration(0), window.Until(at(23, 0)))
+	assert.Equal(t, time.Duration(0), window.Until(at(5, 59)))
+
+	// Outside it, the wait runs to the next opening
+	assert.Equal(t, 30*time.Minute, window.Until(at(21, 30)))
+	assert.Equal(t, 16*time.Hour, window.Until(at(6, 0)))
+
+	// Times in other zones are converted first
+	assert.Equal(t, time.Duration(0), window.Until(at(23, 0).In(time.FixedZone("UTC+5", 5*60*60))))
+}
+
+func TestExecutor_AllowedWindowWaitsOutside(t *testing.T) {
+	// Given a clock at 20:00 and a 22:00-06:00 window
+	window, err := ParseAllowedWindow("22:00-06:00 UTC")
+	require.NoError(t, err)
+	clock := &fakeClock{now: time.Date(2024, 3, 1, 20, 0, 0, 0, time.UTC)}
+	runner := &FakeCommandRunner{ExitCode: 0}
+	var buf bytes.Buffer
+	executor := &Executor{MaxAttempts: 3, Runner: runner, AllowedWindow: window, Clock: clock, Reporter: ui.NewReporter(&buf)}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"nightly-job"})
+
+	// Then it should sleep until the window opens and then run the command
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, []time.Duration{2 * time.Hour}, clock.sleeps)
+	assert.Equal(t, 1, runner.CallCount)
+	assert.Contains(t, buf.String(), "attempt 1 is outside the allowed window 22:00-06:00 UTC (waiting 2h)")
+}
+
+func TestExecutor_AllowedWindowProceedsInside(t *testing.T) {
+	// Given a clock at 23:00 inside a 22:00-06:00 window
+	window, err := ParseAllowedWindow("22:00-06:00 UTC")
+	require.NoError(t, err)
+	clock := &fakeClock{now: time.Date(2024, 3, 1, 23, 0, 0, 0, time.UTC)}
+	runner := &FakeCommandRunner{ExitCode: 0}
+	executor := &Executor{MaxAttempts: 3, Runner: runner, AllowedWindow: window, Clock: clock}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"nightly-job"})
+
+	// Then the attempt should start without waiting
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Empty(t, clock.sleeps)
+	assert.Equal(t, 1, runner.CallCount)
+}
+
+func TestExecutor_AllowedWindowRespectsMaxTotalTime(t *testing.T) {
+	// Given a retry that would fall after the window closes, and a total time limit
+	window, err := ParseAllowedWindow("22:00-06:00 UTC")
+	require.NoError(t, err)
+	clock := &fakeClock{now: time.Date(2024, 3, 1, 5, 59, 0, 0, time.UTC)}
+	runner := &FakeCommandRunner{ExitCode: 1}
+	executor := &Executor{
+		MaxAttempts:     3,
+		Runner:          runner,
+		BackoffStrategy: backoff.NewFixed(2 * time.Minute),
+		AllowedWindow:   window,
+		MaxTotalTime:    time.Hour,
+		Clock:           clock,
+	}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"nightly-job"})
+
+	// Then it should stop instead of waiting most of a day for the next window
+	require.ErrorIs(t, err, ErrTimeout)
+	assert.False(t, result.Success)
+	assert.Equal(t, ReasonMaxTotalTime, result.Reason)
+	assert.Equal(t, FailureTimeout, result.Kind)
+	assert.Equal(t, 1, runner.CallCount)
+	assert.Equal(t, []time.Duration{2 * time.Minute}, clock.sleeps)
+}
+
+func TestExecutor_MaxTotalTimeStopsBeforeLongDelay(t *testing.T) {
+	// Given a delay longer than the remaining total time
+	clock := &fakeClock{now: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)}
+	runner := &FakeCommandRunner{ExitCode: 1}
+	executor := &Executor{
+		MaxAttempts:     5,
+		Runner:          runner,
+		BackoffStrategy: backoff.NewFixed(10 * time.Minute),
+		MaxTotalTime:    15 * time.Minute,
+		Clock:           clock,
+	}
+
+	// When Run() is called
+	result, err := executor.Run([]string{"any", "command"})
+
+	// Then it should stop after the second attempt rather than wait past the limit
+	require.ErrorIs(t, err, ErrTimeout)
+	assert.Equal(t, ReasonMaxTotalTime, result.Reason)
+	assert.Equal(t, 2, runner.CallCount)
+	assert.ErrorIs(t, result.Err(), ErrTimeout)
+}
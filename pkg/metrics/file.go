@@ -0,0 +1,28 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// AppendFile appends m to the NDJSON file at path as one line, creating the
+// file if needed, in the format Replay reads back
+func AppendFile(path string, m *RunMetrics) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("failed to encode metrics: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open metrics file: %w", err)
+	}
+	// A single write keeps concurrent runs appending to the file from
+	// interleaving within a line
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write metrics file: %w", err)
+	}
+	return f.Close()
+}
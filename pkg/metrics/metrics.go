@@ -1,19 +1,40 @@
 package metrics
 
 import (
+	"bytes"
+	"compress/gzip"
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
+	"io"
+	"math"
 	"net"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
 
+// tagKeyPattern restricts tag keys to identifier-like names
+var tagKeyPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_.-]*$`)
+
 // AttemptMetric represents metrics for a single command attempt
 type AttemptMetric struct {
 	Duration time.Duration `json:"-"`
 	ExitCode int           `json:"exit_code"`
 	Success  bool          `json:"success"`
+
+	// StepExitCodes are the exit codes of the steps an attempt of several
+	// sequential commands ran, stopping at the first failure
+	StepExitCodes []int `json:"step_exit_codes,omitempty"`
+
+	// Signal names the signal that terminated the attempt, such as "SIGKILL"
+	Signal string `json:"signal,omitempty"`
+
+	// PeakRSSBytes is the command's peak resident memory and CPUTime the CPU
+	// time it consumed; both are zero where the platform cannot measure them
+	PeakRSSBytes uint64        `json:"peak_rss_bytes,omitempty"`
+	CPUTime      time.Duration `json:"-"`
 }
 
 // DurationSeconds returns the duration in seconds as a float64
@@ -26,13 +47,34 @@ func (a *AttemptMetric) MarshalJSON() ([]byte, error) {
 	type Alias AttemptMetric
 	return json.Marshal(&struct {
 		DurationSeconds float64 `json:"duration_seconds"`
+		CPUSeconds      float64 `json:"cpu_seconds,omitempty"`
 		*Alias
 	}{
 		DurationSeconds: a.DurationSeconds(),
+		CPUSeconds:      a.CPUTime.Seconds(),
 		Alias:           (*Alias)(a),
 	})
 }
 
+// UnmarshalJSON restores Duration and CPUTime from the duration_seconds and
+// cpu_seconds written by MarshalJSON
+func (a *AttemptMetric) UnmarshalJSON(data []byte) error {
+	type Alias AttemptMetric
+	aux := &struct {
+		DurationSeconds float64 `json:"duration_seconds"`
+		CPUSeconds      float64 `json:"cpu_seconds"`
+		*Alias
+	}{
+		Alias: (*Alias)(a),
+	}
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+	a.Duration = time.Duration(aux.DurationSeconds * float64(time.Second))
+	a.CPUTime = time.Duration(aux.CPUSeconds * float64(time.Second))
+	return nil
+}
+
 // RunMetrics represents metrics for a complete retry run
 type RunMetrics struct {
 	Command              string          `json:"command"`
@@ -44,6 +86,139 @@ type RunMetrics struct {
 	FailedAttempts       int             `json:"failed_attempts"`
 	Attempts             []AttemptMetric `json:"attempts"`
 	Timestamp            int64           `json:"timestamp"` // Unix timestamp
+
+	// TotalDelaySeconds is the time spent waiting between attempts and
+	// TotalExecutionSeconds the time spent in attempts; together they make up
+	// TotalDurationSeconds apart from patience's own overhead
+	TotalDelaySeconds     float64 `json:"total_delay_seconds,omitempty"`
+	TotalExecutionSeconds float64 `json:"total_execution_seconds,omitempty"`
+
+	// Tags are operator-supplied labels (e.g. env=prod) for slicing metrics
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// CustomMetrics are operator-supplied numeric fields (e.g. build_number=1234)
+	CustomMetrics map[string]float64 `json:"custom_metrics,omitempty"`
+
+	// RateLimit is the most informative rate limit discovered in the command's output
+	RateLimit *RateLimitSummary `json:"rate_limit,omitempty"`
+
+	// FailureKind says why a failed run stopped, e.g. "max_attempts",
+	// "failure_pattern" or "fatal_pattern"
+	FailureKind string `json:"failure_kind,omitempty"`
+
+	// FailureCategory is the operator-defined class of a failed run, such as
+	// "auth", from the first --failure-category that matched it
+	FailureCategory string `json:"failure_category,omitempty"`
+}
+
+// Category returns the class of a failed run for alerting: its
+// FailureCategory, else its FailureKind, else "unknown". Successful runs have
+// none.
+func (m *RunMetrics) Category() string {
+	switch {
+	case m.FinalStatus == "succeeded":
+		return ""
+	case m.FailureCategory != "":
+		return m.FailureCategory
+	case m.FailureKind != "":
+		return m.FailureKind
+	default:
+		return "unknown"
+	}
+}
+
+// RateLimitSummary describes rate limit information reported by the server
+// during a run, as found by header or JSON body discovery
+type RateLimitSummary struct {
+	Limit      int     `json:"limit"`
+	Remaining  int     `json:"remaining"`
+	ResetUnix  int64   `json:"reset_unix,omitempty"` // When the window resets (0 if unknown)
+	Source     string  `json:"source"`               // http_header or json_body
+	Confidence float64 `json:"confidence"`           // 0.0-1.0
+}
+
+// ParseTag parses a key=value tag. Keys start with a letter and contain only
+// letters, digits, '_', '.' or '-'; values must be non-empty and contain no
+// whitespace or commas.
+func ParseTag(expr string) (string, string, error) {
+	key, value, found := strings.Cut(expr, "=")
+	if !found {
+		return "", "", fmt.Errorf("invalid tag %q: expected key=value", expr)
+	}
+	if !tagKeyPattern.MatchString(key) {
+		return "", "", fmt.Errorf("invalid tag %q: key must start with a letter and contain only letters, digits, '_', '.' or '-'", expr)
+	}
+	if value == "" || strings.ContainsAny(value, ", \t\n\r") {
+		return "", "", fmt.Errorf("invalid tag %q: value must be non-empty without whitespace or commas", expr)
+	}
+	return key, value, nil
+}
+
+// ParseTags parses repeated key=value tags, rejecting duplicate keys
+func ParseTags(exprs []string) (map[string]string, error) {
+	if len(exprs) == 0 {
+		return nil, nil
+	}
+
+	tags := make(map[string]string, len(exprs))
+	for _, expr := range exprs {
+		key, value, err := ParseTag(expr)
+		if err != nil {
+			return nil, err
+		}
+		if _, exists := tags[key]; exists {
+			return nil, fmt.Errorf("duplicate tag key %q", key)
+		}
+		tags[key] = value
+	}
+	return tags, nil
+}
+
+// ParseMetric parses a key=number custom metric. Keys follow the rules for tag
+// keys; values must be finite numbers.
+func ParseMetric(expr string) (string, float64, error) {
+	key, text, found := strings.Cut(expr, "=")
+	if !found {
+		return "", 0, fmt.Errorf("invalid metric %q: expected key=number", expr)
+	}
+	if !tagKeyPattern.MatchString(key) {
+		return "", 0, fmt.Errorf("invalid metric %q: key must start with a letter and contain only letters, digits, '_', '.' or '-'", expr)
+	}
+	value, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return "", 0, fmt.Errorf("invalid metric %q: value must be a finite number", expr)
+	}
+	return key, value, nil
+}
+
+// ParseMetrics parses repeated key=number custom metrics, rejecting duplicate keys
+func ParseMetrics(exprs []string) (map[string]float64, error) {
+	if len(exprs) == 0 {
+		return nil, nil
+	}
+
+	values := make(map[string]float64, len(exprs))
+	for _, expr := range exprs {
+		key, value, err := ParseMetric(expr)
+		if err != nil {
+			return nil, err
+		}
+		if _, exists := values[key]; exists {
+			return nil, fmt.Errorf("duplicate metric key %q", key)
+		}
+		values[key] = value
+	}
+	return values, nil
+}
+
+// HasTags reports whether the run carries every key=value pair in filter
+func (r *RunMetrics) HasTags(filter map[string]string) bool {
+	for key, value := range filter {
+		if r.Tags[key] != value {
+			return false
+		}
+	}
+	return true
 }
 
 // NewRunMetrics creates a new RunMetrics instance
@@ -107,6 +282,61 @@ func DefaultSocketPath() string {
 	return "/tmp/retryd.sock"
 }
 
+// Socket payload framing. Older clients send bare JSON; larger payloads are now
+// gzip-compressed behind a one-byte version prefix. JSON never starts with the
+// prefix byte, so the daemon can tell the two formats apart.
+const (
+	payloadVersionGzip byte = 0x01
+
+	// CompressThreshold is the JSON size at which payloads are compressed
+	CompressThreshold = 1024
+
+	// MaxPayloadSize bounds the decompressed size of a payload
+	MaxPayloadSize = 16 << 20
+)
+
+// EncodePayload frames serialized metrics for the daemon socket, compressing
+// payloads of at least CompressThreshold bytes
+func EncodePayload(data []byte) ([]byte, error) {
+	if len(data) < CompressThreshold {
+		return data, nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte(payloadVersionGzip)
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to compress metrics: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress metrics: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// DecodePayload returns the JSON carried by a socket payload, accepting both
+// bare JSON and compressed payloads
+func DecodePayload(data []byte) ([]byte, error) {
+	if len(data) == 0 || data[0] != payloadVersionGzip {
+		return data, nil
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(data[1:]))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress metrics: %w", err)
+	}
+	defer zr.Close()
+
+	decoded, err := io.ReadAll(io.LimitReader(zr, MaxPayloadSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress metrics: %w", err)
+	}
+	if len(decoded) > MaxPayloadSize {
+		return nil, fmt.Errorf("decompressed metrics exceed %d bytes", MaxPayloadSize)
+	}
+	return decoded, nil
+}
+
 // SendMetrics sends metrics to the daemon synchronously
 func (c *Client) SendMetrics(metrics *RunMetrics) error {
 	// Serialize metrics to JSON
@@ -115,6 +345,11 @@ func (c *Client) SendMetrics(metrics *RunMetrics) error {
 		return fmt.Errorf("failed to marshal metrics: %w", err)
 	}
 
+	data, err = EncodePayload(data)
+	if err != nil {
+		return err
+	}
+
 	// Connect to Unix socket with timeout
 	conn, err := net.DialTimeout("unix", c.socketPath, c.timeout)
 	if err != nil {
@@ -98,6 +98,28 @@ func TestMetrics_JSONSerialization(t *testing.T) {
 	assert.Equal(t, metrics.FinalStatus, deserialized.FinalStatus)
 }
 
+func TestAttemptMetric_ResourceUsageJSON(t *testing.T) {
+	// Given an attempt that recorded its peak memory and CPU time
+	attempt := AttemptMetric{Duration: time.Second, PeakRSSBytes: 8 << 20, CPUTime: 750 * time.Millisecond}
+
+	// When serializing to JSON
+	data, err := json.Marshal(&attempt)
+
+	// Then both are written and read back
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"peak_rss_bytes":8388608`)
+	assert.Contains(t, string(data), `"cpu_seconds":0.75`)
+	var deserialized AttemptMetric
+	require.NoError(t, json.Unmarshal(data, &deserialized))
+	assert.Equal(t, attempt, deserialized)
+
+	// And an attempt without them omits both fields
+	data, err = json.Marshal(&AttemptMetric{Duration: time.Second})
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "peak_rss_bytes")
+	assert.NotContains(t, string(data), "cpu_seconds")
+}
+
 func TestClient_NewClient(t *testing.T) {
 	// When creating a new client
 	client := NewClient("/tmp/test-retryd.sock")
@@ -207,6 +229,42 @@ func TestClient_SendMetrics_WithMockDaemon(t *testing.T) {
 		t.Fatal("Timeout waiting for metrics data")
 	}
 }
+func TestEncodeDecodePayload(t *testing.T) {
+	// Given small and large serialized metrics
+	small := []byte(`{"command":"echo hi"}`)
+	attempts := make([]AttemptMetric, 400)
+	large, err := json.Marshal(&RunMetrics{Command: "big", Attempts: attempts})
+	require.NoError(t, err)
+
+	// When small payloads are encoded
+	encoded, err := EncodePayload(small)
+	require.NoError(t, err)
+
+	// Then they are sent as bare JSON, readable by older daemons
+	assert.Equal(t, small, encoded)
+
+	// When large payloads are encoded
+	encoded, err = EncodePayload(large)
+	require.NoError(t, err)
+
+	// Then they are compressed behind the version prefix
+	assert.Equal(t, payloadVersionGzip, encoded[0])
+	assert.Less(t, len(encoded), len(large))
+
+	// And both round-trip through DecodePayload
+	decoded, err := DecodePayload(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, large, decoded)
+
+	decoded, err = DecodePayload(small)
+	require.NoError(t, err)
+	assert.Equal(t, small, decoded)
+
+	// And a corrupt compressed payload is rejected
+	_, err = DecodePayload([]byte{payloadVersionGzip, 'n', 'o', 'p', 'e'})
+	assert.Error(t, err)
+}
+
 func TestClient_SendMetricsAsync_WithMockDaemon(t *testing.T) {
 	// Given a mock Unix socket server
 	socketPath := "/tmp/test-async-retryd-" + fmt.Sprintf("%d", time.Now().UnixNano()) + ".sock"
@@ -303,3 +361,79 @@ func TestAttemptMetric_Creation(t *testing.T) {
 	assert.Equal(t, 0, attempt.ExitCode)
 	assert.True(t, attempt.Success)
 }
+
+func TestParseTags(t *testing.T) {
+	// Given valid repeated tags
+	tags, err := ParseTags([]string{"env=prod", "team=payments", "region=eu-west-1"})
+
+	// Then they should be parsed into a map
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"env": "prod", "team": "payments", "region": "eu-west-1"}, tags)
+
+	// And no tags should yield a nil map
+	none, err := ParseTags(nil)
+	require.NoError(t, err)
+	assert.Nil(t, none)
+
+	// When tags are malformed or duplicated
+	invalid := [][]string{
+		{"env"},
+		{"=prod"},
+		{"1env=prod"},
+		{"env=prod staging"},
+		{"env=a,b"},
+		{"env="},
+		{"env=prod", "env=staging"},
+	}
+
+	// Then they should be rejected
+	for _, exprs := range invalid {
+		_, err := ParseTags(exprs)
+		assert.Error(t, err, "expected %v to be rejected", exprs)
+	}
+}
+
+func TestParseMetrics(t *testing.T) {
+	// Given valid repeated metrics
+	values, err := ParseMetrics([]string{"build_number=1234", "artifact_mb=12.5", "delta=-3"})
+
+	// Then they should be parsed into a map of numbers
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{"build_number": 1234, "artifact_mb": 12.5, "delta": -3}, values)
+
+	// And no metrics should yield a nil map
+	none, err := ParseMetrics(nil)
+	require.NoError(t, err)
+	assert.Nil(t, none)
+
+	// When metrics are malformed, non-numeric or duplicated
+	invalid := [][]string{
+		{"build_number"},
+		{"=12"},
+		{"1build=12"},
+		{"build_number=abc"},
+		{"build_number="},
+		{"build_number=NaN"},
+		{"build_number=Inf"},
+		{"build_number=1", "build_number=2"},
+	}
+
+	// Then they should be rejected
+	for _, exprs := range invalid {
+		_, err := ParseMetrics(exprs)
+		assert.Error(t, err, "expected %v to be rejected", exprs)
+	}
+}
+
+func TestRunMetrics_HasTags(t *testing.T) {
+	// Given run metrics with tags
+	metrics := NewRunMetrics([]string{"echo"}, true, time.Second, nil)
+	metrics.Tags = map[string]string{"env": "prod", "team": "payments"}
+
+	// Then filters should match only when every pair is present
+	assert.True(t, metrics.HasTags(nil))
+	assert.True(t, metrics.HasTags(map[string]string{"env": "prod"}))
+	assert.True(t, metrics.HasTags(map[string]string{"env": "prod", "team": "payments"}))
+	assert.False(t, metrics.HasTags(map[string]string{"env": "staging"}))
+	assert.False(t, metrics.HasTags(map[string]string{"owner": "ops"}))
+}
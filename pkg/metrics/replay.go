@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// maxReplayLineSize bounds a single NDJSON record read by Replay
+const maxReplayLineSize = MaxPayloadSize
+
+// ReplayResult counts the records Replay sent and the lines it skipped
+type ReplayResult struct {
+	Sent    int
+	Skipped int
+}
+
+// Replay reads run metrics from NDJSON, one record per line, and passes each
+// to send, so metrics written while the daemon was down can be backfilled.
+// Blank lines are ignored, and malformed lines and records without a command
+// or final status are skipped and counted; a send error stops the replay.
+func Replay(r io.Reader, send func(*RunMetrics) error) (ReplayResult, error) {
+	var result ReplayResult
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxReplayLineSize)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var record RunMetrics
+		if err := json.Unmarshal([]byte(text), &record); err != nil || record.Command == "" || record.FinalStatus == "" {
+			result.Skipped++
+			continue
+		}
+		if err := send(&record); err != nil {
+			return result, fmt.Errorf("line %d: %w", line, err)
+		}
+		result.Sent++
+	}
+	if err := scanner.Err(); err != nil {
+		return result, fmt.Errorf("failed to read metrics: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplay_SkipsMalformedLines(t *testing.T) {
+	// Given NDJSON with two records, a blank line and three malformed lines,
+	// the last one a run summary rather than run metrics
+	input := strings.Join([]string{
+		`{"command":"curl a","final_status":"succeeded","total_attempts":1,"attempts":[{"duration_seconds":1.5,"exit_code":0,"success":true}],"timestamp":1700000000}`,
+		``,
+		`{not json`,
+		`{"final_status":"failed"}`,
+		`{"command":"curl x","success":false,"exit_code":1}`,
+		`{"command":"curl b","final_status":"failed","total_attempts":2,"timestamp":1700000060}`,
+	}, "\n")
+
+	// When replaying them
+	var sent []*RunMetrics
+	result, err := Replay(strings.NewReader(input), func(m *RunMetrics) error {
+		sent = append(sent, m)
+		return nil
+	})
+
+	// Then the valid records are sent in order and the malformed ones counted
+	require.NoError(t, err)
+	assert.Equal(t, ReplayResult{Sent: 2, Skipped: 3}, result)
+	require.Len(t, sent, 2)
+	assert.Equal(t, "curl a", sent[0].Command)
+	assert.Equal(t, int64(1700000000), sent[0].Timestamp)
+	assert.Equal(t, 1500*time.Millisecond, sent[0].Attempts[0].Duration)
+	assert.Equal(t, "curl b", sent[1].Command)
+}
+
+func TestReplay_ReadsAppendedFile(t *testing.T) {
+	// Given two runs appended to a metrics file
+	path := filepath.Join(t.TempDir(), "runs.ndjson")
+	first := &RunMetrics{Command: "curl a", FinalStatus: "succeeded", TotalAttempts: 1, Timestamp: 1700000000,
+		Attempts: []AttemptMetric{{Duration: 2 * time.Second, ExitCode: 0, Success: true}}}
+	second := &RunMetrics{Command: "curl b", FinalStatus: "failed", TotalAttempts: 3, Timestamp: 1700000060}
+	require.NoError(t, AppendFile(path, first))
+	require.NoError(t, AppendFile(path, second))
+
+	// When replaying the file
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	var sent []*RunMetrics
+	result, err := Replay(f, func(m *RunMetrics) error {
+		sent = append(sent, m)
+		return nil
+	})
+
+	// Then both runs come back as written
+	require.NoError(t, err)
+	assert.Equal(t, ReplayResult{Sent: 2}, result)
+	assert.Equal(t, []*RunMetrics{first, second}, sent)
+}
+
+func TestReplay_StopsOnSendError(t *testing.T) {
+	// Given two records and a daemon that is unreachable
+	input := `{"command":"a","final_status":"failed"}` + "\n" + `{"command":"b","final_status":"failed"}` + "\n"
+
+	// When replaying them
+	result, err := Replay(strings.NewReader(input), func(*RunMetrics) error {
+		return errors.New("failed to connect to daemon")
+	})
+
+	// Then the replay stops at the first record
+	assert.ErrorContains(t, err, "line 1: failed to connect to daemon")
+	assert.Equal(t, 0, result.Sent)
+}
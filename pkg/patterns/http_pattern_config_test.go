@@ -324,3 +324,112 @@ func TestHTTPPatternConfig_Integration(t *testing.T) {
 		})
 	}
 }
+
+func TestHTTPPatternConfig_CustomStatusTypes(t *testing.T) {
+	config := DefaultHTTPPatternConfig()
+	config.StatusTypes = map[int]string{
+		418: "rate_limit",
+		200: "retryable",
+	}
+
+	matcher, err := NewHTTPPatternMatcher(config)
+	if err != nil {
+		t.Fatalf("NewHTTPPatternMatcher() with status types error = %v", err)
+	}
+
+	tests := []struct {
+		name             string
+		response         *HTTPResponse
+		expectedType     string
+		expectedStrategy string
+		expectedDelay    time.Duration
+	}{
+		{
+			name:             "418 mapped to rate limit",
+			response:         &HTTPResponse{StatusCode: 418, Body: `{"error": "slow down"}`},
+			expectedType:     "rate_limit",
+			expectedStrategy: "fixed",
+			expectedDelay:    60 * time.Second,
+		},
+		{
+			name:             "200 with error body mapped to retryable",
+			response:         &HTTPResponse{StatusCode: 200, Body: `{"error": "try again"}`},
+			expectedType:     "retryable",
+			expectedStrategy: "exponential",
+			expectedDelay:    1 * time.Second,
+		},
+		{
+			name:             "unmapped status keeps built-in type",
+			response:         &HTTPResponse{StatusCode: 500, Body: `{"error": "boom"}`},
+			expectedType:     "server_error",
+			expectedStrategy: "exponential",
+			expectedDelay:    1 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := matcher.MatchHTTPResponse(tt.response)
+			if err != nil {
+				t.Fatalf("MatchHTTPResponse() error = %v", err)
+			}
+
+			if result.PatternType != tt.expectedType {
+				t.Errorf("MatchHTTPResponse() pattern type = %s, want %s", result.PatternType, tt.expectedType)
+			}
+
+			recommendation := matcher.GetBackoffRecommendation(result)
+			if recommendation.Strategy != tt.expectedStrategy {
+				t.Errorf("GetBackoffRecommendation() strategy = %s, want %s", recommendation.Strategy, tt.expectedStrategy)
+			}
+			if recommendation.InitialDelay != tt.expectedDelay {
+				t.Errorf("GetBackoffRecommendation() initial delay = %v, want %v", recommendation.InitialDelay, tt.expectedDelay)
+			}
+		})
+	}
+}
+
+func TestHTTPPatternConfig_InvalidStatusTypes(t *testing.T) {
+	tests := []struct {
+		name        string
+		statusTypes map[int]string
+	}{
+		{name: "unknown pattern type", statusTypes: map[int]string{418: "teapot"}},
+		{name: "status code out of range", statusTypes: map[int]string{42: "rate_limit"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultHTTPPatternConfig()
+			config.StatusTypes = tt.statusTypes
+
+			if _, err := NewHTTPPatternMatcher(config); err == nil {
+				t.Error("NewHTTPPatternMatcher() expected error for invalid status types")
+			}
+		})
+	}
+}
+
+func TestHTTPPatternConfig_LoadStatusTypesFromYAML(t *testing.T) {
+	yamlConfig := `
+enable_status_routing: false
+enable_header_routing: false
+enable_api_detection: false
+status_types:
+  418: "rate_limit"
+  200: "retryable"
+`
+
+	config, err := LoadHTTPPatternConfigFromYAML([]byte(yamlConfig))
+	if err != nil {
+		t.Fatalf("LoadHTTPPatternConfigFromYAML() error = %v", err)
+	}
+
+	if config.StatusTypes[418] != "rate_limit" {
+		t.Errorf("LoadHTTPPatternConfigFromYAML() status type for 418 = %q, want rate_limit", config.StatusTypes[418])
+	}
+
+	if _, err := LoadHTTPPatternConfigFromYAML([]byte("status_types:\n  418: \"teapot\"\n")); err == nil {
+		t.Error("LoadHTTPPatternConfigFromYAML() expected error for unknown pattern type")
+	}
+}
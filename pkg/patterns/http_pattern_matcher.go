@@ -17,6 +17,9 @@ type HTTPResponse struct {
 	Headers    map[string]string `json:"headers"`
 	Body       string            `json:"body"`
 	URL        string            `json:"url,omitempty"`
+
+	// Trailers holds a header block sent after the body (HTTP/2, gRPC-Web)
+	Trailers map[string]string `json:"trailers,omitempty"`
 }
 
 // HTTPMatchResult represents the result of HTTP pattern matching
@@ -49,6 +52,19 @@ type HTTPPatternConfig struct {
 	HeaderPatterns      map[string]string    `json:"header_patterns" yaml:"header_patterns"`
 	APIPatterns         map[APIType][]string `json:"api_patterns" yaml:"api_patterns"`
 	DefaultPattern      string               `json:"default_pattern" yaml:"default_pattern"`
+	// StatusTypes overrides the built-in status code to pattern type mapping
+	// (e.g. 418: rate_limit) used for backoff recommendations
+	StatusTypes map[int]string `json:"status_types,omitempty" yaml:"status_types,omitempty"`
+}
+
+// validPatternTypes lists the pattern types a status code may be mapped to
+var validPatternTypes = map[string]bool{
+	"success":      true,
+	"retryable":    true,
+	"rate_limit":   true,
+	"server_error": true,
+	"auth_error":   true,
+	"client_error": true,
 }
 
 // BackoffRecommendation represents a recommended backoff strategy
@@ -74,8 +90,13 @@ type HTTPPatternMatcher struct {
 	statusMatchers map[int]PatternMatcher
 	apiDetector    APIDetector
 	metrics        MatchMetrics
+	extractors     []ContextExtractor // Run after the built-in context extraction
 }
 
+// ContextExtractor adds custom entries, such as a trace ID header, to the
+// context of a matched response
+type ContextExtractor func(response *HTTPResponse, result *HTTPMatchResult)
+
 // APIDetector interface for detecting API types
 type APIDetector interface {
 	DetectAPI(response *HTTPResponse) APIType
@@ -163,6 +184,9 @@ func NewHTTPPatternMatcher(config HTTPPatternConfig) (*HTTPPatternMatcher, error
 	if config.EnableStatusRouting && len(config.StatusPatterns) == 0 {
 		return nil, NewPatternError("config_error", "status routing enabled but no status patterns provided", "")
 	}
+	if err := validateStatusTypes(config.StatusTypes); err != nil {
+		return nil, NewPatternError("config_error", err.Error(), "")
+	}
 
 	// Create status matchers
 	statusMatchers := make(map[int]PatternMatcher)
@@ -193,6 +217,15 @@ func NewHTTPPatternMatcher(config HTTPPatternConfig) (*HTTPPatternMatcher, error
 	}, nil
 }
 
+// RegisterContextExtractor adds an extractor run on every matched response
+// after the built-in context extraction, in registration order, so it can
+// read or extend result.Context. Register extractors before matching starts.
+func (h *HTTPPatternMatcher) RegisterContextExtractor(extractor ContextExtractor) {
+	if extractor != nil {
+		h.extractors = append(h.extractors, extractor)
+	}
+}
+
 // MatchHTTPResponse matches an HTTP response against configured patterns
 func (h *HTTPPatternMatcher) MatchHTTPResponse(response *HTTPResponse) (*HTTPMatchResult, error) {
 	start := time.Now()
@@ -465,13 +498,103 @@ func ParseHTTPResponse(rawResponse string) (*HTTPResponse, error) {
 		return nil, err
 	}
 
+	// A trailing header block is not part of the body. Retry hints found only in
+	// the trailers are promoted so header-based consumers see them.
+	trailers := ExtractTrailers(rawResponse)
+	if len(trailers) > 0 {
+		body = stripTrailerBlock(body)
+		if _, ok := lookupHeader(headers, "Retry-After"); !ok {
+			if value, ok := lookupHeader(trailers, "Retry-After"); ok {
+				headers["Retry-After"] = value
+			}
+		}
+	}
+
 	return &HTTPResponse{
 		StatusCode: statusCode,
 		Headers:    headers,
 		Body:       body,
+		Trailers:   trailers,
 	}, nil
 }
 
+// trailerLinePattern matches a "Name: value" header field line
+var trailerLinePattern = regexp.MustCompile("^[A-Za-z0-9!#$%&'*+.^_`|~-]+:[ \t]*\\S")
+
+// ExtractTrailers extracts a trailer block from an HTTP response: a final
+// blank-line-separated section after the body in which every line is a header
+// field. It returns nil when the response has no trailers.
+func ExtractTrailers(response string) map[string]string {
+	lines := strings.Split(strings.TrimRight(response, "\r\n \t"), "\n")
+
+	// Locate the blank line ending the leading headers
+	bodyStart := -1
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "" {
+			bodyStart = i + 1
+			break
+		}
+	}
+	if bodyStart == -1 {
+		return nil
+	}
+
+	// The trailer block starts after the last blank line, which must follow
+	// some body content
+	blockStart := -1
+	for i := len(lines) - 1; i >= bodyStart; i-- {
+		if strings.TrimSpace(lines[i]) == "" {
+			blockStart = i + 1
+			break
+		}
+	}
+	if blockStart == -1 || !hasContent(lines[bodyStart:blockStart]) {
+		return nil
+	}
+
+	trailers := make(map[string]string)
+	for _, line := range lines[blockStart:] {
+		line = strings.TrimRight(line, "\r")
+		if !trailerLinePattern.MatchString(line) {
+			return nil
+		}
+		parts := strings.SplitN(line, ":", 2)
+		trailers[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return trailers
+}
+
+// stripTrailerBlock removes the final blank-line-separated block from body
+func stripTrailerBlock(body string) string {
+	lines := strings.Split(body, "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if strings.TrimSpace(lines[i]) == "" {
+			return strings.TrimSpace(strings.Join(lines[:i], "\n"))
+		}
+	}
+	return body
+}
+
+// hasContent reports whether any line is non-blank
+func hasContent(lines []string) bool {
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// lookupHeader finds a header value by case-insensitive name
+func lookupHeader(headers map[string]string, name string) (string, bool) {
+	for key, value := range headers {
+		if strings.EqualFold(key, name) {
+			return value, true
+		}
+	}
+	return "", false
+}
+
 // ExtractStatusCode extracts the status code from an HTTP response
 func ExtractStatusCode(response string) (int, error) {
 	lines := strings.Split(response, "\n")
@@ -625,6 +748,19 @@ func validateHTTPPatternConfig(config *HTTPPatternConfig) error {
 		}
 	}
 
+	return validateStatusTypes(config.StatusTypes)
+}
+
+// validateStatusTypes validates custom status code to pattern type overrides
+func validateStatusTypes(statusTypes map[int]string) error {
+	for statusCode, patternType := range statusTypes {
+		if statusCode < 100 || statusCode > 599 {
+			return fmt.Errorf("invalid status code %d in status types", statusCode)
+		}
+		if !validPatternTypes[patternType] {
+			return fmt.Errorf("unknown pattern type %q for status code %d", patternType, statusCode)
+		}
+	}
 	return nil
 }
 
@@ -775,7 +911,7 @@ func (h *HTTPPatternMatcher) setRetryStrategy(response *HTTPResponse, result *HT
 	case "rate_limit":
 		result.Context["retry_strategy"] = "fixed_delay"
 
-	case "server_error":
+	case "server_error", "retryable":
 		result.Context["retry_strategy"] = "exponential"
 
 	case "auth_error":
@@ -801,8 +937,13 @@ func (h *HTTPPatternMatcher) matchPattern(response *HTTPResponse, patternName st
 	return false, nil
 }
 
-// getPatternTypeFromStatus determines pattern type based on HTTP status code
+// getPatternTypeFromStatus determines pattern type based on HTTP status code.
+// Custom StatusTypes entries take precedence over the built-in mapping.
 func (h *HTTPPatternMatcher) getPatternTypeFromStatus(statusCode int) string {
+	if patternType, exists := h.config.StatusTypes[statusCode]; exists {
+		return patternType
+	}
+
 	switch {
 	case statusCode >= 200 && statusCode < 300:
 		return "success"
@@ -862,4 +1003,8 @@ func (h *HTTPPatternMatcher) extractContextFromResponse(response *HTTPResponse,
 
 	// Set retry strategy based on pattern type and response characteristics
 	h.setRetryStrategy(response, result)
+
+	for _, extractor := range h.extractors {
+		extractor(response, result)
+	}
 }
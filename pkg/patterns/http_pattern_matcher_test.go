@@ -473,6 +473,99 @@ Stack trace:
 	}
 }
 
+func TestHTTPPatternMatcher_ResponseTrailers(t *testing.T) {
+	tests := []struct {
+		name             string
+		rawResponse      string
+		expectedTrailers map[string]string
+		expectedRetry    string
+		expectedBody     string
+	}{
+		{
+			name: "Retry-After only in trailers",
+			rawResponse: "HTTP/2 503 Service Unavailable\r\n" +
+				"Content-Type: application/grpc-web+proto\r\n" +
+				"\r\n" +
+				"{\"error\": \"unavailable\"}\r\n" +
+				"\r\n" +
+				"grpc-status: 14\r\n" +
+				"Retry-After: 7\r\n",
+			expectedTrailers: map[string]string{"grpc-status": "14", "Retry-After": "7"},
+			expectedRetry:    "7",
+			expectedBody:     `{"error": "unavailable"}`,
+		},
+		{
+			name: "leading Retry-After wins over trailer",
+			rawResponse: `HTTP/1.1 429 Too Many Requests
+Retry-After: 3
+
+rate limited
+
+retry-after: 30`,
+			expectedTrailers: map[string]string{"retry-after": "30"},
+			expectedRetry:    "3",
+			expectedBody:     "rate limited",
+		},
+		{
+			name: "body that looks like a header is not a trailer",
+			rawResponse: `HTTP/1.1 500 Internal Server Error
+
+Error: upstream failed`,
+			expectedBody: "Error: upstream failed",
+		},
+		{
+			name: "header-like final block after the body is a trailer",
+			rawResponse: `HTTP/1.1 503 Service Unavailable
+
+first paragraph
+
+Note: try again later, please`,
+			expectedTrailers: map[string]string{"Note": "try again later, please"},
+			expectedRetry:    "",
+			expectedBody:     "first paragraph",
+		},
+		{
+			name: "multi-line final block with non-header line",
+			rawResponse: `HTTP/1.1 503 Service Unavailable
+
+first paragraph
+
+Retry-After: 5
+see docs for details`,
+			expectedBody: `first paragraph
+
+Retry-After: 5
+see docs for details`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := ParseHTTPResponse(tt.rawResponse)
+			if err != nil {
+				t.Fatalf("ParseHTTPResponse() error = %v", err)
+			}
+
+			if len(response.Trailers) != len(tt.expectedTrailers) {
+				t.Errorf("ParseHTTPResponse() trailers = %v, want %v", response.Trailers, tt.expectedTrailers)
+			}
+			for key, expected := range tt.expectedTrailers {
+				if actual := response.Trailers[key]; actual != expected {
+					t.Errorf("ParseHTTPResponse() trailer %s = %q, want %q", key, actual, expected)
+				}
+			}
+
+			if actual := response.Headers["Retry-After"]; actual != tt.expectedRetry {
+				t.Errorf("ParseHTTPResponse() Retry-After header = %q, want %q", actual, tt.expectedRetry)
+			}
+
+			if strings.TrimSpace(response.Body) != tt.expectedBody {
+				t.Errorf("ParseHTTPResponse() body = %q, want %q", response.Body, tt.expectedBody)
+			}
+		})
+	}
+}
+
 func TestHTTPPatternMatcher_RateLimitingIntegration(t *testing.T) {
 	tests := []struct {
 		name               string
@@ -985,3 +1078,55 @@ func TestHTTPPatternMatcher_EdgeCases(t *testing.T) {
 	}
 }
 
+
+func TestHTTPPatternMatcher_RegisterContextExtractor(t *testing.T) {
+	matcher, err := NewHTTPPatternMatcher(DefaultHTTPPatternConfig())
+	if err != nil {
+		t.Fatalf("NewHTTPPatternMatcher() error = %v", err)
+	}
+
+	var calls []string
+	matcher.RegisterContextExtractor(func(response *HTTPResponse, result *HTTPMatchResult) {
+		calls = append(calls, "trace")
+		if traceID, exists := response.Headers["X-Trace-Id"]; exists {
+			result.Context["trace_id"] = traceID
+		}
+	})
+	matcher.RegisterContextExtractor(nil)
+	matcher.RegisterContextExtractor(func(response *HTTPResponse, result *HTTPMatchResult) {
+		calls = append(calls, "second")
+		// Later extractors see the built-in context and earlier extractors' keys
+		result.Context["saw_rate_limit"] = result.Context["rate_limit"] == "5000" && result.Context["trace_id"] == "trace-123"
+	})
+
+	response := &HTTPResponse{
+		StatusCode: 429,
+		Headers: map[string]string{
+			"X-RateLimit-Limit": "5000",
+			"Retry-After":       "60",
+			"X-Trace-Id":        "trace-123",
+		},
+		Body: `{"message": "API rate limit exceeded"}`,
+	}
+
+	result, err := matcher.MatchHTTPResponse(response)
+	if err != nil {
+		t.Fatalf("MatchHTTPResponse() error = %v", err)
+	}
+	if !result.Matched {
+		t.Fatalf("MatchHTTPResponse() matched = false, want true")
+	}
+
+	if got := result.Context["trace_id"]; got != "trace-123" {
+		t.Errorf("Context[trace_id] = %v, want trace-123", got)
+	}
+	if got := result.Context["saw_rate_limit"]; got != true {
+		t.Errorf("Context[saw_rate_limit] = %v, want true", got)
+	}
+	if got := result.Context["rate_limit"]; got != "5000" {
+		t.Errorf("built-in Context[rate_limit] = %v, want 5000", got)
+	}
+	if strings.Join(calls, ",") != "trace,second" {
+		t.Errorf("extractor calls = %v, want [trace second]", calls)
+	}
+}
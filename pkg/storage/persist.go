@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// snapshotVersion is the on-disk format version written by SaveSnapshot
+const snapshotVersion = 1
+
+// snapshot is the on-disk representation of stored metrics
+type snapshot struct {
+	Version int            `json:"version"`
+	SavedAt time.Time      `json:"saved_at"`
+	Metrics []StoredMetric `json:"metrics"`
+}
+
+// SaveSnapshot writes all stored metrics to path with WriteFileAtomic, so a
+// crash leaves either the previous snapshot or the new one, never a partial file.
+func (s *MetricsStorage) SaveSnapshot(path string) error {
+	s.mu.RLock()
+	data, err := json.Marshal(snapshot{
+		Version: snapshotVersion,
+		SavedAt: time.Now(),
+		Metrics: s.metrics,
+	})
+	s.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+
+	if err := WriteFileAtomic(path, data); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return nil
+}
+
+// WriteFileAtomic replaces path with data, readable only by the owner. The
+// data is written to a temporary file in the same directory, synced and
+// renamed over path, so readers and a crash see either the previous contents
+// or the new ones, never a partial file.
+func WriteFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) // no-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+
+	// Sync the directory so the rename itself survives a crash
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
+
+// LoadSnapshot restores metrics saved by SaveSnapshot, returning how many were
+// kept. Entries older than the storage's max age are dropped and only the
+// newest max size entries are kept. A missing file is not an error.
+func (s *MetricsStorage) LoadSnapshot(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return 0, fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+	if snap.Version != snapshotVersion {
+		return 0, fmt.Errorf("unsupported snapshot version %d", snap.Version)
+	}
+
+	cutoff := time.Now().Add(-s.maxAge)
+	var loaded []StoredMetric
+	for _, metric := range snap.Metrics {
+		if metric.Metrics != nil && metric.Timestamp.After(cutoff) {
+			loaded = append(loaded, metric)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Loaded entries predate anything stored since start; keep timestamp order
+	s.metrics = append(loaded, s.metrics...)
+	sort.SliceStable(s.metrics, func(i, j int) bool {
+		return s.metrics[i].Timestamp.Before(s.metrics[j].Timestamp)
+	})
+	if len(s.metrics) > s.maxSize {
+		s.metrics = s.metrics[len(s.metrics)-s.maxSize:]
+	}
+
+	if len(loaded) > s.maxSize {
+		return s.maxSize, nil
+	}
+	return len(loaded), nil
+}
@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsStorage_SnapshotRoundTrip(t *testing.T) {
+	// Given a storage with a few metrics
+	path := filepath.Join(t.TempDir(), "metrics.json")
+	original := NewMetricsStorage(100, time.Hour)
+	require.NoError(t, original.Store(createTestMetric("echo a", true, 1.0, 1)))
+	require.NoError(t, original.Store(createTestMetric("echo b", false, 2.0, 3)))
+
+	// When it is saved and loaded into a fresh storage
+	require.NoError(t, original.SaveSnapshot(path))
+	restored := NewMetricsStorage(100, time.Hour)
+	loaded, err := restored.LoadSnapshot(path)
+
+	// Then the metrics and their aggregates should survive
+	require.NoError(t, err)
+	assert.Equal(t, 2, loaded)
+	start, end := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	assert.Equal(t, original.GetAggregatedStats(start, end).TotalRuns, restored.GetAggregatedStats(start, end).TotalRuns)
+	assert.Equal(t, "echo b", restored.GetRecent(1)[0].Metrics.Command)
+
+	// And no temporary files should be left behind
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestMetricsStorage_LoadSnapshotRespectsLimits(t *testing.T) {
+	// Given a snapshot with one expired and three current metrics
+	path := filepath.Join(t.TempDir(), "metrics.json")
+	original := NewMetricsStorage(100, 48*time.Hour)
+	original.metrics = []StoredMetric{
+		{Timestamp: time.Now().Add(-2 * time.Hour), Metrics: createTestMetric("expired", true, 1.0, 1)},
+		{Timestamp: time.Now().Add(-3 * time.Minute), Metrics: createTestMetric("old", true, 1.0, 1)},
+		{Timestamp: time.Now().Add(-2 * time.Minute), Metrics: createTestMetric("middle", true, 1.0, 1)},
+		{Timestamp: time.Now().Add(-1 * time.Minute), Metrics: createTestMetric("new", true, 1.0, 1)},
+	}
+	require.NoError(t, original.SaveSnapshot(path))
+
+	// When loading into a storage with a one-hour max age and room for two
+	restored := NewMetricsStorage(2, time.Hour)
+	loaded, err := restored.LoadSnapshot(path)
+
+	// Then expired metrics should be dropped and only the newest kept
+	require.NoError(t, err)
+	assert.Equal(t, 2, loaded)
+	recent := restored.GetRecent(0)
+	require.Len(t, recent, 2)
+	assert.Equal(t, "middle", recent[0].Metrics.Command)
+	assert.Equal(t, "new", recent[1].Metrics.Command)
+}
+
+func TestMetricsStorage_LoadSnapshotMissingOrCorrupt(t *testing.T) {
+	dir := t.TempDir()
+	storage := NewMetricsStorage(10, time.Hour)
+
+	// A missing snapshot is a fresh start
+	loaded, err := storage.LoadSnapshot(filepath.Join(dir, "missing.json"))
+	require.NoError(t, err)
+	assert.Equal(t, 0, loaded)
+
+	// A corrupt snapshot is reported
+	corrupt := filepath.Join(dir, "corrupt.json")
+	require.NoError(t, os.WriteFile(corrupt, []byte("{not json"), 0600))
+	_, err = storage.LoadSnapshot(corrupt)
+	assert.Error(t, err)
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	// Given a file with previous contents
+	path := filepath.Join(t.TempDir(), "state.json")
+	require.NoError(t, os.WriteFile(path, []byte("old"), 0644))
+
+	// When it is replaced atomically
+	require.NoError(t, WriteFileAtomic(path, []byte("new")))
+
+	// Then it holds the new contents, readable only by the owner
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(data))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// And no temporary files should be left behind
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestWriteFileAtomicMissingDirectory(t *testing.T) {
+	// Given a path in a directory that does not exist
+	path := filepath.Join(t.TempDir(), "missing", "state.json")
+
+	// When it is written, then the write fails
+	assert.Error(t, WriteFileAtomic(path, []byte("data")))
+}
@@ -35,6 +35,42 @@ type AggregatedStats struct {
 	AverageDuration time.Duration  `json:"average_duration"`
 	TopCommands     []CommandStats `json:"top_commands"`
 	HourlyBreakdown []HourlyStats  `json:"hourly_breakdown"`
+
+	// TagFilter echoes the tags runs were required to carry (if any)
+	TagFilter map[string]string `json:"tag_filter,omitempty"`
+	// TagBreakdown aggregates runs by tag key, then tag value
+	TagBreakdown map[string]map[string]*TagStats `json:"tag_breakdown,omitempty"`
+	// MetricBreakdown summarizes each custom metric across the runs carrying it
+	MetricBreakdown map[string]*MetricStats `json:"metric_breakdown,omitempty"`
+	// FailureBreakdown counts failed runs by category (see RunMetrics.Category)
+	FailureBreakdown map[string]int `json:"failure_breakdown,omitempty"`
+}
+
+// MetricStats summarizes the values of one custom metric
+type MetricStats struct {
+	Count int     `json:"count"`
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Mean  float64 `json:"mean"`
+}
+
+// observe adds a run's value to the summary
+func (m *MetricStats) observe(value float64) {
+	if m.Count == 0 || value < m.Min {
+		m.Min = value
+	}
+	if m.Count == 0 || value > m.Max {
+		m.Max = value
+	}
+	m.Count++
+	m.Mean += (value - m.Mean) / float64(m.Count)
+}
+
+// TagStats represents statistics for runs carrying a specific tag value
+type TagStats struct {
+	Count       int     `json:"count"`
+	Successful  int     `json:"successful"`
+	SuccessRate float64 `json:"success_rate"`
 }
 
 // TimeRange represents a time range for aggregation
@@ -73,13 +109,21 @@ func (s *MetricsStorage) Store(metric *metrics.RunMetrics) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	// Add new metric
+	// Keep the time the run recorded, so replayed metrics land when they happened
+	timestamp := time.Now()
+	if metric.Timestamp != 0 {
+		timestamp = time.Unix(metric.Timestamp, 0)
+	}
 	stored := StoredMetric{
-		Timestamp: time.Now(),
+		Timestamp: timestamp,
 		Metrics:   metric,
 	}
 
-	s.metrics = append(s.metrics, stored)
+	// Keep metrics in time order, so cleanup drops the oldest first
+	i := sort.Search(len(s.metrics), func(i int) bool { return s.metrics[i].Timestamp.After(timestamp) })
+	s.metrics = append(s.metrics, StoredMetric{})
+	copy(s.metrics[i+1:], s.metrics[i:])
+	s.metrics[i] = stored
 
 	// Cleanup if needed
 	s.cleanupIfNeeded()
@@ -121,19 +165,31 @@ func (s *MetricsStorage) GetByTimeRange(start, end time.Time) []StoredMetric {
 
 // GetAggregatedStats returns aggregated statistics for a time range
 func (s *MetricsStorage) GetAggregatedStats(start, end time.Time) *AggregatedStats {
+	return s.GetAggregatedStatsWithTags(start, end, nil)
+}
+
+// GetAggregatedStatsWithTags returns aggregated statistics for a time range,
+// counting only runs that carry every key=value pair in tags
+func (s *MetricsStorage) GetAggregatedStatsWithTags(start, end time.Time, tags map[string]string) *AggregatedStats {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	metricsInRange := s.getMetricsInRange(start, end)
-	if len(metricsInRange) == 0 {
-		return &AggregatedStats{
-			TimeRange: TimeRange{Start: start, End: end},
+	var metricsInRange []StoredMetric
+	for _, stored := range s.getMetricsInRange(start, end) {
+		if stored.Metrics.HasTags(tags) {
+			metricsInRange = append(metricsInRange, stored)
 		}
 	}
 
 	stats := &AggregatedStats{
 		TimeRange: TimeRange{Start: start, End: end},
 	}
+	if len(tags) > 0 {
+		stats.TagFilter = tags
+	}
+	if len(metricsInRange) == 0 {
+		return stats
+	}
 
 	// Calculate basic stats
 	var totalDuration time.Duration
@@ -150,6 +206,10 @@ func (s *MetricsStorage) GetAggregatedStats(start, end time.Time) *AggregatedSta
 			stats.SuccessfulRuns++
 		} else {
 			stats.FailedRuns++
+			if stats.FailureBreakdown == nil {
+				stats.FailureBreakdown = make(map[string]int)
+			}
+			stats.FailureBreakdown[metric.Category()]++
 		}
 
 		totalDuration += time.Duration(metric.TotalDurationSeconds * float64(time.Second))
@@ -179,6 +239,39 @@ func (s *MetricsStorage) GetAggregatedStats(start, end time.Time) *AggregatedSta
 			}
 		}
 
+		// Track tag statistics
+		for key, value := range metric.Tags {
+			if stats.TagBreakdown == nil {
+				stats.TagBreakdown = make(map[string]map[string]*TagStats)
+			}
+			if stats.TagBreakdown[key] == nil {
+				stats.TagBreakdown[key] = make(map[string]*TagStats)
+			}
+			tagStats := stats.TagBreakdown[key][value]
+			if tagStats == nil {
+				tagStats = &TagStats{}
+				stats.TagBreakdown[key][value] = tagStats
+			}
+			tagStats.Count++
+			if success {
+				tagStats.Successful++
+			}
+			tagStats.SuccessRate = float64(tagStats.Successful) / float64(tagStats.Count)
+		}
+
+		// Track custom metric statistics
+		for key, value := range metric.CustomMetrics {
+			if stats.MetricBreakdown == nil {
+				stats.MetricBreakdown = make(map[string]*MetricStats)
+			}
+			metricStats := stats.MetricBreakdown[key]
+			if metricStats == nil {
+				metricStats = &MetricStats{}
+				stats.MetricBreakdown[key] = metricStats
+			}
+			metricStats.observe(value)
+		}
+
 		// Track hourly statistics
 		hour := stored.Timestamp.Truncate(time.Hour)
 		hourKey := hour.Format(time.RFC3339)
@@ -28,6 +28,30 @@ func TestMetricsStorage_Store(t *testing.T) {
 	assert.Equal(t, metric, recent[0].Metrics)
 }
 
+func TestMetricsStorage_StoreKeepsRecordedTimestamp(t *testing.T) {
+	// Given a storage instance holding a current run
+	storage := NewMetricsStorage(100, 24*time.Hour)
+	current := createTestMetric("current", true, 1.0, 1)
+	require.NoError(t, storage.Store(current))
+
+	// When a run recorded an hour ago is replayed into it
+	replayed := createTestMetric("replayed", false, 1.0, 3)
+	replayed.Timestamp = time.Now().Add(-time.Hour).Unix()
+	require.NoError(t, storage.Store(replayed))
+
+	// Then it should be stored at its own time, before the current run
+	recent := storage.GetRecent(2)
+	require.Len(t, recent, 2)
+	assert.Equal(t, replayed, recent[0].Metrics)
+	assert.Equal(t, time.Unix(replayed.Timestamp, 0), recent[0].Timestamp)
+	assert.Equal(t, current, recent[1].Metrics)
+
+	// And only the current run should fall in the last half hour
+	inRange := storage.GetByTimeRange(time.Now().Add(-30*time.Minute), time.Now().Add(time.Minute))
+	require.Len(t, inRange, 1)
+	assert.Equal(t, current, inRange[0].Metrics)
+}
+
 func TestMetricsStorage_GetRecent(t *testing.T) {
 	// Given a storage with multiple metrics
 	storage := NewMetricsStorage(100, time.Hour)
@@ -224,7 +248,110 @@ func TestMetricsStorage_ConcurrentAccess(t *testing.T) {
 	assert.Equal(t, 50, len(recent)) // 5 writers * 10 metrics each
 }
 
+func TestMetricsStorage_GetAggregatedStatsWithTags(t *testing.T) {
+	// Given runs tagged with different environments
+	storage := NewMetricsStorage(100, time.Hour)
+
+	prodOK := createTestMetric("deploy", true, 1.0, 1)
+	prodOK.Tags = map[string]string{"env": "prod", "team": "payments"}
+	prodFail := createTestMetric("deploy", false, 2.0, 3)
+	prodFail.Tags = map[string]string{"env": "prod", "team": "search"}
+	staging := createTestMetric("deploy", true, 1.0, 2)
+	staging.Tags = map[string]string{"env": "staging"}
+	untagged := createTestMetric("deploy", true, 1.0, 1)
+
+	for _, metric := range []*metrics.RunMetrics{prodOK, prodFail, staging, untagged} {
+		storage.Store(metric)
+	}
+
+	start := time.Now().Add(-time.Hour)
+	end := time.Now().Add(time.Hour)
+
+	// When aggregating without a filter
+	all := storage.GetAggregatedStats(start, end)
+
+	// Then every run is counted and broken down by tag value
+	assert.Equal(t, 4, all.TotalRuns)
+	require.Contains(t, all.TagBreakdown, "env")
+	assert.Equal(t, 2, all.TagBreakdown["env"]["prod"].Count)
+	assert.Equal(t, 0.5, all.TagBreakdown["env"]["prod"].SuccessRate)
+	assert.Equal(t, 1, all.TagBreakdown["env"]["staging"].Count)
+
+	// When filtering by a tag value
+	prod := storage.GetAggregatedStatsWithTags(start, end, map[string]string{"env": "prod"})
+
+	// Then only matching runs are aggregated
+	assert.Equal(t, 2, prod.TotalRuns)
+	assert.Equal(t, 1, prod.SuccessfulRuns)
+	assert.Equal(t, map[string]string{"env": "prod"}, prod.TagFilter)
+	assert.NotContains(t, prod.TagBreakdown["env"], "staging")
+
+	// When filtering on several tags at once
+	payments := storage.GetAggregatedStatsWithTags(start, end, map[string]string{"env": "prod", "team": "payments"})
+	assert.Equal(t, 1, payments.TotalRuns)
+	assert.Equal(t, 1.0, payments.SuccessRate)
+}
+
+func TestMetricsStorage_GetAggregatedStatsMetricBreakdown(t *testing.T) {
+	// Given runs carrying custom metrics, one of them tagged
+	storage := NewMetricsStorage(100, time.Hour)
+
+	first := createTestMetric("build", true, 1.0, 1)
+	first.CustomMetrics = map[string]float64{"build_number": 100, "artifact_mb": 12.5}
+	second := createTestMetric("build", false, 2.0, 2)
+	second.CustomMetrics = map[string]float64{"build_number": 102}
+	second.Tags = map[string]string{"env": "prod"}
+	plain := createTestMetric("build", true, 1.0, 1)
+
+	for _, metric := range []*metrics.RunMetrics{first, second, plain} {
+		storage.Store(metric)
+	}
+
+	start := time.Now().Add(-time.Hour)
+	end := time.Now().Add(time.Hour)
+
+	// When aggregating
+	all := storage.GetAggregatedStats(start, end)
+
+	// Then each metric is summarized over the runs carrying it
+	require.Contains(t, all.MetricBreakdown, "build_number")
+	assert.Equal(t, MetricStats{Count: 2, Min: 100, Max: 102, Mean: 101}, *all.MetricBreakdown["build_number"])
+	assert.Equal(t, MetricStats{Count: 1, Min: 12.5, Max: 12.5, Mean: 12.5}, *all.MetricBreakdown["artifact_mb"])
+
+	// And tag filters narrow the runs summarized
+	prod := storage.GetAggregatedStatsWithTags(start, end, map[string]string{"env": "prod"})
+	assert.Equal(t, MetricStats{Count: 1, Min: 102, Max: 102, Mean: 102}, *prod.MetricBreakdown["build_number"])
+	assert.NotContains(t, prod.MetricBreakdown, "artifact_mb")
+}
+
 // createTestMetric creates a test RunMetrics instance
+func TestMetricsStorage_FailureBreakdown(t *testing.T) {
+	// Given successes and failures of mixed categories
+	storage := NewMetricsStorage(100, time.Hour)
+	failure := func(kind, category string) *metrics.RunMetrics {
+		metric := createTestMetric("deploy", false, 1.0, 3)
+		metric.FailureKind, metric.FailureCategory = kind, category
+		return metric
+	}
+	for _, metric := range []*metrics.RunMetrics{
+		createTestMetric("deploy", true, 1.0, 1),
+		failure("max_attempts", "auth"),
+		failure("failure_pattern", "auth"),
+		failure("max_attempts", ""),
+		failure("timeout", ""),
+		failure("", ""),
+	} {
+		storage.Store(metric)
+	}
+
+	// When aggregating
+	stats := storage.GetAggregatedStats(time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+
+	// Then failed runs are counted by category, falling back to the failure kind
+	assert.Equal(t, 5, stats.FailedRuns)
+	assert.Equal(t, map[string]int{"auth": 2, "max_attempts": 1, "timeout": 1, "unknown": 1}, stats.FailureBreakdown)
+}
+
 func createTestMetric(command string, success bool, durationSeconds float64, attemptCount int) *metrics.RunMetrics {
 	attempts := make([]metrics.AttemptMetric, attemptCount)
 	for i := 0; i < attemptCount; i++ {
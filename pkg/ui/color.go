@@ -0,0 +1,59 @@
+package ui
+
+import "os"
+
+// Color modes for the human-readable messages. JSON output is never colored,
+// whatever the mode.
+const (
+	// ColorAuto colors messages written to a terminal unless NO_COLOR is set
+	// or TERM is "dumb"
+	ColorAuto = "auto"
+	// ColorAlways colors messages even when they are piped or redirected
+	ColorAlways = "always"
+	// ColorNever writes plain messages
+	ColorNever = "never"
+)
+
+// ANSI color codes used by the reporter
+const (
+	colorRed    = "31"
+	colorGreen  = "32"
+	colorYellow = "33"
+)
+
+// ValidColorMode reports whether mode is a supported color mode
+func ValidColorMode(mode string) bool {
+	switch mode {
+	case ColorAuto, ColorAlways, ColorNever:
+		return true
+	}
+	return false
+}
+
+// UseColor resolves mode for messages written to f
+func UseColor(mode string, f *os.File) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorAuto:
+		if _, ok := os.LookupEnv("NO_COLOR"); ok || os.Getenv("TERM") == "dumb" {
+			return false
+		}
+		return IsTerminal(f)
+	}
+	return false
+}
+
+// SetColor enables ANSI colors in the human-readable messages. The JSON
+// selected by SetJSONOutput is encoded separately and never colored.
+func (r *Reporter) SetColor(enabled bool) {
+	r.color = enabled
+}
+
+// paint wraps text in the given ANSI color when colors are enabled
+func (r *Reporter) paint(code, text string) string {
+	if !r.color {
+		return text
+	}
+	return "\x1b[" + code + "m" + text + "\x1b[0m"
+}
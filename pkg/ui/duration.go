@@ -0,0 +1,63 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FormatDuration formats a duration for people: hours, minutes and whole
+// seconds for long durations ("1h2m", "1m30s"), up to two decimals below a
+// minute ("4.5s"), and one decimal in the largest fitting unit below a second
+// ("150ms", "2.5µs"). The output does not depend on the locale.
+func FormatDuration(d time.Duration) string {
+	if d < 0 {
+		return "-" + FormatDuration(-d)
+	}
+	if d < time.Microsecond {
+		if d == 0 {
+			return "0s"
+		}
+		return fmt.Sprintf("%dns", int64(d))
+	}
+
+	// Round to the precision shown before choosing the unit, so 999.96ms
+	// becomes "1s" rather than "1000ms"
+	if rounded := d.Round(100 * time.Nanosecond); rounded < time.Millisecond {
+		return decimal(rounded, time.Microsecond, 1) + "µs"
+	}
+	if rounded := d.Round(100 * time.Microsecond); rounded < time.Second {
+		return decimal(rounded, time.Millisecond, 1) + "ms"
+	}
+	if rounded := d.Round(10 * time.Millisecond); rounded < time.Minute {
+		return decimal(rounded, time.Second, 2) + "s"
+	}
+
+	d = d.Round(time.Second)
+	hours := d / time.Hour
+	minutes := (d % time.Hour) / time.Minute
+	seconds := (d % time.Minute) / time.Second
+
+	var builder strings.Builder
+	if hours > 0 {
+		fmt.Fprintf(&builder, "%dh", hours)
+	}
+	if minutes > 0 {
+		fmt.Fprintf(&builder, "%dm", minutes)
+	}
+	if seconds > 0 {
+		fmt.Fprintf(&builder, "%ds", seconds)
+	}
+	return builder.String()
+}
+
+// decimal formats d in the given unit with at most the given number of
+// decimals, dropping trailing zeros
+func decimal(d, unit time.Duration, decimals int) string {
+	formatted := strconv.FormatFloat(float64(d)/float64(unit), 'f', decimals, 64)
+	if strings.Contains(formatted, ".") {
+		formatted = strings.TrimRight(strings.TrimRight(formatted, "0"), ".")
+	}
+	return formatted
+}
@@ -0,0 +1,45 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		name     string
+		duration time.Duration
+		expected string
+	}{
+		{"zero", 0, "0s"},
+		{"nanoseconds", 750 * time.Nanosecond, "750ns"},
+		{"microseconds", 2500 * time.Nanosecond, "2.5µs"},
+		{"whole microseconds", 40 * time.Microsecond, "40µs"},
+		{"rounds up to milliseconds", 999960 * time.Nanosecond, "1ms"},
+		{"milliseconds", 150 * time.Millisecond, "150ms"},
+		{"fractional milliseconds", 1250 * time.Microsecond, "1.3ms"},
+		{"rounds up to seconds", 999960 * time.Microsecond, "1s"},
+		{"seconds", 2 * time.Second, "2s"},
+		{"fractional seconds", 15750 * time.Millisecond, "15.75s"},
+		{"rounds up to minutes", 59996 * time.Millisecond, "1m"},
+		{"minutes and seconds", 90 * time.Second, "1m30s"},
+		{"whole minutes", 5 * time.Minute, "5m"},
+		{"drops sub-second detail past a minute", 90*time.Second + 400*time.Millisecond, "1m30s"},
+		{"hours, minutes and seconds", 3661 * time.Second, "1h1m1s"},
+		{"hours and seconds", time.Hour + 5*time.Second, "1h5s"},
+		{"whole hours", 26 * time.Hour, "26h"},
+		{"negative", -1500 * time.Millisecond, "-1.5s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// When formatting the duration
+			formatted := FormatDuration(tt.duration)
+
+			// Then it should use the expected unit and precision
+			assert.Equal(t, tt.expected, formatted)
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/shaneisley/patience/pkg/metrics"
+)
+
+// Machine-readable output modes. Both write JSON to their own writer (stdout in
+// the CLI), one object per line, while human-readable messages stay on the
+// reporter's writer.
+const (
+	// OutputText writes no JSON
+	OutputText = "text"
+	// OutputNDJSON buffers the run and writes exactly one RunSummary object when it ends
+	OutputNDJSON = "ndjson"
+	// OutputJSONEvents streams an Event object per attempt, warning and wait as they happen
+	OutputJSONEvents = "json-events"
+)
+
+// ValidOutputMode reports whether mode is a supported output mode
+func ValidOutputMode(mode string) bool {
+	switch mode {
+	case OutputText, OutputNDJSON, OutputJSONEvents:
+		return true
+	}
+	return false
+}
+
+// Event is one line of json-events output
+type Event struct {
+	Event string `json:"event"` // attempt_start, attempt_failure, warning, waiting or run_end
+	Time  string `json:"time"`  // RFC 3339 with nanoseconds
+
+	Attempt          int      `json:"attempt,omitempty"`
+	MaxAttempts      int      `json:"max_attempts,omitempty"`
+	Reason           string   `json:"reason,omitempty"`
+	NextDelaySeconds *float64 `json:"next_delay_seconds,omitempty"`
+	Message          string   `json:"message,omitempty"`
+	WaitSeconds      *float64 `json:"wait_seconds,omitempty"`
+
+	// Set on run_end only
+	Success              *bool    `json:"success,omitempty"`
+	TotalAttempts        int      `json:"total_attempts,omitempty"`
+	TotalDurationSeconds *float64 `json:"total_duration_seconds,omitempty"`
+	AttemptDurationP50   *float64 `json:"attempt_duration_p50_seconds,omitempty"`
+	AttemptDurationP95   *float64 `json:"attempt_duration_p95_seconds,omitempty"`
+}
+
+// RunSummary is the single object written per run in ndjson output
+type RunSummary struct {
+	Command               string                    `json:"command"`
+	Success               bool                      `json:"success"`
+	ExitCode              int                       `json:"exit_code"`
+	TimedOut              bool                      `json:"timed_out"`
+	Reason                string                    `json:"reason"`
+	TotalAttempts         int                       `json:"total_attempts"`
+	TotalDurationSeconds  float64                   `json:"total_duration_seconds"`
+	TotalDelaySeconds     float64                   `json:"total_delay_seconds"`
+	TotalExecutionSeconds float64                   `json:"total_execution_seconds"`
+	AttemptDurationP50    *float64                  `json:"attempt_duration_p50_seconds,omitempty"`
+	AttemptDurationP95    *float64                  `json:"attempt_duration_p95_seconds,omitempty"`
+	Attempts              []metrics.AttemptMetric   `json:"attempts"`
+	TotalCost             float64                   `json:"total_cost,omitempty"`
+	Tags                  map[string]string         `json:"tags,omitempty"`
+	CustomMetrics         map[string]float64        `json:"custom_metrics,omitempty"`
+	RateLimit             *metrics.RateLimitSummary `json:"rate_limit,omitempty"`
+	MetricsDelivered      bool                      `json:"metrics_delivered"`
+}
+
+// SetJSONOutput selects a machine-readable output mode written to w, in
+// addition to the human-readable messages on the reporter's writer
+func (r *Reporter) SetJSONOutput(mode string, w io.Writer) {
+	r.jsonMode = mode
+	r.jsonWriter = w
+}
+
+// RunResult writes the run's summary object in ndjson mode; other modes ignore it
+func (r *Reporter) RunResult(summary RunSummary) {
+	if r.jsonMode != OutputNDJSON {
+		return
+	}
+	if summary.Attempts == nil {
+		summary.Attempts = []metrics.AttemptMetric{}
+	}
+	r.writeJSONLine(summary)
+}
+
+// emit writes an event in json-events mode
+func (r *Reporter) emit(event Event) {
+	if r.jsonMode != OutputJSONEvents {
+		return
+	}
+	event.Time = time.Now().UTC().Format(time.RFC3339Nano)
+	r.writeJSONLine(event)
+}
+
+// writeJSONLine writes v as a single line of JSON
+func (r *Reporter) writeJSONLine(v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		fmt.Fprintf(r.writer, "[warning] failed to encode JSON output: %v\n", err)
+		return
+	}
+	r.jsonWriter.Write(append(data, '\n'))
+}
+
+// SetStats fills the fields of summary derived from run statistics
+func (summary *RunSummary) SetStats(stats *RunStats) {
+	summary.TotalCost = stats.TotalCost
+	summary.AttemptDurationP50 = attemptPercentileSeconds(stats, 50)
+	summary.AttemptDurationP95 = attemptPercentileSeconds(stats, 95)
+}
+
+// attemptPercentileSeconds returns a percentile of the attempt durations in
+// seconds, or nil when no attempts were recorded
+func attemptPercentileSeconds(stats *RunStats, p float64) *float64 {
+	if len(stats.AttemptDurations) == 0 {
+		return nil
+	}
+	return seconds(stats.AttemptDurationPercentile(p))
+}
+
+// seconds returns d in seconds for optional JSON fields
+func seconds(d time.Duration) *float64 {
+	s := d.Seconds()
+	return &s
+}
@@ -0,0 +1,18 @@
+package ui
+
+import "fmt"
+
+// FormatBytes renders a byte count with a binary unit, e.g. "1.5GiB"
+func FormatBytes(bytes uint64) string {
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB"}
+	value := float64(bytes)
+	unit := 0
+	for value >= 1024 && unit < len(units)-1 {
+		value /= 1024
+		unit++
+	}
+	if unit == 0 {
+		return fmt.Sprintf("%dB", bytes)
+	}
+	return fmt.Sprintf("%.1f%s", value, units[unit])
+}
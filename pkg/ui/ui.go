@@ -1,17 +1,33 @@
 package ui
 
 import (
+	"bufio"
 	"fmt"
 	"io"
+	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/shaneisley/patience/pkg/backoff"
+	"github.com/shaneisley/patience/pkg/metrics"
+	"github.com/shaneisley/patience/pkg/patterns"
 )
 
 // Reporter handles status reporting and terminal output
 type Reporter struct {
-	writer io.Writer
-	quiet  bool
+	writer      io.Writer
+	quiet       bool
+	verbose     bool
+	failureOnly bool          // Final summary only for failed runs (see SetSummaryOnlyOnFailure)
+	color       bool          // ANSI colors in human-readable messages (see SetColor)
+	input       *bufio.Reader // Source of answers for interactive prompts
+	interactive bool          // Whether input is attached to a terminal
+
+	// Machine-readable output (see SetJSONOutput)
+	jsonMode   string
+	jsonWriter io.Writer
 }
 
 // RunStats tracks statistics for a retry run
@@ -22,6 +38,12 @@ type RunStats struct {
 	TotalDuration    time.Duration
 	FinalReason      string
 	Success          bool
+	RateLimit        *metrics.RateLimitSummary        // Discovered rate limit, if any
+	TotalCost        float64                          // Cost charged for attempts (0 when not tracked)
+	AttemptDurations []time.Duration                  // How long each attempt's command ran, in order
+	PatternMetrics   map[string]patterns.MatchMetrics // Match metrics per pattern, when requested
+	PeakRSS          uint64                           // Highest peak memory of any attempt in bytes (0 when not measured)
+	CPUTime          time.Duration                    // CPU time consumed by all attempts
 	startTime        time.Time
 	attemptStartTime time.Time
 }
@@ -39,16 +61,74 @@ func (r *Reporter) SetQuiet(quiet bool) {
 	r.quiet = quiet
 }
 
+// SetSummaryOnlyOnFailure prints the final summary only when the run failed;
+// successful runs end without it. The run_end event is still emitted.
+func (r *Reporter) SetSummaryOnlyOnFailure(failureOnly bool) {
+	r.failureOnly = failureOnly
+}
+
+// SetVerbose enables extra detail in the final summary, such as discovered rate limits
+func (r *Reporter) SetVerbose(verbose bool) {
+	r.verbose = verbose
+}
+
+// SetInput configures where interactive prompts read answers from.
+// Prompts are only shown when interactive is true (i.e. input is a TTY).
+func (r *Reporter) SetInput(input io.Reader, interactive bool) {
+	r.input = bufio.NewReader(input)
+	r.interactive = interactive
+}
+
+// ConfirmWait asks whether to wait for the given delay before retrying.
+// It returns true without prompting when no interactive input is configured.
+func (r *Reporter) ConfirmWait(delay time.Duration) bool {
+	if r.input == nil || !r.interactive {
+		return true
+	}
+
+	fmt.Fprintf(r.writer, "Wait %s before retry? [y/N] ", FormatDuration(delay))
+	answer, err := r.input.ReadString('\n')
+	if err != nil && answer == "" {
+		return false
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
+
+// IsTerminal reports whether the file is attached to a terminal
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
 // AttemptStart reports the start of a retry attempt
 func (r *Reporter) AttemptStart(attempt, maxAttempts int) {
+	r.emit(Event{Event: "attempt_start", Attempt: attempt, MaxAttempts: maxAttempts})
 	if r.quiet {
 		return
 	}
+	if maxAttempts <= 0 {
+		fmt.Fprintf(r.writer, "[retry] Attempt %d starting...\n", attempt)
+		return
+	}
 	fmt.Fprintf(r.writer, "[retry] Attempt %d/%d starting...\n", attempt, maxAttempts)
 }
 
 // AttemptFailure reports a failed attempt with reason and next delay
 func (r *Reporter) AttemptFailure(attempt, maxAttempts int, reason string, nextDelay time.Duration) {
+	event := Event{Event: "attempt_failure", Attempt: attempt, MaxAttempts: maxAttempts, Reason: reason}
+	if attempt != maxAttempts {
+		event.NextDelaySeconds = seconds(nextDelay)
+	}
+	r.emit(event)
 	if r.quiet {
 		return
 	}
@@ -57,9 +137,13 @@ func (r *Reporter) AttemptFailure(attempt, maxAttempts int, reason string, nextD
 	var builder strings.Builder
 	builder.WriteString("[retry] Attempt ")
 	builder.WriteString(strconv.Itoa(attempt))
-	builder.WriteByte('/')
-	builder.WriteString(strconv.Itoa(maxAttempts))
-	builder.WriteString(" failed (")
+	if maxAttempts > 0 {
+		builder.WriteByte('/')
+		builder.WriteString(strconv.Itoa(maxAttempts))
+	}
+	builder.WriteByte(' ')
+	builder.WriteString(r.paint(colorRed, "failed"))
+	builder.WriteString(" (")
 	builder.WriteString(reason)
 	builder.WriteString(")")
 
@@ -69,7 +153,7 @@ func (r *Reporter) AttemptFailure(attempt, maxAttempts int, reason string, nextD
 	} else {
 		// Will retry
 		builder.WriteString(". Retrying in ")
-		builder.WriteString(r.formatDuration(nextDelay))
+		builder.WriteString(FormatDuration(nextDelay))
 		builder.WriteString(".\n")
 	}
 
@@ -78,19 +162,29 @@ func (r *Reporter) AttemptFailure(attempt, maxAttempts int, reason string, nextD
 
 // FinalSummary reports the final outcome and statistics
 func (r *Reporter) FinalSummary(stats *RunStats) {
+	success := stats.Success
+	r.emit(Event{
+		Event:                "run_end",
+		Reason:               stats.FinalReason,
+		Success:              &success,
+		TotalAttempts:        stats.TotalAttempts,
+		TotalDurationSeconds: seconds(stats.TotalDuration),
+		AttemptDurationP50:   attemptPercentileSeconds(stats, 50),
+		AttemptDurationP95:   attemptPercentileSeconds(stats, 95),
+	})
+	if success && r.failureOnly {
+		return
+	}
+
 	// Success/failure message with emoji
+	attempts := "1 attempt"
+	if stats.TotalAttempts != 1 {
+		attempts = fmt.Sprintf("%d attempts", stats.TotalAttempts)
+	}
 	if stats.Success {
-		if stats.TotalAttempts == 1 {
-			fmt.Fprintf(r.writer, "✅ [retry] Command succeeded after 1 attempt.\n")
-		} else {
-			fmt.Fprintf(r.writer, "✅ [retry] Command succeeded after %d attempts.\n", stats.TotalAttempts)
-		}
+		fmt.Fprintf(r.writer, "✅ [retry] %s after %s.\n", r.paint(colorGreen, "Command succeeded"), attempts)
 	} else {
-		if stats.TotalAttempts == 1 {
-			fmt.Fprintf(r.writer, "❌ [retry] Command failed after 1 attempt.\n")
-		} else {
-			fmt.Fprintf(r.writer, "❌ [retry] Command failed after %d attempts.\n", stats.TotalAttempts)
-		}
+		fmt.Fprintf(r.writer, "❌ [retry] %s after %s.\n", r.paint(colorRed, "Command failed"), attempts)
 	}
 
 	// Run statistics
@@ -98,60 +192,61 @@ func (r *Reporter) FinalSummary(stats *RunStats) {
 	fmt.Fprintf(r.writer, "  Total Attempts: %d\n", stats.TotalAttempts)
 	fmt.Fprintf(r.writer, "  Successful Runs: %d\n", stats.SuccessfulRuns)
 	fmt.Fprintf(r.writer, "  Failed Runs: %d\n", stats.FailedRuns)
-	fmt.Fprintf(r.writer, "  Total Duration: %s\n", r.formatDuration(stats.TotalDuration))
+	fmt.Fprintf(r.writer, "  Total Duration: %s\n", FormatDuration(stats.TotalDuration))
 	fmt.Fprintf(r.writer, "  Final Reason: %s\n", stats.FinalReason)
-}
-
-// formatDuration formats a duration in a human-readable way
-func (r *Reporter) formatDuration(d time.Duration) string {
-	if d == 0 {
-		return "0s"
+	if stats.TotalCost > 0 {
+		fmt.Fprintf(r.writer, "  Total Cost: %.6g\n", stats.TotalCost)
 	}
-
-	// Handle sub-second durations
-	if d < time.Second {
-		return fmt.Sprintf("%.1fs", float64(d)/float64(time.Second))
+	if stats.PeakRSS > 0 {
+		fmt.Fprintf(r.writer, "  Peak Memory: %s\n", FormatBytes(stats.PeakRSS))
 	}
-
-	// Handle durations with fractional seconds
-	if d < time.Minute {
-		seconds := float64(d) / float64(time.Second)
-		if seconds == float64(int(seconds)) {
-			return fmt.Sprintf("%.0fs", seconds)
-		}
-		// Format with minimal decimal places, removing trailing zeros
-		formatted := fmt.Sprintf("%.2f", seconds)
-		// Remove trailing zeros
-		formatted = strings.TrimRight(formatted, "0")
-		// Remove trailing decimal point if all decimals were zeros
-		formatted = strings.TrimRight(formatted, ".")
-		return formatted + "s"
+	if stats.CPUTime > 0 {
+		fmt.Fprintf(r.writer, "  CPU Time: %s\n", FormatDuration(stats.CPUTime))
+	}
+	switch len(stats.AttemptDurations) {
+	case 0:
+	case 1:
+		fmt.Fprintf(r.writer, "  Attempt Duration: %s\n", FormatDuration(stats.AttemptDurations[0]))
+	default:
+		fmt.Fprintf(r.writer, "  Attempt Duration: p50 %s, p95 %s\n",
+			FormatDuration(stats.AttemptDurationPercentile(50)), FormatDuration(stats.AttemptDurationPercentile(95)))
 	}
 
-	// Handle longer durations
-	hours := d / time.Hour
-	minutes := (d % time.Hour) / time.Minute
-	seconds := (d % time.Minute) / time.Second
+	if r.verbose && stats.RateLimit != nil {
+		r.rateLimitSummary(stats.RateLimit)
+	}
+	if len(stats.PatternMetrics) > 0 {
+		r.patternMetricsSummary(stats.PatternMetrics)
+	}
+}
 
-	if hours > 0 {
-		if minutes > 0 && seconds > 0 {
-			return fmt.Sprintf("%dh%dm%ds", hours, minutes, seconds)
-		} else if minutes > 0 {
-			return fmt.Sprintf("%dh%dm", hours, minutes)
-		} else if seconds > 0 {
-			return fmt.Sprintf("%dh%ds", hours, seconds)
-		}
-		return fmt.Sprintf("%dh", hours)
+// rateLimitSummary reports the rate limit the server described during the run
+func (r *Reporter) rateLimitSummary(rl *metrics.RateLimitSummary) {
+	fmt.Fprintf(r.writer, "\nDiscovered Rate Limit:\n")
+	fmt.Fprintf(r.writer, "  Limit: %d\n", rl.Limit)
+	fmt.Fprintf(r.writer, "  Remaining: %d\n", rl.Remaining)
+	if rl.ResetUnix > 0 {
+		fmt.Fprintf(r.writer, "  Reset: %s\n", time.Unix(rl.ResetUnix, 0).UTC().Format(time.RFC3339))
 	}
+	fmt.Fprintf(r.writer, "  Source: %s (confidence %.2f)\n", rl.Source, rl.Confidence)
+}
 
-	if minutes > 0 {
-		if seconds > 0 {
-			return fmt.Sprintf("%dm%ds", minutes, seconds)
-		}
-		return fmt.Sprintf("%dm", minutes)
+// patternMetricsSummary reports how often each pattern was matched and how
+// long matching took, in name order
+func (r *Reporter) patternMetricsSummary(byName map[string]patterns.MatchMetrics) {
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
 	}
+	sort.Strings(names)
 
-	return fmt.Sprintf("%ds", seconds)
+	fmt.Fprintf(r.writer, "\nPattern Metrics:\n")
+	for _, name := range names {
+		m := byName[name]
+		fmt.Fprintf(r.writer, "  %s: %d match call(s), %d matched, %d missed, avg %s, total %s\n",
+			name, m.TotalMatches, m.SuccessfulMatches, m.FailedMatches,
+			FormatDuration(m.AverageMatchTime), FormatDuration(m.TotalMatchTime))
+	}
 }
 
 // NewRunStats creates a new run statistics tracker
@@ -176,6 +271,31 @@ func (s *RunStats) RecordAttemptEnd(success bool, reason string) {
 	}
 }
 
+// RecordAttemptDuration records how long an attempt's command ran
+func (s *RunStats) RecordAttemptDuration(duration time.Duration) {
+	s.AttemptDurations = append(s.AttemptDurations, duration)
+}
+
+// RecordAttemptUsage records the peak memory and CPU time of an attempt's
+// command, keeping the highest peak and the total CPU time
+func (s *RunStats) RecordAttemptUsage(peakRSS uint64, cpuTime time.Duration) {
+	s.PeakRSS = max(s.PeakRSS, peakRSS)
+	s.CPUTime += cpuTime
+}
+
+// AttemptDurationPercentile returns the p-th percentile (0-100) of the recorded
+// attempt durations by the nearest-rank method, or 0 when none were recorded.
+// A single attempt is every percentile.
+func (s *RunStats) AttemptDurationPercentile(p float64) time.Duration {
+	if len(s.AttemptDurations) == 0 {
+		return 0
+	}
+	sorted := make([]time.Duration, len(s.AttemptDurations))
+	copy(sorted, s.AttemptDurations)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return backoff.DurationPercentile(sorted, p)
+}
+
 // Finalize calculates final statistics
 func (s *RunStats) Finalize(success bool, finalReason string) {
 	s.Success = success
@@ -185,16 +305,27 @@ func (s *RunStats) Finalize(success bool, finalReason string) {
 
 // ShowWarning displays a warning message
 func (r *Reporter) ShowWarning(message string) {
+	r.emit(Event{Event: "warning", Message: message})
 	if r.quiet {
 		return
 	}
-	fmt.Fprintf(r.writer, "[warning] %s\n", message)
+	fmt.Fprintf(r.writer, "%s %s\n", r.paint(colorYellow, "[warning]"), message)
+}
+
+// ShowNote displays an informational message in verbose mode only
+func (r *Reporter) ShowNote(message string) {
+	r.emit(Event{Event: "note", Message: message})
+	if r.quiet || !r.verbose {
+		return
+	}
+	fmt.Fprintf(r.writer, "[note] %s\n", message)
 }
 
 // ShowWaiting displays a waiting message with duration
 func (r *Reporter) ShowWaiting(duration time.Duration, message string) {
+	r.emit(Event{Event: "waiting", Message: message, WaitSeconds: seconds(duration)})
 	if r.quiet {
 		return
 	}
-	fmt.Fprintf(r.writer, "[waiting] %s (waiting %s)\n", message, r.formatDuration(duration))
+	fmt.Fprintf(r.writer, "[waiting] %s (waiting %s)\n", message, FormatDuration(duration))
 }
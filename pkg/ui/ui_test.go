@@ -2,10 +2,16 @@ package ui
 
 import (
 	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/shaneisley/patience/pkg/metrics"
+	"github.com/shaneisley/patience/pkg/patterns"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestReporter_AttemptStart(t *testing.T) {
@@ -153,13 +159,110 @@ func TestReporter_FinalSuccess_SingleAttempt(t *testing.T) {
 	assert.Contains(t, output, "✅ [retry] Command succeeded after 1 attempt.")
 }
 
+func TestReporter_FinalSummary_RateLimitVerboseOnly(t *testing.T) {
+	// Given run statistics with a discovered rate limit
+	stats := &RunStats{
+		TotalAttempts: 2,
+		FailedRuns:    2,
+		FinalReason:   "max retries reached",
+		RateLimit: &metrics.RateLimitSummary{
+			Limit:      5000,
+			Remaining:  0,
+			ResetUnix:  1760000000,
+			Source:     "http_header",
+			Confidence: 0.8,
+		},
+	}
+
+	// When reporting without verbose mode
+	var quietBuf bytes.Buffer
+	NewReporter(&quietBuf).FinalSummary(stats)
+
+	// Then the rate limit is not shown
+	assert.NotContains(t, quietBuf.String(), "Discovered Rate Limit")
+
+	// When reporting in verbose mode
+	var buf bytes.Buffer
+	reporter := NewReporter(&buf)
+	reporter.SetVerbose(true)
+	reporter.FinalSummary(stats)
+
+	// Then the discovered values are listed
+	output := buf.String()
+	assert.Contains(t, output, "Discovered Rate Limit:")
+	assert.Contains(t, output, "Limit: 5000")
+	assert.Contains(t, output, "Remaining: 0")
+	assert.Contains(t, output, "Reset: 2025-10-09T08:53:20Z")
+	assert.Contains(t, output, "Source: http_header (confidence 0.80)")
+}
+
+func TestReporter_FinalSummary_PatternMetrics(t *testing.T) {
+	// Given run statistics carrying metrics for two patterns
+	stats := &RunStats{
+		TotalAttempts: 3,
+		FailedRuns:    2,
+		FinalReason:   "success pattern matched",
+		Success:       true,
+		PatternMetrics: map[string]patterns.MatchMetrics{
+			"success-pattern": {TotalMatches: 3, SuccessfulMatches: 1, FailedMatches: 2,
+				AverageMatchTime: 20 * time.Microsecond, TotalMatchTime: 60 * time.Microsecond},
+			"failure-pattern": {TotalMatches: 3, FailedMatches: 3,
+				AverageMatchTime: 5 * time.Microsecond, TotalMatchTime: 15 * time.Microsecond},
+		},
+	}
+
+	// When reporting the summary
+	var buf bytes.Buffer
+	NewReporter(&buf).FinalSummary(stats)
+
+	// Then each pattern is listed in name order after the statistics
+	output := buf.String()
+	assert.Contains(t, output, "Pattern Metrics:\n"+
+		"  failure-pattern: 3 match call(s), 0 matched, 3 missed, avg 5µs, total 15µs\n"+
+		"  success-pattern: 3 match call(s), 1 matched, 2 missed, avg 20µs, total 60µs\n")
+}
+
+func TestReporter_FinalSummary_ResourceUsage(t *testing.T) {
+	// Given two attempts whose commands reported their resource usage
+	stats := &RunStats{TotalAttempts: 2, FinalReason: "exit code 0", Success: true}
+	stats.RecordAttemptUsage(3<<19, 250*time.Millisecond)
+	stats.RecordAttemptUsage(1<<20, 1250*time.Millisecond)
+
+	// When reporting the summary
+	var buf bytes.Buffer
+	NewReporter(&buf).FinalSummary(stats)
+
+	// Then it shows the highest peak memory and the total CPU time
+	output := buf.String()
+	assert.Contains(t, output, "  Peak Memory: 1.5MiB\n")
+	assert.Contains(t, output, "  CPU Time: 1.5s\n")
+}
+
+func TestReporter_ShowNoteVerboseOnly(t *testing.T) {
+	// When a note is shown without verbose mode
+	var quietBuf bytes.Buffer
+	NewReporter(&quietBuf).ShowNote("retrying as a transient error")
+
+	// Then nothing is printed
+	assert.Empty(t, quietBuf.String())
+
+	// When a note is shown in verbose mode
+	var buf bytes.Buffer
+	reporter := NewReporter(&buf)
+	reporter.SetVerbose(true)
+	reporter.ShowNote("retrying as a transient error")
+
+	// Then it is printed as a note
+	assert.Equal(t, "[note] retrying as a transient error\n", buf.String())
+}
+
 func TestReporter_DurationFormatting(t *testing.T) {
 	tests := []struct {
 		name     string
 		duration time.Duration
 		expected string
 	}{
-		{"milliseconds", 500 * time.Millisecond, "0.5s"},
+		{"milliseconds", 500 * time.Millisecond, "500ms"},
 		{"seconds", 2 * time.Second, "2s"},
 		{"seconds with milliseconds", 2*time.Second + 500*time.Millisecond, "2.5s"},
 		{"minutes", 90 * time.Second, "1m30s"},
@@ -168,12 +271,8 @@ func TestReporter_DurationFormatting(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Given a reporter
-			var buf bytes.Buffer
-			reporter := NewReporter(&buf)
-
 			// When formatting duration
-			formatted := reporter.formatDuration(tt.duration)
+			formatted := FormatDuration(tt.duration)
 
 			// Then it should match expected format
 			assert.Equal(t, tt.expected, formatted)
@@ -210,6 +309,27 @@ func TestReporter_Quiet_Mode(t *testing.T) {
 	assert.Contains(t, output, "✅ [retry] Command succeeded after 3 attempts.")
 }
 
+func TestReporter_SummaryOnlyOnFailure(t *testing.T) {
+	// Given a reporter that prints the summary only for failed runs
+	var buf bytes.Buffer
+	reporter := NewReporter(&buf)
+	reporter.SetSummaryOnlyOnFailure(true)
+
+	// When a successful run finishes
+	reporter.FinalSummary(&RunStats{TotalAttempts: 1, SuccessfulRuns: 1, FinalReason: "exit code 0", Success: true})
+
+	// Then nothing should be printed
+	assert.Empty(t, buf.String())
+
+	// When a failed run finishes
+	reporter.FinalSummary(&RunStats{TotalAttempts: 2, FailedRuns: 2, FinalReason: "max retries reached"})
+
+	// Then the summary should be printed
+	output := buf.String()
+	assert.Contains(t, output, "❌ [retry] Command failed after 2 attempts.")
+	assert.Contains(t, output, "Run Statistics:")
+}
+
 func TestRunStats_CalculateStats(t *testing.T) {
 	// Given a new run stats tracker
 	stats := NewRunStats()
@@ -235,3 +355,233 @@ func TestRunStats_CalculateStats(t *testing.T) {
 	assert.Equal(t, "exit code 0", stats.FinalReason)
 	assert.True(t, stats.TotalDuration > 0)
 }
+
+func TestReporter_ConfirmWait(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		interactive bool
+		expected    bool
+		prompted    bool
+	}{
+		{name: "declined on TTY", input: "n\n", interactive: true, expected: false, prompted: true},
+		{name: "accepted on TTY", input: "y\n", interactive: true, expected: true, prompted: true},
+		{name: "empty answer defaults to no", input: "\n", interactive: true, expected: false, prompted: true},
+		{name: "non-TTY skips prompt", input: "n\n", interactive: false, expected: true, prompted: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given a reporter with injected input
+			var buf bytes.Buffer
+			reporter := NewReporter(&buf)
+			reporter.SetInput(strings.NewReader(tt.input), tt.interactive)
+
+			// When confirming a long wait
+			confirmed := reporter.ConfirmWait(90 * time.Second)
+
+			// Then the answer should be honored only on a TTY
+			assert.Equal(t, tt.expected, confirmed)
+			if tt.prompted {
+				assert.Contains(t, buf.String(), "Wait 1m30s before retry? [y/N]")
+			} else {
+				assert.Empty(t, buf.String())
+			}
+		})
+	}
+}
+
+// reportRun drives a reporter through a two-attempt run that fails then succeeds
+func reportRun(reporter *Reporter) {
+	reporter.AttemptStart(1, 3)
+	reporter.AttemptFailure(1, 3, "exit code 1", 2*time.Second)
+	reporter.ShowWarning("something odd")
+	reporter.AttemptStart(2, 3)
+	reporter.FinalSummary(&RunStats{TotalAttempts: 2, SuccessfulRuns: 1, FailedRuns: 1, Success: true, FinalReason: "exit code 0"})
+	reporter.RunResult(RunSummary{
+		Command:       "deploy",
+		Success:       true,
+		Reason:        "exit code 0",
+		TotalAttempts: 2,
+		Attempts: []metrics.AttemptMetric{
+			{ExitCode: 1, Success: false, Duration: time.Second},
+			{ExitCode: 0, Success: true, Duration: time.Second},
+		},
+	})
+}
+
+func TestRunStats_AttemptDurationPercentiles(t *testing.T) {
+	// Given attempts of known durations, recorded out of order
+	stats := NewRunStats()
+	for _, seconds := range []int{9, 1, 4, 2, 7, 3, 10, 5, 8, 6} {
+		stats.RecordAttemptDuration(time.Duration(seconds) * time.Second)
+	}
+
+	// Then percentiles should follow the nearest-rank method
+	assert.Equal(t, 5*time.Second, stats.AttemptDurationPercentile(50))
+	assert.Equal(t, 9*time.Second, stats.AttemptDurationPercentile(90))
+	assert.Equal(t, 10*time.Second, stats.AttemptDurationPercentile(95))
+	assert.Equal(t, 1*time.Second, stats.AttemptDurationPercentile(0))
+	assert.Equal(t, 10*time.Second, stats.AttemptDurationPercentile(100))
+
+	// And the recorded order should be preserved
+	assert.Equal(t, 9*time.Second, stats.AttemptDurations[0])
+}
+
+func TestRunStats_AttemptDurationPercentilesSingleAttempt(t *testing.T) {
+	// Given a single attempt
+	stats := NewRunStats()
+	stats.RecordAttemptDuration(1500 * time.Millisecond)
+
+	// Then every percentile is that attempt
+	assert.Equal(t, 1500*time.Millisecond, stats.AttemptDurationPercentile(50))
+	assert.Equal(t, 1500*time.Millisecond, stats.AttemptDurationPercentile(95))
+
+	// And the summary reports the single value
+	var buf bytes.Buffer
+	stats.Finalize(true, "exit code 0")
+	NewReporter(&buf).FinalSummary(stats)
+	assert.Contains(t, buf.String(), "  Attempt Duration: 1.5s\n")
+
+	// Without attempts there is nothing to report
+	assert.Equal(t, time.Duration(0), NewRunStats().AttemptDurationPercentile(50))
+}
+
+func TestReporter_FinalSummary_AttemptDurationPercentiles(t *testing.T) {
+	// Given a finished run with four attempts
+	stats := NewRunStats()
+	for _, d := range []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 20 * time.Second} {
+		stats.RecordAttemptDuration(d)
+	}
+	stats.Finalize(false, "max retries reached")
+
+	// When the summary is shown with ndjson output
+	var text, out bytes.Buffer
+	reporter := NewReporter(&text)
+	reporter.SetJSONOutput(OutputJSONEvents, &out)
+	reporter.FinalSummary(stats)
+
+	// Then p50 and p95 appear in text and JSON
+	assert.Contains(t, text.String(), "  Attempt Duration: p50 2s, p95 20s\n")
+	assert.Contains(t, out.String(), `"attempt_duration_p50_seconds":2`)
+	assert.Contains(t, out.String(), `"attempt_duration_p95_seconds":20`)
+
+	// And the ndjson summary carries them too
+	var summary RunSummary
+	summary.SetStats(stats)
+	require.NotNil(t, summary.AttemptDurationP50)
+	assert.Equal(t, 2.0, *summary.AttemptDurationP50)
+	assert.Equal(t, 20.0, *summary.AttemptDurationP95)
+}
+
+func TestReporter_NDJSONOutput(t *testing.T) {
+	// Given a reporter in ndjson mode
+	var text, out bytes.Buffer
+	reporter := NewReporter(&text)
+	reporter.SetJSONOutput(OutputNDJSON, &out)
+
+	// When a run is reported
+	reportRun(reporter)
+
+	// Then exactly one object with the summary and attempts array is written
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(t, lines, 1)
+	var summary map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &summary))
+	assert.Equal(t, true, summary["success"])
+	assert.Equal(t, float64(2), summary["total_attempts"])
+	assert.Len(t, summary["attempts"], 2)
+	assert.NotContains(t, summary, "event")
+
+	// And human-readable messages stay on the reporter's writer
+	assert.Contains(t, text.String(), "[retry] Attempt 1/3 failed")
+}
+
+func TestReporter_JSONEventsOutput(t *testing.T) {
+	// Given a reporter in json-events mode
+	var text, out bytes.Buffer
+	reporter := NewReporter(&text)
+	reporter.SetJSONOutput(OutputJSONEvents, &out)
+
+	// When a run is reported
+	reportRun(reporter)
+
+	// Then one event object is streamed per event, ending with run_end
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	var events []string
+	for _, line := range lines {
+		var event map[string]interface{}
+		assert.NoError(t, json.Unmarshal([]byte(line), &event))
+		assert.NotContains(t, event, "attempts")
+		events = append(events, event["event"].(string))
+	}
+	assert.Equal(t, []string{"attempt_start", "attempt_failure", "warning", "attempt_start", "run_end"}, events)
+	assert.Contains(t, lines[1], `"next_delay_seconds":2`)
+	assert.Contains(t, lines[4], `"success":true`)
+}
+
+func TestReporter_TextOutputWritesNoJSON(t *testing.T) {
+	// Given a reporter in text mode
+	var text, out bytes.Buffer
+	reporter := NewReporter(&text)
+	reporter.SetJSONOutput(OutputText, &out)
+
+	// When a run is reported
+	reportRun(reporter)
+
+	// Then nothing is written to the JSON writer
+	assert.Empty(t, out.String())
+	assert.Contains(t, text.String(), "Run Statistics:")
+}
+
+func TestReporter_ColorNeverReachesJSON(t *testing.T) {
+	for _, mode := range []string{OutputNDJSON, OutputJSONEvents} {
+		t.Run(mode, func(t *testing.T) {
+			// Given a colored reporter with JSON output
+			var text, out bytes.Buffer
+			reporter := NewReporter(&text)
+			reporter.SetColor(true)
+			reporter.SetJSONOutput(mode, &out)
+
+			// When a run is reported
+			reportRun(reporter)
+
+			// Then the messages are colored but the JSON has no escape sequences
+			assert.Contains(t, text.String(), "\x1b[")
+			assert.NotEmpty(t, out.String())
+			assert.NotContains(t, out.String(), "\x1b")
+			for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+				assert.True(t, json.Valid([]byte(line)), line)
+			}
+		})
+	}
+}
+
+func TestReporter_ColoredMessages(t *testing.T) {
+	// Given a colored reporter
+	var text bytes.Buffer
+	reporter := NewReporter(&text)
+	reporter.SetColor(true)
+
+	// When a run is reported
+	reportRun(reporter)
+
+	// Then failures, warnings and the outcome are colored
+	assert.Contains(t, text.String(), "[retry] Attempt 1/3 \x1b[31mfailed\x1b[0m (exit code 1)")
+	assert.Contains(t, text.String(), "\x1b[33m[warning]\x1b[0m something odd")
+	assert.Contains(t, text.String(), "✅ [retry] \x1b[32mCommand succeeded\x1b[0m after 2 attempts.")
+}
+
+func TestUseColor(t *testing.T) {
+	// Given output that is not a terminal
+	f, err := os.CreateTemp(t.TempDir(), "out")
+	require.NoError(t, err)
+	defer f.Close()
+	t.Setenv("NO_COLOR", "")
+
+	// When each mode is resolved, Then only always colors it
+	assert.True(t, UseColor(ColorAlways, f))
+	assert.False(t, UseColor(ColorAuto, f))
+	assert.False(t, UseColor(ColorNever, f))
+	assert.False(t, ValidColorMode("sometimes"))
+}